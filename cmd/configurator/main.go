@@ -21,12 +21,15 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
-	"strings"
 
-	"github.com/pkg/errors"
+	"github.com/dptech-corp/wlm-operator/pkg/operator/controller"
+	"github.com/dptech-corp/wlm-operator/pkg/slurm"
 	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/pkg/errors"
 	"golang.org/x/sys/unix"
 	"google.golang.org/grpc"
 	v1 "k8s.io/api/core/v1"
@@ -123,11 +126,16 @@ func watchPartitions(ctx context.Context, wg *sync.WaitGroup,
 				log.Printf("Can't get virtual nodes %s", err)
 				continue
 			}
-			// extract partition names from k8s nodes
+			// extract partition names from k8s nodes, nodes are labeled
+			// with partition names in the label form
 			nNames := partitionNames(nodes.Items)
+			partitions := make([]string, len(partitionsResp.Partition))
+			for i, p := range partitionsResp.Partition {
+				partitions[i] = slurm.PartitionLabelValue(p)
+			}
 
 			// check which partitions are not yet represented in k8s
-			partitionToCreate := notIn(partitionsResp.Partition, nNames)
+			partitionToCreate := notIn(partitions, nNames)
 			// creating pods for that partitions
 			if err := createNodeForPartitions(k8sClient, partitionToCreate); err != nil {
 				log.Printf("Can't create partitions  %s", err)
@@ -135,10 +143,16 @@ func watchPartitions(ctx context.Context, wg *sync.WaitGroup,
 
 			// some partitions can be deleted from SLURM, so we need to delete pods
 			// which represent those deleted partitions
-			nodesToDelete := notIn(nNames, partitionsResp.Partition)
+			nodesToDelete := notIn(nNames, partitions)
 			if err := deleteControllingPod(k8sClient, nodesToDelete); err != nil {
 				log.Printf("Can't delete controlling pod %s", err)
 			}
+
+			// virtual kubelet knows nothing about node classes, GPUs and
+			// free capacity, so configurator labels virtual nodes with them
+			if err := labelNodes(slurmClient, k8sClient, nodes.Items); err != nil {
+				log.Printf("Can't label virtual nodes %s", err)
+			}
 		}
 	}
 }
@@ -167,8 +181,112 @@ func deleteControllingPod(podsGetter corev1.PodsGetter, nodes []string) error {
 	return nil
 }
 
+// labelNodes sets node class, GPU and capacity labels on virtual nodes according
+// to partition resources. Labels of the largest class except GPUs are set by
+// virtual kubelet itself.
+func labelNodes(slurmClient api.WorkloadManagerClient, nodesGetter corev1.NodesGetter, nodes []v1.Node) error {
+	for _, n := range nodes {
+		p, ok := n.Labels[controller.PartitionLabel]
+		if !ok {
+			continue
+		}
+
+		resources, err := slurmClient.Resources(context.Background(), &api.ResourcesRequest{Partition: p})
+		if err != nil {
+			return errors.Wrapf(err, "could not get resources for %s partition", p)
+		}
+
+		labels := nodeClassLabels(resources)
+		for k, v := range capacityLabels(resources.Capacity) {
+			labels[k] = v
+		}
+		changed := false
+		for k := range n.Labels {
+			if _, ok := labels[k]; !ok && isManagedLabel(k) {
+				delete(n.Labels, k)
+				changed = true
+			}
+		}
+		for k, v := range labels {
+			if n.Labels[k] != v {
+				n.Labels[k] = v
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		log.Printf("Labeling node %s with %v", n.Name, labels)
+		if _, err := nodesGetter.Nodes().Update(&n); err != nil {
+			return errors.Wrapf(err, "could not update node %s", n.Name)
+		}
+	}
+	return nil
+}
+
+// nodeClassLabels forms node labels for partition node classes. Classes beyond
+// controller.MaxNodeClasses are not labeled. For each class the total number
+// of GPUs per node is set with gpu label and the number of GPUs of each type
+// is set with gpu-<type> label.
+func nodeClassLabels(resources *api.ResourcesResponse) map[string]string {
+	classes := resources.NodeClasses
+	if len(classes) == 0 {
+		// partition gres are reported without classes by older red-box
+		classes = []*api.NodeClass{{Gres: resources.Features}}
+	}
+	if len(classes) > controller.MaxNodeClasses {
+		classes = classes[:controller.MaxNodeClasses]
+	}
+
+	labels := make(map[string]string)
+	for i, c := range classes {
+		if i != 0 {
+			labels[controller.NodeClassLabel(i, "nodes")] = strconv.FormatInt(c.Nodes, 10)
+			labels[controller.NodeClassLabel(i, "cpu-per-node")] = strconv.FormatInt(c.CpuPerNode, 10)
+			labels[controller.NodeClassLabel(i, "mem-per-node")] = strconv.FormatInt(c.MemPerNode, 10)
+		}
+
+		gpus := make(map[string]int64)
+		for _, g := range c.Gres {
+			if g.Name != "gpu" {
+				continue
+			}
+			gpus[controller.GPULabel(i, "")] += g.Quantity
+			if g.Version != "" {
+				gpus[controller.GPULabel(i, g.Version)] += g.Quantity
+			}
+		}
+		for k, v := range gpus {
+			labels[k] = strconv.FormatInt(v, 10)
+		}
+	}
+	return labels
+}
+
+// capacityLabels forms node labels for free partition capacity.
+func capacityLabels(c *api.Capacity) map[string]string {
+	if c == nil {
+		return nil
+	}
+	return map[string]string{
+		"wlm.sylabs.io/idle-nodes": strconv.FormatInt(c.IdleNodes, 10),
+		"wlm.sylabs.io/idle-cpus":  strconv.FormatInt(c.IdleCpus, 10),
+		"wlm.sylabs.io/idle-mem":   strconv.FormatInt(c.IdleMem, 10),
+		"wlm.sylabs.io/idle-gpus":  strconv.FormatInt(c.IdleGpus, 10),
+	}
+}
+
+// isManagedLabel checks if label is managed by configurator.
+func isManagedLabel(label string) bool {
+	return strings.HasPrefix(label, "wlm.sylabs.io/class-") ||
+		strings.HasPrefix(label, "wlm.sylabs.io/idle-") ||
+		strings.HasPrefix(label, controller.GPULabel(0, ""))
+}
+
 // virtualKubeletPodTemplate returns filled pod model ready to be created in k8s.
 // Kubelet pod will create virtual node that will be responsible for handling Slurm jobs.
+// Partition name is expected in the label form, since virtual kubelet labels the node with it.
 func virtualKubeletPodTemplate(partitionName, nodeName string) *v1.Pod {
 	return &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -309,7 +427,7 @@ func virtualKubeletPodTemplate(partitionName, nodeName string) *v1.Pod {
 func partitionNames(nodes []v1.Node) []string {
 	names := make([]string, 0)
 	for _, n := range nodes {
-		if l, ok := n.Labels["wlm.sylabs.io/partition"]; ok {
+		if l, ok := n.Labels[controller.PartitionLabel]; ok {
 			names = append(names, l)
 		}
 	}
@@ -341,7 +459,8 @@ func contains(s []string, e string) bool {
 	return false
 }
 
-// partitionNodeName forms partition name that will be used as pod and node name in k8s
+// partitionNodeName forms partition name that will be used as pod and node name in k8s.
+// Partition name is expected in the label form, which is a valid part of k8s names.
 func partitionNodeName(partition, node string) string {
 	return fmt.Sprintf("slurm-%s-%s", node, strings.ToLower(partition))
 }
@@ -26,8 +26,14 @@ import (
 	"github.com/operator-framework/operator-sdk/pkg/metrics"
 	sdkVersion "github.com/operator-framework/operator-sdk/version"
 	"github.com/dptech-corp/wlm-operator/pkg/operator/apis"
+	"github.com/dptech-corp/wlm-operator/pkg/operator/controller"
+	"github.com/dptech-corp/wlm-operator/pkg/operator/controller/cleanup"
+	"github.com/dptech-corp/wlm-operator/pkg/operator/controller/slurmcronjob"
 	"github.com/dptech-corp/wlm-operator/pkg/operator/controller/slurmjob"
+	"github.com/dptech-corp/wlm-operator/pkg/operator/controller/slurmworkflow"
 	"github.com/dptech-corp/wlm-operator/pkg/operator/controller/wlmjob"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"google.golang.org/grpc"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -40,8 +46,20 @@ var (
 
 	metricsHost       = "0.0.0.0"
 	metricsPort int32 = 8383
+
+	redBox = flag.String("red-box", "", "optional red-box address, e.g. unix:///var/run/syslurm/red-box.sock, "+
+		"when set job status is filled from the job info reported by workload manager")
+	cancelTimeout = flag.Duration("cancel-timeout", controller.DefaultCancelTimeout,
+		"how long to wait for a job to be cancelled before deleted job object is removed")
+
+	ttlDefaults = controller.TTLDefaults{}
 )
 
+func init() {
+	flag.Var(ttlDefaults, "ttl-after-finished", "default time to keep finished jobs that don't set ttlSecondsAfterFinished, "+
+		"either for all namespaces, e.g. 24h, or for a namespace, e.g. dev=1h; may be repeated")
+}
+
 func printVersion() {
 	glog.Infof("Go Version: %s", runtime.Version())
 	glog.Infof("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH)
@@ -93,16 +111,47 @@ func main() {
 		glog.Fatalf("Failed to add manager to apis scheme: %v", err)
 	}
 
-	sj := slurmjob.NewReconciler(mgr)
+	if err := controller.RegisterMetrics(mgr.GetClient()); err != nil {
+		glog.Fatalf("Failed to register job metrics: %v", err)
+	}
+
+	jobCfg := controller.Config{CancelTimeout: *cancelTimeout}
+	if *redBox != "" {
+		conn, err := grpc.Dial(*redBox, grpc.WithInsecure())
+		if err != nil {
+			glog.Fatalf("Failed to connect to red-box: %v", err)
+		}
+		defer conn.Close()
+		jobCfg.WLM = api.NewWorkloadManagerClient(conn)
+	}
+
+	sj := slurmjob.NewReconciler(mgr, jobCfg)
 	if err := sj.AddToManager(mgr); err != nil {
 		glog.Fatalf("Failed to add slurm job controller to manager: %v", err)
 	}
 
-	wj := wlmjob.NewReconciler(mgr)
+	wj := wlmjob.NewReconciler(mgr, jobCfg)
 	if err := wj.AddToManager(mgr); err != nil {
 		glog.Fatalf("Failed to add wlm job controller to manager: %v", err)
 	}
 
+	scj := slurmcronjob.NewReconciler(mgr)
+	if err := scj.AddToManager(mgr); err != nil {
+		glog.Fatalf("Failed to add slurm cron job controller to manager: %v", err)
+	}
+
+	swf := slurmworkflow.NewReconciler(mgr)
+	if err := swf.AddToManager(mgr); err != nil {
+		glog.Fatalf("Failed to add slurm workflow controller to manager: %v", err)
+	}
+
+	for _, kind := range []string{controller.KindSlurmJob, controller.KindWlmJob} {
+		c := cleanup.NewReconciler(mgr, kind, ttlDefaults)
+		if err := c.AddToManager(mgr); err != nil {
+			glog.Fatalf("Failed to add %s cleanup controller to manager: %v", kind, err)
+		}
+	}
+
 	// Create Service object to expose the metrics port.
 	_, err = metrics.ExposeMetricsPort(ctx, metricsPort)
 	if err != nil {
@@ -17,30 +17,58 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/pkg/errors"
 	sgrpc "github.com/dptech-corp/wlm-operator/internal/red-box/api"
+	"github.com/dptech-corp/wlm-operator/internal/red-box/store"
+	"github.com/dptech-corp/wlm-operator/pkg/local"
+	"github.com/dptech-corp/wlm-operator/pkg/remote"
+	"github.com/dptech-corp/wlm-operator/pkg/sge"
 	"github.com/dptech-corp/wlm-operator/pkg/slurm"
+	"github.com/dptech-corp/wlm-operator/pkg/slurm/fake"
 	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
 	"golang.org/x/sys/unix"
 	"google.golang.org/grpc"
 	"gopkg.in/yaml.v2"
 )
 
-var version = "unknown"
+var (
+	version = "unknown"
+
+	statePath = flag.String("state", "", "path to a database where red-box keeps its state, submissions are not idempotent and job history is not kept if empty")
+
+	localDir   = flag.String("local-dir", "/var/lib/red-box", "directory where local jobs are stored")
+	localSlots = flag.Int("local-slots", runtime.NumCPU(), "how many local jobs may run simultaneously")
+
+	sshAddr       = flag.String("ssh-addr", "", "host:port of a slurm login node to run slurm commands on over ssh, slurm is called locally if empty")
+	sshUser       = flag.String("ssh-user", "", "user to log in to the slurm login node as")
+	sshKey        = flag.String("ssh-key", "/etc/red-box/ssh/id_rsa", "path to a private key for ssh authentication")
+	sshKnownHosts = flag.String("ssh-known-hosts", "", "path to a known_hosts file to verify login node key with, key is not verified if empty")
+	sshPoolSize   = flag.Int("ssh-pool-size", 4, "maximum number of ssh connections to the login node")
+	sshTimeout    = flag.Duration("ssh-timeout", 10*time.Second, "ssh connection timeout")
+
+	fakeSlurm       = flag.Bool("fake", false, "serve in-memory slurm simulator instead of a real slurm cluster")
+	fakeDir         = flag.String("fake-dir", filepath.Join(os.TempDir(), "red-box-fake"), "directory where fake job output is written")
+	fakeJobDuration = flag.Duration("fake-job-duration", 30*time.Second, "how long fake jobs run by default")
+)
 
 func main() {
 	fmt.Printf("version: %s\n", version)
 
 	configPath := flag.String("config", "", "path to a red-box config")
 	sock := flag.String("socket", "/var/run/syslurm/red-box.sock", "unix socket to serve slurm API")
+	wlm := flag.String("wlm", "slurm", "workload manager to serve API for: slurm, sge or local")
 	flag.Parse()
 
 	config, err := config(*configPath)
@@ -54,14 +82,27 @@ func main() {
 		log.Fatalf("Could not listen unix: %v", err)
 	}
 
-	c, err := slurm.NewClient()
+	var st *store.Store
+	if *statePath != "" {
+		st, err = store.Open(*statePath)
+		if err != nil {
+			log.Fatalf("Could not open state: %s", err)
+		}
+		defer st.Close()
+	} else {
+		log.Printf("State is not kept, submissions are not idempotent")
+	}
+
+	a, err := workloadManager(*wlm, st, config)
 	if err != nil {
-		log.Fatalf("Could not create slurm client: %s", err)
+		log.Fatalf("Could not create %s workload manager: %s", *wlm, err)
 	}
 
 	s := grpc.NewServer()
-	a := sgrpc.NewSlurm(c, config)
 	api.RegisterWorkloadManagerServer(s, a)
+	if c, ok := a.(io.Closer); ok {
+		defer c.Close()
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -81,17 +122,106 @@ func main() {
 	wg.Wait()
 }
 
+func workloadManager(wlm string, st *store.Store, config sgrpc.Config) (api.WorkloadManagerServer, error) {
+	switch wlm {
+	case "slurm":
+		if *fakeSlurm {
+			c, err := fake.NewSlurm(fakeConfig(config))
+			if err != nil {
+				return nil, errors.Wrap(err, "could not create fake slurm")
+			}
+			return sgrpc.NewSlurm(c, st, config), nil
+		}
+		if *sshAddr != "" {
+			p, err := remote.NewPool(remote.Config{
+				Addr:           *sshAddr,
+				User:           *sshUser,
+				KeyPath:        *sshKey,
+				KnownHostsPath: *sshKnownHosts,
+				PoolSize:       *sshPoolSize,
+				Timeout:        *sshTimeout,
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "could not create ssh connection pool")
+			}
+			c, err := slurm.NewRemoteClient(p, config.Clusters)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not create remote slurm client")
+			}
+			return sgrpc.NewSlurm(c, st, config), nil
+		}
+		c, err := slurm.NewClient(config.Clusters)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create slurm client")
+		}
+		return sgrpc.NewSlurm(c, st, config), nil
+	case "sge":
+		c, err := sge.NewClient()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create grid engine client")
+		}
+		return sgrpc.NewGridEngine(c, st, config), nil
+	case "local":
+		e, err := local.NewExecutor(*localDir, *localSlots)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create local executor")
+		}
+		return sgrpc.NewLocal(e, st, config), nil
+	default:
+		return nil, errors.Errorf("unknown workload manager %q", wlm)
+	}
+}
+
+// fakeConfig returns simulator configuration with partitions from red-box config.
+// Partition resources that are not configured are filled with defaults,
+// and a single debug partition is simulated when config is empty.
+func fakeConfig(config sgrpc.Config) fake.Config {
+	defaults := fake.Partition{
+		Nodes:      2,
+		CPUPerNode: 4,
+		MemPerNode: 4096,
+	}
+
+	partitions := make(map[string]fake.Partition, len(config.Partitions))
+	for name, pr := range config.Partitions {
+		p := fake.Partition{
+			Nodes:      pr.Nodes,
+			CPUPerNode: pr.CPUPerNode,
+			MemPerNode: pr.MemPerNode,
+			WallTime:   pr.WallTime,
+		}
+		if p.Nodes == 0 {
+			p.Nodes = defaults.Nodes
+		}
+		if p.CPUPerNode == 0 {
+			p.CPUPerNode = defaults.CPUPerNode
+		}
+		if p.MemPerNode == 0 {
+			p.MemPerNode = defaults.MemPerNode
+		}
+		partitions[name] = p
+	}
+	if len(partitions) == 0 {
+		partitions["debug"] = defaults
+	}
+
+	return fake.Config{
+		Partitions:  partitions,
+		JobDuration: *fakeJobDuration,
+		Dir:         *fakeDir,
+	}
+}
+
 func config(path string) (sgrpc.Config, error) {
 	if path == "" {
-		// sgrpc.Config is a map under the hood, and the default config is empty.
-		// Here we return default value for map - nil. This will make any further
-		// read successful, and fetched values will be empty PartitionResources.
-		return sgrpc.Config(nil), nil
+		// The default config is empty. Partitions map is nil, this will make
+		// any further read successful, and fetched values will be empty PartitionResources.
+		return sgrpc.Config{}, nil
 	}
 
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not open config file")
+		return sgrpc.Config{}, errors.Wrapf(err, "could not open config file")
 	}
 	defer file.Close()
 
@@ -0,0 +1,121 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// defaultCapacityInterval is used when capacity refresh interval is not configured.
+const defaultCapacityInterval = 30 * time.Second
+
+// capacityCache caches partition capacity. Capacity of partitions that have been
+// requested is refreshed in background once per refresh interval, so that requests
+// are answered from memory and workload manager is queried regardless of their rate.
+type capacityCache struct {
+	interval time.Duration
+	fetch    func(partition string) (*api.Capacity, error)
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*api.Capacity
+}
+
+func newCapacityCache(interval time.Duration, fetch func(partition string) (*api.Capacity, error)) *capacityCache {
+	if interval == 0 {
+		interval = defaultCapacityInterval
+	}
+	return &capacityCache{
+		interval: interval,
+		fetch:    fetch,
+		now:      time.Now,
+		entries:  make(map[string]*api.Capacity),
+	}
+}
+
+// get returns cached partition capacity. Capacity of a partition
+// requested for the first time is queried right away.
+func (c *capacityCache) get(partition string) (*api.Capacity, error) {
+	c.mu.Lock()
+	cached, ok := c.entries[partition]
+	c.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	capacity, err := c.query(partition)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[partition] = capacity
+	c.mu.Unlock()
+	return capacity, nil
+}
+
+// refresh queries capacity of all cached partitions. Capacity of a partition
+// that could not be queried is kept, its UpdatedAt tells how old it is.
+func (c *capacityCache) refresh() {
+	c.mu.Lock()
+	partitions := make([]string, 0, len(c.entries))
+	for partition := range c.entries {
+		partitions = append(partitions, partition)
+	}
+	c.mu.Unlock()
+
+	for _, partition := range partitions {
+		capacity, err := c.query(partition)
+		if err != nil {
+			log.Printf("Could not refresh capacity of partition %s: %s", partition, err)
+			continue
+		}
+		c.mu.Lock()
+		c.entries[partition] = capacity
+		c.mu.Unlock()
+	}
+}
+
+// run refreshes cached capacity once per refresh interval until stop is closed.
+func (c *capacityCache) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.refresh()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (c *capacityCache) query(partition string) (*api.Capacity, error) {
+	now := c.now()
+	capacity, err := c.fetch(partition)
+	if err != nil {
+		return nil, err
+	}
+	capacity.UpdatedAt, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, err
+	}
+	return capacity, nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapacityCache(t *testing.T) {
+	var calls int64
+	c := newCapacityCache(time.Minute, func(partition string) (*api.Capacity, error) {
+		calls++
+		return &api.Capacity{IdleNodes: calls}, nil
+	})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	capacity, err := c.get("debug")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, capacity.IdleNodes)
+	require.Equal(t, now.Unix(), capacity.UpdatedAt.Seconds)
+
+	capacity, err = c.get("debug")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, capacity.IdleNodes)
+
+	now = now.Add(time.Minute)
+	c.refresh()
+	require.EqualValues(t, 2, calls)
+	capacity, err = c.get("debug")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, capacity.IdleNodes)
+	require.Equal(t, now.Unix(), capacity.UpdatedAt.Seconds)
+
+	// capacity is kept if it could not be refreshed
+	c.fetch = func(partition string) (*api.Capacity, error) {
+		return nil, errors.New("slurmctld is not responding")
+	}
+	c.refresh()
+	capacity, err = c.get("debug")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, capacity.IdleNodes)
+
+	_, err = c.get("gpu")
+	require.Error(t, err)
+}
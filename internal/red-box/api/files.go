@@ -0,0 +1,173 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"io"
+	"log"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/files"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/pkg/errors"
+)
+
+// fileServer implements file related part of WorkloadManagerServer
+// and is shared between workload manager implementations.
+type fileServer struct {
+	fs files.FileSystem
+}
+
+// OpenFile opens requested file and return chunks with bytes.
+func (s *fileServer) OpenFile(r *api.OpenFileRequest, req api.WorkloadManager_OpenFileServer) error {
+	fd, err := s.fs.Open(r.Path)
+	if err != nil {
+		return errors.Wrapf(err, "could not open file at %s", r.Path)
+	}
+	defer fd.Close()
+
+	buff := make([]byte, 128)
+	for {
+		n, err := fd.Read(buff)
+		if n > 0 {
+			if err := req.Send(&api.Chunk{Content: buff[:n]}); err != nil {
+				return errors.Wrap(err, "could not send chunk")
+			}
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TailFile tails a file till close requested.
+// To start receiving file bytes client should send a request with file path and action start,
+// to stop client should send a request with action readToEndAndClose (file path is not required)
+// and after reaching end method will send EOF error.
+func (s *fileServer) TailFile(req api.WorkloadManager_TailFileServer) error {
+	r, err := req.Recv()
+	if err != nil {
+		return errors.Wrap(err, "could not receive request")
+	}
+
+	fd, err := s.fs.Tail(r.Path)
+	if err != nil {
+		return errors.Wrapf(err, "could not tail file at %s", r.Path)
+	}
+	defer func(p string) {
+		log.Printf("Tail file at %s finished", p)
+	}(r.Path)
+
+	requestCh := make(chan *api.TailFileRequest)
+	go func() {
+		r, err := req.Recv()
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("could not recive request err: %s", err)
+			}
+			return
+		}
+
+		requestCh <- r
+	}()
+
+	buff := make([]byte, 128)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return req.Context().Err()
+		case r := <-requestCh:
+			if r.Action == api.TailAction_ReadToEndAndClose {
+				_ = fd.Close()
+			}
+		case <-ticker.C:
+			n, err := fd.Read(buff)
+			if err != nil && n == 0 {
+				return err
+			}
+
+			if n == 0 {
+				continue
+			}
+
+			if err := req.Send(&api.Chunk{Content: buff[:n]}); err != nil {
+				return errors.Wrap(err, "could not send chunk")
+			}
+		}
+	}
+}
+
+// CreateFile write chunks with bytes to requested file.
+func (s *fileServer) CreateFile(req api.WorkloadManager_CreateFileServer) error {
+	r, err := req.Recv()
+	if err != nil {
+		return errors.Wrap(err, "could not receive request")
+	}
+	fd, err := s.fs.Create(r.Path)
+	if err != nil {
+		return errors.Wrapf(err, "could not open file at %s", r.Path)
+	}
+	defer fd.Close()
+
+	for {
+		r, err := req.Recv()
+
+		if r != nil && r.Content != nil {
+			if _, err := fd.Write(r.Content); err != nil {
+				log.Fatalf("can't write to file err: %s", err)
+			}
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+	}
+
+	err = req.SendAndClose(&api.CreateFileResponse{})
+	return nil
+}
+
+// Zip file or directory
+func (s *fileServer) Zip(ctx context.Context, req *api.ZipRequest) (*api.ZipResponse, error) {
+	if err := s.fs.Zip(req.Path, req.Target); err != nil {
+		return nil, errors.Wrapf(err, "could not zip")
+	}
+
+	return &api.ZipResponse{}, nil
+}
+
+// Unzip file
+func (s *fileServer) Unzip(ctx context.Context, req *api.UnzipRequest) (*api.UnzipResponse, error) {
+	if err := s.fs.Unzip(req.Source, req.Path); err != nil {
+		return nil, errors.Wrapf(err, "could not unzip")
+	}
+
+	return &api.UnzipResponse{}, nil
+}
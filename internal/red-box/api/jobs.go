@@ -0,0 +1,136 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/slurm"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultJobsStaleness is used when jobs snapshot staleness bound is not configured.
+const defaultJobsStaleness = 5 * time.Second
+
+// jobsSnapshot keeps info of all jobs known to workload manager, so that
+// job info requests are answered from memory. Slurm has no API to watch jobs,
+// so snapshot is taken again in background once per staleness bound and
+// replaces the previous one as a whole.
+type jobsSnapshot struct {
+	staleness time.Duration
+	fetch     func() ([]*slurm.JobInfo, error)
+	now       func() time.Time
+
+	mu      sync.Mutex
+	takenAt time.Time
+	jobs    map[string][]*slurm.JobInfo
+}
+
+func newJobsSnapshot(staleness time.Duration, fetch func() ([]*slurm.JobInfo, error)) *jobsSnapshot {
+	if staleness == 0 {
+		staleness = defaultJobsStaleness
+	}
+	return &jobsSnapshot{
+		staleness: staleness,
+		fetch:     fetch,
+		now:       time.Now,
+	}
+}
+
+// get returns info of a job from the snapshot. Like scontrol, info of a job array
+// contains the array job followed by its tasks. False is returned if job is not in
+// the snapshot, e.g. it was submitted after the snapshot had been taken, as well as
+// when there is no snapshot or it could not be refreshed for twice the staleness bound.
+func (s *jobsSnapshot) get(jobID int64) ([]*slurm.JobInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.jobs == nil || s.now().Sub(s.takenAt) >= 2*s.staleness {
+		return nil, false
+	}
+	infos, ok := s.jobs[strconv.FormatInt(jobID, 10)]
+	return infos, ok
+}
+
+// refresh takes a new snapshot. Workload manager is queried
+// without holding the lock, so requests are not blocked meanwhile.
+func (s *jobsSnapshot) refresh() error {
+	takenAt := s.now()
+	infos, err := s.fetch()
+	if err != nil {
+		return err
+	}
+	jobs := indexJobs(infos)
+
+	s.mu.Lock()
+	s.takenAt = takenAt
+	s.jobs = jobs
+	s.mu.Unlock()
+	return nil
+}
+
+// run takes a snapshot once per staleness bound until stop is closed.
+func (s *jobsSnapshot) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.staleness)
+	defer ticker.Stop()
+
+	for {
+		if err := s.refresh(); err != nil {
+			log.Printf("Could not take jobs snapshot: %s", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// indexJobs indexes job infos by job id. Array tasks are
+// additionally indexed by their array job id.
+func indexJobs(infos []*slurm.JobInfo) map[string][]*slurm.JobInfo {
+	jobs := make(map[string][]*slurm.JobInfo, len(infos))
+	for _, info := range infos {
+		jobs[info.ID] = append([]*slurm.JobInfo{info}, jobs[info.ID]...)
+		if info.ArrayJobID != "" && info.ArrayJobID != info.ID {
+			jobs[info.ArrayJobID] = append(jobs[info.ArrayJobID], info)
+		}
+	}
+	return jobs
+}
+
+// jobsInfo answers JobsInfo request with a JobInfo call for each requested job.
+func jobsInfo(ctx context.Context, req *api.JobsInfoRequest,
+	jobInfo func(context.Context, *api.JobInfoRequest) (*api.JobInfoResponse, error)) (*api.JobsInfoResponse, error) {
+
+	resp := &api.JobsInfoResponse{Jobs: make([]*api.JobInfoResponse, len(req.JobIds))}
+	for i, id := range req.JobIds {
+		info, err := jobInfo(ctx, &api.JobInfoRequest{JobId: id})
+		if status.Code(err) == codes.NotFound {
+			info, err = &api.JobInfoResponse{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp.Jobs[i] = info
+	}
+	return resp, nil
+}
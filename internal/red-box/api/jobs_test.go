@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/slurm"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestJobsSnapshot(t *testing.T) {
+	var snapshots int
+	s := newJobsSnapshot(time.Minute, func() ([]*slurm.JobInfo, error) {
+		snapshots++
+		return []*slurm.JobInfo{
+			{ID: "196", ArrayJobID: "192"},
+			{ID: "192", ArrayJobID: "192"},
+			{ID: "200"},
+		}, nil
+	})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	_, ok := s.get(192)
+	require.False(t, ok)
+
+	require.NoError(t, s.refresh())
+	infos, ok := s.get(192)
+	require.True(t, ok)
+	require.Equal(t, []*slurm.JobInfo{
+		{ID: "192", ArrayJobID: "192"},
+		{ID: "196", ArrayJobID: "192"},
+	}, infos)
+
+	infos, ok = s.get(200)
+	require.True(t, ok)
+	require.Equal(t, []*slurm.JobInfo{{ID: "200"}}, infos)
+
+	_, ok = s.get(201)
+	require.False(t, ok)
+	require.Equal(t, 1, snapshots)
+
+	// snapshot is kept if it could not be refreshed, until it is too old
+	fetch := s.fetch
+	s.fetch = func() ([]*slurm.JobInfo, error) {
+		return nil, errors.New("slurmctld is not responding")
+	}
+	now = now.Add(time.Minute)
+	require.Error(t, s.refresh())
+	_, ok = s.get(200)
+	require.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = s.get(200)
+	require.False(t, ok)
+
+	s.fetch = fetch
+	require.NoError(t, s.refresh())
+	_, ok = s.get(200)
+	require.True(t, ok)
+	require.Equal(t, 2, snapshots)
+}
+
+func TestJobsInfo(t *testing.T) {
+	resp, err := jobsInfo(context.Background(), &api.JobsInfoRequest{JobIds: []int64{1, 2}},
+		func(_ context.Context, req *api.JobInfoRequest) (*api.JobInfoResponse, error) {
+			if req.JobId == 2 {
+				return nil, status.Errorf(codes.NotFound, "job %d is not found", req.JobId)
+			}
+			return &api.JobInfoResponse{Info: []*api.JobInfo{{Id: "1"}}}, nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, []*api.JobInfoResponse{
+		{Info: []*api.JobInfo{{Id: "1"}}},
+		{},
+	}, resp.Jobs)
+}
@@ -0,0 +1,339 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/internal/red-box/store"
+	"github.com/dptech-corp/wlm-operator/pkg/files"
+	"github.com/dptech-corp/wlm-operator/pkg/local"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// localPartition is the only partition served by Local.
+const localPartition = "local"
+
+// Local implements WorkloadManagerServer by running batch scripts
+// directly on the host where red-box is started. The host is exposed
+// as a single partition with one node.
+type Local struct {
+	fileServer
+
+	uid         int64
+	cfg         Config
+	hostName    string
+	executor    *local.Executor
+	submissions *submissions
+	stop        chan struct{}
+}
+
+// NewLocal creates a new instance of Local. Store is used to persist
+// red-box state, submissions are not idempotent if it is nil.
+// Pruning of expired submissions is stopped with Close.
+func NewLocal(e *local.Executor, st *store.Store, cfg Config) *Local {
+	hostName, err := os.Hostname()
+	if err != nil {
+		hostName = "localhost"
+	}
+
+	l := &Local{
+		fileServer:  fileServer{fs: files.Local{}},
+		executor:    e,
+		cfg:         cfg,
+		hostName:    hostName,
+		uid:         int64(os.Geteuid()),
+		submissions: newSubmissions(st, cfg.SubmissionRetention),
+		stop:        make(chan struct{}),
+	}
+	go l.submissions.run(l.stop)
+	return l
+}
+
+// Close stops pruning of expired submissions.
+func (l *Local) Close() error {
+	close(l.stop)
+	return nil
+}
+
+// SubmitJob submits job and returns id of it in case of success.
+func (l *Local) SubmitJob(ctx context.Context, req *api.SubmitJobRequest) (*api.SubmitJobResponse, error) {
+	if err := checkLocalPartition(req.Partition); err != nil {
+		return nil, err
+	}
+
+	id, err := l.submissions.submit(newJob(req.ClientId, req.IdempotencyKey, req.Partition, req.Script, req), func() (int64, error) {
+		return l.executor.Submit(req.Script, "batch")
+	})
+	if err != nil {
+		return nil, submitError(err, "could not submit job script")
+	}
+
+	return &api.SubmitJobResponse{
+		JobId: id,
+	}, nil
+}
+
+// SubmitJobContainer starts a container from the provided image name inside a job script.
+// Requested resources are not enforced since job runs directly on the host.
+func (l *Local) SubmitJobContainer(ctx context.Context, r *api.SubmitJobContainerRequest) (*api.SubmitJobContainerResponse, error) {
+	if err := checkLocalPartition(r.Partition); err != nil {
+		return nil, err
+	}
+
+	lines := append([]string{"#!/bin/sh"}, singularityCommands(r)...)
+	script := strings.Join(lines, "\n")
+	id, err := l.submissions.submit(newJob(r.ClientId, r.IdempotencyKey, r.Partition, script, r), func() (int64, error) {
+		return l.executor.Submit(script, r.ImageName)
+	})
+	if err != nil {
+		return nil, submitError(err, "could not submit job script")
+	}
+
+	return &api.SubmitJobContainerResponse{
+		JobId: id,
+	}, nil
+}
+
+// ValidateJob only checks the partition, any script is accepted
+// and is started as soon as there is a free slot.
+func (l *Local) ValidateJob(ctx context.Context, req *api.SubmitJobRequest) (*api.ValidateJobResponse, error) {
+	if err := checkLocalPartition(req.Partition); err != nil {
+		return &api.ValidateJobResponse{Errors: []string{status.Convert(err).Message()}}, nil
+	}
+
+	return &api.ValidateJobResponse{
+		Valid:     true,
+		Partition: localPartition,
+	}, nil
+}
+
+// CancelJob cancels job.
+func (l *Local) CancelJob(ctx context.Context, req *api.CancelJobRequest) (*api.CancelJobResponse, error) {
+	if err := l.executor.Cancel(req.JobId); err != nil {
+		return nil, errors.Wrapf(err, "could not cancel job %d", req.JobId)
+	}
+
+	return &api.CancelJobResponse{}, nil
+}
+
+// HoldJob is not supported for the local executor.
+func (l *Local) HoldJob(ctx context.Context, req *api.HoldJobRequest) (*api.HoldJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job hold is not available for the local executor")
+}
+
+// ReleaseJob is not supported for the local executor.
+func (l *Local) ReleaseJob(ctx context.Context, req *api.ReleaseJobRequest) (*api.ReleaseJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job release is not available for the local executor")
+}
+
+// SuspendJob is not supported for the local executor.
+func (l *Local) SuspendJob(ctx context.Context, req *api.SuspendJobRequest) (*api.SuspendJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job suspension is not available for the local executor")
+}
+
+// ResumeJob is not supported for the local executor.
+func (l *Local) ResumeJob(ctx context.Context, req *api.ResumeJobRequest) (*api.ResumeJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job suspension is not available for the local executor")
+}
+
+// RequeueJob is not supported for the local executor.
+func (l *Local) RequeueJob(ctx context.Context, req *api.RequeueJobRequest) (*api.RequeueJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job requeue is not available for the local executor")
+}
+
+// JobInfo returns information about a job from the executor job table.
+func (l *Local) JobInfo(ctx context.Context, req *api.JobInfoRequest) (*api.JobInfoResponse, error) {
+	j, err := l.executor.Job(req.JobId)
+	if err == local.ErrJobNotFound {
+		return nil, status.Errorf(codes.NotFound, "job %d is not found", req.JobId)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get job %d info", req.JobId)
+	}
+
+	pi, err := l.toProtoInfo(j)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not convert job into proto info")
+	}
+
+	l.submissions.observe(req.JobId, pi)
+	return &api.JobInfoResponse{Info: []*api.JobInfo{pi}}, nil
+}
+
+// JobsInfo returns information about several jobs, see JobInfo.
+func (l *Local) JobsInfo(ctx context.Context, req *api.JobsInfoRequest) (*api.JobsInfoResponse, error) {
+	return jobsInfo(ctx, req, l.JobInfo)
+}
+
+// JobHistory returns jobs submitted through red-box.
+func (l *Local) JobHistory(ctx context.Context, req *api.JobHistoryRequest) (*api.JobHistoryResponse, error) {
+	return l.submissions.history(req)
+}
+
+// JobScript returns the script job was submitted with. Local jobs
+// inherit red-box environment, so no environment is returned.
+func (l *Local) JobScript(ctx context.Context, req *api.JobScriptRequest) (*api.JobScriptResponse, error) {
+	script, err := l.executor.Script(req.JobId)
+	if err == local.ErrJobNotFound {
+		return nil, status.Errorf(codes.NotFound, "job %d is not found", req.JobId)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get job %d script", req.JobId)
+	}
+
+	return &api.JobScriptResponse{Script: script}, nil
+}
+
+// JobSteps returns information about job steps. Local jobs have no
+// steps, so the job itself is returned as a single step.
+func (l *Local) JobSteps(ctx context.Context, req *api.JobStepsRequest) (*api.JobStepsResponse, error) {
+	j, err := l.executor.Job(req.JobId)
+	if err == local.ErrJobNotFound {
+		return nil, status.Errorf(codes.NotFound, "job %d is not found", req.JobId)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get job %d info", req.JobId)
+	}
+
+	startTime, err := protoTime(j.StartTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not convert start go time to proto time")
+	}
+	endTime, err := protoTime(j.EndTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not convert end go time to proto time")
+	}
+
+	return &api.JobStepsResponse{JobSteps: []*api.JobStepInfo{
+		{
+			Id:        strconv.FormatInt(j.ID, 10),
+			Name:      j.Name,
+			ExitCode:  int32(j.ExitCode),
+			Status:    protoStatus(j.State),
+			StartTime: startTime,
+			EndTime:   endTime,
+		},
+	}}, nil
+}
+
+// Resources return host resources.
+func (l *Local) Resources(_ context.Context, req *api.ResourcesRequest) (*api.ResourcesResponse, error) {
+	if err := checkLocalPartition(req.Partition); err != nil {
+		return nil, err
+	}
+
+	cpus, mem, err := local.HostResources()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get host resources")
+	}
+
+	discovered := &api.ResourcesResponse{
+		Nodes:      1,
+		CpuPerNode: cpus,
+		MemPerNode: mem,
+	}
+	return l.cfg.Partitions[localPartition].apply(discovered), nil
+}
+
+// Partitions returns the only local partition.
+func (l *Local) Partitions(context.Context, *api.PartitionsRequest) (*api.PartitionsResponse, error) {
+	return &api.PartitionsResponse{Partition: []string{localPartition}}, nil
+}
+
+// WorkloadInfo returns wlm info (name, version, red-box uid).
+// Host kernel release is reported as a version.
+func (l *Local) WorkloadInfo(context.Context, *api.WorkloadInfoRequest) (*api.WorkloadInfoResponse, error) {
+	const wlmName = "local"
+
+	version := "unknown"
+	if release, err := ioutil.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		version = strings.TrimSpace(string(release))
+	}
+
+	return &api.WorkloadInfoResponse{
+		Name:    wlmName,
+		Version: version,
+		Uid:     l.uid,
+	}, nil
+}
+
+func (l *Local) toProtoInfo(j *local.Job) (*api.JobInfo, error) {
+	submitTime, err := ptypes.TimestampProto(j.SubmitTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not convert submit go time to proto time")
+	}
+
+	startTime, err := protoTime(j.StartTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not convert start go time to proto time")
+	}
+
+	endTime, err := protoTime(j.EndTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not convert end go time to proto time")
+	}
+
+	var runTime *duration.Duration
+	switch {
+	case j.StartTime != nil && j.EndTime != nil:
+		runTime = ptypes.DurationProto(j.EndTime.Sub(*j.StartTime))
+	case j.StartTime != nil:
+		runTime = ptypes.DurationProto(time.Since(*j.StartTime))
+	}
+
+	var nodeList string
+	if j.StartTime != nil {
+		nodeList = l.hostName
+	}
+
+	return &api.JobInfo{
+		Id:         strconv.FormatInt(j.ID, 10),
+		UserId:     j.UserID,
+		Name:       j.Name,
+		ExitCode:   strconv.Itoa(j.ExitCode),
+		Status:     protoStatus(j.State),
+		State:      j.State,
+		SubmitTime: submitTime,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		RunTime:    runTime,
+		WorkingDir: j.WorkDir,
+		StdOut:     j.StdOut,
+		StdErr:     j.StdErr,
+		Partition:  localPartition,
+		NodeList:   nodeList,
+		BatchHost:  l.hostName,
+		NumNodes:   "1",
+		NumCpus:    1,
+	}, nil
+}
+
+func checkLocalPartition(partition string) error {
+	if partition != "" && partition != localPartition {
+		return status.Errorf(codes.InvalidArgument, "unknown partition %q", partition)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/local"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "red-box-local")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	e, err := local.NewExecutor(dir, 2)
+	require.NoError(t, err)
+	l := NewLocal(e, nil, Config{})
+
+	partitions, err := l.Partitions(context.Background(), &api.PartitionsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"local"}, partitions.Partition)
+
+	resources, err := l.Resources(context.Background(), &api.ResourcesRequest{Partition: "local"})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, resources.Nodes)
+	require.NotZero(t, resources.CpuPerNode)
+
+	_, err = l.SubmitJob(context.Background(), &api.SubmitJobRequest{Script: "#!/bin/sh\ntrue", Partition: "debug"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	submitted, err := l.SubmitJob(context.Background(), &api.SubmitJobRequest{Script: "#!/bin/sh\nexit 2", Partition: "local"})
+	require.NoError(t, err)
+
+	var info *api.JobInfo
+	for i := 0; i < 100; i++ {
+		resp, err := l.JobInfo(context.Background(), &api.JobInfoRequest{JobId: submitted.JobId})
+		require.NoError(t, err)
+		require.Len(t, resp.Info, 1)
+		info = resp.Info[0]
+		if info.Status == api.JobStatus_FAILED {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	require.Equal(t, api.JobStatus_FAILED, info.Status)
+	require.Equal(t, "2", info.ExitCode)
+	require.Equal(t, "local", info.Partition)
+
+	_, err = l.JobInfo(context.Background(), &api.JobInfoRequest{JobId: 100})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	script, err := l.JobScript(context.Background(), &api.JobScriptRequest{JobId: submitted.JobId})
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\nexit 2", script.Script)
+	_, err = l.JobScript(context.Background(), &api.JobScriptRequest{JobId: 100})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = l.SuspendJob(context.Background(), &api.SuspendJobRequest{JobId: submitted.JobId})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
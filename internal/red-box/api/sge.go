@@ -0,0 +1,377 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/internal/red-box/store"
+	"github.com/dptech-corp/wlm-operator/pkg/sge"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sgeParallelEnv is a parallel environment used to request
+// multiple slots for container jobs. It is expected to have
+// allocation rule $pe_slots, which is the case for a default
+// smp environment on most Grid Engine installations.
+const sgeParallelEnv = "smp"
+
+// GridEngine implements WorkloadManagerServer for Son of Grid Engine
+// and compatible workload managers. Grid Engine cluster queues are
+// exposed as partitions.
+type GridEngine struct {
+	fileServer
+
+	uid         int64
+	cfg         Config
+	client      *sge.Client
+	submissions *submissions
+	stop        chan struct{}
+}
+
+// NewGridEngine creates a new instance of GridEngine. Store is used to
+// persist red-box state, submissions are not idempotent if it is nil.
+// Pruning of expired submissions is stopped with Close.
+func NewGridEngine(c *sge.Client, st *store.Store, cfg Config) *GridEngine {
+	g := &GridEngine{
+		fileServer:  fileServer{fs: c},
+		client:      c,
+		cfg:         cfg,
+		uid:         int64(os.Geteuid()),
+		submissions: newSubmissions(st, cfg.SubmissionRetention),
+		stop:        make(chan struct{}),
+	}
+	go g.submissions.run(g.stop)
+	return g
+}
+
+// Close stops pruning of expired submissions.
+func (g *GridEngine) Close() error {
+	close(g.stop)
+	return nil
+}
+
+// SubmitJob submits job and returns id of it in case of success.
+func (g *GridEngine) SubmitJob(ctx context.Context, req *api.SubmitJobRequest) (*api.SubmitJobResponse, error) {
+	id, err := g.submissions.submit(newJob(req.ClientId, req.IdempotencyKey, req.Partition, req.Script, req), func() (int64, error) {
+		return g.client.QSub(req.Script, req.Partition)
+	})
+	if err != nil {
+		return nil, submitError(err, "could not submit job script")
+	}
+
+	return &api.SubmitJobResponse{
+		JobId: id,
+	}, nil
+}
+
+// SubmitJobContainer starts a container from the provided image name inside a job script.
+func (g *GridEngine) SubmitJobContainer(ctx context.Context, r *api.SubmitJobContainerRequest) (*api.SubmitJobContainerResponse, error) {
+	script, err := buildSGEScript(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not build job script")
+	}
+
+	id, err := g.submissions.submit(newJob(r.ClientId, r.IdempotencyKey, r.Partition, script, r), func() (int64, error) {
+		return g.client.QSub(script, r.Partition)
+	})
+	if err != nil {
+		return nil, submitError(err, "could not submit job script")
+	}
+
+	return &api.SubmitJobContainerResponse{
+		JobId: id,
+	}, nil
+}
+
+// ValidateJob is not supported for Grid Engine.
+func (g *GridEngine) ValidateJob(ctx context.Context, req *api.SubmitJobRequest) (*api.ValidateJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job validation is not available for grid engine")
+}
+
+// CancelJob cancels job.
+func (g *GridEngine) CancelJob(ctx context.Context, req *api.CancelJobRequest) (*api.CancelJobResponse, error) {
+	if err := g.client.QDel(req.JobId); err != nil {
+		return nil, errors.Wrapf(err, "could not cancel job %d", req.JobId)
+	}
+
+	return &api.CancelJobResponse{}, nil
+}
+
+// HoldJob is not supported for Grid Engine.
+func (g *GridEngine) HoldJob(ctx context.Context, req *api.HoldJobRequest) (*api.HoldJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job hold is not available for grid engine")
+}
+
+// ReleaseJob is not supported for Grid Engine.
+func (g *GridEngine) ReleaseJob(ctx context.Context, req *api.ReleaseJobRequest) (*api.ReleaseJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job release is not available for grid engine")
+}
+
+// SuspendJob is not supported for Grid Engine.
+func (g *GridEngine) SuspendJob(ctx context.Context, req *api.SuspendJobRequest) (*api.SuspendJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job suspension is not available for grid engine")
+}
+
+// ResumeJob is not supported for Grid Engine.
+func (g *GridEngine) ResumeJob(ctx context.Context, req *api.ResumeJobRequest) (*api.ResumeJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job suspension is not available for grid engine")
+}
+
+// RequeueJob is not supported for Grid Engine.
+func (g *GridEngine) RequeueJob(ctx context.Context, req *api.RequeueJobRequest) (*api.RequeueJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job requeue is not available for grid engine")
+}
+
+// JobInfo returns information about a job from 'qstat -j' or from
+// 'qacct -j' if job is already finished. NotFound status is returned
+// if neither of them knows the job.
+func (g *GridEngine) JobInfo(ctx context.Context, req *api.JobInfoRequest) (*api.JobInfoResponse, error) {
+	info, err := g.client.JobInfo(req.JobId)
+	if errors.Cause(err) == sge.ErrJobNotFound {
+		return nil, status.Errorf(codes.NotFound, "job %d is not found", req.JobId)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get job %d info", req.JobId)
+	}
+
+	pInfo, err := mapQInfoToProtoInfo(info)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not convert grid engine info into proto info")
+	}
+
+	if len(pInfo) != 0 {
+		g.submissions.observe(req.JobId, pInfo[0])
+	}
+	return &api.JobInfoResponse{Info: pInfo}, nil
+}
+
+// JobsInfo returns information about several jobs, see JobInfo.
+func (g *GridEngine) JobsInfo(ctx context.Context, req *api.JobsInfoRequest) (*api.JobsInfoResponse, error) {
+	return jobsInfo(ctx, req, g.JobInfo)
+}
+
+// JobHistory returns jobs submitted through red-box.
+func (g *GridEngine) JobHistory(ctx context.Context, req *api.JobHistoryRequest) (*api.JobHistoryResponse, error) {
+	return g.submissions.history(req)
+}
+
+// JobScript is not supported, Grid Engine keeps job scripts
+// only in qmaster spool directory, which is not readable by users.
+func (g *GridEngine) JobScript(ctx context.Context, req *api.JobScriptRequest) (*api.JobScriptResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "job script is not available for grid engine")
+}
+
+// JobSteps returns information about job steps. Grid Engine has no
+// notion of job steps, so the job itself is returned as a single step.
+func (g *GridEngine) JobSteps(ctx context.Context, req *api.JobStepsRequest) (*api.JobStepsResponse, error) {
+	info, err := g.client.JobInfo(req.JobId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get job %d info", req.JobId)
+	}
+
+	pSteps := make([]*api.JobStepInfo, len(info))
+	for i, inf := range info {
+		startTime, err := protoTime(inf.StartTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not convert start go time to proto time")
+		}
+		endTime, err := protoTime(inf.EndTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not convert end go time to proto time")
+		}
+
+		pSteps[i] = &api.JobStepInfo{
+			Id:        inf.ID,
+			Name:      inf.Name,
+			ExitCode:  int32(inf.ExitCode),
+			Status:    protoStatus(inf.State),
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	}
+
+	return &api.JobStepsResponse{JobSteps: pSteps}, nil
+}
+
+// Resources return available resources on grid engine cluster in a requested queue.
+func (g *GridEngine) Resources(_ context.Context, req *api.ResourcesRequest) (*api.ResourcesResponse, error) {
+	r, err := g.client.Resources(req.Partition)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get resources for queue %s", req.Partition)
+	}
+
+	discovered := &api.ResourcesResponse{
+		Nodes:      r.Nodes,
+		CpuPerNode: r.CPUPerNode,
+		MemPerNode: r.MemPerNode,
+		WallTime:   int64(r.WallTime.Seconds()),
+	}
+	return g.cfg.Partitions[req.Partition].apply(discovered), nil
+}
+
+// Partitions returns cluster queue names.
+func (g *GridEngine) Partitions(context.Context, *api.PartitionsRequest) (*api.PartitionsResponse, error) {
+	names, err := g.client.Queues()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get queue names")
+	}
+
+	return &api.PartitionsResponse{Partition: names}, nil
+}
+
+// WorkloadInfo returns wlm info (name, version, red-box uid)
+func (g *GridEngine) WorkloadInfo(context.Context, *api.WorkloadInfoRequest) (*api.WorkloadInfoResponse, error) {
+	const wlmName = "sge"
+
+	version, err := g.client.Version()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get grid engine version")
+	}
+
+	return &api.WorkloadInfoResponse{
+		Name:    wlmName,
+		Version: version,
+		Uid:     g.uid,
+	}, nil
+}
+
+func mapQInfoToProtoInfo(qi []*sge.JobInfo) ([]*api.JobInfo, error) {
+	pInfs := make([]*api.JobInfo, len(qi))
+	for i, inf := range qi {
+		submitTime, err := protoTime(inf.SubmitTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not convert submit go time to proto time")
+		}
+
+		startTime, err := protoTime(inf.StartTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not convert start go time to proto time")
+		}
+
+		endTime, err := protoTime(inf.EndTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not convert end go time to proto time")
+		}
+
+		var runTime *duration.Duration
+		switch {
+		case inf.WallTime != nil:
+			runTime = ptypes.DurationProto(*inf.WallTime)
+		case inf.StartTime != nil:
+			runTime = ptypes.DurationProto(time.Since(*inf.StartTime))
+		}
+
+		var arrayID string
+		if inf.TaskID != "" {
+			arrayID = strings.SplitN(inf.ID, ".", 2)[0]
+		}
+
+		var batchHost string
+		if len(inf.Hosts) != 0 {
+			batchHost = inf.Hosts[0]
+		}
+
+		pInfs[i] = &api.JobInfo{
+			Id:         inf.ID,
+			UserId:     inf.Owner,
+			Name:       inf.Name,
+			ExitCode:   strconv.Itoa(inf.ExitCode),
+			Status:     protoStatus(inf.State),
+			State:      inf.State,
+			SubmitTime: submitTime,
+			StartTime:  startTime,
+			EndTime:    endTime,
+			RunTime:    runTime,
+			WorkingDir: inf.WorkDir,
+			StdOut:     inf.StdOut,
+			StdErr:     inf.StdErr,
+			Partition:  inf.Queue,
+			NodeList:   strings.Join(inf.Hosts, ","),
+			BatchHost:  batchHost,
+			NumNodes:   strconv.Itoa(len(inf.Hosts)),
+			ArrayId:    arrayID,
+			NumCpus:    inf.Slots,
+		}
+	}
+
+	return pInfs, nil
+}
+
+// protoTime converts optional go time into proto timestamp.
+func protoTime(t *time.Time) (*timestamp.Timestamp, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return ptypes.TimestampProto(*t)
+}
+
+// protoStatus converts workload manager job state into proto job status.
+func protoStatus(state string) api.JobStatus {
+	status, ok := api.JobStatus_value[state]
+	if !ok {
+		return api.JobStatus_UNKNOWN
+	}
+	return api.JobStatus(status)
+}
+
+func buildSGEScript(r *api.SubmitJobContainerRequest) (string, error) {
+	const (
+		timeT  = `#$ -l h_rt=%d`    // seconds
+		memT   = `#$ -l h_vmem=%dM` // mbs per slot
+		slotsT = `#$ -pe %s %d`
+	)
+
+	if r.Nodes > 1 {
+		return "", errors.New("multi node container jobs are not supported")
+	}
+
+	lines := []string{
+		"#!/bin/sh",
+		"#$ -S /bin/sh",
+		"#$ -cwd",
+	}
+
+	if r.WallTime != 0 {
+		lines = append(lines, fmt.Sprintf(timeT, r.WallTime))
+	}
+
+	if r.CpuPerNode > 1 {
+		lines = append(lines, fmt.Sprintf(slotsT, sgeParallelEnv, r.CpuPerNode))
+	}
+
+	if r.MemPerNode != 0 {
+		// h_vmem is a per slot limit in grid engine
+		mem := r.MemPerNode
+		if r.CpuPerNode > 1 {
+			mem = (mem + r.CpuPerNode - 1) / r.CpuPerNode
+		}
+		lines = append(lines, fmt.Sprintf(memT, mem))
+	}
+
+	lines = append(lines, singularityCommands(r)...)
+	return strings.Join(lines, "\n"), nil
+}
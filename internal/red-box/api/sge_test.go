@@ -0,0 +1,79 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dptech-corp/wlm-operator/pkg/sge"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_buildSGEScript(t *testing.T) {
+	script, err := buildSGEScript(&api.SubmitJobContainerRequest{
+		ImageName:  "local.file/home/vagrant/lolcow.sif",
+		CpuPerNode: 4,
+		MemPerNode: 1022,
+		WallTime:   3600,
+		Options: &api.SingularityOptions{
+			App: "main",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `#!/bin/sh
+#$ -S /bin/sh
+#$ -cwd
+#$ -l h_rt=3600
+#$ -pe smp 4
+#$ -l h_vmem=256M
+singularity verify "/home/vagrant/lolcow.sif" || exit
+singularity run --app="main" "/home/vagrant/lolcow.sif" || exit`, script)
+
+	_, err = buildSGEScript(&api.SubmitJobContainerRequest{
+		ImageName: "library://sylabsed/examples/lolcow",
+		Nodes:     2,
+		Options:   &api.SingularityOptions{},
+	})
+	require.Error(t, err)
+}
+
+func TestGridEngine_JobInfo_notFound(t *testing.T) {
+	dir, err := ioutil.TempDir("", "red-box-sge")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// qstat lists the job as unknown, qacct has no record of it
+	bins := map[string]string{
+		"qstat": "#!/bin/sh\necho '<?xml version=\"1.0\"?><unknown_jobs><element><ST_name>42</ST_name></element></unknown_jobs>'\nexit 1\n",
+		"qacct": "#!/bin/sh\necho 'error: job id 42 not found' >&2\nexit 1\n",
+	}
+	for name, script := range bins {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0755))
+	}
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	require.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+path))
+
+	g := &GridEngine{client: &sge.Client{}}
+	_, err = g.JobInfo(context.Background(), &api.JobInfoRequest{JobId: 42})
+	require.Equal(t, codes.NotFound, status.Code(err), err)
+}
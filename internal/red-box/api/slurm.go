@@ -17,9 +17,9 @@ package api
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,8 +29,11 @@ import (
 	"github.com/golang/protobuf/ptypes/duration"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/pkg/errors"
+	"github.com/dptech-corp/wlm-operator/internal/red-box/store"
 	"github.com/dptech-corp/wlm-operator/pkg/slurm"
 	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const localFilePrefix = "local.file"
@@ -38,14 +41,32 @@ const localFilePrefix = "local.file"
 type (
 	// Slurm implements WorkloadManagerServer.
 	Slurm struct {
-		uid    int64
-		cfg    Config
-		client *slurm.Client
+		fileServer
+
+		uid         int64
+		cfg         Config
+		client      slurm.Slurm
+		capacity    *capacityCache
+		jobs        *jobsSnapshot
+		submissions *submissions
+		stop        chan struct{}
+	}
+
+	// Config is a red-box configuration. It lists Slurm clusters to serve
+	// and configures resources for each partition available. When clusters
+	// are set partitions are named as cluster/partition, cluster.partition label
+	// form of the name is accepted in requests as well. Partition capacity
+	// is refreshed in background every CapacityInterval. Job info is answered
+	// from a snapshot of all jobs that is taken in background every JobsStaleness.
+	// Idempotency keys of submitted jobs are kept for SubmissionRetention.
+	Config struct {
+		Clusters            []string                      `yaml:"clusters"`
+		Partitions          map[string]PartitionResources `yaml:"partitions"`
+		CapacityInterval    time.Duration                 `yaml:"capacity_interval"`
+		JobsStaleness       time.Duration                 `yaml:"jobs_staleness"`
+		SubmissionRetention time.Duration                 `yaml:"submission_retention"`
 	}
 
-	// Config is a red-box configuration for each partition available.
-	Config map[string]PartitionResources
-
 	// PartitionResources configure how red-box will see slurm partition resources.
 	// In auto mode red-box will attempt to query partition resources from slurm, but
 	// administrator can set up them manually.
@@ -71,17 +92,112 @@ type (
 	}
 )
 
-// NewSlurm creates a new instance of Slurm.
-func NewSlurm(c *slurm.Client, cfg Config) *Slurm {
-	return &Slurm{client: c, cfg: cfg, uid: int64(os.Geteuid())}
+// NewSlurm creates a new instance of Slurm. Store is used to persist
+// red-box state, submissions are not idempotent if it is nil.
+// Background refreshes are stopped with Close.
+func NewSlurm(c slurm.Slurm, st *store.Store, cfg Config) *Slurm {
+	s := &Slurm{
+		fileServer: fileServer{fs: c},
+		client:     c,
+		cfg:        cfg,
+		uid:        int64(os.Geteuid()),
+		capacity: newCapacityCache(cfg.CapacityInterval, func(partition string) (*api.Capacity, error) {
+			c, err := c.Capacity(partition)
+			if err != nil {
+				return nil, err
+			}
+			return &api.Capacity{
+				IdleNodes:  c.IdleNodes,
+				AllocNodes: c.AllocNodes,
+				IdleCpus:   c.IdleCPUs,
+				AllocCpus:  c.AllocCPUs,
+				IdleMem:    c.IdleMem,
+				AllocMem:   c.AllocMem,
+				IdleGpus:   c.IdleGPUs,
+				AllocGpus:  c.AllocGPUs,
+			}, nil
+		}),
+		jobs:        newJobsSnapshot(cfg.JobsStaleness, c.SJobsInfo),
+		submissions: newSubmissions(st, cfg.SubmissionRetention),
+		stop:        make(chan struct{}),
+	}
+	go s.capacity.run(s.stop)
+	go s.jobs.run(s.stop)
+	go s.submissions.run(s.stop)
+	return s
+}
+
+// Close stops background refreshes and pruning of expired submissions.
+func (s *Slurm) Close() error {
+	close(s.stop)
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. Besides a config with clusters
+// and partitions sections it accepts a plain map of partitions.
+func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var sections map[string]interface{}
+	if err := unmarshal(&sections); err != nil {
+		return err
+	}
+
+	type plain Config
+	for _, section := range []string{"clusters", "partitions", "capacity_interval", "jobs_staleness", "submission_retention"} {
+		if _, ok := sections[section]; ok {
+			return unmarshal((*plain)(c))
+		}
+	}
+	return unmarshal(&c.Partitions)
+}
+
+// apply merges discovered partition resources with the configured ones.
+// Configured values take precedence unless auto discovery is enabled for them,
+// configured features are added to the discovered ones.
+func (pr PartitionResources) apply(discovered *api.ResourcesResponse) *api.ResourcesResponse {
+	response := &api.ResourcesResponse{
+		Nodes:       pr.Nodes,
+		CpuPerNode:  pr.CPUPerNode,
+		MemPerNode:  pr.MemPerNode,
+		WallTime:    int64(pr.WallTime.Seconds()),
+		Features:    discovered.Features,
+		NodeClasses: discovered.NodeClasses,
+
+		Tres:               discovered.Tres,
+		TresBillingWeights: discovered.TresBillingWeights,
+	}
+
+	for _, f := range pr.AdditionalFeatures {
+		response.Features = append(response.Features, &api.Feature{
+			Name:     f.Name,
+			Version:  f.Version,
+			Quantity: f.Quantity,
+		})
+	}
+
+	if pr.AutoNodes || response.Nodes == 0 {
+		response.Nodes = discovered.Nodes
+	}
+	if pr.AutoCPUPerNode || response.CpuPerNode == 0 {
+		response.CpuPerNode = discovered.CpuPerNode
+	}
+	if pr.AutoMemPerNode || response.MemPerNode == 0 {
+		response.MemPerNode = discovered.MemPerNode
+	}
+	if pr.AutoWallTime || response.WallTime == 0 {
+		response.WallTime = discovered.WallTime
+	}
+
+	return response
 }
 
 // SubmitJob submits job and returns id of it in case of success.
 func (s *Slurm) SubmitJob(ctx context.Context, req *api.SubmitJobRequest) (*api.SubmitJobResponse, error) {
-	// todo use client id from req
-	id, err := s.client.SBatch(req.Script, req.Partition)
+	partition := s.partition(req.Partition)
+	id, err := s.submissions.submit(newJob(req.ClientId, req.IdempotencyKey, partition, req.Script, req), func() (int64, error) {
+		return s.client.SBatch(req.Script, partition)
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "could not submit sbatch script")
+		return nil, submitError(err, "could not submit sbatch script")
 	}
 
 	return &api.SubmitJobResponse{
@@ -93,9 +209,12 @@ func (s *Slurm) SubmitJob(ctx context.Context, req *api.SubmitJobRequest) (*api.
 func (s *Slurm) SubmitJobContainer(ctx context.Context, r *api.SubmitJobContainerRequest) (*api.SubmitJobContainerResponse, error) {
 	script := buildSLURMScript(r)
 
-	id, err := s.client.SBatch(script, r.Partition)
+	partition := s.partition(r.Partition)
+	id, err := s.submissions.submit(newJob(r.ClientId, r.IdempotencyKey, partition, script, r), func() (int64, error) {
+		return s.client.SBatch(script, partition)
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "could not submit sbatch script")
+		return nil, submitError(err, "could not submit sbatch script")
 	}
 
 	return &api.SubmitJobContainerResponse{
@@ -103,6 +222,28 @@ func (s *Slurm) SubmitJobContainer(ctx context.Context, r *api.SubmitJobContaine
 	}, nil
 }
 
+// ValidateJob tests job submission with 'sbatch --test-only'.
+func (s *Slurm) ValidateJob(ctx context.Context, req *api.SubmitJobRequest) (*api.ValidateJobResponse, error) {
+	e, err := s.client.SBatchTest(req.Script, s.partition(req.Partition))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not test sbatch script")
+	}
+
+	startTime, err := protoTime(e.StartTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not convert start time")
+	}
+	return &api.ValidateJobResponse{
+		Valid:     len(e.Errors) == 0,
+		Errors:    e.Errors,
+		Warnings:  e.Warnings,
+		StartTime: startTime,
+		Cpus:      e.CPUs,
+		NodeList:  e.NodeList,
+		Partition: e.Partition,
+	}, nil
+}
+
 // CancelJob cancels job.
 func (s *Slurm) CancelJob(ctx context.Context, req *api.CancelJobRequest) (*api.CancelJobResponse, error) {
 	if err := s.client.SCancel(req.JobId); err != nil {
@@ -112,227 +253,194 @@ func (s *Slurm) CancelJob(ctx context.Context, req *api.CancelJobRequest) (*api.
 	return &api.CancelJobResponse{}, nil
 }
 
-// JobInfo returns information about a job from 'scontrol show jobid'.
-// Safe to call before job finished. After it could return an error.
-func (s *Slurm) JobInfo(ctx context.Context, req *api.JobInfoRequest) (*api.JobInfoResponse, error) {
-	info, err := s.client.SJobInfo(req.JobId)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not get job %d info", req.JobId)
+// HoldJob holds pending job.
+func (s *Slurm) HoldJob(ctx context.Context, req *api.HoldJobRequest) (*api.HoldJobResponse, error) {
+	if err := s.client.SHold(req.JobId); err != nil {
+		return nil, errors.Wrapf(err, "could not hold job %d", req.JobId)
 	}
 
-	pInfo, err := mapSInfoToProtoInfo(info)
-	if err != nil {
-		return nil, errors.Wrap(err, "could not convert slurm info into proto info")
-	}
+	return &api.HoldJobResponse{}, nil
+}
 
-	if len(pInfo) == 0 {
-		return nil, errors.New("job info slice is empty, probably invalid scontrol output")
+// ReleaseJob releases held job.
+func (s *Slurm) ReleaseJob(ctx context.Context, req *api.ReleaseJobRequest) (*api.ReleaseJobResponse, error) {
+	if err := s.client.SRelease(req.JobId); err != nil {
+		return nil, errors.Wrapf(err, "could not release job %d", req.JobId)
 	}
 
-	return &api.JobInfoResponse{Info: pInfo}, nil
+	return &api.ReleaseJobResponse{}, nil
 }
 
-// JobSteps returns information about job steps from 'sacct'.
-// Safe to call after job started. Before it could return an error.
-func (s *Slurm) JobSteps(ctx context.Context, req *api.JobStepsRequest) (*api.JobStepsResponse, error) {
-	steps, err := s.client.SJobSteps(req.JobId)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not get job %d steps", req.JobId)
-	}
-
-	pSteps, err := toProtoSteps(steps)
-	if err != nil {
-		return nil, errors.Wrap(err, "could not convert slurm steps into proto steps")
+// SuspendJob suspends running job.
+func (s *Slurm) SuspendJob(ctx context.Context, req *api.SuspendJobRequest) (*api.SuspendJobResponse, error) {
+	if err := s.client.SSuspend(req.JobId); err != nil {
+		return nil, errors.Wrapf(err, "could not suspend job %d", req.JobId)
 	}
 
-	return &api.JobStepsResponse{JobSteps: pSteps}, nil
+	return &api.SuspendJobResponse{}, nil
 }
 
-// OpenFile opens requested file and return chunks with bytes.
-func (s *Slurm) OpenFile(r *api.OpenFileRequest, req api.WorkloadManager_OpenFileServer) error {
-	fd, err := s.client.Open(r.Path)
-	if err != nil {
-		return errors.Wrapf(err, "could not open file at %s", r.Path)
+// ResumeJob resumes suspended job.
+func (s *Slurm) ResumeJob(ctx context.Context, req *api.ResumeJobRequest) (*api.ResumeJobResponse, error) {
+	if err := s.client.SResume(req.JobId); err != nil {
+		return nil, errors.Wrapf(err, "could not resume job %d", req.JobId)
 	}
-	defer fd.Close()
 
-	buff := make([]byte, 128)
-	for {
-		n, err := fd.Read(buff)
-		if n > 0 {
-			if err := req.Send(&api.Chunk{Content: buff[:n]}); err != nil {
-				return errors.Wrap(err, "could not send chunk")
-			}
-		}
+	return &api.ResumeJobResponse{}, nil
+}
 
+// RequeueJob requeues running job or a single task of a job array.
+func (s *Slurm) RequeueJob(ctx context.Context, req *api.RequeueJobRequest) (*api.RequeueJobResponse, error) {
+	if req.ArrayTaskId != "" {
+		taskID, err := strconv.ParseInt(req.ArrayTaskId, 10, 64)
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
-
-			return err
+			return nil, status.Errorf(codes.InvalidArgument, "invalid array task id %q", req.ArrayTaskId)
+		}
+		if err := s.client.SRequeueTask(req.JobId, taskID); err != nil {
+			return nil, errors.Wrapf(err, "could not requeue task %d of job %d", taskID, req.JobId)
 		}
+		return &api.RequeueJobResponse{}, nil
 	}
 
-	return nil
+	if err := s.client.SRequeue(req.JobId, req.Hold); err != nil {
+		return nil, errors.Wrapf(err, "could not requeue job %d", req.JobId)
+	}
+
+	return &api.RequeueJobResponse{}, nil
 }
 
-// TailFile tails a file till close requested.
-// To start receiving file bytes client should send a request with file path and action start,
-// to stop client should send a request with action readToEndAndClose (file path is not required)
-// and after reaching end method will send EOF error.
-func (s *Slurm) TailFile(req api.WorkloadManager_TailFileServer) error {
-	r, err := req.Recv()
+// JobInfo returns information about a job from a snapshot of 'scontrol show job'.
+// Jobs that are not in the snapshot are queried with 'scontrol show jobid', jobs
+// already purged by slurmctld are looked up in accounting with 'sacct'.
+// NotFound status is returned if neither of them knows the job.
+func (s *Slurm) JobInfo(ctx context.Context, req *api.JobInfoRequest) (*api.JobInfoResponse, error) {
+	info, ok := s.jobs.get(req.JobId)
+	var err error
+	if !ok {
+		info, err = s.client.SJobInfo(req.JobId)
+	}
+	if errors.Cause(err) == slurm.ErrJobNotFound {
+		return nil, status.Errorf(codes.NotFound, "job %d is not found", req.JobId)
+	}
 	if err != nil {
-		return errors.Wrap(err, "could not receive request")
+		return nil, errors.Wrapf(err, "could not get job %d info", req.JobId)
 	}
 
-	fd, err := s.client.Tail(r.Path)
+	pInfo, err := mapSInfoToProtoInfo(info)
 	if err != nil {
-		return errors.Wrapf(err, "could not tail file at %s", r.Path)
+		return nil, errors.Wrap(err, "could not convert slurm info into proto info")
 	}
-	defer func(p string) {
-		log.Printf("Tail file at %s finished", p)
-	}(r.Path)
-
-	requestCh := make(chan *api.TailFileRequest)
-	go func() {
-		r, err := req.Recv()
-		if err != nil {
-			if err != io.EOF {
-				log.Printf("could not recive request err: %s", err)
-			}
-			return
-		}
-
-		requestCh <- r
-	}()
-
-	buff := make([]byte, 128)
 
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
+	if len(pInfo) == 0 {
+		return nil, errors.New("job info slice is empty, probably invalid scontrol output")
+	}
 
-	for {
-		select {
-		case <-req.Context().Done():
-			return req.Context().Err()
-		case r := <-requestCh:
-			if r.Action == api.TailAction_ReadToEndAndClose {
-				_ = fd.Close()
-			}
-		case <-ticker.C:
-			n, err := fd.Read(buff)
-			if err != nil && n == 0 {
-				return err
-			}
+	s.submissions.observe(req.JobId, pInfo[0])
+	return &api.JobInfoResponse{Info: pInfo}, nil
+}
 
-			if n == 0 {
-				continue
-			}
+// JobsInfo returns information about several jobs, see JobInfo.
+func (s *Slurm) JobsInfo(ctx context.Context, req *api.JobsInfoRequest) (*api.JobsInfoResponse, error) {
+	return jobsInfo(ctx, req, s.JobInfo)
+}
 
-			if err := req.Send(&api.Chunk{Content: buff[:n]}); err != nil {
-				return errors.Wrap(err, "could not send chunk")
-			}
-		}
-	}
+// JobHistory returns jobs submitted through red-box.
+func (s *Slurm) JobHistory(ctx context.Context, req *api.JobHistoryRequest) (*api.JobHistoryResponse, error) {
+	return s.submissions.history(req)
 }
 
-// CreateFile write chunks with bytes to requested file.
-func (s *Slurm) CreateFile(req api.WorkloadManager_CreateFileServer) error {
-	r, err := req.Recv()
-	if err != nil {
-		return errors.Wrap(err, "could not receive request")
+// JobScript returns batch script of a job with 'scontrol write batch_script'.
+func (s *Slurm) JobScript(ctx context.Context, req *api.JobScriptRequest) (*api.JobScriptResponse, error) {
+	js, err := s.client.SJobScript(req.JobId)
+	if errors.Cause(err) == slurm.ErrJobNotFound {
+		return nil, status.Errorf(codes.NotFound, "batch script of job %d is not found", req.JobId)
 	}
-	fd, err := s.client.Create(r.Path)
 	if err != nil {
-		return errors.Wrapf(err, "could not open file at %s", r.Path)
+		return nil, errors.Wrapf(err, "could not get job %d script", req.JobId)
 	}
-	defer fd.Close()
-
-	for {
-		r, err := req.Recv()
-
-		if r != nil && r.Content != nil {
-			if _, err := fd.Write(r.Content); err != nil {
-				log.Fatalf("can't write to file err: %s", err)
-			}
-		}
 
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return err
-		}
-	}
-
-	err = req.SendAndClose(&api.CreateFileResponse{})
-	return nil
+	return &api.JobScriptResponse{
+		Script:      js.Script,
+		Environment: js.Environment,
+	}, nil
 }
 
-// Zip file or directory
-func (s *Slurm) Zip(ctx context.Context, req *api.ZipRequest) (*api.ZipResponse, error) {
-	if err := s.client.Zip(req.Path, req.Target); err != nil {
-		return nil, errors.Wrapf(err, "could not zip")
+// JobSteps returns information about job steps from 'sacct'.
+// Safe to call after job started. Before it could return an error.
+func (s *Slurm) JobSteps(ctx context.Context, req *api.JobStepsRequest) (*api.JobStepsResponse, error) {
+	steps, err := s.client.SJobSteps(req.JobId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get job %d steps", req.JobId)
 	}
 
-	return &api.ZipResponse{}, nil
-}
-
-// Unzip file
-func (s *Slurm) Unzip(ctx context.Context, req *api.UnzipRequest) (*api.UnzipResponse, error) {
-	if err := s.client.Unzip(req.Source, req.Path); err != nil {
-		return nil, errors.Wrapf(err, "could not unzip")
+	pSteps, err := toProtoSteps(steps)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not convert slurm steps into proto steps")
 	}
 
-	return &api.UnzipResponse{}, nil
+	return &api.JobStepsResponse{JobSteps: pSteps}, nil
 }
 
 // Resources return available resources on slurm cluster in a requested partition.
 func (s *Slurm) Resources(_ context.Context, req *api.ResourcesRequest) (*api.ResourcesResponse, error) {
-	slurmResources, err := s.client.Resources(req.Partition)
+	partition := s.partition(req.Partition)
+	slurmResources, err := s.client.Resources(partition)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not get resources for partition %s", req.Partition)
+		return nil, errors.Wrapf(err, "could not get resources for partition %s", partition)
 	}
 
-	partitionResources := s.cfg[req.Partition]
-	response := &api.ResourcesResponse{
-		Nodes:      partitionResources.Nodes,
-		CpuPerNode: partitionResources.CPUPerNode,
-		MemPerNode: partitionResources.MemPerNode,
-		WallTime:   int64(partitionResources.WallTime.Seconds()),
-	}
+	discovered := &api.ResourcesResponse{
+		Nodes:      slurmResources.Nodes,
+		CpuPerNode: slurmResources.CPUPerNode,
+		MemPerNode: slurmResources.MemPerNode,
+		WallTime:   int64(slurmResources.WallTime.Seconds()),
 
+		Tres:               slurmResources.TRES,
+		TresBillingWeights: slurmResources.TRESBillingWeights,
+	}
 	for _, f := range slurmResources.Features {
-		response.Features = append(response.Features, &api.Feature{
+		discovered.Features = append(discovered.Features, &api.Feature{
 			Name:     f.Name,
 			Version:  f.Version,
 			Quantity: f.Quantity,
 		})
 	}
-	for _, f := range partitionResources.AdditionalFeatures {
-		response.Features = append(response.Features, &api.Feature{
-			Name:     f.Name,
-			Version:  f.Version,
-			Quantity: f.Quantity,
-		})
+	for _, nc := range slurmResources.NodeClasses {
+		discovered.NodeClasses = append(discovered.NodeClasses, toNodeClass(nc))
 	}
 
-	if partitionResources.AutoNodes || response.Nodes == 0 {
-		response.Nodes = slurmResources.Nodes
-	}
-	if partitionResources.AutoCPUPerNode || response.CpuPerNode == 0 {
-		response.CpuPerNode = slurmResources.CPUPerNode
-	}
-	if partitionResources.AutoMemPerNode || response.MemPerNode == 0 {
-		response.MemPerNode = slurmResources.MemPerNode
+	resources := s.cfg.Partitions[partition].apply(discovered)
+	// capacity is optional, so partition resources are still
+	// reported if it could not be queried
+	resources.Capacity, err = s.capacity.get(partition)
+	if err != nil {
+		log.Printf("Could not get capacity of partition %s: %s", partition, err)
 	}
-	if partitionResources.AutoWallTime || response.WallTime == 0 {
-		response.WallTime = int64(slurmResources.WallTime.Seconds())
+	return resources, nil
+}
+
+// toNodeClass converts slurm node class to its api representation.
+func toNodeClass(nc slurm.NodeClass) *api.NodeClass {
+	class := &api.NodeClass{
+		Nodes:      nc.Nodes,
+		CpuPerNode: nc.CPUPerNode,
+		MemPerNode: nc.MemPerNode,
+		Features:   nc.Features,
+	}
+	for _, g := range nc.Gres {
+		class.Gres = append(class.Gres, &api.Feature{
+			Name:     g.Name,
+			Version:  g.Version,
+			Quantity: g.Quantity,
+		})
 	}
+	return class
+}
 
-	return response, nil
+// partition returns partition name for the one requested, virtual nodes
+// request partitions of multiple clusters in their label form.
+func (s *Slurm) partition(name string) string {
+	return slurm.PartitionFromLabel(name, s.cfg.Clusters)
 }
 
 // Partitions returns partition names.
@@ -354,10 +462,16 @@ func (s *Slurm) WorkloadInfo(context.Context, *api.WorkloadInfoRequest) (*api.Wo
 		return nil, errors.Wrap(err, "could not get slurm version")
 	}
 
+	clusters, err := s.client.Clusters()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get slurm clusters")
+	}
+
 	return &api.WorkloadInfoResponse{
-		Name:    wlmName,
-		Version: sVersion,
-		Uid:     s.uid,
+		Name:     wlmName,
+		Version:  sVersion,
+		Uid:      s.uid,
+		Clusters: clusters,
 	}, nil
 }
 
@@ -426,6 +540,16 @@ func mapSInfoToProtoInfo(si []*slurm.JobInfo) ([]*api.JobInfo, error) {
 			startTime = pt
 		}
 
+		endTime, err := protoTime(inf.EndTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not convert end go time to proto time")
+		}
+
+		estimatedStart, err := protoTime(inf.EstimatedStart)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not convert estimated start go time to proto time")
+		}
+
 		var runTime *duration.Duration
 		if inf.RunTime != nil {
 			runTime = ptypes.DurationProto(*inf.RunTime)
@@ -459,6 +583,17 @@ func mapSInfoToProtoInfo(si []*slurm.JobInfo) ([]*api.JobInfo, error) {
 			BatchHost:  inf.BatchHost,
 			NumNodes:   inf.NumNodes,
 			ArrayId:    inf.ArrayJobID,
+			EndTime:    endTime,
+			Reason:     inf.Reason,
+			Priority:   inf.Priority,
+			NumCpus:    inf.NumCPUs,
+			Tres:       inf.TRES,
+			Account:    inf.Account,
+			Qos:        inf.QOS,
+			State:      inf.State,
+
+			ArrayTaskId:        inf.ArrayTaskID,
+			EstimatedStartTime: estimatedStart,
 		}
 		pInfs[i] = &pi
 	}
@@ -520,7 +655,46 @@ func buildSLURMScript(r *api.SubmitJobContainerRequest) string {
 }
 
 func buildRunCommand(opt *api.SingularityOptions) string {
-	run := "srun singularity run"
+	return "srun " + singularityRunCommand(opt)
+}
+
+// singularityCommands returns commands that run a container from the requested
+// image directly on a host, i.e. without launching it with srun.
+func singularityCommands(r *api.SubmitJobContainerRequest) []string {
+	const (
+		verifyT = `singularity verify "%s" || exit`
+		pullT   = `singularity pull --name "%s" "%s" || exit`
+		pullUT  = `singularity pull -U --name "%s" "%s" || exit`
+		rmT     = `rm "%s"`
+	)
+
+	runT := singularityRunCommand(r.Options)
+
+	var lines []string
+	// checks if sif is located somewhere on the host machine
+	if strings.HasPrefix(r.ImageName, localFilePrefix) {
+		image := strings.TrimPrefix(r.ImageName, localFilePrefix)
+		if !r.Options.AllowUnsigned {
+			lines = append(lines, fmt.Sprintf(verifyT, image))
+		}
+		lines = append(lines, fmt.Sprintf(runT, image))
+	} else {
+		id := uuid.New().String()
+		if r.Options.AllowUnsigned {
+			lines = append(lines, fmt.Sprintf(pullUT, id, r.ImageName))
+		} else {
+			lines = append(lines, fmt.Sprintf(pullT, id, r.ImageName))
+		}
+		lines = append(lines, fmt.Sprintf(runT, id))
+		lines = append(lines, fmt.Sprintf(rmT, id))
+	}
+	return lines
+}
+
+// singularityRunCommand returns a singularity run command template with respect
+// to the passed options. Image should be substituted in the returned template.
+func singularityRunCommand(opt *api.SingularityOptions) string {
+	run := "singularity run"
 	flags := []string{}
 
 	if opt.App != "" {
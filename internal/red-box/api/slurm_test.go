@@ -15,34 +15,51 @@
 package api
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/dptech-corp/wlm-operator/pkg/slurm/fake"
 	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
 
-	"github.com/stretchr/testify/require"
 	"github.com/dptech-corp/wlm-operator/pkg/slurm"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
 )
 
 func Test_mapSInfoToProtoInfo(t *testing.T) {
 	testInfo := slurm.JobInfo{
-		ID:         "1",
-		UserID:     "vagrant",
-		Name:       "test.job",
-		ExitCode:   "0:1",
-		State:      "COMPLETED",
-		SubmitTime: &[]time.Time{time.Now()}[0],
-		StartTime:  &[]time.Time{time.Now().Add(1 * time.Second)}[0],
-		RunTime:    &[]time.Duration{time.Second}[0],
-		TimeLimit:  &[]time.Duration{time.Hour}[0],
-		WorkDir:    "/home",
-		StdOut:     "1",
-		StdErr:     "2",
-		Partition:  "debug",
-		NodeList:   "node1",
-		BatchHost:  "host1",
-		NumNodes:   "2",
-		ArrayJobID: "111",
+		ID:          "1",
+		UserID:      "vagrant",
+		Name:        "test.job",
+		ExitCode:    "0:1",
+		State:       "COMPLETED",
+		SubmitTime:  &[]time.Time{time.Now()}[0],
+		StartTime:   &[]time.Time{time.Now().Add(1 * time.Second)}[0],
+		RunTime:     &[]time.Duration{time.Second}[0],
+		TimeLimit:   &[]time.Duration{time.Hour}[0],
+		WorkDir:     "/home",
+		StdOut:      "1",
+		StdErr:      "2",
+		Partition:   "debug",
+		NodeList:    "node1",
+		BatchHost:   "host1",
+		NumNodes:    "2",
+		ArrayJobID:  "111",
+		ArrayTaskID: "3",
+		EndTime:     &[]time.Time{time.Now().Add(time.Hour)}[0],
+		Reason:      "Resources",
+		Priority:    100,
+		NumCPUs:     4,
+		TRES:        map[string]string{"cpu": "4", "gres/gpu": "1"},
+		Account:     "physics",
+		QOS:         "normal",
+
+		EstimatedStart: &[]time.Time{time.Now().Add(time.Minute)}[0],
 	}
 	pinfs, err := mapSInfoToProtoInfo([]*slurm.JobInfo{&testInfo})
 	require.NoError(t, err)
@@ -68,6 +85,15 @@ func Test_mapSInfoToProtoInfo(t *testing.T) {
 	require.EqualValues(t, testInfo.BatchHost, pi.BatchHost)
 	require.EqualValues(t, testInfo.NumNodes, pi.NumNodes)
 	require.EqualValues(t, testInfo.ArrayJobID, pi.ArrayId)
+	require.EqualValues(t, testInfo.ArrayTaskID, pi.ArrayTaskId)
+	require.EqualValues(t, testInfo.EndTime.Unix(), pi.EndTime.Seconds)
+	require.EqualValues(t, testInfo.EstimatedStart.Unix(), pi.EstimatedStartTime.Seconds)
+	require.EqualValues(t, testInfo.Reason, pi.Reason)
+	require.EqualValues(t, testInfo.Priority, pi.Priority)
+	require.EqualValues(t, testInfo.NumCPUs, pi.NumCpus)
+	require.EqualValues(t, testInfo.TRES, pi.Tres)
+	require.EqualValues(t, testInfo.Account, pi.Account)
+	require.EqualValues(t, testInfo.QOS, pi.Qos)
 }
 
 func Test_mapSStepsToProtoSteps(t *testing.T) {
@@ -121,3 +147,140 @@ func Test_buildRunCommand(t *testing.T) {
 		Binds:    []string{"b1", "b2"},
 	}, `srun singularity run --app="main" --hostname="test1" --bind="b1,b2" -c -f -i -p --no-privs -w "%s" || exit`)
 }
+
+func TestSlurm_fake(t *testing.T) {
+	dir, err := ioutil.TempDir("", "red-box-fake")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	clock := fake.NewManualClock(time.Now())
+	c, err := fake.NewSlurm(fake.Config{
+		Partitions:  map[string]fake.Partition{"debug": {Nodes: 1, CPUPerNode: 2, MemPerNode: 1024}},
+		JobDuration: time.Minute,
+		Dir:         dir,
+		Clock:       clock,
+	})
+	require.NoError(t, err)
+	s := NewSlurm(c, nil, Config{Partitions: map[string]PartitionResources{"debug": {Nodes: 1, CPUPerNode: 1}}})
+
+	resources, err := s.Resources(context.Background(), &api.ResourcesRequest{Partition: "debug"})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, resources.CpuPerNode)
+	require.EqualValues(t, 1024, resources.MemPerNode)
+	require.Equal(t, []*api.NodeClass{{Nodes: 1, CpuPerNode: 2, MemPerNode: 1024}}, resources.NodeClasses)
+	require.Equal(t, map[string]string{"cpu": "2", "mem": "1024M", "node": "1"}, resources.Tres)
+	require.EqualValues(t, 1, resources.Capacity.IdleNodes)
+	require.EqualValues(t, 2, resources.Capacity.IdleCpus)
+
+	validated, err := s.ValidateJob(context.Background(), &api.SubmitJobRequest{Script: "#!/bin/sh", Partition: "gpu"})
+	require.NoError(t, err)
+	require.False(t, validated.Valid)
+	require.Len(t, validated.Errors, 1)
+
+	submitted, err := s.SubmitJobContainer(context.Background(), &api.SubmitJobContainerRequest{
+		ImageName:  "library://alpine",
+		Partition:  "debug",
+		WallTime:   30,
+		CpuPerNode: 1,
+		Options:    &api.SingularityOptions{},
+	})
+	require.NoError(t, err)
+
+	clock.Advance(time.Minute)
+	info, err := s.JobInfo(context.Background(), &api.JobInfoRequest{JobId: submitted.JobId})
+	require.NoError(t, err)
+	require.Equal(t, api.JobStatus_TIMEOUT, info.Info[0].Status)
+
+	_, err = s.JobInfo(context.Background(), &api.JobInfoRequest{JobId: 100})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	infos, err := s.JobsInfo(context.Background(), &api.JobsInfoRequest{JobIds: []int64{submitted.JobId, 100}})
+	require.NoError(t, err)
+	require.Len(t, infos.Jobs, 2)
+	require.Equal(t, api.JobStatus_TIMEOUT, infos.Jobs[0].Info[0].Status)
+	require.Empty(t, infos.Jobs[1].Info)
+
+	script, err := s.JobScript(context.Background(), &api.JobScriptRequest{JobId: submitted.JobId})
+	require.NoError(t, err)
+	require.Contains(t, script.Script, "singularity run")
+	_, err = s.JobScript(context.Background(), &api.JobScriptRequest{JobId: 100})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	steps, err := s.JobSteps(context.Background(), &api.JobStepsRequest{JobId: submitted.JobId})
+	require.NoError(t, err)
+	require.Len(t, steps.JobSteps, 2)
+
+	_, err = s.CancelJob(context.Background(), &api.CancelJobRequest{JobId: submitted.JobId})
+	require.Error(t, err)
+	_, err = s.SuspendJob(context.Background(), &api.SuspendJobRequest{JobId: submitted.JobId})
+	require.Error(t, err)
+
+	// the second job waits for the node taken by the first one
+	_, err = s.SubmitJob(context.Background(), &api.SubmitJobRequest{Script: "#!/bin/sh", Partition: "debug"})
+	require.NoError(t, err)
+	held, err := s.SubmitJob(context.Background(), &api.SubmitJobRequest{Script: "#!/bin/sh", Partition: "debug"})
+	require.NoError(t, err)
+	_, err = s.HoldJob(context.Background(), &api.HoldJobRequest{JobId: held.JobId})
+	require.NoError(t, err)
+	clock.Advance(time.Minute)
+	info, err = s.JobInfo(context.Background(), &api.JobInfoRequest{JobId: held.JobId})
+	require.NoError(t, err)
+	require.Equal(t, "JobHeldUser", info.Info[0].Reason)
+	_, err = s.ReleaseJob(context.Background(), &api.ReleaseJobRequest{JobId: held.JobId})
+	require.NoError(t, err)
+
+	// a single failed array task is requeued without touching the others
+	array, err := s.SubmitJob(context.Background(), &api.SubmitJobRequest{
+		Script:    "#!/bin/sh\n#SBATCH --array=0-1\n#FAKE failed_tasks=1",
+		Partition: "debug",
+	})
+	require.NoError(t, err)
+	clock.Advance(5 * time.Minute)
+	info, err = s.JobInfo(context.Background(), &api.JobInfoRequest{JobId: array.JobId})
+	require.NoError(t, err)
+	require.Len(t, info.Info, 2)
+	require.Equal(t, "1", info.Info[1].ArrayTaskId)
+	require.Equal(t, api.JobStatus_FAILED, info.Info[1].Status)
+	_, err = s.RequeueJob(context.Background(), &api.RequeueJobRequest{JobId: array.JobId, ArrayTaskId: "x"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = s.RequeueJob(context.Background(), &api.RequeueJobRequest{JobId: array.JobId, ArrayTaskId: "1"})
+	require.NoError(t, err)
+	info, err = s.JobInfo(context.Background(), &api.JobInfoRequest{JobId: array.JobId})
+	require.NoError(t, err)
+	require.Equal(t, api.JobStatus_COMPLETED, info.Info[0].Status)
+	require.Equal(t, "RUNNING", info.Info[1].State)
+
+	wlm, err := s.WorkloadInfo(context.Background(), &api.WorkloadInfoRequest{})
+	require.NoError(t, err)
+	require.Equal(t, fake.Version, wlm.Version)
+}
+
+func TestConfig_UnmarshalYAML(t *testing.T) {
+	var legacy Config
+	err := yaml.Unmarshal([]byte(`
+debug:
+  nodes: 2
+  wall_time: 1h
+`), &legacy)
+	require.NoError(t, err)
+	require.Equal(t, Config{
+		Partitions: map[string]PartitionResources{
+			"debug": {Nodes: 2, WallTime: time.Hour},
+		},
+	}, legacy)
+
+	var multi Config
+	err = yaml.Unmarshal([]byte(`
+clusters: [east, west]
+partitions:
+  east/debug:
+    nodes: 2
+`), &multi)
+	require.NoError(t, err)
+	require.Equal(t, Config{
+		Clusters: []string{"east", "west"},
+		Partitions: map[string]PartitionResources{
+			"east/debug": {Nodes: 2},
+		},
+	}, multi)
+}
@@ -0,0 +1,320 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/internal/red-box/store"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultSubmissionRetention is used when submission retention is not configured.
+	defaultSubmissionRetention = 24 * time.Hour
+	// submissionsPruneInterval is how often expired submissions are removed.
+	submissionsPruneInterval = 10 * time.Minute
+
+	// defaultHistoryPageSize is used when job history request has no page size.
+	defaultHistoryPageSize = 100
+	maxHistoryPageSize     = 1000
+)
+
+// submissions records submitted jobs in the store, so that job history
+// can be queried after jobs are gone from the workload manager.
+// It also makes job submission idempotent: job submitted with an idempotency
+// key is returned when the same client replays the key within retention period.
+// Nothing is recorded when there is no store.
+type submissions struct {
+	store     *store.Store
+	retention time.Duration
+
+	mu   sync.Mutex
+	keys map[string]*keyLock
+}
+
+// keyLock serializes submissions with the same idempotency key.
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newSubmissions(st *store.Store, retention time.Duration) *submissions {
+	if retention == 0 {
+		retention = defaultSubmissionRetention
+	}
+	return &submissions{
+		store:     st,
+		retention: retention,
+		keys:      make(map[string]*keyLock),
+	}
+}
+
+// lock locks the idempotency key and returns a func to unlock it.
+func (s *submissions) lock(key string) func() {
+	s.mu.Lock()
+	l, ok := s.keys[key]
+	if !ok {
+		l = &keyLock{}
+		s.keys[key] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+
+		s.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(s.keys, key)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// newJob returns a history record of a job submitted with the request.
+func newJob(clientID, key, partition, script string, req interface{}) store.Job {
+	raw, err := json.Marshal(req)
+	if err != nil {
+		log.Printf("Could not marshal submit request: %s", err)
+	}
+	hash := sha256.Sum256([]byte(script))
+	return store.Job{
+		ClientID:       clientID,
+		IdempotencyKey: key,
+		Partition:      partition,
+		Request:        string(raw),
+		ScriptHash:     hex.EncodeToString(hash[:]),
+	}
+}
+
+// submit calls submit func unless a job with the same idempotency key
+// has been already submitted by the client. Submitted job is recorded in
+// the history. InvalidArgument status is returned when the key is replayed
+// with another script.
+func (s *submissions) submit(j store.Job, submit func() (int64, error)) (int64, error) {
+	if s.store == nil {
+		return submit()
+	}
+
+	var key string
+	if j.IdempotencyKey != "" {
+		// keys are chosen by clients, so they are namespaced by client id
+		key = j.ClientID + "/" + j.IdempotencyKey
+
+		// submissions with the same key may race, so they are serialized
+		defer s.lock(key)()
+
+		sub, err := s.store.Submission(key)
+		if err != nil && err != store.ErrNotFound {
+			return 0, errors.Wrap(err, "could not look up submission")
+		}
+		if err == nil && time.Since(sub.CreatedAt) < s.retention {
+			if sub.ScriptHash != j.ScriptHash {
+				return 0, status.Errorf(codes.InvalidArgument,
+					"idempotency key %s is already used for job %d with another script", j.IdempotencyKey, sub.JobID)
+			}
+			log.Printf("Job with idempotency key %s is already submitted as %d", key, sub.JobID)
+			return sub.JobID, nil
+		}
+	}
+
+	id, err := submit()
+	if err != nil {
+		return 0, err
+	}
+
+	// job is submitted anyway, so failing here would make client submit it again
+	now := time.Now()
+	j.JobID = id
+	j.SubmittedAt = now
+	if _, err := s.store.PutJob(j); err != nil {
+		log.Printf("Could not record job %d in history: %s", id, err)
+	}
+	if key == "" {
+		return id, nil
+	}
+	sub := store.Submission{JobID: id, ScriptHash: j.ScriptHash, CreatedAt: now}
+	if err := s.store.PutSubmission(key, sub); err != nil {
+		log.Printf("Could not record submission of job %d: %s", id, err)
+	}
+	return id, nil
+}
+
+// run prunes expired submissions once per prune interval until stop is closed.
+// Expired submissions are ignored anyway, pruning only keeps the store small.
+func (s *submissions) run(stop <-chan struct{}) {
+	if s.store == nil {
+		return
+	}
+
+	ticker := time.NewTicker(submissionsPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.prune()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// prune removes submissions older than retention period from the store.
+func (s *submissions) prune() {
+	if err := s.store.PruneSubmissions(time.Now().Add(-s.retention)); err != nil {
+		log.Printf("Could not prune expired submissions: %s", err)
+	}
+}
+
+// submitError wraps an error of a failed submission. Status errors, e.g. for
+// a replayed idempotency key, are returned as is, so that clients get the code.
+func submitError(err error, msg string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return errors.Wrap(err, msg)
+}
+
+// observe records final status of a job in the history once it is known.
+func (s *submissions) observe(jobID int64, info *api.JobInfo) {
+	if s.store == nil || !isFinished(info.Status) {
+		return
+	}
+
+	j, err := s.store.Job(jobID)
+	if err == store.ErrNotFound {
+		return
+	}
+	if err != nil {
+		log.Printf("Could not look up job %d in history: %s", jobID, err)
+		return
+	}
+	if j.State != "" {
+		return
+	}
+
+	if err := s.store.FinishJob(jobID, info.Status.String(), info.ExitCode, time.Now()); err != nil {
+		log.Printf("Could not record final status of job %d: %s", jobID, err)
+	}
+}
+
+// history returns a page of recorded jobs that match the request filters.
+func (s *submissions) history(req *api.JobHistoryRequest) (*api.JobHistoryResponse, error) {
+	if s.store == nil {
+		return nil, status.Error(codes.FailedPrecondition, "job history is not recorded without red-box state")
+	}
+
+	f := store.JobFilter{
+		ClientID:  req.ClientId,
+		Partition: req.Partition,
+	}
+	if req.FilterStatus {
+		f.State = req.Status.String()
+	}
+	var err error
+	if req.SubmittedAfter != nil {
+		if f.SubmittedAfter, err = ptypes.Timestamp(req.SubmittedAfter); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid submitted_after: %s", err)
+		}
+	}
+	if req.SubmittedBefore != nil {
+		if f.SubmittedBefore, err = ptypes.Timestamp(req.SubmittedBefore); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid submitted_before: %s", err)
+		}
+	}
+
+	var after uint64
+	if req.PageToken != "" {
+		if after, err = strconv.ParseUint(req.PageToken, 10, 64); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token %q", req.PageToken)
+		}
+	}
+	size := int(req.PageSize)
+	if size <= 0 {
+		size = defaultHistoryPageSize
+	}
+	if size > maxHistoryPageSize {
+		size = maxHistoryPageSize
+	}
+
+	jobs, next, err := s.store.Jobs(f, after, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read job history")
+	}
+
+	resp := &api.JobHistoryResponse{
+		Jobs: make([]*api.JobRecord, len(jobs)),
+	}
+	if next != 0 {
+		resp.NextPageToken = strconv.FormatUint(next, 10)
+	}
+	for i, j := range jobs {
+		if resp.Jobs[i], err = toJobRecord(j); err != nil {
+			return nil, errors.Wrapf(err, "could not convert job %d record", j.JobID)
+		}
+	}
+	return resp, nil
+}
+
+func toJobRecord(j *store.Job) (*api.JobRecord, error) {
+	submitTime, err := ptypes.TimestampProto(j.SubmittedAt)
+	if err != nil {
+		return nil, err
+	}
+	endTime, err := protoTime(j.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	jobStatus := api.JobStatus_UNKNOWN
+	if s, ok := api.JobStatus_value[j.State]; ok {
+		jobStatus = api.JobStatus(s)
+	}
+	return &api.JobRecord{
+		JobId:          j.JobID,
+		ClientId:       j.ClientID,
+		IdempotencyKey: j.IdempotencyKey,
+		Partition:      j.Partition,
+		Request:        j.Request,
+		ScriptHash:     j.ScriptHash,
+		SubmitTime:     submitTime,
+		EndTime:        endTime,
+		Status:         jobStatus,
+		ExitCode:       j.ExitCode,
+	}, nil
+}
+
+// isFinished returns whether job with the status will not change anymore.
+func isFinished(s api.JobStatus) bool {
+	switch s {
+	case api.JobStatus_COMPLETED, api.JobStatus_CANCELLED, api.JobStatus_FAILED, api.JobStatus_TIMEOUT:
+		return true
+	}
+	return false
+}
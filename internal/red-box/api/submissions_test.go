@@ -0,0 +1,202 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/internal/red-box/store"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSubmissions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "red-box-submissions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	st, err := store.Open(filepath.Join(dir, "red-box.db"))
+	require.NoError(t, err)
+	defer st.Close()
+
+	var lastID int64
+	submit := func() (int64, error) {
+		lastID++
+		return lastID, nil
+	}
+
+	s := newSubmissions(st, time.Hour)
+	id, err := s.submit(store.Job{IdempotencyKey: "foo"}, submit)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, id)
+
+	// replay returns the original job
+	id, err = s.submit(store.Job{IdempotencyKey: "foo"}, submit)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, id)
+
+	// failed submission is not recorded
+	_, err = s.submit(store.Job{IdempotencyKey: "bar"}, func() (int64, error) { return 0, errors.New("sbatch failed") })
+	require.Error(t, err)
+	id, err = s.submit(store.Job{IdempotencyKey: "bar"}, submit)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, id)
+
+	// jobs without a key are always submitted
+	id, err = s.submit(store.Job{IdempotencyKey: ""}, submit)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, id)
+
+	// key is forgotten after retention period
+	require.NoError(t, st.PutSubmission("/foo", store.Submission{JobID: 1, CreatedAt: time.Now().Add(-2 * time.Hour)}))
+	id, err = s.submit(store.Job{IdempotencyKey: "foo"}, submit)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, id)
+
+	id, err = newSubmissions(nil, 0).submit(store.Job{IdempotencyKey: "foo"}, submit)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, id)
+
+	// keys of different clients do not clash
+	job := newJob("operator", "baz", "debug", "#!/bin/sh", nil)
+	id, err = s.submit(job, submit)
+	require.NoError(t, err)
+	require.EqualValues(t, 6, id)
+	id, err = s.submit(newJob("other", "baz", "debug", "#!/bin/sh", nil), submit)
+	require.NoError(t, err)
+	require.EqualValues(t, 7, id)
+	id, err = s.submit(job, submit)
+	require.NoError(t, err)
+	require.EqualValues(t, 6, id)
+
+	// expired submissions are pruned
+	s.prune()
+	_, err = st.Submission("/foo")
+	require.NoError(t, err)
+	require.NoError(t, st.PutSubmission("/foo", store.Submission{JobID: 4, CreatedAt: time.Now().Add(-2 * time.Hour)}))
+	s.prune()
+	_, err = st.Submission("/foo")
+	require.Equal(t, store.ErrNotFound, err)
+
+	// key may not be replayed with another script
+	_, err = s.submit(newJob("operator", "baz", "debug", "#!/bin/bash", nil), submit)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, codes.InvalidArgument, status.Code(submitError(err, "could not submit job script")))
+	require.EqualValues(t, 7, lastID)
+}
+
+func TestSubmissions_concurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "red-box-submissions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	st, err := store.Open(filepath.Join(dir, "red-box.db"))
+	require.NoError(t, err)
+	defer st.Close()
+
+	s := newSubmissions(st, time.Hour)
+
+	// a slow submission does not block submissions with other keys
+	started := make(chan struct{})
+	release := make(chan struct{})
+	submitted := make(chan int64)
+	go func() {
+		id, err := s.submit(store.Job{IdempotencyKey: "slow"}, func() (int64, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+		require.NoError(t, err)
+		submitted <- id
+	}()
+	<-started
+	id, err := s.submit(store.Job{IdempotencyKey: "fast"}, func() (int64, error) { return 2, nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 2, id)
+	id, err = s.submit(store.Job{}, func() (int64, error) { return 3, nil })
+	require.NoError(t, err)
+	require.EqualValues(t, 3, id)
+
+	// replay of the slow submission waits for it
+	replayed := make(chan int64)
+	go func() {
+		id, err := s.submit(store.Job{IdempotencyKey: "slow"}, func() (int64, error) { return 4, nil })
+		require.NoError(t, err)
+		replayed <- id
+	}()
+	close(release)
+	require.EqualValues(t, 1, <-submitted)
+	require.EqualValues(t, 1, <-replayed)
+}
+
+func TestSubmissions_history(t *testing.T) {
+	dir, err := ioutil.TempDir("", "red-box-submissions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	st, err := store.Open(filepath.Join(dir, "red-box.db"))
+	require.NoError(t, err)
+	defer st.Close()
+
+	s := newSubmissions(st, time.Hour)
+	for i, client := range []string{"foo", "bar", "foo"} {
+		req := &api.SubmitJobRequest{Script: "#!/bin/sh", Partition: "debug", ClientId: client}
+		id := int64(i + 1)
+		_, err := s.submit(newJob(req.ClientId, "", req.Partition, req.Script, req), func() (int64, error) {
+			return id, nil
+		})
+		require.NoError(t, err)
+	}
+
+	// only final status is recorded and only once
+	s.observe(1, &api.JobInfo{Status: api.JobStatus_PENDING})
+	s.observe(1, &api.JobInfo{Status: api.JobStatus_FAILED, ExitCode: "1:0"})
+	s.observe(1, &api.JobInfo{Status: api.JobStatus_CANCELLED, ExitCode: "0:15"})
+	s.observe(42, &api.JobInfo{Status: api.JobStatus_COMPLETED})
+
+	resp, err := s.history(&api.JobHistoryRequest{ClientId: "foo", PageSize: 1})
+	require.NoError(t, err)
+	require.Len(t, resp.Jobs, 1)
+	require.EqualValues(t, 1, resp.Jobs[0].JobId)
+	require.Equal(t, api.JobStatus_FAILED, resp.Jobs[0].Status)
+	require.Equal(t, "1:0", resp.Jobs[0].ExitCode)
+	require.NotNil(t, resp.Jobs[0].EndTime)
+	require.Len(t, resp.Jobs[0].ScriptHash, 64)
+	require.Contains(t, resp.Jobs[0].Request, "#!/bin/sh")
+	require.NotEmpty(t, resp.NextPageToken)
+
+	resp, err = s.history(&api.JobHistoryRequest{ClientId: "foo", PageSize: 1, PageToken: resp.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, resp.Jobs, 1)
+	require.EqualValues(t, 3, resp.Jobs[0].JobId)
+	require.Equal(t, api.JobStatus_UNKNOWN, resp.Jobs[0].Status)
+	require.Nil(t, resp.Jobs[0].EndTime)
+
+	resp, err = s.history(&api.JobHistoryRequest{Status: api.JobStatus_COMPLETED, FilterStatus: true})
+	require.NoError(t, err)
+	require.Empty(t, resp.Jobs)
+
+	_, err = s.history(&api.JobHistoryRequest{PageToken: "foo"})
+	require.Error(t, err)
+	_, err = newSubmissions(nil, 0).history(&api.JobHistoryRequest{})
+	require.Error(t, err)
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package store implements red-box persistent state kept
+// in an embedded key-value database.
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// ErrNotFound is returned when a requested record is not in the store.
+	ErrNotFound = errors.New("record is not found")
+
+	submissionsBucket = []byte("submissions")
+	historyBucket     = []byte("history")
+	// historyJobsBucket indexes history records by job id.
+	historyJobsBucket = []byte("history-jobs")
+)
+
+type (
+	// Store keeps red-box state in a bolt database file.
+	Store struct {
+		db *bolt.DB
+	}
+
+	// Submission is a job submitted with an idempotency key.
+	Submission struct {
+		JobID      int64     `json:"job_id"`
+		ScriptHash string    `json:"script_hash,omitempty"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+
+	// Job is a history record of a submitted job.
+	Job struct {
+		// Seq is assigned by the store, records are ordered by it.
+		Seq            uint64     `json:"seq"`
+		JobID          int64      `json:"job_id"`
+		ClientID       string     `json:"client_id"`
+		IdempotencyKey string     `json:"idempotency_key,omitempty"`
+		Partition      string     `json:"partition"`
+		Request        string     `json:"request"`
+		ScriptHash     string     `json:"script_hash"`
+		SubmittedAt    time.Time  `json:"submitted_at"`
+		FinishedAt     *time.Time `json:"finished_at,omitempty"`
+		State          string     `json:"state,omitempty"`
+		ExitCode       string     `json:"exit_code,omitempty"`
+	}
+
+	// JobFilter selects history records, empty fields match any record.
+	JobFilter struct {
+		ClientID        string
+		Partition       string
+		State           string
+		SubmittedAfter  time.Time
+		SubmittedBefore time.Time
+	}
+)
+
+// Open opens a store at path, database file is created if it doesn't exist.
+// Only one process may have the store opened at a time.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, errors.Wrap(err, "could not create store directory")
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open store at %s", path)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{submissionsBucket, historyBucket, historyJobsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "could not create buckets")
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Submission returns a job submitted with the idempotency key.
+// ErrNotFound is returned if there is no such submission.
+func (s *Store) Submission(key string) (*Submission, error) {
+	var sub *Submission
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(submissionsBucket).Get([]byte(key))
+		if raw == nil {
+			return ErrNotFound
+		}
+		sub = &Submission{}
+		return json.Unmarshal(raw, sub)
+	})
+	return sub, err
+}
+
+// PutSubmission records a job submitted with the idempotency key.
+func (s *Store) PutSubmission(key string, sub Submission) error {
+	raw, err := json.Marshal(sub)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal submission")
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(submissionsBucket).Put([]byte(key), raw)
+	})
+}
+
+// PruneSubmissions removes submissions created before t.
+func (s *Store) PruneSubmissions(t time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(submissionsBucket)
+		var expired [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			var sub Submission
+			if err := json.Unmarshal(v, &sub); err != nil {
+				return err
+			}
+			if sub.CreatedAt.Before(t) {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PutJob adds a job to the history and returns its record sequence number.
+func (s *Store) PutJob(j Job) (uint64, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		j.Seq = seq
+
+		raw, err := json.Marshal(j)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal job")
+		}
+		if err := b.Put(itob(seq), raw); err != nil {
+			return err
+		}
+		return tx.Bucket(historyJobsBucket).Put(itob(uint64(j.JobID)), itob(seq))
+	})
+	return j.Seq, err
+}
+
+// Job returns the latest history record of a job.
+// ErrNotFound is returned if there is no such record.
+func (s *Store) Job(jobID int64) (*Job, error) {
+	var j *Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		j, err = job(tx, jobID)
+		return err
+	})
+	return j, err
+}
+
+// FinishJob records final state of a job.
+// ErrNotFound is returned if there is no history record of the job.
+func (s *Store) FinishJob(jobID int64, state, exitCode string, finishedAt time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		j, err := job(tx, jobID)
+		if err != nil {
+			return err
+		}
+
+		j.State = state
+		j.ExitCode = exitCode
+		j.FinishedAt = &finishedAt
+		raw, err := json.Marshal(j)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal job")
+		}
+		return tx.Bucket(historyBucket).Put(itob(j.Seq), raw)
+	})
+}
+
+// Jobs returns up to limit history records that match filter and follow
+// record with after sequence number. Sequence number of the last returned
+// record is returned to fetch the next page, it is zero when there are no more records.
+func (s *Store) Jobs(f JobFilter, after uint64, limit int) ([]*Job, uint64, error) {
+	var jobs []*Job
+	var next uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyBucket).Cursor()
+		for k, v := c.Seek(itob(after + 1)); k != nil; k, v = c.Next() {
+			var j Job
+			if err := json.Unmarshal(v, &j); err != nil {
+				return errors.Wrap(err, "could not unmarshal job")
+			}
+			if !f.match(&j) {
+				continue
+			}
+			// the page is full, but there is another matching record
+			if len(jobs) == limit {
+				next = jobs[len(jobs)-1].Seq
+				return nil
+			}
+			jobs = append(jobs, &j)
+		}
+		return nil
+	})
+	return jobs, next, err
+}
+
+func (f JobFilter) match(j *Job) bool {
+	return (f.ClientID == "" || f.ClientID == j.ClientID) &&
+		(f.Partition == "" || f.Partition == j.Partition) &&
+		(f.State == "" || f.State == j.State) &&
+		(f.SubmittedAfter.IsZero() || j.SubmittedAt.After(f.SubmittedAfter)) &&
+		(f.SubmittedBefore.IsZero() || j.SubmittedAt.Before(f.SubmittedBefore))
+}
+
+func job(tx *bolt.Tx, jobID int64) (*Job, error) {
+	seq := tx.Bucket(historyJobsBucket).Get(itob(uint64(jobID)))
+	if seq == nil {
+		return nil, ErrNotFound
+	}
+	raw := tx.Bucket(historyBucket).Get(seq)
+	if raw == nil {
+		return nil, ErrNotFound
+	}
+
+	var j Job
+	if err := json.Unmarshal(raw, &j); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal job")
+	}
+	return &j, nil
+}
+
+// itob returns big endian representation of v, so that keys are sorted.
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Submission(t *testing.T) {
+	dir, err := ioutil.TempDir("", "red-box-store")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state", "red-box.db")
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	_, err = s.Submission("foo")
+	require.Equal(t, ErrNotFound, err)
+
+	now := time.Now().UTC().Round(0)
+	require.NoError(t, s.PutSubmission("foo", Submission{JobID: 42, CreatedAt: now.Add(-time.Hour)}))
+	require.NoError(t, s.PutSubmission("bar", Submission{JobID: 43, CreatedAt: now}))
+
+	// submissions survive restart
+	require.NoError(t, s.Close())
+	s, err = Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	sub, err := s.Submission("foo")
+	require.NoError(t, err)
+	require.Equal(t, &Submission{JobID: 42, CreatedAt: now.Add(-time.Hour)}, sub)
+
+	require.NoError(t, s.PruneSubmissions(now.Add(-time.Minute)))
+	_, err = s.Submission("foo")
+	require.Equal(t, ErrNotFound, err)
+	sub, err = s.Submission("bar")
+	require.NoError(t, err)
+	require.EqualValues(t, 43, sub.JobID)
+}
+
+func TestStore_Jobs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "red-box-store")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := Open(filepath.Join(dir, "red-box.db"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.Job(1)
+	require.Equal(t, ErrNotFound, err)
+	require.Equal(t, ErrNotFound, s.FinishJob(1, "COMPLETED", "0:0", time.Now()))
+
+	now := time.Now().UTC().Round(0)
+	for i, client := range []string{"foo", "bar", "foo", "foo"} {
+		seq, err := s.PutJob(Job{
+			JobID:       int64(i + 1),
+			ClientID:    client,
+			Partition:   "debug",
+			SubmittedAt: now.Add(time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, i+1, seq)
+	}
+
+	require.NoError(t, s.FinishJob(3, "FAILED", "1:0", now.Add(time.Hour)))
+	j, err := s.Job(3)
+	require.NoError(t, err)
+	require.Equal(t, "FAILED", j.State)
+	require.Equal(t, "1:0", j.ExitCode)
+	require.Equal(t, now.Add(time.Hour), *j.FinishedAt)
+
+	jobs, next, err := s.Jobs(JobFilter{ClientID: "foo"}, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	require.EqualValues(t, 1, jobs[0].JobID)
+	require.EqualValues(t, 3, jobs[1].JobID)
+	require.EqualValues(t, 3, next)
+
+	jobs, next, err = s.Jobs(JobFilter{ClientID: "foo"}, next, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.EqualValues(t, 4, jobs[0].JobID)
+	require.Zero(t, next)
+
+	// no token is returned when the following records don't match
+	jobs, next, err = s.Jobs(JobFilter{ClientID: "bar"}, 0, 1)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.EqualValues(t, 2, jobs[0].JobID)
+	require.Zero(t, next)
+
+	jobs, _, err = s.Jobs(JobFilter{State: "FAILED"}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.EqualValues(t, 3, jobs[0].JobID)
+
+	jobs, _, err = s.Jobs(JobFilter{SubmittedAfter: now, SubmittedBefore: now.Add(3 * time.Minute)}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	require.EqualValues(t, 2, jobs[0].JobID)
+}
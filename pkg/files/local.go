@@ -0,0 +1,216 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package files implements file operations that red-box performs
+// on behalf of its clients, e.g. data upload and results collection.
+package files
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dptech-corp/wlm-operator/pkg/tail"
+	"github.com/pkg/errors"
+)
+
+// ErrFileNotFound is returned when Open fails to find a file.
+var ErrFileNotFound = errors.New("file is not found")
+
+type (
+	// FileSystem is a set of file operations that red-box performs
+	// on behalf of its clients.
+	FileSystem interface {
+		Open(path string) (io.ReadCloser, error)
+		Create(path string) (io.WriteCloser, error)
+		Tail(path string) (io.ReadCloser, error)
+		Zip(path string, target string) error
+		Unzip(source string, path string) error
+	}
+
+	// Local performs file operations on the local file system.
+	Local struct{}
+)
+
+// Open opens arbitrary file at path in a read-only mode.
+func (Local) Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrFileNotFound
+	}
+	return file, errors.Wrapf(err, "could not open %s", path)
+}
+
+// Create opens a file at path in write mode.
+func (Local) Create(path string) (io.WriteCloser, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return file, errors.Wrapf(err, "could not create %s", path)
+}
+
+// Tail opens arbitrary file at path in a read-only mode.
+// Unlike Open, Tail will watch file changes in a real-time.
+func (Local) Tail(path string) (io.ReadCloser, error) {
+	tr, err := tail.NewReader(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create tail reader")
+	}
+
+	return tr, nil
+}
+
+// Zip file or directory
+func (Local) Zip(path string, target string) error {
+	err := zipFile(path, target)
+	if err != nil {
+		return errors.Wrap(err, "could not zip file or directory")
+	}
+	return nil
+}
+
+// Unzip file or directory
+func (Local) Unzip(source string, path string) error {
+	err := unzip(source, path)
+	if err != nil {
+		return errors.Wrap(err, "could not unzip file")
+	}
+	return nil
+}
+
+func zipFile(source, target string) error {
+	// 1. Create a ZIP file and zip.Writer
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	writer := zip.NewWriter(f)
+	defer writer.Close()
+
+	// 2. Go through all the files of the source
+	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// 3. Create a local file header
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+
+		// set compression
+		header.Method = zip.Deflate
+
+		// 4. Set relative path of a file as the header name
+		header.Name, err = filepath.Rel(filepath.Dir(source), path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		// 5. Create writer for the file header and save content of the file
+		headerWriter, err := writer.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(headerWriter, f)
+		return err
+	})
+}
+
+func unzip(source, destination string) error {
+	// 1. Open the zip file
+	reader, err := zip.OpenReader(source)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// 2. Get the absolute destination path
+	destination, err = filepath.Abs(destination)
+	if err != nil {
+		return err
+	}
+
+	// 3. Iterate over zip files inside the archive and unzip each of them
+	for _, f := range reader.File {
+		err := unzipFile(f, destination)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func unzipFile(f *zip.File, destination string) error {
+	// 4. Check if file paths are not vulnerable to Zip Slip
+	filePath := filepath.Join(destination, f.Name)
+	if !strings.HasPrefix(filePath, filepath.Clean(destination)+string(os.PathSeparator)) {
+		return errors.Errorf("invalid file path: %s", filePath)
+	}
+
+	// 5. Create directory tree
+	if f.FileInfo().IsDir() {
+		if err := os.MkdirAll(filePath, os.ModePerm); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return err
+	}
+
+	// 6. Create a destination file for unzipped content
+	destinationFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+	if err != nil {
+		return err
+	}
+	defer destinationFile.Close()
+
+	// 7. Unzip the content of a file and copy it to the destination file
+	zippedFile, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer zippedFile.Close()
+
+	if _, err := io.Copy(destinationFile, zippedFile); err != nil {
+		return err
+	}
+	return nil
+}
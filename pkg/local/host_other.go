@@ -0,0 +1,27 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !linux
+
+package local
+
+import (
+	"runtime"
+)
+
+// HostResources returns amount of CPUs and memory in megabytes available on the host.
+// Memory discovery is supported on linux only, on other platforms it is reported as 0.
+func HostResources() (cpus, mem int64, err error) {
+	return int64(runtime.NumCPU()), 0, nil
+}
@@ -0,0 +1,430 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package local implements a workload manager that runs batch scripts
+// directly on the host as supervised child processes. It is intended for
+// small single-host deployments and end-to-end tests without a real WLM.
+package local
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// StatePending means job is waiting for a free slot.
+	StatePending = "PENDING"
+	// StateRunning means job process is running.
+	StateRunning = "RUNNING"
+	// StateCompleted means job process exited with zero code.
+	StateCompleted = "COMPLETED"
+	// StateFailed means job process exited with non-zero code or could not be started.
+	StateFailed = "FAILED"
+	// StateCancelled means job was cancelled by a user.
+	StateCancelled = "CANCELLED"
+
+	jobFile      = "job.json"
+	scriptFile   = "script.sh"
+	stdoutFile   = "stdout"
+	stderrFile   = "stderr"
+	exitCodeFile = ".exit_code"
+
+	// killTimeout is how long a cancelled job is given to
+	// terminate after SIGTERM before it is killed.
+	killTimeout = 10 * time.Second
+	// pollInterval is how often processes that were started by
+	// a previous executor instance are checked for liveness.
+	pollInterval = time.Second
+)
+
+var (
+	// ErrJobNotFound is returned when job with requested id does not exist.
+	ErrJobNotFound = errors.New("job is not found")
+)
+
+type (
+	// Job contains information about a job run by Executor.
+	Job struct {
+		ID         int64      `json:"id"`
+		Name       string     `json:"name"`
+		UserID     string     `json:"user_id"`
+		State      string     `json:"state"`
+		ExitCode   int        `json:"exit_code"`
+		SubmitTime time.Time  `json:"submit_time"`
+		StartTime  *time.Time `json:"start_time,omitempty"`
+		EndTime    *time.Time `json:"end_time,omitempty"`
+		WorkDir    string     `json:"work_dir"`
+		StdOut     string     `json:"std_out"`
+		StdErr     string     `json:"std_err"`
+		PID        int        `json:"pid,omitempty"`
+		Cancelled  bool       `json:"cancelled,omitempty"`
+	}
+
+	// Executor runs submitted batch scripts as child processes. Each job gets
+	// its own working directory inside the executor directory, where the script,
+	// its stdout and stderr and the job table record are stored. No more than
+	// slots jobs are run simultaneously, the rest are pending in submission order.
+	Executor struct {
+		dir   string
+		slots int
+		user  string
+
+		mu      sync.Mutex
+		jobs    map[int64]*Job
+		lastID  int64
+		running int
+	}
+)
+
+// NewExecutor creates a new executor that keeps its state in dir and runs
+// at most slots jobs at a time. Jobs left by a previous executor are restored:
+// pending ones are queued again, running ones are watched till they exit.
+func NewExecutor(dir string, slots int) (*Executor, error) {
+	if slots < 1 {
+		return nil, errors.Errorf("invalid amount of slots %d", slots)
+	}
+
+	jobsDir := filepath.Join(dir, "jobs")
+	if err := os.MkdirAll(jobsDir, 0755); err != nil {
+		return nil, errors.Wrap(err, "could not create jobs directory")
+	}
+
+	userName := strconv.Itoa(os.Getuid())
+	if u, err := user.Current(); err == nil {
+		userName = u.Username
+	}
+
+	e := &Executor{
+		dir:   jobsDir,
+		slots: slots,
+		user:  userName,
+		jobs:  make(map[int64]*Job),
+	}
+	if err := e.restore(); err != nil {
+		return nil, errors.Wrap(err, "could not restore job table")
+	}
+
+	e.mu.Lock()
+	e.schedule()
+	e.mu.Unlock()
+	return e, nil
+}
+
+// Submit stores the script in a new job directory and queues it for execution.
+func (e *Executor) Submit(script, name string) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := e.lastID + 1
+	workDir := filepath.Join(e.dir, strconv.FormatInt(id, 10))
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return 0, errors.Wrap(err, "could not create job directory")
+	}
+	scriptPath := filepath.Join(workDir, scriptFile)
+	if err := ioutil.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+		return 0, errors.Wrap(err, "could not write job script")
+	}
+	// script is executed directly to respect its shebang, umask
+	// may have dropped the execute bit on file creation
+	if err := os.Chmod(scriptPath, 0755); err != nil {
+		return 0, errors.Wrap(err, "could not make job script executable")
+	}
+
+	j := &Job{
+		ID:         id,
+		Name:       name,
+		UserID:     e.user,
+		State:      StatePending,
+		SubmitTime: time.Now(),
+		WorkDir:    workDir,
+		StdOut:     filepath.Join(workDir, stdoutFile),
+		StdErr:     filepath.Join(workDir, stderrFile),
+	}
+	if err := e.save(j); err != nil {
+		return 0, err
+	}
+
+	e.lastID = id
+	e.jobs[id] = j
+	e.schedule()
+	return id, nil
+}
+
+// Cancel cancels a job. Pending job is cancelled immediately, running one
+// is terminated by signalling its process group.
+func (e *Executor) Cancel(id int64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	j, ok := e.jobs[id]
+	if !ok {
+		return ErrJobNotFound
+	}
+
+	switch j.State {
+	case StatePending:
+		now := time.Now()
+		j.State = StateCancelled
+		j.EndTime = &now
+		return e.save(j)
+	case StateRunning:
+		if j.Cancelled {
+			return nil
+		}
+		j.Cancelled = true
+		if err := e.save(j); err != nil {
+			return err
+		}
+		return terminate(j.PID)
+	default:
+		return errors.Errorf("job %d is already finished", id)
+	}
+}
+
+// Job returns a copy of job table record.
+func (e *Executor) Job(id int64) (*Job, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	j, ok := e.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	jc := *j
+	return &jc, nil
+}
+
+// Script returns the script job was submitted with.
+func (e *Executor) Script(id int64) (string, error) {
+	j, err := e.Job(id)
+	if err != nil {
+		return "", err
+	}
+
+	script, err := ioutil.ReadFile(filepath.Join(j.WorkDir, scriptFile))
+	if err != nil {
+		return "", errors.Wrap(err, "could not read job script")
+	}
+	return string(script), nil
+}
+
+// Slots returns maximum number of simultaneously running jobs.
+func (e *Executor) Slots() int {
+	return e.slots
+}
+
+// schedule starts pending jobs while there are free slots.
+// Must be called with mutex held.
+func (e *Executor) schedule() {
+	if e.running >= e.slots {
+		return
+	}
+
+	var pending []*Job
+	for _, j := range e.jobs {
+		if j.State == StatePending {
+			pending = append(pending, j)
+		}
+	}
+	sort.Slice(pending, func(i, k int) bool { return pending[i].ID < pending[k].ID })
+
+	for _, j := range pending {
+		if e.running >= e.slots {
+			return
+		}
+		if err := e.start(j); err != nil {
+			log.Printf("Could not start job %d: %s", j.ID, err)
+			now := time.Now()
+			j.State = StateFailed
+			j.ExitCode = -1
+			j.EndTime = &now
+			_ = e.save(j)
+		}
+	}
+}
+
+// start runs job script in its own process group. Exit code of the script is
+// written to a file by a wrapping shell, so that it survives executor restart.
+// Must be called with mutex held.
+func (e *Executor) start(j *Job) error {
+	stdout, err := os.Create(j.StdOut)
+	if err != nil {
+		return errors.Wrap(err, "could not create stdout file")
+	}
+	defer stdout.Close()
+	stderr, err := os.Create(j.StdErr)
+	if err != nil {
+		return errors.Wrap(err, "could not create stderr file")
+	}
+	defer stderr.Close()
+
+	cmd := exec.Command("/bin/sh", "-c", `./`+scriptFile+`; echo $? > `+exitCodeFile)
+	cmd.Dir = j.WorkDir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Env = append(os.Environ(),
+		"WLM_JOB_ID="+strconv.FormatInt(j.ID, 10),
+		"WLM_JOB_NAME="+j.Name,
+	)
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "could not start job process")
+	}
+
+	now := time.Now()
+	j.State = StateRunning
+	j.StartTime = &now
+	j.PID = cmd.Process.Pid
+	e.running++
+	if err := e.save(j); err != nil {
+		log.Printf("Could not save job %d: %s", j.ID, err)
+	}
+
+	go func() {
+		_ = cmd.Wait()
+		e.finish(j.ID)
+	}()
+	return nil
+}
+
+// watch waits till a process started by a previous executor exits.
+func (e *Executor) watch(id int64, pid int) {
+	for alive(pid) {
+		time.Sleep(pollInterval)
+	}
+	e.finish(id)
+}
+
+// finish updates job record after its process exited and frees the slot.
+func (e *Executor) finish(id int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	j := e.jobs[id]
+	now := time.Now()
+	j.EndTime = &now
+	j.PID = 0
+
+	code, err := readExitCode(filepath.Join(j.WorkDir, exitCodeFile))
+	switch {
+	case j.Cancelled:
+		j.State = StateCancelled
+		if err == nil {
+			j.ExitCode = code
+		}
+	case err != nil:
+		log.Printf("Could not get job %d exit code: %s", id, err)
+		j.State = StateFailed
+		j.ExitCode = -1
+	case code == 0:
+		j.State = StateCompleted
+	default:
+		j.State = StateFailed
+		j.ExitCode = code
+	}
+	if err := e.save(j); err != nil {
+		log.Printf("Could not save job %d: %s", id, err)
+	}
+
+	e.running--
+	e.schedule()
+}
+
+// restore loads job table from the executor directory.
+func (e *Executor) restore() error {
+	entries, err := ioutil.ReadDir(e.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		id, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		if id > e.lastID {
+			e.lastID = id
+		}
+
+		raw, err := ioutil.ReadFile(filepath.Join(e.dir, entry.Name(), jobFile))
+		if err != nil {
+			// job directory was created, but submission did not succeed
+			continue
+		}
+		var j Job
+		if err := json.Unmarshal(raw, &j); err != nil {
+			return errors.Wrapf(err, "could not decode job %d", id)
+		}
+		e.jobs[id] = &j
+
+		if j.State == StateRunning {
+			e.running++
+			go e.watch(j.ID, j.PID)
+		}
+	}
+	return nil
+}
+
+// save atomically writes job record into its directory.
+func (e *Executor) save(j *Job) error {
+	raw, err := json.Marshal(j)
+	if err != nil {
+		return errors.Wrap(err, "could not encode job")
+	}
+
+	path := filepath.Join(j.WorkDir, jobFile)
+	if err := ioutil.WriteFile(path+".tmp", raw, 0644); err != nil {
+		return errors.Wrap(err, "could not write job")
+	}
+	return errors.Wrap(os.Rename(path+".tmp", path), "could not write job")
+}
+
+func readExitCode(path string) (int, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(raw)))
+}
+
+// terminate sends SIGTERM to the process group and SIGKILL
+// if it is still alive after killTimeout.
+func terminate(pid int) error {
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil {
+		return errors.Wrap(err, "could not terminate job process group")
+	}
+
+	go func() {
+		time.Sleep(killTimeout)
+		_ = syscall.Kill(-pid, syscall.SIGKILL)
+	}()
+	return nil
+}
+
+func alive(pid int) bool {
+	return pid > 0 && syscall.Kill(pid, 0) == nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func waitState(t *testing.T, e *Executor, id int64, state string) *Job {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		j, err := e.Job(id)
+		require.NoError(t, err)
+		if j.State == state {
+			return j
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	j, _ := e.Job(id)
+	t.Fatalf("job %d is in %s state, expected %s", id, j.State, state)
+	return nil
+}
+
+func TestExecutor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-executor")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	e, err := NewExecutor(dir, 1)
+	require.NoError(t, err)
+
+	t.Run("completed", func(t *testing.T) {
+		id, err := e.Submit("#!/bin/sh\necho hello $WLM_JOB_ID\necho oops >&2\n", "test")
+		require.NoError(t, err)
+
+		j := waitState(t, e, id, StateCompleted)
+		require.Equal(t, 0, j.ExitCode)
+		require.NotNil(t, j.StartTime)
+		require.NotNil(t, j.EndTime)
+
+		out, err := ioutil.ReadFile(j.StdOut)
+		require.NoError(t, err)
+		require.Equal(t, "hello 1\n", string(out))
+		out, err = ioutil.ReadFile(j.StdErr)
+		require.NoError(t, err)
+		require.Equal(t, "oops\n", string(out))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		id, err := e.Submit("#!/bin/sh\nexit 3\n", "test")
+		require.NoError(t, err)
+
+		j := waitState(t, e, id, StateFailed)
+		require.Equal(t, 3, j.ExitCode)
+	})
+
+	t.Run("slots and cancel", func(t *testing.T) {
+		first, err := e.Submit("#!/bin/sh\nsleep 60\n", "first")
+		require.NoError(t, err)
+		second, err := e.Submit("#!/bin/sh\necho second\n", "second")
+		require.NoError(t, err)
+
+		waitState(t, e, first, StateRunning)
+		j, err := e.Job(second)
+		require.NoError(t, err)
+		require.Equal(t, StatePending, j.State)
+
+		require.NoError(t, e.Cancel(first))
+		waitState(t, e, first, StateCancelled)
+		waitState(t, e, second, StateCompleted)
+
+		require.Error(t, e.Cancel(second))
+		require.Equal(t, ErrJobNotFound, e.Cancel(100))
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		restored, err := NewExecutor(dir, 1)
+		require.NoError(t, err)
+
+		j, err := restored.Job(1)
+		require.NoError(t, err)
+		require.Equal(t, StateCompleted, j.State)
+
+		id, err := restored.Submit("#!/bin/sh\ntrue\n", "test")
+		require.NoError(t, err)
+		require.EqualValues(t, 5, id)
+	})
+}
+
+func TestExecutor_shebang(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-executor")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	e, err := NewExecutor(dir, 1)
+	require.NoError(t, err)
+
+	const script = "#!/bin/cat\nthis is not a shell script\n"
+	id, err := e.Submit(script, "test")
+	require.NoError(t, err)
+
+	j := waitState(t, e, id, StateCompleted)
+	out, err := ioutil.ReadFile(j.StdOut)
+	require.NoError(t, err)
+	require.Equal(t, script, string(out))
+
+	// script without shebang is run by the shell
+	id, err = e.Submit("echo plain\n", "test")
+	require.NoError(t, err)
+	j = waitState(t, e, id, StateCompleted)
+	out, err = ioutil.ReadFile(j.StdOut)
+	require.NoError(t, err)
+	require.Equal(t, "plain\n", string(out))
+}
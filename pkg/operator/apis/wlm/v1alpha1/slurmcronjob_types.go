@@ -0,0 +1,101 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConcurrencyPolicy describes how a SlurmCronJob handles a scheduled run
+// while a previously created SlurmJob is still active.
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent allows SlurmJobs to run concurrently.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent skips the scheduled run if the previous one hasn't finished yet.
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+	// ReplaceConcurrent deletes the active SlurmJobs and creates a new one.
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// SlurmCronJobSpec defines the desired state of SlurmCronJob.
+// +k8s:openapi-gen=true
+type SlurmCronJobSpec struct {
+	// Schedule in Cron format, e.g. "0 2 * * *", see https://en.wikipedia.org/wiki/Cron.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// StartingDeadlineSeconds is a deadline for starting a SlurmJob if it misses
+	// its scheduled time for any reason. Missed runs are counted as failed ones.
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// ConcurrencyPolicy specifies how to treat concurrent runs, one of Allow (default),
+	// Forbid or Replace.
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// JobTemplate is a spec of SlurmJobs created on schedule.
+	JobTemplate SlurmJobSpec `json:"jobTemplate"`
+
+	// SuccessfulJobsHistoryLimit is a number of successful finished SlurmJobs to keep.
+	// Defaults to 3.
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit is a number of failed finished SlurmJobs to keep.
+	// Defaults to 1.
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+// SlurmCronJobStatus defines the observed state of a SlurmCronJob.
+// +k8s:openapi-gen=true
+type SlurmCronJobStatus struct {
+	// Active is a list of references to currently running SlurmJobs.
+	Active []v1.ObjectReference `json:"active,omitempty"`
+
+	// LastScheduleTime is time the SlurmJob was last successfully scheduled at.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SlurmCronJob is the Schema for the slurmcronjobs API.
+// +genclient
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:shortName=scj
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",description="cron schedule"
+// +kubebuilder:printcolumn:name="Last Schedule",type="date",JSONPath=".status.lastScheduleTime",description="time job was last scheduled at"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type SlurmCronJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SlurmCronJobSpec   `json:"spec,omitempty"`
+	Status SlurmCronJobStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SlurmCronJobList contains a list of SlurmCronJob.
+type SlurmCronJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SlurmCronJob `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SlurmCronJob{}, &SlurmCronJobList{})
+}
@@ -46,6 +46,25 @@ type SlurmJobSpec struct {
 	// When specified, after job is completed all results will be downloaded from Slurm
 	// cluster with respect to this configuration.
 	Results *JobResults `json:"results,omitempty"`
+
+	// RetryPolicy may be specified to retry failed jobs.
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// TTLSecondsAfterFinished limits lifetime of a finished job. When set, job is
+	// deleted together with its pods once the TTL passes after the job is finished.
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// Suspend holds a pending job and suspends a running one when true.
+	// Setting it back to false releases or resumes the job.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// SuspendMode defines what happens to a running job when it is suspended,
+	// either Requeue (default) or Suspend, which requires Slurm operator or admin rights.
+	SuspendMode SuspendMode `json:"suspendMode,omitempty"`
+
+	// Array submits the batch script as a Slurm job array. When set, job status
+	// tracks array tasks and retry policy applies to failed tasks individually.
+	Array *JobArray `json:"array,omitempty"`
 }
 
 // SlurmJobStatus defines the observed state of a SlurmJob.
@@ -57,6 +76,9 @@ type SlurmJobStatus struct {
 
 	// Status reflects job status, e.g running, succeeded.
 	Status string `json:"status"`
+
+	// JobStatus describes the workload manager job.
+	JobStatus `json:",inline"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -68,6 +90,11 @@ type SlurmJobStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status",description="status of the kind"
+// +kubebuilder:printcolumn:name="Job ID",type="string",JSONPath=".status.jobID",description="id of the workload manager job"
+// +kubebuilder:printcolumn:name="Partition",type="string",JSONPath=".status.partition",description="partition job was submitted to"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="workload manager job state"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.reason",description="reason job is pending or failed",priority=1
+// +kubebuilder:printcolumn:name="Nodes",type="string",JSONPath=".status.nodeList",description="nodes job runs on",priority=1
 type SlurmJob struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,158 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DependencyCondition defines when a workflow step dependency is satisfied.
+// Conditions are named after Slurm dependency types.
+type DependencyCondition string
+
+const (
+	// DependencyAfterOK is satisfied once the dependency succeeds.
+	DependencyAfterOK DependencyCondition = "afterok"
+	// DependencyAfterAny is satisfied once the dependency finishes either way.
+	DependencyAfterAny DependencyCondition = "afterany"
+)
+
+// WorkflowStepPhase is a phase of a workflow step.
+type WorkflowStepPhase string
+
+const (
+	// StepWaiting means step job is not created yet since its dependencies are not satisfied.
+	StepWaiting WorkflowStepPhase = "Waiting"
+	// StepPending means step job is created but it is not running yet.
+	StepPending WorkflowStepPhase = "Pending"
+	// StepRunning means step job is running.
+	StepRunning WorkflowStepPhase = "Running"
+	// StepSucceeded means step job finished successfully.
+	StepSucceeded WorkflowStepPhase = "Succeeded"
+	// StepFailed means step job failed.
+	StepFailed WorkflowStepPhase = "Failed"
+	// StepSkipped means step will never run since its dependencies can't be satisfied.
+	StepSkipped WorkflowStepPhase = "Skipped"
+)
+
+// WorkflowPhase is a phase of a workflow.
+type WorkflowPhase string
+
+const (
+	// WorkflowRunning means some of the workflow steps are not finished yet.
+	WorkflowRunning WorkflowPhase = "Running"
+	// WorkflowSucceeded means all workflow steps succeeded.
+	WorkflowSucceeded WorkflowPhase = "Succeeded"
+	// WorkflowFailed means all workflow steps are finished and some of them failed or were skipped.
+	WorkflowFailed WorkflowPhase = "Failed"
+)
+
+// WorkflowDependency is an edge of a workflow.
+// +k8s:openapi-gen=true
+type WorkflowDependency struct {
+	// Step is a name of a step this step depends on.
+	Step string `json:"step"`
+	// Condition is one of afterok (default) or afterany.
+	Condition DependencyCondition `json:"condition,omitempty"`
+}
+
+// WorkflowStep is a named job of a workflow.
+// +k8s:openapi-gen=true
+type WorkflowStep struct {
+	// Name of the step, unique within the workflow.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// DependsOn is a list of steps that should finish before this step starts.
+	DependsOn []WorkflowDependency `json:"dependsOn,omitempty"`
+
+	// SlurmJob is a spec of a SlurmJob the step runs.
+	// Exactly one of SlurmJob and WlmJob should be set.
+	SlurmJob *SlurmJobSpec `json:"slurmJob,omitempty"`
+
+	// WlmJob is a spec of a WlmJob the step runs.
+	// Exactly one of SlurmJob and WlmJob should be set.
+	WlmJob *WlmJobSpec `json:"wlmJob,omitempty"`
+}
+
+// SlurmWorkflowSpec defines the desired state of SlurmWorkflow.
+// +k8s:openapi-gen=true
+type SlurmWorkflowSpec struct {
+	// Steps of the workflow, dependencies should form a directed acyclic graph.
+	Steps []WorkflowStep `json:"steps"`
+}
+
+// WorkflowStepStatus describes observed state of a workflow step.
+// +k8s:openapi-gen=true
+type WorkflowStepStatus struct {
+	// Name of the step.
+	Name string `json:"name"`
+	// Phase of the step.
+	Phase WorkflowStepPhase `json:"phase"`
+	// Job is a name of the SlurmJob or WlmJob created for the step.
+	Job string `json:"job,omitempty"`
+	// JobID is an id of the workload manager job.
+	JobID string `json:"jobID,omitempty"`
+	// State is workload manager job state.
+	State string `json:"state,omitempty"`
+	// StartTime is time the step job was started at.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// EndTime is time the step job finished at.
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// Message is a human readable description of the step phase.
+	Message string `json:"message,omitempty"`
+}
+
+// SlurmWorkflowStatus defines the observed state of SlurmWorkflow.
+// +k8s:openapi-gen=true
+type SlurmWorkflowStatus struct {
+	// Phase of the workflow.
+	Phase WorkflowPhase `json:"phase,omitempty"`
+	// Message is a human readable description of the workflow phase,
+	// e.g. why the workflow spec is invalid.
+	Message string `json:"message,omitempty"`
+	// Steps describe workflow steps in the spec order.
+	Steps []WorkflowStepStatus `json:"steps,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SlurmWorkflow is the Schema for the slurmworkflows API.
+// +genclient
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:shortName=swf
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="workflow phase"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type SlurmWorkflow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SlurmWorkflowSpec   `json:"spec,omitempty"`
+	Status SlurmWorkflowStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SlurmWorkflowList contains a list of SlurmWorkflow.
+type SlurmWorkflowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SlurmWorkflow `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SlurmWorkflow{}, &SlurmWorkflowList{})
+}
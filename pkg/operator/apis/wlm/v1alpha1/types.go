@@ -14,7 +14,45 @@
 
 package v1alpha1
 
-import v1 "k8s.io/api/core/v1"
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// JobConditionType is a type of a job condition.
+type JobConditionType string
+
+const (
+	// JobSubmitted means job was submitted to a workload manager.
+	JobSubmitted JobConditionType = "Submitted"
+	// JobRunning means job is running.
+	JobRunning JobConditionType = "Running"
+	// JobSucceeded means job finished successfully.
+	JobSucceeded JobConditionType = "Succeeded"
+	// JobFailed means job failed, was cancelled or timed out.
+	JobFailed JobConditionType = "Failed"
+	// JobResultsCollected means job results were collected.
+	JobResultsCollected JobConditionType = "ResultsCollected"
+	// JobSuspended means job is held or suspended on user request.
+	JobSuspended JobConditionType = "Suspended"
+	// JobCancelRequested means workload manager job was cancelled because
+	// the job object is deleted, and it is awaited to reach a terminal state.
+	JobCancelRequested JobConditionType = "CancelRequested"
+)
+
+// SuspendMode defines how a running job is suspended.
+type SuspendMode string
+
+const (
+	// SuspendModeSuspend suspends a running job, the job keeps its
+	// allocation and continues where it stopped when resumed.
+	// Slurm allows it to operators and administrators only.
+	SuspendModeSuspend SuspendMode = "Suspend"
+	// SuspendModeRequeue puts a running job back to the queue and holds it,
+	// the job releases its allocation and starts over when released.
+	// It is the default mode.
+	SuspendModeRequeue SuspendMode = "Requeue"
+)
 
 // PrepareData is a schema for data preparation.
 // +k8s:openapi-gen=true
@@ -36,3 +74,156 @@ type JobResults struct {
 	// From is a path to the results to be collected from a Slurm cluster.
 	From string `json:"from"`
 }
+
+// JobStatus describes a workload manager job that runs a SlurmJob or a WlmJob.
+// +k8s:openapi-gen=true
+type JobStatus struct {
+	// JobID is an id of the submitted job.
+	JobID string `json:"jobID,omitempty"`
+	// Cluster is a name of a cluster job was submitted to, it is empty unless
+	// red-box serves several clusters.
+	Cluster string `json:"cluster,omitempty"`
+	// Partition is a name of a partition job was submitted to.
+	Partition string `json:"partition,omitempty"`
+	// State is job state reported by workload manager, e.g. PENDING or RUNNING.
+	State string `json:"state,omitempty"`
+	// Reason is a reason job is pending or failed, e.g. Resources.
+	Reason string `json:"reason,omitempty"`
+	// SubmitTime is time job was submitted at.
+	SubmitTime *metav1.Time `json:"submitTime,omitempty"`
+	// StartTime is time job was started at.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// EndTime is time job finished at.
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// ExitCode is job exit code, for Slurm it is in form "code:signal".
+	ExitCode string `json:"exitCode,omitempty"`
+	// NodeList is a list of nodes job runs on.
+	NodeList string `json:"nodeList,omitempty"`
+	// Conditions describe observed job lifecycle.
+	Conditions []JobCondition `json:"conditions,omitempty"`
+	// Attempts are previous failed attempts to run the job
+	// that were retried according to the retry policy.
+	Attempts []JobAttempt `json:"attempts,omitempty"`
+	// Array describes tasks of a job array.
+	Array *ArrayStatus `json:"array,omitempty"`
+}
+
+// JobAttempt describes a finished attempt to run a job.
+// +k8s:openapi-gen=true
+type JobAttempt struct {
+	// JobID is an id of the submitted job.
+	JobID string `json:"jobID,omitempty"`
+	// Cluster is a name of a cluster job was submitted to.
+	Cluster string `json:"cluster,omitempty"`
+	// Partition is a name of a partition job was submitted to.
+	Partition string `json:"partition,omitempty"`
+	// State is job state reported by workload manager, e.g. NODE_FAIL.
+	State string `json:"state,omitempty"`
+	// Reason is a reason job failed.
+	Reason string `json:"reason,omitempty"`
+	// StartTime is time job was started at.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// EndTime is time job finished at.
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// ExitCode is job exit code.
+	ExitCode string `json:"exitCode,omitempty"`
+	// NodeList is a list of nodes job ran on.
+	NodeList string `json:"nodeList,omitempty"`
+}
+
+// JobArray describes a Slurm job array.
+// +k8s:openapi-gen=true
+type JobArray struct {
+	// Indices are array task indices in sbatch --array syntax,
+	// e.g. 0-99, 0-15:4 or 1,3,5-7.
+	// +kubebuilder:validation:MinLength=1
+	Indices string `json:"indices"`
+	// Throttle limits the number of simultaneously running tasks.
+	// +kubebuilder:validation:Minimum=0
+	Throttle int32 `json:"throttle,omitempty"`
+}
+
+// ArrayStatus describes tasks of a job array.
+// +k8s:openapi-gen=true
+type ArrayStatus struct {
+	// Total is the number of array tasks.
+	Total int32 `json:"total"`
+	// Pending is the number of tasks waiting to be started.
+	Pending int32 `json:"pending,omitempty"`
+	// Running is the number of running or suspended tasks.
+	Running int32 `json:"running,omitempty"`
+	// Succeeded is the number of tasks completed successfully.
+	Succeeded int32 `json:"succeeded,omitempty"`
+	// Failed is the number of tasks that failed.
+	Failed int32 `json:"failed,omitempty"`
+	// SucceededIndices are indices of succeeded tasks, e.g. 0,2,6-9.
+	SucceededIndices string `json:"succeededIndices,omitempty"`
+	// FailedIndices are indices of failed tasks, e.g. 1,3-5.
+	FailedIndices string `json:"failedIndices,omitempty"`
+	// Tasks describe array tasks that were retried, failed or are running,
+	// at most 100 of them. Other tasks are only counted.
+	Tasks []ArrayTaskStatus `json:"tasks,omitempty"`
+}
+
+// ArrayTaskStatus describes a task of a job array.
+// +k8s:openapi-gen=true
+type ArrayTaskStatus struct {
+	// Index is the array task index.
+	Index int32 `json:"index"`
+	// JobID is an id of the task job.
+	JobID string `json:"jobID,omitempty"`
+	// State is task state reported by workload manager.
+	State string `json:"state,omitempty"`
+	// ExitCode is task exit code in form "code:signal".
+	ExitCode string `json:"exitCode,omitempty"`
+	// EndTime is time task finished at.
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// Retries is the number of times the task was requeued.
+	Retries int32 `json:"retries,omitempty"`
+	// RetryTime is time the task was last requeued at.
+	RetryTime *metav1.Time `json:"retryTime,omitempty"`
+}
+
+// RetryPolicy describes how failed jobs are retried.
+// +k8s:openapi-gen=true
+type RetryPolicy struct {
+	// BackoffLimit is a number of retries before job is considered failed.
+	BackoffLimit int32 `json:"backoffLimit"`
+	// BackoffSeconds is a delay before the first retry, the delay is doubled
+	// for each next retry up to one hour. Defaults to 10.
+	BackoffSeconds int32 `json:"backoffSeconds,omitempty"`
+	// RetryOn is a list of workload manager job states that are retried,
+	// e.g. NODE_FAIL, PREEMPTED, BOOT_FAIL, FAILED or TIMEOUT.
+	// Defaults to NODE_FAIL, PREEMPTED and BOOT_FAIL.
+	RetryOn []string `json:"retryOn,omitempty"`
+	// Alternatives is an ordered list of targets for retries. N-th retry
+	// is scheduled to the N-th alternative, retries beyond the list length
+	// are scheduled to the last alternative. When empty all retries are
+	// scheduled the same way as the first attempt.
+	Alternatives []RetryTarget `json:"alternatives,omitempty"`
+}
+
+// RetryTarget describes where a job is scheduled on retry.
+// +k8s:openapi-gen=true
+type RetryTarget struct {
+	// Partition is a name of a partition to submit job to. Partitions of
+	// multiple clusters are named as cluster/partition.
+	Partition string `json:"partition,omitempty"`
+	// NodeSelector replaces job node selector when set.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// JobCondition describes job state at a certain point.
+// +k8s:openapi-gen=true
+type JobCondition struct {
+	// Type of the condition.
+	Type JobConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// LastTransitionTime is time the condition changed status at.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a brief reason of the last transition.
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable description of the last transition.
+	Message string `json:"message,omitempty"`
+}
@@ -27,15 +27,324 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobResults":         schema_operator_apis_wlm_v1alpha1_JobResults(ref),
-		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SingularityOptions": schema_operator_apis_wlm_v1alpha1_SingularityOptions(ref),
-		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJob":           schema_operator_apis_wlm_v1alpha1_SlurmJob(ref),
-		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobSpec":       schema_operator_apis_wlm_v1alpha1_SlurmJobSpec(ref),
-		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobStatus":     schema_operator_apis_wlm_v1alpha1_SlurmJobStatus(ref),
-		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmJob":             schema_operator_apis_wlm_v1alpha1_WlmJob(ref),
-		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmJobSpec":         schema_operator_apis_wlm_v1alpha1_WlmJobSpec(ref),
-		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmJobStatus":       schema_operator_apis_wlm_v1alpha1_WlmJobStatus(ref),
-		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmResources":       schema_operator_apis_wlm_v1alpha1_WlmResources(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.ArrayStatus":         schema_operator_apis_wlm_v1alpha1_ArrayStatus(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.ArrayTaskStatus":     schema_operator_apis_wlm_v1alpha1_ArrayTaskStatus(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobArray":            schema_operator_apis_wlm_v1alpha1_JobArray(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobAttempt":          schema_operator_apis_wlm_v1alpha1_JobAttempt(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobCondition":        schema_operator_apis_wlm_v1alpha1_JobCondition(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobResults":          schema_operator_apis_wlm_v1alpha1_JobResults(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobStatus":           schema_operator_apis_wlm_v1alpha1_JobStatus(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.PrepareData":         schema_operator_apis_wlm_v1alpha1_PrepareData(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.RetryPolicy":         schema_operator_apis_wlm_v1alpha1_RetryPolicy(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.RetryTarget":         schema_operator_apis_wlm_v1alpha1_RetryTarget(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SingularityOptions":  schema_operator_apis_wlm_v1alpha1_SingularityOptions(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmCronJob":        schema_operator_apis_wlm_v1alpha1_SlurmCronJob(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmCronJobSpec":    schema_operator_apis_wlm_v1alpha1_SlurmCronJobSpec(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmCronJobStatus":  schema_operator_apis_wlm_v1alpha1_SlurmCronJobStatus(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJob":            schema_operator_apis_wlm_v1alpha1_SlurmJob(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobSpec":        schema_operator_apis_wlm_v1alpha1_SlurmJobSpec(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobStatus":      schema_operator_apis_wlm_v1alpha1_SlurmJobStatus(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmWorkflow":       schema_operator_apis_wlm_v1alpha1_SlurmWorkflow(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmWorkflowSpec":   schema_operator_apis_wlm_v1alpha1_SlurmWorkflowSpec(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmWorkflowStatus": schema_operator_apis_wlm_v1alpha1_SlurmWorkflowStatus(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmJob":              schema_operator_apis_wlm_v1alpha1_WlmJob(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmJobSpec":          schema_operator_apis_wlm_v1alpha1_WlmJobSpec(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmJobStatus":        schema_operator_apis_wlm_v1alpha1_WlmJobStatus(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmResources":        schema_operator_apis_wlm_v1alpha1_WlmResources(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WorkflowDependency":  schema_operator_apis_wlm_v1alpha1_WorkflowDependency(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WorkflowStep":        schema_operator_apis_wlm_v1alpha1_WorkflowStep(ref),
+		"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WorkflowStepStatus":  schema_operator_apis_wlm_v1alpha1_WorkflowStepStatus(ref),
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_ArrayStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ArrayStatus describes tasks of a job array.",
+				Properties: map[string]spec.Schema{
+					"total": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Total is the number of array tasks.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"pending": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Pending is the number of tasks waiting to be started.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"running": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Running is the number of running or suspended tasks.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"succeeded": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Succeeded is the number of tasks completed successfully.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"failed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Failed is the number of tasks that failed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"succeededIndices": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SucceededIndices are indices of succeeded tasks, e.g. 0,2,6-9.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"failedIndices": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FailedIndices are indices of failed tasks, e.g. 1,3-5.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tasks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tasks describe array tasks that were retried, failed or are running, at most 100 of them. Other tasks are only counted.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.ArrayTaskStatus"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"total"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.ArrayTaskStatus"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_ArrayTaskStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ArrayTaskStatus describes a task of a job array.",
+				Properties: map[string]spec.Schema{
+					"index": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Index is the array task index.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"jobID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JobID is an id of the task job.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State is task state reported by workload manager.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"exitCode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExitCode is task exit code in form \"code:signal\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"endTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndTime is time task finished at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"retries": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Retries is the number of times the task was requeued.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"retryTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RetryTime is time the task was last requeued at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"index"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_JobArray(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "JobArray describes a Slurm job array.",
+				Properties: map[string]spec.Schema{
+					"indices": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Indices are array task indices in sbatch --array syntax, e.g. 0-99, 0-15:4 or 1,3,5-7.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"throttle": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Throttle limits the number of simultaneously running tasks.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"indices"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_JobAttempt(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "JobAttempt describes a finished attempt to run a job.",
+				Properties: map[string]spec.Schema{
+					"jobID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JobID is an id of the submitted job.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"cluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cluster is a name of a cluster job was submitted to.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"partition": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Partition is a name of a partition job was submitted to.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State is job state reported by workload manager, e.g. NODE_FAIL.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a reason job failed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartTime is time job was started at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"endTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndTime is time job finished at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"exitCode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExitCode is job exit code.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodeList": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeList is a list of nodes job ran on.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_JobCondition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "JobCondition describes job state at a certain point.",
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type of the condition.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status of the condition, one of True, False, Unknown.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastTransitionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastTransitionTime is time the condition changed status at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a brief reason of the last transition.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable description of the last transition.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"type", "status"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -43,55 +352,544 @@ func schema_operator_apis_wlm_v1alpha1_JobResults(ref common.ReferenceCallback)
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "JobResults is a schema for results collection.",
+				Description: "JobResults is a schema for results collection.",
+				Properties: map[string]spec.Schema{
+					"mount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Mount is a directory where job results will be stored. After results collection all job generated files can be found in Mount/<SlurmJob.Name> directory.",
+							Ref:         ref("k8s.io/api/core/v1.Volume"),
+						},
+					},
+					"from": {
+						SchemaProps: spec.SchemaProps{
+							Description: "From is a path to the results to be collected from a Slurm cluster.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"mount", "from"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.Volume"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_JobStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "JobStatus describes a workload manager job that runs a SlurmJob or a WlmJob.",
+				Properties: map[string]spec.Schema{
+					"jobID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JobID is an id of the submitted job.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"cluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cluster is a name of a cluster job was submitted to, it is empty unless red-box serves several clusters.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"partition": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Partition is a name of a partition job was submitted to.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State is job state reported by workload manager, e.g. PENDING or RUNNING.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a reason job is pending or failed, e.g. Resources.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"submitTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SubmitTime is time job was submitted at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartTime is time job was started at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"endTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndTime is time job finished at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"exitCode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExitCode is job exit code, for Slurm it is in form \"code:signal\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodeList": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeList is a list of nodes job runs on.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions describe observed job lifecycle.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobCondition"),
+									},
+								},
+							},
+						},
+					},
+					"attempts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Attempts are previous failed attempts to run the job that were retried according to the retry policy.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobAttempt"),
+									},
+								},
+							},
+						},
+					},
+					"array": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Array describes tasks of a job array.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.ArrayStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.ArrayStatus", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobAttempt", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobCondition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_PrepareData(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PrepareData is a schema for data preparation.",
+				Properties: map[string]spec.Schema{
+					"mount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Mount is a directory where input data will be given.",
+							Ref:         ref("k8s.io/api/core/v1.Volume"),
+						},
+					},
+					"to": {
+						SchemaProps: spec.SchemaProps{
+							Description: "To is a path to the data to be uploaded to a Slurm cluster.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"mount", "to"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.Volume"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_RetryPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RetryPolicy describes how failed jobs are retried.",
+				Properties: map[string]spec.Schema{
+					"backoffLimit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BackoffLimit is a number of retries before job is considered failed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"backoffSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BackoffSeconds is a delay before the first retry, the delay is doubled for each next retry up to one hour. Defaults to 10.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"retryOn": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RetryOn is a list of workload manager job states that are retried, e.g. NODE_FAIL, PREEMPTED, BOOT_FAIL, FAILED or TIMEOUT. Defaults to NODE_FAIL, PREEMPTED and BOOT_FAIL.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"alternatives": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Alternatives is an ordered list of targets for retries. N-th retry is scheduled to the N-th alternative, retries beyond the list length are scheduled to the last alternative. When empty all retries are scheduled the same way as the first attempt.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.RetryTarget"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"backoffLimit"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.RetryTarget"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_RetryTarget(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RetryTarget describes where a job is scheduled on retry.",
+				Properties: map[string]spec.Schema{
+					"partition": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Partition is a name of a partition to submit job to. Partitions of multiple clusters are named as cluster/partition.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodeSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSelector replaces job node selector when set.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_SingularityOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SingularityOptions singularity run options.",
+				Properties: map[string]spec.Schema{
+					"allowUnsigned": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Allow to pull and run unsigned images.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"cleanEnv": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Clean environment before running container.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"fakeRoot": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Run container in new user namespace as uid 0.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"ipc": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Run container in a new IPC namespace.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"pid": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Run container in a new PID namespace.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"noPrivs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Drop all privileges from root user in container.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"writable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "By default all Singularity containers are available as read only. This option makes the file system accessible as read/write.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"app": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Set an application to run inside a container.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"hostName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Set container hostname.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"binds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Binds a user-bind path specification. Spec has the format src[:dest[:opts]], where src and dest are outside and inside paths.  If dest is not given, it is set equal to src. Mount options ('opts') may be specified as 'ro' (read-only) or 'rw' (read/write, which is the default). Multiple bind paths can be given by a comma separated list.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_SlurmCronJob(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SlurmCronJob is the Schema for the slurmcronjobs API.",
 				Properties: map[string]spec.Schema{
-					"mount": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Mount is a directory where job results will be stored. After results collection all job generated files can be found in Mount/<SlurmJob.Name> directory.",
-							Ref:         ref("k8s.io/api/core/v1.Volume"),
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"from": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "From is a path to the results to be collected from a Slurm cluster.",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmCronJobSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmCronJobStatus"),
+						},
+					},
 				},
-				Required: []string{"mount", "from"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.Volume"},
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmCronJobSpec", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmCronJobStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
 	}
 }
 
-func schema_operator_apis_wlm_v1alpha1_SingularityOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_operator_apis_wlm_v1alpha1_SlurmCronJobSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "SingularityOptions singularity run options.",
+				Description: "SlurmCronJobSpec defines the desired state of SlurmCronJob.",
 				Properties: map[string]spec.Schema{
-					"app": {
+					"schedule": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Set an application to run inside a container.",
+							Description: "Schedule in Cron format, e.g. \"0 2 * * *\", see https://en.wikipedia.org/wiki/Cron.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"allowUnsigned": {
+					"startingDeadlineSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Allow to pull and run unsigned images.",
-							Type:        []string{"boolean"},
+							Description: "StartingDeadlineSeconds is a deadline for starting a SlurmJob if it misses its scheduled time for any reason. Missed runs are counted as failed ones.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"concurrencyPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConcurrencyPolicy specifies how to treat concurrent runs, one of Allow (default), Forbid or Replace.",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"binds": {
+					"jobTemplate": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Binds a user-bind path specification. Spec has the format src[:dest[:opts]], where src and dest are outside and inside paths.  If dest is not given, it is set equal to src. Mount options ('opts') may be specified as 'ro' (read-only) or 'rw' (read/write, which is the default). Multiple bind paths can be given by a comma separated list.",
+							Description: "JobTemplate is a spec of SlurmJobs created on schedule.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobSpec"),
+						},
+					},
+					"successfulJobsHistoryLimit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SuccessfulJobsHistoryLimit is a number of successful finished SlurmJobs to keep. Defaults to 3.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"failedJobsHistoryLimit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FailedJobsHistoryLimit is a number of failed finished SlurmJobs to keep. Defaults to 1.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"schedule", "jobTemplate"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobSpec"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_SlurmCronJobStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SlurmCronJobStatus defines the observed state of a SlurmCronJob.",
+				Properties: map[string]spec.Schema{
+					"active": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Active is a list of references to currently running SlurmJobs.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.ObjectReference"),
+									},
+								},
+							},
+						},
+					},
+					"lastScheduleTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastScheduleTime is time the SlurmJob was last successfully scheduled at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ObjectReference", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_SlurmJob(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SlurmJob is the Schema for the slurmjobs API.",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobSpec", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_SlurmJobSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SlurmJobSpec defines the desired state of SlurmJob",
+				Properties: map[string]spec.Schema{
+					"batch": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Batch is a script that will be submitted to a Slurm cluster as a batch job.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodeSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSelector is a selector which must be true for the SlurmJob to fit on a node. Selector which must match a node's labels for the SlurmJob to be scheduled on that node. More info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Type:   []string{"string"},
@@ -101,67 +899,186 @@ func schema_operator_apis_wlm_v1alpha1_SingularityOptions(ref common.ReferenceCa
 							},
 						},
 					},
-					"cleanEnv": {
+					"prepare": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Clean environment before running container.",
+							Description: "Prepare may be specified for an optional data preparation step. When specified, before job is started required data will be uploaded to Slurm cluster with respect to this configuration.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.PrepareData"),
+						},
+					},
+					"results": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Results may be specified for an optional results collection step. When specified, after job is completed all results will be downloaded from Slurm cluster with respect to this configuration.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobResults"),
+						},
+					},
+					"retryPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RetryPolicy may be specified to retry failed jobs.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.RetryPolicy"),
+						},
+					},
+					"ttlSecondsAfterFinished": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TTLSecondsAfterFinished limits lifetime of a finished job. When set, job is deleted together with its pods once the TTL passes after the job is finished.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"suspend": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Suspend holds a pending job and suspends a running one when true. Setting it back to false releases or resumes the job.",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"fakeRoot": {
+					"suspendMode": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Run container in new user namespace as uid 0.",
-							Type:        []string{"boolean"},
+							Description: "SuspendMode defines what happens to a running job when it is suspended, either Requeue (default) or Suspend, which requires Slurm operator or admin rights.",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"hostName": {
+					"array": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Set container hostname.",
+							Description: "Array submits the batch script as a Slurm job array. When set, job status tracks array tasks and retry policy applies to failed tasks individually.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobArray"),
+						},
+					},
+				},
+				Required: []string{"batch"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobArray", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobResults", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.PrepareData", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.RetryPolicy"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_SlurmJobStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SlurmJobStatus defines the observed state of a SlurmJob.",
+				Properties: map[string]spec.Schema{
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status reflects job status, e.g running, succeeded.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"ipc": {
+					"jobID": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Run container in a new IPC namespace.",
-							Type:        []string{"boolean"},
+							Description: "JobID is an id of the submitted job.",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"pid": {
+					"cluster": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Run container in a new PID namespace.",
-							Type:        []string{"boolean"},
+							Description: "Cluster is a name of a cluster job was submitted to, it is empty unless red-box serves several clusters.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"partition": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Partition is a name of a partition job was submitted to.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State is job state reported by workload manager, e.g. PENDING or RUNNING.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a reason job is pending or failed, e.g. Resources.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"submitTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SubmitTime is time job was submitted at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartTime is time job was started at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"endTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndTime is time job finished at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"exitCode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExitCode is job exit code, for Slurm it is in form \"code:signal\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodeList": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeList is a list of nodes job runs on.",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"noPrivs": {
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions describe observed job lifecycle.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobCondition"),
+									},
+								},
+							},
+						},
+					},
+					"attempts": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Drop all privileges from root user in container.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Attempts are previous failed attempts to run the job that were retried according to the retry policy.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobAttempt"),
+									},
+								},
+							},
 						},
 					},
-					"writable": {
+					"array": {
 						SchemaProps: spec.SchemaProps{
-							Description: "By default all Singularity containers are available as read only. This option makes the file system accessible as read/write.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Array describes tasks of a job array.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.ArrayStatus"),
 						},
 					},
 				},
+				Required: []string{"status"},
 			},
 		},
-		Dependencies: []string{},
+		Dependencies: []string{
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.ArrayStatus", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobAttempt", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobCondition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
-func schema_operator_apis_wlm_v1alpha1_SlurmJob(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_operator_apis_wlm_v1alpha1_SlurmWorkflow(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "SlurmJob is the Schema for the slurmjobs API.",
+				Description: "SlurmWorkflow is the Schema for the slurmworkflows API.",
 				Properties: map[string]spec.Schema{
 					"kind": {
 						SchemaProps: spec.SchemaProps{
@@ -184,82 +1101,88 @@ func schema_operator_apis_wlm_v1alpha1_SlurmJob(ref common.ReferenceCallback) co
 					},
 					"spec": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobSpec"),
+							Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmWorkflowSpec"),
 						},
 					},
 					"status": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobStatus"),
+							Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmWorkflowStatus"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobSpec", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmWorkflowSpec", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmWorkflowStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
 	}
 }
 
-func schema_operator_apis_wlm_v1alpha1_SlurmJobSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_operator_apis_wlm_v1alpha1_SlurmWorkflowSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "SlurmJobSpec defines the desired state of SlurmJob",
+				Description: "SlurmWorkflowSpec defines the desired state of SlurmWorkflow.",
 				Properties: map[string]spec.Schema{
-					"batch": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Batch is a script that will be submitted to a Slurm cluster as a batch job.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"nodeSelector": {
+					"steps": {
 						SchemaProps: spec.SchemaProps{
-							Description: "NodeSelector is a selector which must be true for the SlurmJob to fit on a node. Selector which must match a node's labels for the SlurmJob to be scheduled on that node. More info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/.",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
+							Description: "Steps of the workflow, dependencies should form a directed acyclic graph.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WorkflowStep"),
 									},
 								},
 							},
 						},
 					},
-					"results": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Results may be specified for an optional results collection step. When specified, after job is completed all results will be downloaded from Slurm cluster with respect to this configuration.",
-							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobResults"),
-						},
-					},
 				},
-				Required: []string{"batch"},
+				Required: []string{"steps"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobResults"},
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WorkflowStep"},
 	}
 }
 
-func schema_operator_apis_wlm_v1alpha1_SlurmJobStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_operator_apis_wlm_v1alpha1_SlurmWorkflowStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "SlurmJobStatus defines the observed state of a SlurmJob.",
+				Description: "SlurmWorkflowStatus defines the observed state of SlurmWorkflow.",
 				Properties: map[string]spec.Schema{
-					"status": {
+					"phase": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Status reflects job status, e.g running, succeeded.",
+							Description: "Phase of the workflow.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable description of the workflow phase, e.g. why the workflow spec is invalid.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"steps": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Steps describe workflow steps in the spec order.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WorkflowStepStatus"),
+									},
+								},
+							},
+						},
+					},
 				},
-				Required: []string{"status"},
 			},
 		},
-		Dependencies: []string{},
+		Dependencies: []string{
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WorkflowStepStatus"},
 	}
 }
 
@@ -351,12 +1274,39 @@ func schema_operator_apis_wlm_v1alpha1_WlmJobSpec(ref common.ReferenceCallback)
 							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobResults"),
 						},
 					},
+					"retryPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RetryPolicy may be specified to retry failed jobs.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.RetryPolicy"),
+						},
+					},
+					"ttlSecondsAfterFinished": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TTLSecondsAfterFinished limits lifetime of a finished job. When set, job is deleted together with its pods once the TTL passes after the job is finished.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"suspend": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Suspend holds a pending job and suspends a running one when true. Setting it back to false releases or resumes the job.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"suspendMode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SuspendMode defines what happens to a running job when it is suspended, either Requeue (default) or Suspend, which requires Slurm operator or admin rights.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"image"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobResults", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SingularityOptions", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmResources"},
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobResults", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.RetryPolicy", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SingularityOptions", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmResources"},
 	}
 }
 
@@ -373,11 +1323,111 @@ func schema_operator_apis_wlm_v1alpha1_WlmJobStatus(ref common.ReferenceCallback
 							Format:      "",
 						},
 					},
+					"jobID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JobID is an id of the submitted job.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"cluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cluster is a name of a cluster job was submitted to, it is empty unless red-box serves several clusters.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"partition": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Partition is a name of a partition job was submitted to.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State is job state reported by workload manager, e.g. PENDING or RUNNING.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a reason job is pending or failed, e.g. Resources.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"submitTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SubmitTime is time job was submitted at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartTime is time job was started at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"endTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndTime is time job finished at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"exitCode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExitCode is job exit code, for Slurm it is in form \"code:signal\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodeList": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeList is a list of nodes job runs on.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions describe observed job lifecycle.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobCondition"),
+									},
+								},
+							},
+						},
+					},
+					"attempts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Attempts are previous failed attempts to run the job that were retried according to the retry policy.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobAttempt"),
+									},
+								},
+							},
+						},
+					},
+					"array": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Array describes tasks of a job array.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.ArrayStatus"),
+						},
+					},
 				},
 				Required: []string{"status"},
 			},
 		},
-		Dependencies: []string{},
+		Dependencies: []string{
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.ArrayStatus", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobAttempt", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.JobCondition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -418,3 +1468,147 @@ func schema_operator_apis_wlm_v1alpha1_WlmResources(ref common.ReferenceCallback
 		Dependencies: []string{},
 	}
 }
+
+func schema_operator_apis_wlm_v1alpha1_WorkflowDependency(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WorkflowDependency is an edge of a workflow.",
+				Properties: map[string]spec.Schema{
+					"step": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Step is a name of a step this step depends on.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"condition": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Condition is one of afterok (default) or afterany.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"step"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_WorkflowStep(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WorkflowStep is a named job of a workflow.",
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the step, unique within the workflow.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"dependsOn": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DependsOn is a list of steps that should finish before this step starts.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WorkflowDependency"),
+									},
+								},
+							},
+						},
+					},
+					"slurmJob": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SlurmJob is a spec of a SlurmJob the step runs. Exactly one of SlurmJob and WlmJob should be set.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobSpec"),
+						},
+					},
+					"wlmJob": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WlmJob is a spec of a WlmJob the step runs. Exactly one of SlurmJob and WlmJob should be set.",
+							Ref:         ref("github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmJobSpec"),
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.SlurmJobSpec", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WlmJobSpec", "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1.WorkflowDependency"},
+	}
+}
+
+func schema_operator_apis_wlm_v1alpha1_WorkflowStepStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WorkflowStepStatus describes observed state of a workflow step.",
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the step.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Phase of the step.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"job": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Job is a name of the SlurmJob or WlmJob created for the step.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"jobID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JobID is an id of the workload manager job.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State is workload manager job state.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartTime is time the step job was started at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"endTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndTime is time the step job finished at.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable description of the step phase.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "phase"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
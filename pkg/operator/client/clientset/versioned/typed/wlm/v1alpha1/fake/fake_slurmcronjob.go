@@ -0,0 +1,138 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeSlurmCronJobs implements SlurmCronJobInterface
+type FakeSlurmCronJobs struct {
+	Fake *FakeWlmV1alpha1
+	ns   string
+}
+
+var slurmcronjobsResource = schema.GroupVersionResource{Group: "wlm.sylabs.io", Version: "v1alpha1", Resource: "slurmcronjobs"}
+
+var slurmcronjobsKind = schema.GroupVersionKind{Group: "wlm.sylabs.io", Version: "v1alpha1", Kind: "SlurmCronJob"}
+
+// Get takes name of the slurmCronJob, and returns the corresponding slurmCronJob object, and an error if there is any.
+func (c *FakeSlurmCronJobs) Get(name string, options v1.GetOptions) (result *v1alpha1.SlurmCronJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(slurmcronjobsResource, c.ns, name), &v1alpha1.SlurmCronJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SlurmCronJob), err
+}
+
+// List takes label and field selectors, and returns the list of SlurmCronJobs that match those selectors.
+func (c *FakeSlurmCronJobs) List(opts v1.ListOptions) (result *v1alpha1.SlurmCronJobList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(slurmcronjobsResource, slurmcronjobsKind, c.ns, opts), &v1alpha1.SlurmCronJobList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.SlurmCronJobList{ListMeta: obj.(*v1alpha1.SlurmCronJobList).ListMeta}
+	for _, item := range obj.(*v1alpha1.SlurmCronJobList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested slurmCronJobs.
+func (c *FakeSlurmCronJobs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(slurmcronjobsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a slurmCronJob and creates it.  Returns the server's representation of the slurmCronJob, and an error, if there is any.
+func (c *FakeSlurmCronJobs) Create(slurmCronJob *v1alpha1.SlurmCronJob) (result *v1alpha1.SlurmCronJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(slurmcronjobsResource, c.ns, slurmCronJob), &v1alpha1.SlurmCronJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SlurmCronJob), err
+}
+
+// Update takes the representation of a slurmCronJob and updates it. Returns the server's representation of the slurmCronJob, and an error, if there is any.
+func (c *FakeSlurmCronJobs) Update(slurmCronJob *v1alpha1.SlurmCronJob) (result *v1alpha1.SlurmCronJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(slurmcronjobsResource, c.ns, slurmCronJob), &v1alpha1.SlurmCronJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SlurmCronJob), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeSlurmCronJobs) UpdateStatus(slurmCronJob *v1alpha1.SlurmCronJob) (*v1alpha1.SlurmCronJob, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(slurmcronjobsResource, "status", c.ns, slurmCronJob), &v1alpha1.SlurmCronJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SlurmCronJob), err
+}
+
+// Delete takes name of the slurmCronJob and deletes it. Returns an error if one occurs.
+func (c *FakeSlurmCronJobs) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(slurmcronjobsResource, c.ns, name), &v1alpha1.SlurmCronJob{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeSlurmCronJobs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(slurmcronjobsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.SlurmCronJobList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched slurmCronJob.
+func (c *FakeSlurmCronJobs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SlurmCronJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(slurmcronjobsResource, c.ns, name, pt, data, subresources...), &v1alpha1.SlurmCronJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SlurmCronJob), err
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeSlurmWorkflows implements SlurmWorkflowInterface
+type FakeSlurmWorkflows struct {
+	Fake *FakeWlmV1alpha1
+	ns   string
+}
+
+var slurmworkflowsResource = schema.GroupVersionResource{Group: "wlm.sylabs.io", Version: "v1alpha1", Resource: "slurmworkflows"}
+
+var slurmworkflowsKind = schema.GroupVersionKind{Group: "wlm.sylabs.io", Version: "v1alpha1", Kind: "SlurmWorkflow"}
+
+// Get takes name of the slurmWorkflow, and returns the corresponding slurmWorkflow object, and an error if there is any.
+func (c *FakeSlurmWorkflows) Get(name string, options v1.GetOptions) (result *v1alpha1.SlurmWorkflow, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(slurmworkflowsResource, c.ns, name), &v1alpha1.SlurmWorkflow{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SlurmWorkflow), err
+}
+
+// List takes label and field selectors, and returns the list of SlurmWorkflows that match those selectors.
+func (c *FakeSlurmWorkflows) List(opts v1.ListOptions) (result *v1alpha1.SlurmWorkflowList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(slurmworkflowsResource, slurmworkflowsKind, c.ns, opts), &v1alpha1.SlurmWorkflowList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.SlurmWorkflowList{ListMeta: obj.(*v1alpha1.SlurmWorkflowList).ListMeta}
+	for _, item := range obj.(*v1alpha1.SlurmWorkflowList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested slurmWorkflows.
+func (c *FakeSlurmWorkflows) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(slurmworkflowsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a slurmWorkflow and creates it.  Returns the server's representation of the slurmWorkflow, and an error, if there is any.
+func (c *FakeSlurmWorkflows) Create(slurmWorkflow *v1alpha1.SlurmWorkflow) (result *v1alpha1.SlurmWorkflow, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(slurmworkflowsResource, c.ns, slurmWorkflow), &v1alpha1.SlurmWorkflow{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SlurmWorkflow), err
+}
+
+// Update takes the representation of a slurmWorkflow and updates it. Returns the server's representation of the slurmWorkflow, and an error, if there is any.
+func (c *FakeSlurmWorkflows) Update(slurmWorkflow *v1alpha1.SlurmWorkflow) (result *v1alpha1.SlurmWorkflow, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(slurmworkflowsResource, c.ns, slurmWorkflow), &v1alpha1.SlurmWorkflow{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SlurmWorkflow), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeSlurmWorkflows) UpdateStatus(slurmWorkflow *v1alpha1.SlurmWorkflow) (*v1alpha1.SlurmWorkflow, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(slurmworkflowsResource, "status", c.ns, slurmWorkflow), &v1alpha1.SlurmWorkflow{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SlurmWorkflow), err
+}
+
+// Delete takes name of the slurmWorkflow and deletes it. Returns an error if one occurs.
+func (c *FakeSlurmWorkflows) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(slurmworkflowsResource, c.ns, name), &v1alpha1.SlurmWorkflow{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeSlurmWorkflows) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(slurmworkflowsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.SlurmWorkflowList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched slurmWorkflow.
+func (c *FakeSlurmWorkflows) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SlurmWorkflow, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(slurmworkflowsResource, c.ns, name, pt, data, subresources...), &v1alpha1.SlurmWorkflow{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SlurmWorkflow), err
+}
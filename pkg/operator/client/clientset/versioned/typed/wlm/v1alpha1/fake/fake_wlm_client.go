@@ -26,10 +26,18 @@ type FakeWlmV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeWlmV1alpha1) SlurmCronJobs(namespace string) v1alpha1.SlurmCronJobInterface {
+	return &FakeSlurmCronJobs{c, namespace}
+}
+
 func (c *FakeWlmV1alpha1) SlurmJobs(namespace string) v1alpha1.SlurmJobInterface {
 	return &FakeSlurmJobs{c, namespace}
 }
 
+func (c *FakeWlmV1alpha1) SlurmWorkflows(namespace string) v1alpha1.SlurmWorkflowInterface {
+	return &FakeSlurmWorkflows{c, namespace}
+}
+
 func (c *FakeWlmV1alpha1) WlmJobs(namespace string) v1alpha1.WlmJobInterface {
 	return &FakeWlmJobs{c, namespace}
 }
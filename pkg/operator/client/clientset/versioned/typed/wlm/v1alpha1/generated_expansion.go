@@ -16,6 +16,10 @@
 
 package v1alpha1
 
+type SlurmCronJobExpansion interface{}
+
 type SlurmJobExpansion interface{}
 
+type SlurmWorkflowExpansion interface{}
+
 type WlmJobExpansion interface{}
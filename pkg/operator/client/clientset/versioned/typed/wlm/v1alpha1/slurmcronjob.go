@@ -0,0 +1,189 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by main. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	scheme "github.com/dptech-corp/wlm-operator/pkg/operator/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// SlurmCronJobsGetter has a method to return a SlurmCronJobInterface.
+// A group's client should implement this interface.
+type SlurmCronJobsGetter interface {
+	SlurmCronJobs(namespace string) SlurmCronJobInterface
+}
+
+// SlurmCronJobInterface has methods to work with SlurmCronJob resources.
+type SlurmCronJobInterface interface {
+	Create(*v1alpha1.SlurmCronJob) (*v1alpha1.SlurmCronJob, error)
+	Update(*v1alpha1.SlurmCronJob) (*v1alpha1.SlurmCronJob, error)
+	UpdateStatus(*v1alpha1.SlurmCronJob) (*v1alpha1.SlurmCronJob, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.SlurmCronJob, error)
+	List(opts v1.ListOptions) (*v1alpha1.SlurmCronJobList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SlurmCronJob, err error)
+	SlurmCronJobExpansion
+}
+
+// slurmCronJobs implements SlurmCronJobInterface
+type slurmCronJobs struct {
+	client rest.Interface
+	ns     string
+}
+
+// newSlurmCronJobs returns a SlurmCronJobs
+func newSlurmCronJobs(c *WlmV1alpha1Client, namespace string) *slurmCronJobs {
+	return &slurmCronJobs{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the slurmCronJob, and returns the corresponding slurmCronJob object, and an error if there is any.
+func (c *slurmCronJobs) Get(name string, options v1.GetOptions) (result *v1alpha1.SlurmCronJob, err error) {
+	result = &v1alpha1.SlurmCronJob{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("slurmcronjobs").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of SlurmCronJobs that match those selectors.
+func (c *slurmCronJobs) List(opts v1.ListOptions) (result *v1alpha1.SlurmCronJobList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.SlurmCronJobList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("slurmcronjobs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested slurmCronJobs.
+func (c *slurmCronJobs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("slurmcronjobs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a slurmCronJob and creates it.  Returns the server's representation of the slurmCronJob, and an error, if there is any.
+func (c *slurmCronJobs) Create(slurmCronJob *v1alpha1.SlurmCronJob) (result *v1alpha1.SlurmCronJob, err error) {
+	result = &v1alpha1.SlurmCronJob{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("slurmcronjobs").
+		Body(slurmCronJob).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a slurmCronJob and updates it. Returns the server's representation of the slurmCronJob, and an error, if there is any.
+func (c *slurmCronJobs) Update(slurmCronJob *v1alpha1.SlurmCronJob) (result *v1alpha1.SlurmCronJob, err error) {
+	result = &v1alpha1.SlurmCronJob{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("slurmcronjobs").
+		Name(slurmCronJob.Name).
+		Body(slurmCronJob).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *slurmCronJobs) UpdateStatus(slurmCronJob *v1alpha1.SlurmCronJob) (result *v1alpha1.SlurmCronJob, err error) {
+	result = &v1alpha1.SlurmCronJob{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("slurmcronjobs").
+		Name(slurmCronJob.Name).
+		SubResource("status").
+		Body(slurmCronJob).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the slurmCronJob and deletes it. Returns an error if one occurs.
+func (c *slurmCronJobs) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("slurmcronjobs").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *slurmCronJobs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("slurmcronjobs").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched slurmCronJob.
+func (c *slurmCronJobs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SlurmCronJob, err error) {
+	result = &v1alpha1.SlurmCronJob{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("slurmcronjobs").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by main. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	scheme "github.com/dptech-corp/wlm-operator/pkg/operator/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// SlurmWorkflowsGetter has a method to return a SlurmWorkflowInterface.
+// A group's client should implement this interface.
+type SlurmWorkflowsGetter interface {
+	SlurmWorkflows(namespace string) SlurmWorkflowInterface
+}
+
+// SlurmWorkflowInterface has methods to work with SlurmWorkflow resources.
+type SlurmWorkflowInterface interface {
+	Create(*v1alpha1.SlurmWorkflow) (*v1alpha1.SlurmWorkflow, error)
+	Update(*v1alpha1.SlurmWorkflow) (*v1alpha1.SlurmWorkflow, error)
+	UpdateStatus(*v1alpha1.SlurmWorkflow) (*v1alpha1.SlurmWorkflow, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.SlurmWorkflow, error)
+	List(opts v1.ListOptions) (*v1alpha1.SlurmWorkflowList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SlurmWorkflow, err error)
+	SlurmWorkflowExpansion
+}
+
+// slurmWorkflows implements SlurmWorkflowInterface
+type slurmWorkflows struct {
+	client rest.Interface
+	ns     string
+}
+
+// newSlurmWorkflows returns a SlurmWorkflows
+func newSlurmWorkflows(c *WlmV1alpha1Client, namespace string) *slurmWorkflows {
+	return &slurmWorkflows{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the slurmWorkflow, and returns the corresponding slurmWorkflow object, and an error if there is any.
+func (c *slurmWorkflows) Get(name string, options v1.GetOptions) (result *v1alpha1.SlurmWorkflow, err error) {
+	result = &v1alpha1.SlurmWorkflow{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("slurmworkflows").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of SlurmWorkflows that match those selectors.
+func (c *slurmWorkflows) List(opts v1.ListOptions) (result *v1alpha1.SlurmWorkflowList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.SlurmWorkflowList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("slurmworkflows").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested slurmWorkflows.
+func (c *slurmWorkflows) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("slurmworkflows").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a slurmWorkflow and creates it.  Returns the server's representation of the slurmWorkflow, and an error, if there is any.
+func (c *slurmWorkflows) Create(slurmWorkflow *v1alpha1.SlurmWorkflow) (result *v1alpha1.SlurmWorkflow, err error) {
+	result = &v1alpha1.SlurmWorkflow{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("slurmworkflows").
+		Body(slurmWorkflow).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a slurmWorkflow and updates it. Returns the server's representation of the slurmWorkflow, and an error, if there is any.
+func (c *slurmWorkflows) Update(slurmWorkflow *v1alpha1.SlurmWorkflow) (result *v1alpha1.SlurmWorkflow, err error) {
+	result = &v1alpha1.SlurmWorkflow{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("slurmworkflows").
+		Name(slurmWorkflow.Name).
+		Body(slurmWorkflow).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *slurmWorkflows) UpdateStatus(slurmWorkflow *v1alpha1.SlurmWorkflow) (result *v1alpha1.SlurmWorkflow, err error) {
+	result = &v1alpha1.SlurmWorkflow{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("slurmworkflows").
+		Name(slurmWorkflow.Name).
+		SubResource("status").
+		Body(slurmWorkflow).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the slurmWorkflow and deletes it. Returns an error if one occurs.
+func (c *slurmWorkflows) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("slurmworkflows").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *slurmWorkflows) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("slurmworkflows").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched slurmWorkflow.
+func (c *slurmWorkflows) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SlurmWorkflow, err error) {
+	result = &v1alpha1.SlurmWorkflow{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("slurmworkflows").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
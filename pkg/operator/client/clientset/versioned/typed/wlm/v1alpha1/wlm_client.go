@@ -25,7 +25,9 @@ import (
 
 type WlmV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	SlurmCronJobsGetter
 	SlurmJobsGetter
+	SlurmWorkflowsGetter
 	WlmJobsGetter
 }
 
@@ -34,10 +36,18 @@ type WlmV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *WlmV1alpha1Client) SlurmCronJobs(namespace string) SlurmCronJobInterface {
+	return newSlurmCronJobs(c, namespace)
+}
+
 func (c *WlmV1alpha1Client) SlurmJobs(namespace string) SlurmJobInterface {
 	return newSlurmJobs(c, namespace)
 }
 
+func (c *WlmV1alpha1Client) SlurmWorkflows(namespace string) SlurmWorkflowInterface {
+	return newSlurmWorkflows(c, namespace)
+}
+
 func (c *WlmV1alpha1Client) WlmJobs(namespace string) WlmJobInterface {
 	return newWlmJobs(c, namespace)
 }
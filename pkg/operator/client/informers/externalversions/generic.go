@@ -51,8 +51,12 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=wlm.sylabs.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("slurmcronjobs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Wlm().V1alpha1().SlurmCronJobs().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("slurmjobs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Wlm().V1alpha1().SlurmJobs().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("slurmworkflows"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Wlm().V1alpha1().SlurmWorkflows().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("wlmjobs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Wlm().V1alpha1().WlmJobs().Informer()}, nil
 
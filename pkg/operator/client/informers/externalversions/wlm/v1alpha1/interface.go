@@ -22,8 +22,12 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// SlurmCronJobs returns a SlurmCronJobInformer.
+	SlurmCronJobs() SlurmCronJobInformer
 	// SlurmJobs returns a SlurmJobInformer.
 	SlurmJobs() SlurmJobInformer
+	// SlurmWorkflows returns a SlurmWorkflowInformer.
+	SlurmWorkflows() SlurmWorkflowInformer
 	// WlmJobs returns a WlmJobInformer.
 	WlmJobs() WlmJobInformer
 }
@@ -39,11 +43,21 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// SlurmCronJobs returns a SlurmCronJobInformer.
+func (v *version) SlurmCronJobs() SlurmCronJobInformer {
+	return &slurmCronJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // SlurmJobs returns a SlurmJobInformer.
 func (v *version) SlurmJobs() SlurmJobInformer {
 	return &slurmJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// SlurmWorkflows returns a SlurmWorkflowInformer.
+func (v *version) SlurmWorkflows() SlurmWorkflowInformer {
+	return &slurmWorkflowInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // WlmJobs returns a WlmJobInformer.
 func (v *version) WlmJobs() WlmJobInformer {
 	return &wlmJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
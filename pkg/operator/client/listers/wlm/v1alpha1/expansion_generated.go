@@ -16,6 +16,14 @@
 
 package v1alpha1
 
+// SlurmCronJobListerExpansion allows custom methods to be added to
+// SlurmCronJobLister.
+type SlurmCronJobListerExpansion interface{}
+
+// SlurmCronJobNamespaceListerExpansion allows custom methods to be added to
+// SlurmCronJobNamespaceLister.
+type SlurmCronJobNamespaceListerExpansion interface{}
+
 // SlurmJobListerExpansion allows custom methods to be added to
 // SlurmJobLister.
 type SlurmJobListerExpansion interface{}
@@ -24,6 +32,14 @@ type SlurmJobListerExpansion interface{}
 // SlurmJobNamespaceLister.
 type SlurmJobNamespaceListerExpansion interface{}
 
+// SlurmWorkflowListerExpansion allows custom methods to be added to
+// SlurmWorkflowLister.
+type SlurmWorkflowListerExpansion interface{}
+
+// SlurmWorkflowNamespaceListerExpansion allows custom methods to be added to
+// SlurmWorkflowNamespaceLister.
+type SlurmWorkflowNamespaceListerExpansion interface{}
+
 // WlmJobListerExpansion allows custom methods to be added to
 // WlmJobLister.
 type WlmJobListerExpansion interface{}
@@ -0,0 +1,92 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by main. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SlurmCronJobLister helps list SlurmCronJobs.
+type SlurmCronJobLister interface {
+	// List lists all SlurmCronJobs in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.SlurmCronJob, err error)
+	// SlurmCronJobs returns an object that can list and get SlurmCronJobs.
+	SlurmCronJobs(namespace string) SlurmCronJobNamespaceLister
+	SlurmCronJobListerExpansion
+}
+
+// slurmCronJobLister implements the SlurmCronJobLister interface.
+type slurmCronJobLister struct {
+	indexer cache.Indexer
+}
+
+// NewSlurmCronJobLister returns a new SlurmCronJobLister.
+func NewSlurmCronJobLister(indexer cache.Indexer) SlurmCronJobLister {
+	return &slurmCronJobLister{indexer: indexer}
+}
+
+// List lists all SlurmCronJobs in the indexer.
+func (s *slurmCronJobLister) List(selector labels.Selector) (ret []*v1alpha1.SlurmCronJob, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.SlurmCronJob))
+	})
+	return ret, err
+}
+
+// SlurmCronJobs returns an object that can list and get SlurmCronJobs.
+func (s *slurmCronJobLister) SlurmCronJobs(namespace string) SlurmCronJobNamespaceLister {
+	return slurmCronJobNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// SlurmCronJobNamespaceLister helps list and get SlurmCronJobs.
+type SlurmCronJobNamespaceLister interface {
+	// List lists all SlurmCronJobs in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.SlurmCronJob, err error)
+	// Get retrieves the SlurmCronJob from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.SlurmCronJob, error)
+	SlurmCronJobNamespaceListerExpansion
+}
+
+// slurmCronJobNamespaceLister implements the SlurmCronJobNamespaceLister
+// interface.
+type slurmCronJobNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all SlurmCronJobs in the indexer for a given namespace.
+func (s slurmCronJobNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.SlurmCronJob, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.SlurmCronJob))
+	})
+	return ret, err
+}
+
+// Get retrieves the SlurmCronJob from the indexer for a given namespace and name.
+func (s slurmCronJobNamespaceLister) Get(name string) (*v1alpha1.SlurmCronJob, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("slurmcronjob"), name)
+	}
+	return obj.(*v1alpha1.SlurmCronJob), nil
+}
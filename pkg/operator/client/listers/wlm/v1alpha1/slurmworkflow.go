@@ -0,0 +1,92 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by main. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SlurmWorkflowLister helps list SlurmWorkflows.
+type SlurmWorkflowLister interface {
+	// List lists all SlurmWorkflows in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.SlurmWorkflow, err error)
+	// SlurmWorkflows returns an object that can list and get SlurmWorkflows.
+	SlurmWorkflows(namespace string) SlurmWorkflowNamespaceLister
+	SlurmWorkflowListerExpansion
+}
+
+// slurmWorkflowLister implements the SlurmWorkflowLister interface.
+type slurmWorkflowLister struct {
+	indexer cache.Indexer
+}
+
+// NewSlurmWorkflowLister returns a new SlurmWorkflowLister.
+func NewSlurmWorkflowLister(indexer cache.Indexer) SlurmWorkflowLister {
+	return &slurmWorkflowLister{indexer: indexer}
+}
+
+// List lists all SlurmWorkflows in the indexer.
+func (s *slurmWorkflowLister) List(selector labels.Selector) (ret []*v1alpha1.SlurmWorkflow, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.SlurmWorkflow))
+	})
+	return ret, err
+}
+
+// SlurmWorkflows returns an object that can list and get SlurmWorkflows.
+func (s *slurmWorkflowLister) SlurmWorkflows(namespace string) SlurmWorkflowNamespaceLister {
+	return slurmWorkflowNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// SlurmWorkflowNamespaceLister helps list and get SlurmWorkflows.
+type SlurmWorkflowNamespaceLister interface {
+	// List lists all SlurmWorkflows in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.SlurmWorkflow, err error)
+	// Get retrieves the SlurmWorkflow from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.SlurmWorkflow, error)
+	SlurmWorkflowNamespaceListerExpansion
+}
+
+// slurmWorkflowNamespaceLister implements the SlurmWorkflowNamespaceLister
+// interface.
+type slurmWorkflowNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all SlurmWorkflows in the indexer for a given namespace.
+func (s slurmWorkflowNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.SlurmWorkflow, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.SlurmWorkflow))
+	})
+	return ret, err
+}
+
+// Get retrieves the SlurmWorkflow from the indexer for a given namespace and name.
+func (s slurmWorkflowNamespaceLister) Get(name string) (*v1alpha1.SlurmWorkflow, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("slurmworkflow"), name)
+	}
+	return obj.(*v1alpha1.SlurmWorkflow), nil
+}
@@ -16,7 +16,9 @@ package controller
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -46,19 +48,76 @@ type Resources struct {
 	MemPerNode int64
 	CPUPerNode int64
 	WallTime   time.Duration
+
+	// GPUs is a number of GPUs required on each node.
+	GPUs int64
+	// GPUType is a required GPU type, e.g. v100. Any type
+	// will satisfy the job if it is empty.
+	GPUType string
+}
+
+// MaxNodeClasses is the number of partition node classes virtual nodes are
+// labeled with. Labels of the largest class have no class prefix, e.g.
+// wlm.sylabs.io/nodes, labels of the other classes are prefixed with
+// their index, e.g. wlm.sylabs.io/class-1-nodes.
+const MaxNodeClasses = 3
+
+// NodeClassLabel returns node label with the given name for the node class.
+func NodeClassLabel(class int, name string) string {
+	if class == 0 {
+		return "wlm.sylabs.io/" + name
+	}
+	return fmt.Sprintf("wlm.sylabs.io/class-%d-%s", class, name)
+}
+
+// GPULabel returns node class label that holds the number of GPUs per node
+// of the given type, or the total number of GPUs per node if type is empty.
+func GPULabel(class int, gpuType string) string {
+	if gpuType == "" {
+		return NodeClassLabel(class, "gpu")
+	}
+	return NodeClassLabel(class, "gpu-"+strings.ToLower(gpuType))
 }
 
 // AffinityForResources returns k8s affinity for requested resources
 // In case empty(default) resources ErrAffinityIsNotRequired will be returned.
+// Partition nodes may differ, so affinity requires at least the requested
+// number of nodes of any single node class to fit the per node resources.
 func AffinityForResources(r Resources) (*corev1.Affinity, error) {
+	classes := MaxNodeClasses
+	if r.Nodes == 0 && r.MemPerNode == 0 && r.CPUPerNode == 0 && r.GPUs == 0 {
+		classes = 1
+	}
+
+	var terms []corev1.NodeSelectorTerm
+	for class := 0; class < classes; class++ {
+		nodeMatch := nodeClassRequirements(r, class)
+		if len(nodeMatch) == 0 {
+			return nil, ErrAffinityIsNotRequired
+		}
+		terms = append(terms, corev1.NodeSelectorTerm{MatchExpressions: nodeMatch})
+	}
+
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: terms,
+			},
+		},
+	}, nil
+}
+
+// nodeClassRequirements returns requirements for node class labels.
+func nodeClassRequirements(r Resources, class int) []corev1.NodeSelectorRequirement {
 	var nodeMatch []corev1.NodeSelectorRequirement
 	if r.Nodes != 0 {
 		nodeMatch = append(nodeMatch, corev1.NodeSelectorRequirement{
-			Key:      "wlm.sylabs.io/nodes",
+			Key:      NodeClassLabel(class, "nodes"),
 			Operator: "Gt",
 			Values:   []string{strconv.FormatInt(r.Nodes-1, 10)},
 		})
 	}
+	// wall time is set for a partition, not for a node class
 	if r.WallTime != 0 {
 		nodeMatch = append(nodeMatch, corev1.NodeSelectorRequirement{
 			Key:      "wlm.sylabs.io/wall-time",
@@ -68,28 +127,24 @@ func AffinityForResources(r Resources) (*corev1.Affinity, error) {
 	}
 	if r.MemPerNode != 0 {
 		nodeMatch = append(nodeMatch, corev1.NodeSelectorRequirement{
-			Key:      "wlm.sylabs.io/mem-per-node",
+			Key:      NodeClassLabel(class, "mem-per-node"),
 			Operator: "Gt",
 			Values:   []string{strconv.FormatInt(r.MemPerNode-1, 10)},
 		})
 	}
 	if r.CPUPerNode != 0 {
 		nodeMatch = append(nodeMatch, corev1.NodeSelectorRequirement{
-			Key:      "wlm.sylabs.io/cpu-per-node",
+			Key:      NodeClassLabel(class, "cpu-per-node"),
 			Operator: "Gt",
 			Values:   []string{strconv.FormatInt(r.CPUPerNode-1, 10)},
 		})
 	}
-
-	if len(nodeMatch) == 0 {
-		return nil, ErrAffinityIsNotRequired
+	if r.GPUs != 0 {
+		nodeMatch = append(nodeMatch, corev1.NodeSelectorRequirement{
+			Key:      GPULabel(class, r.GPUType),
+			Operator: "Gt",
+			Values:   []string{strconv.FormatInt(r.GPUs-1, 10)},
+		})
 	}
-
-	return &corev1.Affinity{
-		NodeAffinity: &corev1.NodeAffinity{
-			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
-				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: nodeMatch}},
-			},
-		},
-	}, nil
+	return nodeMatch
 }
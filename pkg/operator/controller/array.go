@@ -0,0 +1,323 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/dptech-corp/wlm-operator/pkg/slurm"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Phases array tasks are counted by.
+const (
+	taskPending = iota
+	taskRunning
+	taskSucceeded
+	taskFailed
+)
+
+// isArray checks whether job info describes tasks of a job array.
+func isArray(infos []*api.JobInfo) bool {
+	for _, info := range infos {
+		if info.ArrayId != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// taskPhase maps workload manager state of an array task to the task phase.
+func taskPhase(state string) int {
+	if fields := strings.Fields(state); len(fields) != 0 {
+		state = fields[0]
+	}
+	switch {
+	case state == statePending:
+		return taskPending
+	case state == api.JobStatus_COMPLETED.String():
+		return taskSucceeded
+	case state == api.JobStatus_CANCELLED.String(), state == api.JobStatus_FAILED.String(),
+		state == api.JobStatus_TIMEOUT.String(), failedStates[state]:
+		return taskFailed
+	}
+	return taskRunning
+}
+
+// maxArrayTasks limits the number of tasks listed in job array status,
+// so that status of a large array stays well within object size limits.
+const maxArrayTasks = 100
+
+// arrayStatusFromInfo fills job status from info of job array tasks. Tasks pending
+// within a range of indices are only counted. Tasks that are no longer reported
+// keep their last known status, retries of the tasks are preserved. Job status
+// is aggregated over the tasks: the job is running while any task runs and is
+// finished once all tasks are finished, it succeeds only if all tasks succeed.
+func arrayStatusFromInfo(status *v1alpha1.JobStatus, infos []*api.JobInfo, now metav1.Time) bool {
+	known := make(map[int32]v1alpha1.ArrayTaskStatus)
+	if status.Array != nil {
+		// tasks that are not listed are known by their indices only
+		for state, indices := range map[string]string{
+			api.JobStatus_COMPLETED.String(): status.Array.SucceededIndices,
+			api.JobStatus_FAILED.String():    status.Array.FailedIndices,
+		} {
+			parsed, _, _ := slurm.ParseArrayIndices(indices)
+			for _, i := range parsed {
+				known[int32(i)] = v1alpha1.ArrayTaskStatus{Index: int32(i), State: state}
+			}
+		}
+		for _, t := range status.Array.Tasks {
+			known[t.Index] = t
+		}
+	}
+
+	array := &v1alpha1.ArrayStatus{}
+	ranged := make(map[int32]bool)
+	tasks := make(map[int32]v1alpha1.ArrayTaskStatus)
+	for _, info := range infos {
+		state := info.State
+		if state == "" {
+			state = info.Status.String()
+		}
+		index, err := strconv.ParseInt(info.ArrayTaskId, 10, 32)
+		if err != nil {
+			indices, _, err := slurm.ParseArrayIndices(info.ArrayTaskId)
+			if err != nil {
+				continue
+			}
+			for _, i := range indices {
+				ranged[int32(i)] = true
+			}
+			continue
+		}
+		t := known[int32(index)]
+		t.Index = int32(index)
+		t.JobID = info.Id
+		t.State = state
+		t.ExitCode = info.ExitCode
+		t.EndTime = protoTime(info.EndTime)
+		tasks[t.Index] = t
+	}
+	for i, t := range known {
+		if _, ok := tasks[i]; !ok && !ranged[i] {
+			tasks[i] = t
+		}
+	}
+	for i := range ranged {
+		if _, ok := tasks[i]; !ok {
+			array.Pending++
+		}
+	}
+
+	all := make([]v1alpha1.ArrayTaskStatus, 0, len(tasks))
+	for _, t := range tasks {
+		// requeued task may still be reported with the state of the failed run
+		if t.RetryTime != nil && taskPhase(t.State) != taskRunning &&
+			(t.EndTime == nil || !t.EndTime.After(t.RetryTime.Time)) {
+			t.State, t.ExitCode, t.EndTime = statePending, "", nil
+		}
+		all = append(all, t)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Index < all[j].Index })
+	var succeeded, failed []int64
+	for _, t := range all {
+		switch taskPhase(t.State) {
+		case taskPending:
+			array.Pending++
+		case taskRunning:
+			array.Running++
+		case taskSucceeded:
+			array.Succeeded++
+			succeeded = append(succeeded, int64(t.Index))
+		case taskFailed:
+			array.Failed++
+			failed = append(failed, int64(t.Index))
+		}
+	}
+	array.Total = array.Pending + array.Running + array.Succeeded + array.Failed
+	array.SucceededIndices = slurm.FormatArrayIndices(succeeded)
+	array.FailedIndices = slurm.FormatArrayIndices(failed)
+	array.Tasks = listedTasks(all)
+	status.Array = array
+
+	return statusFromInfo(status, arrayInfo(infos, array, all), now)
+}
+
+// listedTasks returns tasks that are listed in job array status: the ones that
+// were retried, failed or are running, at most maxArrayTasks of them. Retried and
+// failed tasks go first, since their retries are counted in the list. Tasks are
+// sorted by index.
+func listedTasks(tasks []v1alpha1.ArrayTaskStatus) []v1alpha1.ArrayTaskStatus {
+	var listed, running []v1alpha1.ArrayTaskStatus
+	for _, t := range tasks {
+		switch phase := taskPhase(t.State); {
+		case t.Retries != 0, phase == taskFailed:
+			listed = append(listed, t)
+		case phase == taskRunning:
+			running = append(running, t)
+		}
+	}
+	listed = append(listed, running...)
+	if len(listed) > maxArrayTasks {
+		listed = listed[:maxArrayTasks]
+	}
+	sort.Slice(listed, func(i, j int) bool { return listed[i].Index < listed[j].Index })
+	return listed
+}
+
+// arrayInfo aggregates info of job array tasks into info of the whole job,
+// tasks hold status of all known tasks of the array, not only the listed ones.
+func arrayInfo(infos []*api.JobInfo, array *v1alpha1.ArrayStatus, tasks []v1alpha1.ArrayTaskStatus) *api.JobInfo {
+	info := &api.JobInfo{
+		Id:        infos[0].Id,
+		Partition: infos[0].Partition,
+	}
+	var nodes []string
+	seen := make(map[string]bool)
+	for _, t := range infos {
+		info.SubmitTime = earliest(info.SubmitTime, t.SubmitTime)
+		info.StartTime = earliest(info.StartTime, t.StartTime)
+		if t.EndTime != nil && (info.EndTime == nil || t.EndTime.Seconds > info.EndTime.Seconds) {
+			info.EndTime = t.EndTime
+		}
+		if t.NodeList != "" && !seen[t.NodeList] && taskPhase(t.State) == taskRunning {
+			seen[t.NodeList] = true
+			nodes = append(nodes, t.NodeList)
+		}
+		if t.Status == api.JobStatus_PENDING && info.Reason == "" {
+			info.Reason = t.Reason
+		}
+	}
+	info.NodeList = strings.Join(nodes, ",")
+
+	switch {
+	case array.Running != 0:
+		// the job is suspended only when all of its started tasks are
+		info.Status, info.State, info.Reason, info.EndTime = api.JobStatus_UNKNOWN, stateSuspended, "", nil
+		for _, t := range tasks {
+			if taskPhase(t.State) == taskRunning && t.State != stateSuspended {
+				info.State = stateRunning
+			}
+		}
+	case array.Pending != 0:
+		info.Status, info.State, info.EndTime = api.JobStatus_PENDING, statePending, nil
+	case array.Failed != 0:
+		info.Status, info.State, info.Reason = api.JobStatus_FAILED, api.JobStatus_FAILED.String(), ""
+		for _, t := range tasks {
+			if taskPhase(t.State) == taskFailed {
+				info.State, info.ExitCode = t.State, t.ExitCode
+				break
+			}
+		}
+	default:
+		info.Status, info.State, info.Reason = api.JobStatus_COMPLETED, api.JobStatus_COMPLETED.String(), ""
+		// tasks known by their indices only have no exit code
+		for _, t := range tasks {
+			if t.ExitCode != "" {
+				info.ExitCode = t.ExitCode
+				break
+			}
+		}
+	}
+	return info
+}
+
+func earliest(a, b *timestamp.Timestamp) *timestamp.Timestamp {
+	if a == nil || (b != nil && b.Seconds < a.Seconds) {
+		return b
+	}
+	return a
+}
+
+// nextTaskRetry checks whether any failed task of the job array should be
+// retried according to the policy. Each task is retried up to the backoff limit
+// times, backoff is counted from the time the task finished at. It returns
+// true with time left to wait before the earliest retry.
+func nextTaskRetry(p *v1alpha1.RetryPolicy, array *v1alpha1.ArrayStatus, now time.Time) (bool, time.Duration) {
+	retry, wait := false, time.Duration(0)
+	for i := range array.Tasks {
+		ok, w := taskRetry(p, &array.Tasks[i], now)
+		if ok && (!retry || w < wait) {
+			retry, wait = true, w
+		}
+	}
+	return retry, wait
+}
+
+func taskRetry(p *v1alpha1.RetryPolicy, t *v1alpha1.ArrayTaskStatus, now time.Time) (bool, time.Duration) {
+	if t.Retries >= p.BackoffLimit || t.EndTime == nil || taskPhase(t.State) != taskFailed {
+		return false, 0
+	}
+	// task has not been restarted since the last retry yet
+	if t.RetryTime != nil && !t.EndTime.After(t.RetryTime.Time) {
+		return false, 0
+	}
+	if !retryOn(p, t.State) {
+		return false, 0
+	}
+	wait := t.EndTime.Add(retryBackoff(p, int(t.Retries))).Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	return true, wait
+}
+
+// RetryTasks requeues failed tasks of the job array that are due for retry
+// according to the policy. Retries of the requeued tasks are counted and the
+// Failed condition is reset since the job is not finished anymore. It returns
+// indices of the requeued tasks.
+func RetryTasks(ctx context.Context, wlm api.WorkloadManagerClient, p *v1alpha1.RetryPolicy,
+	st *v1alpha1.JobStatus, now metav1.Time) ([]int32, error) {
+	if p == nil || st.Array == nil {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(st.JobID, 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid job id %q", st.JobID)
+	}
+
+	var requeued []int32
+	for i := range st.Array.Tasks {
+		t := &st.Array.Tasks[i]
+		if retry, wait := taskRetry(p, t, now.Time); !retry || wait > 0 {
+			continue
+		}
+		_, err = wlm.RequeueJob(ctx, &api.RequeueJobRequest{
+			JobId:       id,
+			ArrayTaskId: strconv.Itoa(int(t.Index)),
+		})
+		if err != nil {
+			err = errors.Wrapf(err, "could not requeue task %d of job %d", t.Index, id)
+			break
+		}
+		t.Retries++
+		t.RetryTime = now.DeepCopy()
+		requeued = append(requeued, t.Index)
+	}
+
+	if c := JobCondition(st, v1alpha1.JobFailed); len(requeued) != 0 && c != nil && c.Status == corev1.ConditionTrue {
+		SetJobCondition(st, v1alpha1.JobFailed, corev1.ConditionFalse, RetryingStatus, "", now)
+	}
+	return requeued, err
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateJobStatus_array(t *testing.T) {
+	started := time.Now().Add(-time.Hour).Truncate(time.Second)
+	ended := started.Add(time.Minute)
+	startTime, err := ptypes.TimestampProto(started)
+	require.NoError(t, err)
+	endTime, err := ptypes.TimestampProto(ended)
+	require.NoError(t, err)
+
+	task := func(id, index, state, exitCode string) *api.JobInfo {
+		info := &api.JobInfo{
+			Id:          id,
+			ArrayId:     "40",
+			ArrayTaskId: index,
+			State:       state,
+			Status:      api.JobStatus_UNKNOWN,
+			Partition:   "debug",
+			ExitCode:    exitCode,
+			NodeList:    "node" + index,
+			StartTime:   startTime,
+		}
+		switch state {
+		case "COMPLETED":
+			info.Status, info.EndTime = api.JobStatus_COMPLETED, endTime
+		case "FAILED":
+			info.Status, info.EndTime = api.JobStatus_FAILED, endTime
+		case "PENDING":
+			info.Status, info.StartTime, info.NodeList, info.Reason = api.JobStatus_PENDING, nil, "", "JobArrayTaskLimit"
+		}
+		return info
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{JobIDAnnotation: "40"}}}
+	wlm := &fakeWLM{tasks: []*api.JobInfo{
+		task("40", "0", "COMPLETED", "0:0"),
+		task("41", "1", "FAILED", "2:0"),
+		task("42", "2", "RUNNING", "0:0"),
+		task("40", "3-5%2", "PENDING", "0:0"),
+	}}
+
+	var st v1alpha1.JobStatus
+	finished, err := UpdateJobStatus(context.Background(), wlm, pod, nil, &st)
+	require.NoError(t, err)
+	require.False(t, finished)
+	require.Equal(t, "RUNNING", st.State)
+	require.Equal(t, "node2", st.NodeList)
+	require.Nil(t, st.EndTime)
+	require.Equal(t, corev1.ConditionTrue, JobCondition(&st, v1alpha1.JobRunning).Status)
+	require.Equal(t, &v1alpha1.ArrayStatus{
+		Total:            6,
+		Pending:          3,
+		Running:          1,
+		Succeeded:        1,
+		Failed:           1,
+		SucceededIndices: "0",
+		FailedIndices:    "1",
+		Tasks: []v1alpha1.ArrayTaskStatus{
+			{Index: 1, JobID: "41", State: "FAILED", ExitCode: "2:0", EndTime: st.Array.Tasks[0].EndTime},
+			{Index: 2, JobID: "42", State: "RUNNING", ExitCode: "0:0"},
+		},
+	}, st.Array)
+	require.True(t, st.Array.Tasks[0].EndTime.Time.Equal(ended))
+
+	// finished tasks are no longer reported
+	st.Array.Tasks[0].Retries = 1
+	wlm.tasks = []*api.JobInfo{
+		task("42", "2", "COMPLETED", "0:0"),
+		task("43", "3", "COMPLETED", "0:0"),
+		task("44", "4", "FAILED", "1:0"),
+		task("45", "5", "COMPLETED", "0:0"),
+	}
+	finished, err = UpdateJobStatus(context.Background(), wlm, pod, nil, &st)
+	require.NoError(t, err)
+	require.True(t, finished)
+	require.Equal(t, "FAILED", st.State)
+	require.Equal(t, "2:0", st.ExitCode)
+	require.True(t, st.EndTime.Time.Equal(ended))
+	require.EqualValues(t, 6, st.Array.Total)
+	require.EqualValues(t, 4, st.Array.Succeeded)
+	require.EqualValues(t, 2, st.Array.Failed)
+	require.Equal(t, "0,2-3,5", st.Array.SucceededIndices)
+	require.Equal(t, "1,4", st.Array.FailedIndices)
+	require.EqualValues(t, 1, st.Array.Tasks[0].Retries)
+	require.Equal(t, corev1.ConditionTrue, JobCondition(&st, v1alpha1.JobFailed).Status)
+}
+
+func TestRetryTasks(t *testing.T) {
+	failedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	endTime := metav1.NewTime(failedAt)
+	st := &v1alpha1.JobStatus{
+		JobID: "40",
+		State: "FAILED",
+		Array: &v1alpha1.ArrayStatus{Tasks: []v1alpha1.ArrayTaskStatus{
+			{Index: 0, State: "COMPLETED", EndTime: &endTime},
+			{Index: 1, State: "FAILED", EndTime: &endTime},
+			{Index: 2, State: "NODE_FAIL", EndTime: &endTime, Retries: 1},
+			{Index: 3, State: "FAILED", EndTime: &endTime, Retries: 2},
+			{Index: 4, State: "RUNNING"},
+		}},
+	}
+	SetJobCondition(st, v1alpha1.JobFailed, corev1.ConditionTrue, "FAILED", "", endTime)
+	policy := &v1alpha1.RetryPolicy{BackoffLimit: 2, BackoffSeconds: 40, RetryOn: []string{"FAILED", "NODE_FAIL"}}
+
+	retry, wait := NextRetry(nil, st, failedAt)
+	require.False(t, retry)
+	retry, wait = NextRetry(policy, st, failedAt)
+	require.True(t, retry)
+	require.Equal(t, 40*time.Second, wait)
+	_, ok := FinishedAt(st, policy, false, failedAt)
+	require.False(t, ok)
+
+	// the first task is due, the second one waits for a doubled backoff
+	wlm := &fakeWLM{}
+	now := metav1.NewTime(failedAt.Add(time.Minute))
+	requeued, err := RetryTasks(context.Background(), wlm, policy, st, now)
+	require.NoError(t, err)
+	require.Equal(t, []int32{1}, requeued)
+	require.Equal(t, []string{"requeue 40_1"}, wlm.controlled)
+	require.EqualValues(t, 1, st.Array.Tasks[1].Retries)
+	require.True(t, st.Array.Tasks[1].RetryTime.Equal(&now))
+	require.Equal(t, corev1.ConditionFalse, JobCondition(st, v1alpha1.JobFailed).Status)
+
+	retry, wait = NextRetry(policy, st, now.Time)
+	require.True(t, retry)
+	require.Equal(t, 20*time.Second, wait)
+
+	// requeued task is not retried again until it fails once more
+	requeued, err = RetryTasks(context.Background(), wlm, policy, st, metav1.NewTime(now.Add(time.Minute)))
+	require.NoError(t, err)
+	require.Equal(t, []int32{2}, requeued)
+	retry, _ = NextRetry(policy, st, now.Add(time.Hour))
+	require.False(t, retry)
+
+	// workload manager may still report the failed run of the requeued task
+	endProto, err := ptypes.TimestampProto(failedAt)
+	require.NoError(t, err)
+	finished := arrayStatusFromInfo(st, []*api.JobInfo{
+		{Id: "41", ArrayId: "40", ArrayTaskId: "1", Status: api.JobStatus_FAILED, State: "FAILED", EndTime: endProto},
+	}, now)
+	require.False(t, finished)
+	require.EqualValues(t, 1, st.Array.Tasks[0].Index)
+	require.Equal(t, "PENDING", st.Array.Tasks[0].State)
+	require.Nil(t, st.Array.Tasks[0].EndTime)
+	require.EqualValues(t, 2, st.Array.Tasks[1].Index)
+	require.Equal(t, "PENDING", st.Array.Tasks[1].State)
+	require.Equal(t, "3", st.Array.FailedIndices)
+	require.Equal(t, "0", st.Array.SucceededIndices)
+}
+
+func TestListedTasks(t *testing.T) {
+	var tasks []v1alpha1.ArrayTaskStatus
+	for i := 0; i < 10000; i++ {
+		state := "COMPLETED"
+		switch {
+		case i%10 == 0:
+			state = "RUNNING"
+		case i == 9999:
+			state = "FAILED"
+		}
+		tasks = append(tasks, v1alpha1.ArrayTaskStatus{Index: int32(i), State: state})
+	}
+	tasks[5].Retries = 1
+
+	// succeeded tasks are only counted, retried and failed ones are listed first
+	listed := listedTasks(tasks)
+	require.Len(t, listed, maxArrayTasks)
+	require.EqualValues(t, 0, listed[0].Index)
+	require.EqualValues(t, 5, listed[1].Index)
+	require.EqualValues(t, 9999, listed[maxArrayTasks-1].Index)
+	for _, task := range listed[2 : maxArrayTasks-1] {
+		require.Equal(t, "RUNNING", task.State)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import "strings"
+
+// WithBatchDirective inserts #SBATCH directive into the batch script right after the shebang.
+func WithBatchDirective(batch, directive string) string {
+	if directive == "" {
+		return batch
+	}
+	if !strings.HasPrefix(batch, "#!") {
+		return directive + "\n" + batch
+	}
+	i := strings.IndexByte(batch, '\n')
+	if i == -1 {
+		return batch + "\n" + directive + "\n"
+	}
+	return batch[:i+1] + directive + "\n" + batch[i+1:]
+}
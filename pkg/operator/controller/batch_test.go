@@ -0,0 +1,31 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBatchDirective(t *testing.T) {
+	const directive = "#SBATCH --dependency=afterok:12"
+
+	require.Equal(t, "#!/bin/sh\n#SBATCH --dependency=afterok:12\n#SBATCH --nodes=1\nhostname\n",
+		WithBatchDirective("#!/bin/sh\n#SBATCH --nodes=1\nhostname\n", directive))
+	require.Equal(t, "#SBATCH --dependency=afterok:12\nhostname\n", WithBatchDirective("hostname\n", directive))
+	require.Equal(t, "#!/bin/sh\n#SBATCH --dependency=afterok:12\n", WithBatchDirective("#!/bin/sh", directive))
+	require.Equal(t, "hostname\n", WithBatchDirective("hostname\n", ""))
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	wlmcontroller "github.com/dptech-corp/wlm-operator/pkg/operator/controller"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// EventTTLExpired is a reason of the event recorded when finished job is deleted.
+const EventTTLExpired = "TTLExpired"
+
+// Reconciler deletes finished jobs of a single kind once their TTL passes.
+// Owned job pods are deleted by the garbage collector.
+type Reconciler struct {
+	client   client.Client
+	recorder record.EventRecorder
+
+	kind     string
+	defaults wlmcontroller.TTLDefaults
+}
+
+// NewReconciler returns a new cleanup controller for jobs of the given kind,
+// which is either wlmcontroller.KindSlurmJob or wlmcontroller.KindWlmJob.
+func NewReconciler(mgr manager.Manager, kind string, defaults wlmcontroller.TTLDefaults) *Reconciler {
+	return &Reconciler{
+		client:   mgr.GetClient(),
+		recorder: mgr.GetRecorder(controllerName(kind)),
+		kind:     kind,
+		defaults: defaults,
+	}
+}
+
+func controllerName(kind string) string {
+	return strings.ToLower(kind) + "-cleanup-controller"
+}
+
+// AddToManager adds cleanup Reconciler to the given Manager.
+func (r *Reconciler) AddToManager(mgr manager.Manager) error {
+	c, err := controller.New(controllerName(r.kind), mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	return c.Watch(&source.Kind{Type: r.newJob()}, &handler.EnqueueRequestForObject{})
+}
+
+// Reconcile deletes the job if it is finished and its TTL has passed,
+// otherwise it requeues the job to be checked once the TTL passes.
+func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	job := r.newJob()
+	err := r.client.Get(context.Background(), req.NamespacedName, job)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		glog.Errorf("Could not get %s: %v", r.kind, err)
+		return reconcile.Result{}, err
+	}
+	if job.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, nil
+	}
+
+	ttlSeconds, st, policy, results := jobDetails(job)
+	ttl, ok := r.defaults.TTL(job.GetNamespace(), ttlSeconds)
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	now := time.Now()
+	finishedAt, ok := wlmcontroller.FinishedAt(st, policy, results, now)
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	if wait := finishedAt.Add(ttl).Sub(now); wait > 0 {
+		return reconcile.Result{RequeueAfter: wait}, nil
+	}
+
+	glog.Infof("Deleting %s %q finished at %s", r.kind, job.GetName(), finishedAt)
+	r.recorder.Event(job, corev1.EventTypeNormal, EventTTLExpired,
+		fmt.Sprintf("Job finished more than %s ago, deleting", ttl))
+	err = r.client.Delete(context.Background(), job)
+	if err != nil && !errors.IsNotFound(err) {
+		glog.Errorf("Could not delete %s: %v", r.kind, err)
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+func (r *Reconciler) newJob() wlmcontroller.Object {
+	if r.kind == wlmcontroller.KindWlmJob {
+		return &wlmv1alpha1.WlmJob{}
+	}
+	return &wlmv1alpha1.SlurmJob{}
+}
+
+func jobDetails(job wlmcontroller.Object) (*int32, *wlmv1alpha1.JobStatus, *wlmv1alpha1.RetryPolicy, bool) {
+	switch j := job.(type) {
+	case *wlmv1alpha1.SlurmJob:
+		return j.Spec.TTLSecondsAfterFinished, &j.Status.JobStatus, j.Spec.RetryPolicy, j.Spec.Results != nil
+	case *wlmv1alpha1.WlmJob:
+		return j.Spec.TTLSecondsAfterFinished, &j.Status.JobStatus, j.Spec.RetryPolicy, j.Spec.Results != nil
+	}
+	return nil, &wlmv1alpha1.JobStatus{}, nil, false
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cleanup
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	wlmcontroller "github.com/dptech-corp/wlm-operator/pkg/operator/controller"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var testScheme = runtime.NewScheme()
+
+func TestMain(m *testing.M) {
+	if err := wlmv1alpha1.AddToScheme(testScheme); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func finishedJob(name string, finishedAt time.Time) *wlmv1alpha1.SlurmJob {
+	sj := &wlmv1alpha1.SlurmJob{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	sj.Status.Conditions = []wlmv1alpha1.JobCondition{{
+		Type:               wlmv1alpha1.JobSucceeded,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(finishedAt),
+	}}
+	return sj
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	now := time.Now()
+	running := &wlmv1alpha1.SlurmJob{ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default"}}
+	keep := int32(3600)
+	recent := finishedJob("recent", now.Add(-time.Minute))
+	recent.Spec.TTLSecondsAfterFinished = &keep
+	expired := finishedJob("expired", now.Add(-2*time.Hour))
+
+	c := fake.NewFakeClientWithScheme(testScheme, running, recent, expired)
+	rec := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		client:   c,
+		recorder: rec,
+		kind:     wlmcontroller.KindSlurmJob,
+		defaults: wlmcontroller.TTLDefaults{"": time.Hour},
+	}
+
+	tt := []struct {
+		name        string
+		expectWait  bool
+		expectExist bool
+	}{
+		{name: "running", expectExist: true},
+		{name: "recent", expectWait: true, expectExist: true},
+		{name: "expired", expectExist: false},
+		{name: "missing", expectExist: false},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			key := types.NamespacedName{Namespace: "default", Name: tc.name}
+			res, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+			require.NoError(t, err)
+			require.False(t, res.Requeue)
+			if tc.expectWait {
+				require.True(t, res.RequeueAfter > 0 && res.RequeueAfter <= time.Hour, res.RequeueAfter)
+			} else {
+				require.Zero(t, res.RequeueAfter)
+			}
+
+			err = c.Get(context.Background(), key, &wlmv1alpha1.SlurmJob{})
+			if tc.expectExist {
+				require.NoError(t, err)
+			} else {
+				require.True(t, errors.IsNotFound(err), err)
+			}
+		})
+	}
+	require.Equal(t, "Normal TTLExpired Job finished more than 1h0m0s ago, deleting", <-rec.Events)
+	require.Empty(t, rec.Events)
+}
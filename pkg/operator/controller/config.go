@@ -0,0 +1,32 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+)
+
+// Config holds settings shared by SlurmJob and WlmJob reconcilers.
+type Config struct {
+	// WLM is an optional red-box client. When set, job status is filled from
+	// the job info reported by workload manager and workload manager jobs
+	// are cancelled when job objects are deleted.
+	WLM api.WorkloadManagerClient
+	// CancelTimeout is time to wait for a job to be cancelled before
+	// the job object is removed anyway.
+	CancelTimeout time.Duration
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of events recorded for jobs.
+const (
+	EventPodCreated              = "PodCreated"
+	EventAffinityComputed        = "AffinityComputed"
+	EventInvalidSpec             = "InvalidSpec"
+	EventUnschedulable           = "Unschedulable"
+	EventJobIDMissing            = "JobIDMissing"
+	EventSubmitted               = "Submitted"
+	EventStateChanged            = "StateChanged"
+	EventCollectingResults       = "CollectingResults"
+	EventResultsCollected        = "ResultsCollected"
+	EventResultsCollectionFailed = "ResultsCollectionFailed"
+	EventRetrying                = "Retrying"
+	EventCancelling              = "Cancelling"
+	EventCancelled               = "Cancelled"
+	EventCancelTimeout           = "CancelTimeout"
+	EventSuspended               = "Suspended"
+	EventResumed                 = "Resumed"
+	EventSuspendFailed           = "SuspendFailed"
+	EventResumeFailed            = "ResumeFailed"
+	EventRedBoxRequired          = "RedBoxRequired"
+)
+
+// Reasons of job conditions.
+const (
+	reasonUnschedulable    = "Unschedulable"
+	reasonJobIDMissing     = "JobIDMissing"
+	reasonCollecting       = "Collecting"
+	reasonCollectionFailed = "CollectionFailed"
+)
+
+// RecordStatusEvents records events for transitions between the old and the current job status.
+func RecordStatusEvents(rec record.EventRecorder, o runtime.Object, old, cur *v1alpha1.JobStatus) {
+	if c := changedCondition(old, cur, v1alpha1.JobSubmitted); c != nil {
+		switch {
+		case c.Status == corev1.ConditionTrue:
+			rec.Eventf(o, corev1.EventTypeNormal, EventSubmitted, "Submitted to workload manager as job %s", cur.JobID)
+		case c.Reason == reasonUnschedulable:
+			rec.Event(o, corev1.EventTypeWarning, EventUnschedulable, c.Message)
+		case c.Reason == reasonJobIDMissing:
+			rec.Event(o, corev1.EventTypeWarning, EventJobIDMissing, c.Message)
+		}
+	}
+
+	if cur.State != "" && cur.State != old.State {
+		eventType := corev1.EventTypeNormal
+		if c := JobCondition(cur, v1alpha1.JobFailed); c != nil && c.Status == corev1.ConditionTrue {
+			eventType = corev1.EventTypeWarning
+		}
+		msg := fmt.Sprintf("Job state changed to %s", cur.State)
+		if cur.Reason != "" {
+			msg += fmt.Sprintf(" (%s)", cur.Reason)
+		}
+		rec.Event(o, eventType, EventStateChanged, msg)
+	}
+
+	if c := changedCondition(old, cur, v1alpha1.JobSuspended); c != nil {
+		switch {
+		case c.Reason == reasonSuspendFailed:
+			rec.Event(o, corev1.EventTypeWarning, EventSuspendFailed, c.Message)
+		case c.Reason == reasonResumeFailed:
+			rec.Event(o, corev1.EventTypeWarning, EventResumeFailed, c.Message)
+		case c.Status == corev1.ConditionFalse:
+			rec.Eventf(o, corev1.EventTypeNormal, EventResumed, "Job %s is resumed", cur.JobID)
+		case c.Reason == reasonHeld:
+			rec.Eventf(o, corev1.EventTypeNormal, EventSuspended, "Job %s is held", cur.JobID)
+		case c.Reason == reasonRequeued:
+			rec.Eventf(o, corev1.EventTypeNormal, EventSuspended, "Job %s is requeued and held", cur.JobID)
+		default:
+			rec.Eventf(o, corev1.EventTypeNormal, EventSuspended, "Job %s is suspended", cur.JobID)
+		}
+	}
+
+	if c := changedCondition(old, cur, v1alpha1.JobResultsCollected); c != nil {
+		switch {
+		case c.Status == corev1.ConditionTrue:
+			rec.Event(o, corev1.EventTypeNormal, EventResultsCollected, "Results collected")
+		case c.Reason == reasonCollecting:
+			rec.Event(o, corev1.EventTypeNormal, EventCollectingResults, "Collecting results")
+		case c.Reason == reasonCollectionFailed:
+			rec.Event(o, corev1.EventTypeWarning, EventResultsCollectionFailed, c.Message)
+		}
+	}
+}
+
+// RecordRedBoxRequired records a warning event for each job spec field that needs
+// red-box access, it should be called when the operator is started without -red-box.
+func RecordRedBoxRequired(rec record.EventRecorder, o runtime.Object, suspend bool,
+	retry *v1alpha1.RetryPolicy, array *v1alpha1.JobArray) {
+	const noRedBox = "Operator has no red-box access"
+	if suspend {
+		rec.Event(o, corev1.EventTypeWarning, EventRedBoxRequired,
+			noRedBox+", spec.suspend is ignored")
+	}
+	if retry != nil {
+		rec.Event(o, corev1.EventTypeWarning, EventRedBoxRequired,
+			noRedBox+", spec.retryPolicy only sees job state derived from the job pod")
+	}
+	if array != nil {
+		rec.Event(o, corev1.EventTypeWarning, EventRedBoxRequired,
+			noRedBox+", array tasks are not reported in status and failed tasks are not retried")
+	}
+}
+
+// changedCondition returns the current condition of the given type
+// if its status or reason differs from the old one.
+func changedCondition(old, cur *v1alpha1.JobStatus, t v1alpha1.JobConditionType) *v1alpha1.JobCondition {
+	c := JobCondition(cur, t)
+	if c == nil {
+		return nil
+	}
+	o := JobCondition(old, t)
+	if o != nil && o.Status == c.Status && o.Reason == c.Reason {
+		return nil
+	}
+	return c
+}
+
+// DescribeAffinity returns a human readable description of the node
+// requirements of the largest node class.
+func DescribeAffinity(a *corev1.Affinity) string {
+	if a == nil || a.NodeAffinity == nil || a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	terms := a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) == 0 {
+		return ""
+	}
+	var reqs []string
+	for _, e := range terms[0].MatchExpressions {
+		reqs = append(reqs, fmt.Sprintf("%s %s %s", e.Key, e.Operator, strings.Join(e.Values, ",")))
+	}
+	return strings.Join(reqs, ", ")
+}
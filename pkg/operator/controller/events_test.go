@@ -0,0 +1,108 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRecordStatusEvents(t *testing.T) {
+	rec := record.NewFakeRecorder(10)
+	sj := &v1alpha1.SlurmJob{}
+	now := metav1.Now()
+
+	old := v1alpha1.JobStatus{}
+	cur := v1alpha1.JobStatus{}
+	SetJobCondition(&cur, v1alpha1.JobSubmitted, corev1.ConditionFalse, reasonUnschedulable, "0/1 nodes are available", now)
+	RecordStatusEvents(rec, sj, &old, &cur)
+	require.Equal(t, "Warning Unschedulable 0/1 nodes are available", <-rec.Events)
+
+	// nothing changed
+	old = *cur.DeepCopy()
+	RecordStatusEvents(rec, sj, &old, &cur)
+	require.Empty(t, rec.Events)
+
+	old = *cur.DeepCopy()
+	SetJobCondition(&cur, v1alpha1.JobSubmitted, corev1.ConditionUnknown, reasonJobIDMissing, "no job id", now)
+	RecordStatusEvents(rec, sj, &old, &cur)
+	require.Equal(t, "Warning JobIDMissing no job id", <-rec.Events)
+	old = *cur.DeepCopy()
+
+	cur.JobID = "42"
+	cur.State = "PENDING"
+	cur.Reason = "Resources"
+	SetJobCondition(&cur, v1alpha1.JobSubmitted, corev1.ConditionTrue, "", "", now)
+	RecordStatusEvents(rec, sj, &old, &cur)
+	require.Equal(t, "Normal Submitted Submitted to workload manager as job 42", <-rec.Events)
+	require.Equal(t, "Normal StateChanged Job state changed to PENDING (Resources)", <-rec.Events)
+
+	old = *cur.DeepCopy()
+	cur.State = "FAILED"
+	cur.Reason = ""
+	SetJobCondition(&cur, v1alpha1.JobFailed, corev1.ConditionTrue, "", "", now)
+	SetJobCondition(&cur, v1alpha1.JobResultsCollected, corev1.ConditionFalse, reasonCollecting, "", now)
+	RecordStatusEvents(rec, sj, &old, &cur)
+	require.Equal(t, "Warning StateChanged Job state changed to FAILED", <-rec.Events)
+	require.Equal(t, "Normal CollectingResults Collecting results", <-rec.Events)
+
+	old = *cur.DeepCopy()
+	SetJobCondition(&cur, v1alpha1.JobSuspended, corev1.ConditionTrue, reasonHeld, "", now)
+	RecordStatusEvents(rec, sj, &old, &cur)
+	require.Equal(t, "Normal Suspended Job 42 is held", <-rec.Events)
+	old = *cur.DeepCopy()
+	SetJobCondition(&cur, v1alpha1.JobSuspended, corev1.ConditionFalse, reasonResumed, "", now)
+	RecordStatusEvents(rec, sj, &old, &cur)
+	require.Equal(t, "Normal Resumed Job 42 is resumed", <-rec.Events)
+	old = *cur.DeepCopy()
+	SetJobCondition(&cur, v1alpha1.JobSuspended, corev1.ConditionFalse, reasonSuspendFailed, "could not suspend job 42", now)
+	RecordStatusEvents(rec, sj, &old, &cur)
+	require.Equal(t, "Warning SuspendFailed could not suspend job 42", <-rec.Events)
+
+	old = *cur.DeepCopy()
+	SetJobCondition(&cur, v1alpha1.JobResultsCollected, corev1.ConditionTrue, "", "", now)
+	RecordStatusEvents(rec, sj, &old, &cur)
+	require.Equal(t, "Normal ResultsCollected Results collected", <-rec.Events)
+	require.Empty(t, rec.Events)
+}
+
+func TestRecordRedBoxRequired(t *testing.T) {
+	rec := record.NewFakeRecorder(10)
+	sj := &v1alpha1.SlurmJob{}
+
+	RecordRedBoxRequired(rec, sj, false, nil, nil)
+	require.Empty(t, rec.Events)
+
+	RecordRedBoxRequired(rec, sj, true, &v1alpha1.RetryPolicy{BackoffLimit: 1}, &v1alpha1.JobArray{Indices: "0-9"})
+	require.Equal(t, "Warning RedBoxRequired Operator has no red-box access, spec.suspend is ignored", <-rec.Events)
+	require.Equal(t, "Warning RedBoxRequired Operator has no red-box access, "+
+		"spec.retryPolicy only sees job state derived from the job pod", <-rec.Events)
+	require.Equal(t, "Warning RedBoxRequired Operator has no red-box access, "+
+		"array tasks are not reported in status and failed tasks are not retried", <-rec.Events)
+	require.Empty(t, rec.Events)
+}
+
+func TestDescribeAffinity(t *testing.T) {
+	require.Empty(t, DescribeAffinity(nil))
+
+	a, err := AffinityForResources(Resources{Nodes: 2, CPUPerNode: 4})
+	require.NoError(t, err)
+	require.Equal(t, "wlm.sylabs.io/nodes Gt 1, wlm.sylabs.io/cpu-per-node Gt 3", DescribeAffinity(a))
+}
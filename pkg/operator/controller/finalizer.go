@@ -0,0 +1,141 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// CancelJobFinalizer is set on jobs to cancel the workload manager
+// job before the job object is removed.
+const CancelJobFinalizer = "wlm.sylabs.io/cancel-job"
+
+// DefaultCancelTimeout is a default time to wait for a cancelled job
+// to reach a terminal state.
+const DefaultCancelTimeout = 5 * time.Minute
+
+// CancelPollInterval is an interval cancelled job state is checked at.
+const CancelPollInterval = 5 * time.Second
+
+// Object is a job object, e.g. SlurmJob or WlmJob.
+type Object interface {
+	metav1.Object
+	runtime.Object
+}
+
+// HasFinalizer checks whether object has the given finalizer.
+func HasFinalizer(o metav1.Object, finalizer string) bool {
+	for _, f := range o.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// AddFinalizer adds the given finalizer to the object.
+func AddFinalizer(o metav1.Object, finalizer string) {
+	if HasFinalizer(o, finalizer) {
+		return
+	}
+	o.SetFinalizers(append(o.GetFinalizers(), finalizer))
+}
+
+// RemoveFinalizer removes the given finalizer from the object.
+func RemoveFinalizer(o metav1.Object, finalizer string) {
+	var finalizers []string
+	for _, f := range o.GetFinalizers() {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	o.SetFinalizers(finalizers)
+}
+
+// CancelJob cancels workload manager job of the deleted job object. It returns true when
+// the job reached a terminal state, was never submitted or when the timeout since object
+// deletion has passed, so the finalizer may be removed. The job is cancelled once, which is
+// recorded with JobCancelRequested condition, later calls only check the job state.
+func CancelJob(ctx context.Context, wlm api.WorkloadManagerClient, rec record.EventRecorder,
+	o Object, st *v1alpha1.JobStatus, timeout time.Duration) (bool, error) {
+	if st.JobID == "" {
+		return true, nil
+	}
+	if d := o.GetDeletionTimestamp(); d != nil && time.Since(d.Time) > timeout {
+		glog.Warningf("Job %s was not cancelled in %s, giving up", st.JobID, timeout)
+		rec.Eventf(o, corev1.EventTypeWarning, EventCancelTimeout, "Job %s was not cancelled in %s", st.JobID, timeout)
+		return true, nil
+	}
+
+	id, err := strconv.ParseInt(st.JobID, 10, 64)
+	if err != nil {
+		glog.Errorf("Invalid job id %q, job will not be cancelled", st.JobID)
+		return true, nil
+	}
+
+	resp, err := wlm.JobInfo(ctx, &api.JobInfoRequest{JobId: id})
+	if status.Code(err) == codes.NotFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "could not get job %d info", id)
+	}
+	if len(resp.Info) == 0 {
+		return true, nil
+	}
+	// tasks of a job array are reported separately, all of them must be finished
+	terminal := true
+	for _, info := range resp.Info {
+		terminal = terminal && isTerminal(info)
+	}
+	if terminal {
+		if resp.Info[0].Status == api.JobStatus_CANCELLED {
+			rec.Eventf(o, corev1.EventTypeNormal, EventCancelled, "Job %d is cancelled", id)
+		}
+		return true, nil
+	}
+
+	if c := JobCondition(st, v1alpha1.JobCancelRequested); c != nil && c.Status == corev1.ConditionTrue {
+		return false, nil
+	}
+	_, err = wlm.CancelJob(ctx, &api.CancelJobRequest{JobId: id})
+	if err != nil {
+		return false, errors.Wrapf(err, "could not cancel job %d", id)
+	}
+	SetJobCondition(st, v1alpha1.JobCancelRequested, corev1.ConditionTrue, "", "", metav1.Now())
+	rec.Eventf(o, corev1.EventTypeNormal, EventCancelling, "Cancelling job %d", id)
+	return false, nil
+}
+
+func isTerminal(info *api.JobInfo) bool {
+	switch info.Status {
+	case api.JobStatus_COMPLETED, api.JobStatus_CANCELLED, api.JobStatus_FAILED, api.JobStatus_TIMEOUT:
+		return true
+	}
+	return failedStates[info.State]
+}
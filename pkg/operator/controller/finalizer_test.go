@@ -0,0 +1,107 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestFinalizers(t *testing.T) {
+	sj := &v1alpha1.SlurmJob{}
+	require.False(t, HasFinalizer(sj, CancelJobFinalizer))
+
+	AddFinalizer(sj, "foo")
+	AddFinalizer(sj, CancelJobFinalizer)
+	AddFinalizer(sj, CancelJobFinalizer)
+	require.True(t, HasFinalizer(sj, CancelJobFinalizer))
+	require.Equal(t, []string{"foo", CancelJobFinalizer}, sj.Finalizers)
+
+	RemoveFinalizer(sj, CancelJobFinalizer)
+	require.False(t, HasFinalizer(sj, CancelJobFinalizer))
+	require.Equal(t, []string{"foo"}, sj.Finalizers)
+}
+
+func TestCancelJob(t *testing.T) {
+	deleted := metav1.Now()
+	sj := &v1alpha1.SlurmJob{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted}}
+	wlm := &fakeWLM{info: &api.JobInfo{Id: "42", Status: api.JobStatus_PENDING}}
+	rec := record.NewFakeRecorder(10)
+
+	// job was never submitted
+	done, err := CancelJob(context.Background(), wlm, rec, sj, &sj.Status.JobStatus, time.Minute)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Empty(t, wlm.cancelled)
+
+	sj.Status.JobID = "42"
+	done, err = CancelJob(context.Background(), wlm, rec, sj, &sj.Status.JobStatus, time.Minute)
+	require.NoError(t, err)
+	require.False(t, done)
+	require.Equal(t, []int64{42}, wlm.cancelled)
+	require.Equal(t, "Normal Cancelling Cancelling job 42", <-rec.Events)
+	require.Equal(t, corev1.ConditionTrue, JobCondition(&sj.Status.JobStatus, v1alpha1.JobCancelRequested).Status)
+
+	// job is cancelled once, then its state is polled
+	done, err = CancelJob(context.Background(), wlm, rec, sj, &sj.Status.JobStatus, time.Minute)
+	require.NoError(t, err)
+	require.False(t, done)
+	require.Equal(t, []int64{42}, wlm.cancelled)
+	require.Empty(t, rec.Events)
+
+	wlm.info.Status = api.JobStatus_CANCELLED
+	done, err = CancelJob(context.Background(), wlm, rec, sj, &sj.Status.JobStatus, time.Minute)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Len(t, wlm.cancelled, 1)
+	require.Equal(t, "Normal Cancelled Job 42 is cancelled", <-rec.Events)
+
+	// job array is cancelled while any of its tasks is running
+	sj.Status.Conditions = nil
+	wlm.tasks = []*api.JobInfo{
+		{Id: "42", ArrayId: "42", ArrayTaskId: "0", Status: api.JobStatus_COMPLETED},
+		{Id: "43", ArrayId: "42", ArrayTaskId: "1", Status: api.JobStatus_UNKNOWN, State: "RUNNING"},
+	}
+	done, err = CancelJob(context.Background(), wlm, rec, sj, &sj.Status.JobStatus, time.Minute)
+	require.NoError(t, err)
+	require.False(t, done)
+	require.Equal(t, []int64{42, 42}, wlm.cancelled)
+	require.Equal(t, "Normal Cancelling Cancelling job 42", <-rec.Events)
+	wlm.tasks = nil
+
+	// job is purged by workload manager
+	wlm.info = nil
+	done, err = CancelJob(context.Background(), wlm, rec, sj, &sj.Status.JobStatus, time.Minute)
+	require.NoError(t, err)
+	require.True(t, done)
+
+	// job is stuck
+	wlm.info = &api.JobInfo{Id: "42", State: "COMPLETING"}
+	deleted = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	done, err = CancelJob(context.Background(), wlm, rec, sj, &sj.Status.JobStatus, time.Minute)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Len(t, wlm.cancelled, 2)
+	require.Equal(t, "Warning CancelTimeout Job 42 was not cancelled in 1m0s", <-rec.Events)
+	require.Empty(t, rec.Events)
+}
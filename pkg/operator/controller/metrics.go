@@ -0,0 +1,185 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "wlm_operator"
+
+// Kinds of jobs metrics are labeled with.
+const (
+	KindSlurmJob = "SlurmJob"
+	KindWlmJob   = "WlmJob"
+)
+
+var (
+	jobsFinished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "jobs_finished_total",
+		Help:      "Number of finished jobs by terminal state.",
+	}, []string{"kind", "namespace", "partition", "state"})
+
+	jobQueueWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "job_queue_wait_seconds",
+		Help:      "Time jobs spent in workload manager queue between submission and start.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"kind", "namespace", "partition"})
+
+	jobRunTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "job_run_seconds",
+		Help:      "Time finished jobs were running by terminal state.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"kind", "namespace", "partition", "state"})
+
+	resultsCollection = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "results_collection_seconds",
+		Help:      "Time job results collection took.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"kind", "namespace", "partition"})
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of failed job reconciliations.",
+	}, []string{"kind", "namespace"})
+
+	activeJobsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "jobs_active"),
+		"Number of jobs currently pending or running.",
+		[]string{"kind", "namespace", "partition", "state"}, nil,
+	)
+)
+
+// RegisterMetrics registers job metrics in controller-runtime metrics registry, so
+// that they are served by the manager. Client is used to count active jobs on scrape.
+func RegisterMetrics(c client.Client) error {
+	for _, c := range []prometheus.Collector{
+		jobsFinished,
+		jobQueueWait,
+		jobRunTime,
+		resultsCollection,
+		reconcileErrors,
+		&activeJobsCollector{client: c},
+	} {
+		if err := metrics.Registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObserveReconcileError counts failed reconciliation of a job.
+func ObserveReconcileError(kind, namespace string) {
+	reconcileErrors.WithLabelValues(kind, namespace).Inc()
+}
+
+// ObserveJobStatus updates job metrics according to transitions between
+// the old and the current job status.
+func ObserveJobStatus(kind, namespace string, old, cur *v1alpha1.JobStatus, now time.Time) {
+	if old.StartTime == nil && cur.StartTime != nil && cur.SubmitTime != nil {
+		jobQueueWait.WithLabelValues(kind, namespace, cur.Partition).
+			Observe(cur.StartTime.Sub(cur.SubmitTime.Time).Seconds())
+	}
+
+	if finishedCondition(old) == nil {
+		if c := finishedCondition(cur); c != nil {
+			jobsFinished.WithLabelValues(kind, namespace, cur.Partition, cur.State).Inc()
+			if cur.StartTime != nil && cur.EndTime != nil {
+				jobRunTime.WithLabelValues(kind, namespace, cur.Partition, cur.State).
+					Observe(cur.EndTime.Sub(cur.StartTime.Time).Seconds())
+			}
+		}
+	}
+
+	c := changedCondition(old, cur, v1alpha1.JobResultsCollected)
+	o := JobCondition(old, v1alpha1.JobResultsCollected)
+	if c != nil && c.Status == corev1.ConditionTrue && o != nil && o.Reason == reasonCollecting {
+		resultsCollection.WithLabelValues(kind, namespace, cur.Partition).
+			Observe(now.Sub(o.LastTransitionTime.Time).Seconds())
+	}
+}
+
+// finishedCondition returns Succeeded or Failed condition if the job is finished.
+func finishedCondition(st *v1alpha1.JobStatus) *v1alpha1.JobCondition {
+	for _, t := range []v1alpha1.JobConditionType{v1alpha1.JobSucceeded, v1alpha1.JobFailed} {
+		if c := JobCondition(st, t); c != nil && c.Status == corev1.ConditionTrue {
+			return c
+		}
+	}
+	return nil
+}
+
+// activeJobsCollector counts pending and running jobs on scrape.
+type activeJobsCollector struct {
+	client client.Client
+}
+
+type activeJobsKey struct {
+	kind, namespace, partition, state string
+}
+
+// Describe implements prometheus.Collector.
+func (c *activeJobsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeJobsDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *activeJobsCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := make(map[activeJobsKey]int)
+	count := func(kind, namespace string, st *v1alpha1.JobStatus) {
+		if finishedCondition(st) != nil {
+			return
+		}
+		state := "pending"
+		if r := JobCondition(st, v1alpha1.JobRunning); r != nil && r.Status == corev1.ConditionTrue {
+			state = "running"
+		}
+		counts[activeJobsKey{kind, namespace, st.Partition, state}]++
+	}
+
+	var sjs v1alpha1.SlurmJobList
+	if err := c.client.List(context.Background(), &client.ListOptions{}, &sjs); err != nil {
+		glog.Errorf("Could not list slurm jobs: %v", err)
+	}
+	for i := range sjs.Items {
+		count(KindSlurmJob, sjs.Items[i].Namespace, &sjs.Items[i].Status.JobStatus)
+	}
+
+	var wjs v1alpha1.WlmJobList
+	if err := c.client.List(context.Background(), &client.ListOptions{}, &wjs); err != nil {
+		glog.Errorf("Could not list wlm jobs: %v", err)
+	}
+	for i := range wjs.Items {
+		count(KindWlmJob, wjs.Items[i].Namespace, &wjs.Items[i].Status.JobStatus)
+	}
+
+	for k, n := range counts {
+		ch <- prometheus.MustNewConstMetric(activeJobsDesc, prometheus.GaugeValue, float64(n),
+			k.kind, k.namespace, k.partition, k.state)
+	}
+}
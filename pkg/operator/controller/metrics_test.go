@@ -0,0 +1,113 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func metricValue(t *testing.T, m prometheus.Metric) *dto.Metric {
+	var out dto.Metric
+	require.NoError(t, m.Write(&out))
+	return &out
+}
+
+func TestObserveJobStatus(t *testing.T) {
+	submitted := metav1.NewTime(time.Now().Add(-time.Hour))
+	started := metav1.NewTime(submitted.Add(10 * time.Minute))
+	ended := metav1.NewTime(started.Add(20 * time.Minute))
+
+	old := v1alpha1.JobStatus{Partition: "debug", State: "PENDING", SubmitTime: &submitted}
+	cur := *old.DeepCopy()
+	cur.State = "RUNNING"
+	cur.StartTime = &started
+	ObserveJobStatus(KindSlurmJob, "test-metrics", &old, &cur, time.Now())
+
+	wait := metricValue(t, jobQueueWait.WithLabelValues(KindSlurmJob, "test-metrics", "debug").(prometheus.Histogram))
+	require.EqualValues(t, 1, wait.GetHistogram().GetSampleCount())
+	require.EqualValues(t, 600, wait.GetHistogram().GetSampleSum())
+
+	old = *cur.DeepCopy()
+	cur.State = "COMPLETED"
+	cur.EndTime = &ended
+	SetJobCondition(&cur, v1alpha1.JobSucceeded, corev1.ConditionTrue, "", "", ended)
+	collecting := metav1.NewTime(time.Now().Add(-time.Minute))
+	SetJobCondition(&cur, v1alpha1.JobResultsCollected, corev1.ConditionFalse, reasonCollecting, "", collecting)
+	ObserveJobStatus(KindSlurmJob, "test-metrics", &old, &cur, time.Now())
+
+	// finished job is counted once
+	old = *cur.DeepCopy()
+	SetJobCondition(&cur, v1alpha1.JobResultsCollected, corev1.ConditionTrue, "", "", metav1.Now())
+	ObserveJobStatus(KindSlurmJob, "test-metrics", &old, &cur, collecting.Add(30*time.Second))
+
+	finished := metricValue(t, jobsFinished.WithLabelValues(KindSlurmJob, "test-metrics", "debug", "COMPLETED"))
+	require.EqualValues(t, 1, finished.GetCounter().GetValue())
+	run := metricValue(t, jobRunTime.WithLabelValues(KindSlurmJob, "test-metrics", "debug", "COMPLETED").(prometheus.Histogram))
+	require.EqualValues(t, 1200, run.GetHistogram().GetSampleSum())
+	collection := metricValue(t, resultsCollection.WithLabelValues(KindSlurmJob, "test-metrics", "debug").(prometheus.Histogram))
+	require.EqualValues(t, 1, collection.GetHistogram().GetSampleCount())
+	require.EqualValues(t, 30, collection.GetHistogram().GetSampleSum())
+}
+
+type fakeLister struct {
+	client.Client
+	sjs []v1alpha1.SlurmJob
+}
+
+func (f *fakeLister) List(_ context.Context, _ *client.ListOptions, list runtime.Object) error {
+	if l, ok := list.(*v1alpha1.SlurmJobList); ok {
+		l.Items = f.sjs
+	}
+	return nil
+}
+
+func TestActiveJobsCollector(t *testing.T) {
+	running := v1alpha1.SlurmJob{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}}
+	running.Status.Partition = "debug"
+	SetJobCondition(&running.Status.JobStatus, v1alpha1.JobRunning, corev1.ConditionTrue, "", "", metav1.Now())
+	pending := v1alpha1.SlurmJob{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}}
+	pending.Status.Partition = "debug"
+	finished := *running.DeepCopy()
+	SetJobCondition(&finished.Status.JobStatus, v1alpha1.JobSucceeded, corev1.ConditionTrue, "", "", metav1.Now())
+
+	c := &activeJobsCollector{client: &fakeLister{sjs: []v1alpha1.SlurmJob{running, pending, pending, finished}}}
+	ch := make(chan prometheus.Metric, 10)
+	c.Collect(ch)
+	close(ch)
+
+	counts := make(map[string]float64)
+	for m := range ch {
+		v := metricValue(t, m)
+		labels := make(map[string]string)
+		for _, l := range v.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		require.Equal(t, KindSlurmJob, labels["kind"])
+		require.Equal(t, "debug", labels["partition"])
+		counts[labels["state"]] = v.GetGauge().GetValue()
+	}
+	require.Equal(t, map[string]float64{"running": 1, "pending": 2}, counts)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/dptech-corp/wlm-operator/pkg/slurm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PartitionLabel is a virtual node label that holds name of the partition the node represents,
+// see slurm.PartitionLabelValue for the form of its value.
+const PartitionLabel = "wlm.sylabs.io/partition"
+
+// RetryingStatus is set to job status while pod of the failed job is replaced.
+const RetryingStatus = "Retrying"
+
+// DefaultRetryOn is a list of job states retried when retry policy doesn't specify them.
+var DefaultRetryOn = []string{"NODE_FAIL", "PREEMPTED", "BOOT_FAIL"}
+
+const (
+	defaultBackoff = 10 * time.Second
+	maxBackoff     = time.Hour
+)
+
+// NextRetry checks whether the failed job should be retried according to the policy.
+// It returns true with time left to wait before the retry if the job should be retried.
+// Failed tasks of a job array are retried individually, see RetryTasks.
+func NextRetry(p *v1alpha1.RetryPolicy, st *v1alpha1.JobStatus, now time.Time) (bool, time.Duration) {
+	if p == nil {
+		return false, 0
+	}
+	if st.Array != nil {
+		return nextTaskRetry(p, st.Array, now)
+	}
+	if int32(len(st.Attempts)) >= p.BackoffLimit {
+		return false, 0
+	}
+	failed := JobCondition(st, v1alpha1.JobFailed)
+	if failed == nil || failed.Status != corev1.ConditionTrue {
+		return false, 0
+	}
+
+	if !retryOn(p, st.State) {
+		return false, 0
+	}
+
+	wait := failed.LastTransitionTime.Add(retryBackoff(p, len(st.Attempts))).Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	return true, wait
+}
+
+// retryOn checks whether jobs in the given state are retried by the policy.
+func retryOn(p *v1alpha1.RetryPolicy, state string) bool {
+	states := p.RetryOn
+	if len(states) == 0 {
+		states = DefaultRetryOn
+	}
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// retryBackoff returns delay before the retry that follows the given number of retries.
+func retryBackoff(p *v1alpha1.RetryPolicy, retries int) time.Duration {
+	backoff := defaultBackoff
+	if p.BackoffSeconds > 0 {
+		backoff = time.Duration(p.BackoffSeconds) * time.Second
+	}
+	for i := 0; i < retries && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// RecordAttempt moves details of the finished job attempt to the attempts
+// and resets the status for the next attempt.
+func RecordAttempt(st *v1alpha1.JobStatus) {
+	attempts := append(st.Attempts, v1alpha1.JobAttempt{
+		JobID:     st.JobID,
+		Cluster:   st.Cluster,
+		Partition: st.Partition,
+		State:     st.State,
+		Reason:    st.Reason,
+		StartTime: st.StartTime,
+		EndTime:   st.EndTime,
+		ExitCode:  st.ExitCode,
+		NodeList:  st.NodeList,
+	})
+	*st = v1alpha1.JobStatus{Attempts: attempts}
+}
+
+// RetryNodeSelector returns node selector for the job attempt. Base is
+// the node selector of the first attempt.
+func RetryNodeSelector(p *v1alpha1.RetryPolicy, attempt int, base map[string]string) map[string]string {
+	if p == nil || attempt == 0 || len(p.Alternatives) == 0 {
+		return base
+	}
+	if attempt > len(p.Alternatives) {
+		attempt = len(p.Alternatives)
+	}
+	target := p.Alternatives[attempt-1]
+
+	nodeSelector := make(map[string]string)
+	for k, v := range DefaultNodeSelectors {
+		nodeSelector[k] = v
+	}
+	if target.NodeSelector != nil {
+		for k, v := range target.NodeSelector {
+			nodeSelector[k] = v
+		}
+	} else {
+		for k, v := range base {
+			nodeSelector[k] = v
+		}
+	}
+	if target.Partition != "" {
+		nodeSelector[PartitionLabel] = slurm.PartitionLabelValue(target.Partition)
+	}
+	return nodeSelector
+}
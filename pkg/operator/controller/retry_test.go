@@ -0,0 +1,153 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNextRetry(t *testing.T) {
+	failedAt := time.Now().Truncate(time.Second)
+	failed := func(state string, attempts int) *v1alpha1.JobStatus {
+		st := &v1alpha1.JobStatus{State: state, Attempts: make([]v1alpha1.JobAttempt, attempts)}
+		SetJobCondition(st, v1alpha1.JobFailed, corev1.ConditionTrue, state, "", metav1.NewTime(failedAt))
+		return st
+	}
+
+	tt := []struct {
+		name   string
+		policy *v1alpha1.RetryPolicy
+		status *v1alpha1.JobStatus
+		now    time.Time
+		retry  bool
+		wait   time.Duration
+	}{
+		{
+			name:   "no policy",
+			status: failed("NODE_FAIL", 0),
+			now:    failedAt,
+		},
+		{
+			name:   "not failed",
+			policy: &v1alpha1.RetryPolicy{BackoffLimit: 1},
+			status: &v1alpha1.JobStatus{State: "RUNNING"},
+			now:    failedAt,
+		},
+		{
+			name:   "default states",
+			policy: &v1alpha1.RetryPolicy{BackoffLimit: 1},
+			status: failed("NODE_FAIL", 0),
+			now:    failedAt.Add(5 * time.Second),
+			retry:  true,
+			wait:   5 * time.Second,
+		},
+		{
+			name:   "state is not retried",
+			policy: &v1alpha1.RetryPolicy{BackoffLimit: 1},
+			status: failed("FAILED", 0),
+			now:    failedAt,
+		},
+		{
+			name:   "custom states",
+			policy: &v1alpha1.RetryPolicy{BackoffLimit: 1, RetryOn: []string{"FAILED"}},
+			status: failed("FAILED", 0),
+			now:    failedAt.Add(time.Minute),
+			retry:  true,
+		},
+		{
+			name:   "exponential backoff",
+			policy: &v1alpha1.RetryPolicy{BackoffLimit: 5, BackoffSeconds: 60},
+			status: failed("PREEMPTED", 2),
+			now:    failedAt,
+			retry:  true,
+			wait:   4 * time.Minute,
+		},
+		{
+			name:   "max backoff",
+			policy: &v1alpha1.RetryPolicy{BackoffLimit: 50, BackoffSeconds: 60},
+			status: failed("PREEMPTED", 20),
+			now:    failedAt,
+			retry:  true,
+			wait:   time.Hour,
+		},
+		{
+			name:   "backoff limit",
+			policy: &v1alpha1.RetryPolicy{BackoffLimit: 2},
+			status: failed("BOOT_FAIL", 2),
+			now:    failedAt.Add(time.Hour),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			retry, wait := NextRetry(tc.policy, tc.status, tc.now)
+			require.Equal(t, tc.retry, retry)
+			require.Equal(t, tc.wait, wait)
+		})
+	}
+}
+
+func TestRecordAttempt(t *testing.T) {
+	now := metav1.Now()
+	st := &v1alpha1.JobStatus{
+		JobID:     "42",
+		Partition: "debug",
+		State:     "NODE_FAIL",
+		EndTime:   &now,
+		ExitCode:  "0:0",
+		NodeList:  "node1",
+		Attempts:  []v1alpha1.JobAttempt{{JobID: "41"}},
+	}
+	SetJobCondition(st, v1alpha1.JobFailed, corev1.ConditionTrue, "", "", now)
+
+	RecordAttempt(st)
+	require.Equal(t, v1alpha1.JobStatus{
+		Attempts: []v1alpha1.JobAttempt{
+			{JobID: "41"},
+			{JobID: "42", Partition: "debug", State: "NODE_FAIL", EndTime: &now, ExitCode: "0:0", NodeList: "node1"},
+		},
+	}, *st)
+}
+
+func TestRetryNodeSelector(t *testing.T) {
+	base := map[string]string{"type": "virtual-kubelet", "foo": "bar"}
+	policy := &v1alpha1.RetryPolicy{
+		BackoffLimit: 3,
+		Alternatives: []v1alpha1.RetryTarget{
+			{Partition: "backup"},
+			{NodeSelector: map[string]string{"zone": "west"}},
+		},
+	}
+
+	require.Equal(t, base, RetryNodeSelector(nil, 1, base))
+	require.Equal(t, base, RetryNodeSelector(policy, 0, base))
+	require.Equal(t, map[string]string{
+		"type": "virtual-kubelet", "foo": "bar", PartitionLabel: "backup",
+	}, RetryNodeSelector(policy, 1, base))
+	require.Equal(t, map[string]string{
+		"type": "virtual-kubelet", "zone": "west",
+	}, RetryNodeSelector(policy, 2, base))
+	require.Equal(t, RetryNodeSelector(policy, 2, base), RetryNodeSelector(policy, 3, base))
+
+	// partitions of multiple clusters are selected by their label form
+	policy.Alternatives = []v1alpha1.RetryTarget{{Partition: "east/gpu"}}
+	require.Equal(t, "east.gpu", RetryNodeSelector(policy, 1, base)[PartitionLabel])
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurmcronjob
+
+import (
+	"sort"
+	"time"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+)
+
+// maxMissedRuns limits number of missed runs that are looked through,
+// it protects from a schedule that hasn't run for a long time.
+const maxMissedRuns = 100
+
+const (
+	defaultSuccessfulJobsHistoryLimit = 3
+	defaultFailedJobsHistoryLimit     = 1
+)
+
+// lastMissedRun returns the most recent scheduled time that is after earliest
+// and not after now. Zero time is returned if there is no such time.
+func lastMissedRun(sched cron.Schedule, earliest, now time.Time) (time.Time, error) {
+	var last time.Time
+	var missed int
+	for t := sched.Next(earliest); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+		last = t
+		missed++
+		if missed > maxMissedRuns {
+			return time.Time{}, errors.Errorf("too many missed start times (> %d), "+
+				"set or decrease startingDeadlineSeconds or check clock skew", maxMissedRuns)
+		}
+	}
+	return last, nil
+}
+
+// earliestRun returns time after which scheduled runs of the cron job are still to be started.
+func earliestRun(cj *wlmv1alpha1.SlurmCronJob, now time.Time) time.Time {
+	earliest := cj.CreationTimestamp.Time
+	if cj.Status.LastScheduleTime != nil {
+		earliest = cj.Status.LastScheduleTime.Time
+	}
+	if d := cj.Spec.StartingDeadlineSeconds; d != nil {
+		deadline := now.Add(-time.Duration(*d) * time.Second)
+		if deadline.After(earliest) {
+			earliest = deadline
+		}
+	}
+	return earliest
+}
+
+// historyLimit returns the limit or the default if limit is not set.
+func historyLimit(limit *int32, def int) int {
+	if limit == nil {
+		return def
+	}
+	return int(*limit)
+}
+
+// oldJobs returns finished jobs that exceed the history limit, the oldest first.
+func oldJobs(jobs []*wlmv1alpha1.SlurmJob, limit int) []*wlmv1alpha1.SlurmJob {
+	if len(jobs) <= limit {
+		return nil
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return scheduledAt(jobs[i]).Before(scheduledAt(jobs[j]))
+	})
+	return jobs[:len(jobs)-limit]
+}
+
+// scheduledAt returns time the job was scheduled at, creation
+// time is used for jobs that are missing the annotation.
+func scheduledAt(sj *wlmv1alpha1.SlurmJob) time.Time {
+	t, err := time.Parse(time.RFC3339, sj.Annotations[ScheduledAtAnnotation])
+	if err != nil {
+		return sj.CreationTimestamp.Time
+	}
+	return t
+}
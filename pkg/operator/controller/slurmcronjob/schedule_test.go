@@ -0,0 +1,90 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurmcronjob
+
+import (
+	"testing"
+	"time"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/robfig/cron"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLastMissedRun(t *testing.T) {
+	nightly, err := cron.ParseStandard("0 2 * * *")
+	require.NoError(t, err)
+	day := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	missed, err := lastMissedRun(nightly, day, day.Add(time.Hour))
+	require.NoError(t, err)
+	require.True(t, missed.IsZero())
+
+	missed, err = lastMissedRun(nightly, day, day.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, day.Add(2*time.Hour), missed)
+
+	// the most recent of several missed runs is returned
+	missed, err = lastMissedRun(nightly, day, day.Add(49*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, day.Add(26*time.Hour), missed)
+
+	_, err = lastMissedRun(nightly, day, day.Add(200*24*time.Hour))
+	require.Error(t, err)
+}
+
+func TestEarliestRun(t *testing.T) {
+	created := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	now := created.Add(24 * time.Hour)
+	cj := &wlmv1alpha1.SlurmCronJob{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+	}
+	require.Equal(t, created, earliestRun(cj, now))
+
+	last := metav1.NewTime(created.Add(time.Hour))
+	cj.Status.LastScheduleTime = &last
+	require.Equal(t, last.Time, earliestRun(cj, now))
+
+	deadline := int64(600)
+	cj.Spec.StartingDeadlineSeconds = &deadline
+	require.Equal(t, now.Add(-10*time.Minute), earliestRun(cj, now))
+}
+
+func TestOldJobs(t *testing.T) {
+	newJob := func(name string, scheduled time.Time) *wlmv1alpha1.SlurmJob {
+		return &wlmv1alpha1.SlurmJob{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{ScheduledAtAnnotation: scheduled.Format(time.RFC3339)},
+		}}
+	}
+	day := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	jobs := []*wlmv1alpha1.SlurmJob{
+		newJob("second", day.Add(24*time.Hour)),
+		newJob("third", day.Add(48*time.Hour)),
+		newJob("first", day),
+	}
+
+	require.Empty(t, oldJobs(jobs, 3))
+	old := oldJobs(jobs, 1)
+	require.Len(t, old, 2)
+	require.Equal(t, "first", old[0].Name)
+	require.Equal(t, "second", old[1].Name)
+	require.Len(t, oldJobs(jobs, 0), 3)
+
+	limit := int32(5)
+	require.Equal(t, 5, historyLimit(&limit, defaultSuccessfulJobsHistoryLimit))
+	require.Equal(t, defaultFailedJobsHistoryLimit, historyLimit(nil, defaultFailedJobsHistoryLimit))
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurmcronjob
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	wlmcontroller "github.com/dptech-corp/wlm-operator/pkg/operator/controller"
+	"github.com/golang/glog"
+	"github.com/robfig/cron"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// ScheduledAtAnnotation is set on SlurmJobs created by a SlurmCronJob
+// and holds time the job was scheduled at.
+const ScheduledAtAnnotation = "wlm.sylabs.io/scheduled-at"
+
+// Reasons of events recorded for cron jobs.
+const (
+	EventSuccessfulCreate = "SuccessfulCreate"
+	EventFailedCreate     = "FailedCreate"
+	EventSuccessfulDelete = "SuccessfulDelete"
+	EventJobAlreadyActive = "JobAlreadyActive"
+	EventMissSchedule     = "MissSchedule"
+	EventInvalidSchedule  = "InvalidSchedule"
+)
+
+// Reconciler creates SlurmJobs of a SlurmCronJob on schedule.
+type Reconciler struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+// NewReconciler returns a new SlurmCronJob controller.
+func NewReconciler(mgr manager.Manager) *Reconciler {
+	return &Reconciler{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetRecorder("slurmcronjob-controller"),
+	}
+}
+
+// AddToManager adds SlurmCronJob Reconciler to the given Manager.
+func (r *Reconciler) AddToManager(mgr manager.Manager) error {
+	c, err := controller.New("slurmcronjob-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &wlmv1alpha1.SlurmCronJob{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// SlurmCronJob is reconciled whenever one of its jobs changes, so that
+	// history is cleaned up and a forbidden run starts once the previous one finishes
+	return c.Watch(&source.Kind{Type: &wlmv1alpha1.SlurmJob{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &wlmv1alpha1.SlurmCronJob{},
+	})
+}
+
+// Reconcile creates a SlurmJob if the SlurmCronJob missed its scheduled run,
+// deletes finished SlurmJobs beyond history limits and requeues the SlurmCronJob
+// to be reconciled at its next scheduled time.
+func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	glog.Infof("Received reconcile request: %v", req)
+
+	cj := &wlmv1alpha1.SlurmCronJob{}
+	err := r.client.Get(context.Background(), req.NamespacedName, cj)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		glog.Errorf("Could not get slurm cron job: %v", err)
+		return reconcile.Result{}, err
+	}
+	if cj.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	now := time.Now()
+	active, succeeded, failed, err := r.jobs(cj, now)
+	if err != nil {
+		glog.Errorf("Could not list slurm jobs: %v", err)
+		return reconcile.Result{}, err
+	}
+	r.deleteJobs(cj, oldJobs(succeeded, historyLimit(cj.Spec.SuccessfulJobsHistoryLimit, defaultSuccessfulJobsHistoryLimit)))
+	r.deleteJobs(cj, oldJobs(failed, historyLimit(cj.Spec.FailedJobsHistoryLimit, defaultFailedJobsHistoryLimit)))
+
+	sched, err := cron.ParseStandard(cj.Spec.Schedule)
+	if err != nil {
+		glog.Errorf("Invalid schedule %q of slurm cron job %q: %v", cj.Spec.Schedule, cj.Name, err)
+		r.recorder.Eventf(cj, corev1.EventTypeWarning, EventInvalidSchedule, "Invalid schedule %q: %v", cj.Spec.Schedule, err)
+		return reconcile.Result{}, r.updateStatus(cj, active)
+	}
+	res := reconcile.Result{RequeueAfter: sched.Next(now).Sub(now)}
+
+	missed, err := lastMissedRun(sched, earliestRun(cj, now), now)
+	if err != nil {
+		r.recorder.Event(cj, corev1.EventTypeWarning, EventMissSchedule, err.Error())
+		return res, r.updateStatus(cj, active)
+	}
+	if missed.IsZero() {
+		return res, r.updateStatus(cj, active)
+	}
+
+	switch cj.Spec.ConcurrencyPolicy {
+	case wlmv1alpha1.ForbidConcurrent:
+		if len(active) != 0 {
+			glog.Infof("Not starting slurm cron job %q run, previous one is still active", cj.Name)
+			r.recorder.Eventf(cj, corev1.EventTypeNormal, EventJobAlreadyActive,
+				"Not starting job scheduled at %s, previous one is still active", missed.Format(time.RFC3339))
+			return res, r.updateStatus(cj, active)
+		}
+	case wlmv1alpha1.ReplaceConcurrent:
+		r.deleteJobs(cj, active)
+		active = nil
+	}
+
+	sj, err := r.newJob(cj, missed)
+	if err != nil {
+		glog.Errorf("Could not create slurm job: %v", err)
+		return reconcile.Result{}, err
+	}
+	glog.Infof("Creating slurm job %q for slurm cron job %q", sj.Name, cj.Name)
+	err = r.client.Create(context.Background(), sj)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		glog.Errorf("Could not create slurm job: %v", err)
+		r.recorder.Eventf(cj, corev1.EventTypeWarning, EventFailedCreate, "Could not create job %s: %v", sj.Name, err)
+		return reconcile.Result{}, err
+	}
+	if err == nil {
+		r.recorder.Eventf(cj, corev1.EventTypeNormal, EventSuccessfulCreate, "Created job %s", sj.Name)
+		active = append(active, sj)
+	}
+
+	scheduled := metav1.NewTime(missed)
+	cj.Status.LastScheduleTime = &scheduled
+	return res, r.updateStatus(cj, active)
+}
+
+// jobs returns SlurmJobs of the SlurmCronJob split into active,
+// succeeded and failed ones.
+func (r *Reconciler) jobs(cj *wlmv1alpha1.SlurmCronJob, now time.Time) (active, succeeded, failed []*wlmv1alpha1.SlurmJob, err error) {
+	var jobs wlmv1alpha1.SlurmJobList
+	err = r.client.List(context.Background(), client.InNamespace(cj.Namespace), &jobs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for i := range jobs.Items {
+		sj := &jobs.Items[i]
+		owner := metav1.GetControllerOf(sj)
+		if owner == nil || owner.UID != cj.UID || sj.DeletionTimestamp != nil {
+			continue
+		}
+
+		_, finished := wlmcontroller.FinishedAt(&sj.Status.JobStatus, sj.Spec.RetryPolicy, sj.Spec.Results != nil, now)
+		c := wlmcontroller.JobCondition(&sj.Status.JobStatus, wlmv1alpha1.JobSucceeded)
+		switch {
+		case !finished:
+			active = append(active, sj)
+		case c != nil && c.Status == corev1.ConditionTrue:
+			succeeded = append(succeeded, sj)
+		default:
+			failed = append(failed, sj)
+		}
+	}
+	return active, succeeded, failed, nil
+}
+
+// newJob returns a SlurmJob for the run scheduled at the given time. Job name
+// is derived from the scheduled time, so that a run is never started twice.
+func (r *Reconciler) newJob(cj *wlmv1alpha1.SlurmCronJob, scheduled time.Time) (*wlmv1alpha1.SlurmJob, error) {
+	sj := &wlmv1alpha1.SlurmJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%d", cj.Name, scheduled.Unix()/60),
+			Namespace:   cj.Namespace,
+			Labels:      cj.Labels,
+			Annotations: map[string]string{ScheduledAtAnnotation: scheduled.Format(time.RFC3339)},
+		},
+		Spec: *cj.Spec.JobTemplate.DeepCopy(),
+	}
+	err := controllerutil.SetControllerReference(cj, sj, r.scheme)
+	if err != nil {
+		return nil, err
+	}
+	return sj, nil
+}
+
+// deleteJobs deletes the given jobs of the SlurmCronJob along with their pods.
+func (r *Reconciler) deleteJobs(cj *wlmv1alpha1.SlurmCronJob, jobs []*wlmv1alpha1.SlurmJob) {
+	for _, sj := range jobs {
+		err := r.client.Delete(context.Background(), sj)
+		if err != nil && !errors.IsNotFound(err) {
+			glog.Errorf("Could not delete slurm job %q: %v", sj.Name, err)
+			continue
+		}
+		r.recorder.Eventf(cj, corev1.EventTypeNormal, EventSuccessfulDelete, "Deleted job %s", sj.Name)
+	}
+}
+
+// updateStatus updates the list of active jobs in SlurmCronJob status.
+func (r *Reconciler) updateStatus(cj *wlmv1alpha1.SlurmCronJob, active []*wlmv1alpha1.SlurmJob) error {
+	cj.Status.Active = nil
+	for _, sj := range active {
+		cj.Status.Active = append(cj.Status.Active, corev1.ObjectReference{
+			APIVersion: wlmv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "SlurmJob",
+			Namespace:  sj.Namespace,
+			Name:       sj.Name,
+			UID:        sj.UID,
+		})
+	}
+	err := r.client.Status().Update(context.Background(), cj)
+	if err != nil {
+		glog.Errorf("Could not update slurm cron job: %v", err)
+	}
+	return err
+}
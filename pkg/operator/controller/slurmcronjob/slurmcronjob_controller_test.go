@@ -0,0 +1,195 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurmcronjob
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var testScheme = runtime.NewScheme()
+
+func TestMain(m *testing.M) {
+	if err := wlmv1alpha1.AddToScheme(testScheme); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func newCronJob(created time.Time) *wlmv1alpha1.SlurmCronJob {
+	return &wlmv1alpha1.SlurmCronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "cron",
+			Namespace:         "default",
+			UID:               "cron-uid",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: wlmv1alpha1.SlurmCronJobSpec{
+			Schedule:    "* * * * *",
+			JobTemplate: wlmv1alpha1.SlurmJobSpec{Batch: "#!/bin/sh\nhostname"},
+		},
+	}
+}
+
+// newOwnedJob returns a SlurmJob of the cron job scheduled at the given time,
+// job is finished with the given condition unless it is empty.
+func newOwnedJob(cj *wlmv1alpha1.SlurmCronJob, scheduled time.Time, finished wlmv1alpha1.JobConditionType) *wlmv1alpha1.SlurmJob {
+	ref := metav1.NewControllerRef(cj, wlmv1alpha1.SchemeGroupVersion.WithKind("SlurmCronJob"))
+	sj := &wlmv1alpha1.SlurmJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%d", cj.Name, scheduled.Unix()/60),
+			Namespace:       cj.Namespace,
+			UID:             types.UID(fmt.Sprintf("job-%d", scheduled.Unix())),
+			Annotations:     map[string]string{ScheduledAtAnnotation: scheduled.Format(time.RFC3339)},
+			OwnerReferences: []metav1.OwnerReference{*ref},
+		},
+	}
+	if finished != "" {
+		sj.Status.Conditions = []wlmv1alpha1.JobCondition{{
+			Type:               finished,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(scheduled.Add(time.Second)),
+		}}
+	}
+	return sj
+}
+
+func jobNames(t *testing.T, c client.Client) []string {
+	var jobs wlmv1alpha1.SlurmJobList
+	require.NoError(t, c.List(context.Background(), client.InNamespace("default"), &jobs))
+	var names []string
+	for _, sj := range jobs.Items {
+		names = append(names, sj.Name)
+	}
+	return names
+}
+
+func TestReconciler_Reconcile_concurrency(t *testing.T) {
+	// expected run is the current minute, so the test shouldn't cross it
+	if time.Now().Second() == 59 {
+		time.Sleep(time.Second)
+	}
+	now := time.Now()
+	created := now.Add(-10 * time.Minute).Truncate(time.Minute)
+	missed := now.Truncate(time.Minute)
+	key := types.NamespacedName{Namespace: "default", Name: "cron"}
+
+	tt := []struct {
+		name         string
+		policy       wlmv1alpha1.ConcurrencyPolicy
+		expectJobs   []string
+		expectEvents []string
+		expectActive string
+	}{
+		{
+			name:         "forbid",
+			policy:       wlmv1alpha1.ForbidConcurrent,
+			expectJobs:   []string{fmt.Sprintf("cron-%d", created.Unix()/60)},
+			expectActive: fmt.Sprintf("cron-%d", created.Unix()/60),
+			expectEvents: []string{
+				fmt.Sprintf("Normal JobAlreadyActive Not starting job scheduled at %s, previous one is still active",
+					missed.Format(time.RFC3339)),
+			},
+		},
+		{
+			name:         "replace",
+			policy:       wlmv1alpha1.ReplaceConcurrent,
+			expectJobs:   []string{fmt.Sprintf("cron-%d", missed.Unix()/60)},
+			expectActive: fmt.Sprintf("cron-%d", missed.Unix()/60),
+			expectEvents: []string{
+				fmt.Sprintf("Normal SuccessfulDelete Deleted job cron-%d", created.Unix()/60),
+				fmt.Sprintf("Normal SuccessfulCreate Created job cron-%d", missed.Unix()/60),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cj := newCronJob(created)
+			cj.Spec.ConcurrencyPolicy = tc.policy
+			c := fake.NewFakeClientWithScheme(testScheme, cj, newOwnedJob(cj, created, ""))
+			rec := record.NewFakeRecorder(10)
+			r := &Reconciler{client: c, scheme: testScheme, recorder: rec}
+
+			res, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+			require.NoError(t, err)
+			require.True(t, res.RequeueAfter > 0 && res.RequeueAfter <= time.Minute, res.RequeueAfter)
+			require.Equal(t, tc.expectJobs, jobNames(t, c))
+			for _, e := range tc.expectEvents {
+				require.Equal(t, e, <-rec.Events)
+			}
+			require.Empty(t, rec.Events)
+
+			actual := &wlmv1alpha1.SlurmCronJob{}
+			require.NoError(t, c.Get(context.Background(), key, actual))
+			require.Len(t, actual.Status.Active, 1)
+			require.Equal(t, tc.expectActive, actual.Status.Active[0].Name)
+		})
+	}
+}
+
+func TestReconciler_Reconcile_history(t *testing.T) {
+	now := time.Now()
+	created := now.Add(-time.Hour).Truncate(time.Minute)
+	cj := newCronJob(created)
+	last := metav1.NewTime(now)
+	cj.Status.LastScheduleTime = &last
+	two := int32(2)
+	cj.Spec.SuccessfulJobsHistoryLimit = &two
+
+	objs := []runtime.Object{cj}
+	var expect []string
+	for i := 0; i < 5; i++ {
+		sj := newOwnedJob(cj, created.Add(time.Duration(i)*time.Minute), wlmv1alpha1.JobSucceeded)
+		objs = append(objs, sj)
+		if i >= 3 {
+			expect = append(expect, sj.Name)
+		}
+	}
+	for i := 5; i < 7; i++ {
+		sj := newOwnedJob(cj, created.Add(time.Duration(i)*time.Minute), wlmv1alpha1.JobFailed)
+		objs = append(objs, sj)
+		if i >= 6 {
+			expect = append(expect, sj.Name)
+		}
+	}
+	// jobs of other owners are never deleted
+	other := newOwnedJob(cj, created, wlmv1alpha1.JobSucceeded)
+	other.Name = "other"
+	other.OwnerReferences = nil
+	objs = append(objs, other)
+	expect = append(expect, other.Name)
+
+	c := fake.NewFakeClientWithScheme(testScheme, objs...)
+	rec := record.NewFakeRecorder(10)
+	r := &Reconciler{client: c, scheme: testScheme, recorder: rec}
+	_, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "cron"}})
+	require.NoError(t, err)
+	require.ElementsMatch(t, expect, jobNames(t, c))
+	require.Len(t, rec.Events, 4)
+}
@@ -19,6 +19,7 @@ import (
 	"strconv"
 	"strings"
 
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
 	"github.com/dptech-corp/wlm-operator/pkg/operator/controller"
 
 	"github.com/pkg/errors"
@@ -27,7 +28,7 @@ import (
 
 // extractBatchResources extracts resources that should be satisfied for a slurm
 // job to run. More particularly, the following SBATCH directives are parsed:
-// nodes, time, mem, ntasks and/or (n)tasks-per-node.
+// nodes, time, mem, ntasks and/or (n)tasks-per-node, gres and gpus-per-node.
 // A zero value is returned if corresponding value is not provided.
 func extractBatchResources(script string) (*controller.Resources, error) {
 	const sbatchHeader = "#SBATCH"
@@ -70,6 +71,43 @@ func extractBatchResources(script string) (*controller.Resources, error) {
 	return &res, nil
 }
 
+// arrayBatch returns the batch script with --array directive built from the job
+// array spec inserted right after the shebang. The spec may not be combined with
+// another --array directive in the script, since the one given last would take
+// precedence. The script is returned as is when it already holds the directive.
+func arrayBatch(script string, array *wlmv1alpha1.JobArray) (string, error) {
+	if array == nil {
+		return script, nil
+	}
+	if _, _, err := slurm.ParseArrayIndices(array.Indices); err != nil {
+		return "", errors.Wrap(err, "invalid job array")
+	}
+	if array.Throttle < 0 {
+		return "", errors.Errorf("invalid job array throttle %d", array.Throttle)
+	}
+	directive := "#SBATCH --array=" + array.Indices
+	if array.Throttle != 0 {
+		directive += "%" + strconv.Itoa(int(array.Throttle))
+	}
+
+	found := false
+	for _, line := range strings.Split(script, "\n") {
+		params := strings.Fields(line)
+		if len(params) < 2 || params[0] != "#SBATCH" ||
+			!(strings.HasPrefix(params[1], "--array") || strings.HasPrefix(params[1], "-a")) {
+			continue
+		}
+		if strings.TrimSpace(line) != directive {
+			return "", errors.New("job array is set both in spec and with --array directive in batch script")
+		}
+		found = true
+	}
+	if found {
+		return script, nil
+	}
+	return controller.WithBatchDirective(script, directive), nil
+}
+
 func applySbatchParam(res controller.Resources, param, value string) (controller.Resources, error) {
 	const (
 		timeLimit        = "--time"
@@ -80,6 +118,8 @@ func applySbatchParam(res controller.Resources, param, value string) (controller
 		tasksPerNode     = "--ntasks-per-node"
 		cpusPerTask      = "--cpus-per-task"
 		cpusPerTaskShort = "-c"
+		gres             = "--gres"
+		gpusPerNode      = "--gpus-per-node"
 	)
 
 	switch param {
@@ -127,6 +167,44 @@ func applySbatchParam(res controller.Resources, param, value string) (controller
 			res.CPUPerNode = 1
 		}
 		res.CPUPerNode *= tasks
+	case gres:
+		for _, g := range strings.Split(value, ",") {
+			parts := strings.Split(g, ":")
+			if parts[0] != "gpu" {
+				continue
+			}
+			gpus, gpuType, err := parseGPUs(parts[1:])
+			if err != nil {
+				return controller.Resources{}, errors.Wrapf(err, "could not parse gres")
+			}
+			res.GPUs, res.GPUType = gpus, gpuType
+		}
+	case gpusPerNode:
+		gpus, gpuType, err := parseGPUs(strings.Split(value, ":"))
+		if err != nil {
+			return controller.Resources{}, errors.Wrapf(err, "could not parse gpus per node")
+		}
+		res.GPUs, res.GPUType = gpus, gpuType
 	}
 	return res, nil
 }
+
+// parseGPUs parses [type:]count GPU specification split by colon.
+// Count defaults to 1 when it is omitted.
+func parseGPUs(spec []string) (int64, string, error) {
+	switch len(spec) {
+	case 0:
+		return 1, "", nil
+	case 1:
+		gpus, err := strconv.ParseInt(spec[0], 10, 0)
+		if err != nil {
+			// gpu:type without count
+			return 1, spec[0], nil
+		}
+		return gpus, "", nil
+	case 2:
+		gpus, err := strconv.ParseInt(spec[1], 10, 0)
+		return gpus, spec[0], err
+	}
+	return 0, "", errors.Errorf("unexpected gpu specification %s", strings.Join(spec, ":"))
+}
@@ -18,6 +18,7 @@ import (
 	"testing"
 	"time"
 
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
 	"github.com/dptech-corp/wlm-operator/pkg/operator/controller"
 
 	"github.com/stretchr/testify/require"
@@ -222,6 +223,50 @@ srun rm lolcow_latest.sif
 				Nodes:      3,
 			},
 		},
+		{
+			name: "gres gpu",
+			script: `
+#!/bin/sh
+#SBATCH --gres=mps:100,gpu:v100:2 -N 2
+srun nvidia-smi
+`,
+			expectResources: &controller.Resources{
+				Nodes:   2,
+				GPUs:    2,
+				GPUType: "v100",
+			},
+		},
+		{
+			name: "gres gpu without count",
+			script: `
+#!/bin/sh
+#SBATCH --gres gpu
+srun nvidia-smi
+`,
+			expectResources: &controller.Resources{
+				GPUs: 1,
+			},
+		},
+		{
+			name: "gpus per node",
+			script: `
+#!/bin/sh
+#SBATCH --gpus-per-node=4
+srun nvidia-smi
+`,
+			expectResources: &controller.Resources{
+				GPUs: 4,
+			},
+		},
+		{
+			name: "invalid gpus per node",
+			script: `
+#!/bin/sh
+#SBATCH --gpus-per-node=v100:foo
+srun nvidia-smi
+`,
+			expectError: true,
+		},
 	}
 
 	for _, tc := range tt {
@@ -236,3 +281,31 @@ srun rm lolcow_latest.sif
 		})
 	}
 }
+
+func TestArrayBatch(t *testing.T) {
+	const script = "#!/bin/sh\n#SBATCH --account=physics\nhostname\n"
+
+	batch, err := arrayBatch(script, nil)
+	require.NoError(t, err)
+	require.Equal(t, script, batch)
+
+	batch, err = arrayBatch(script, &wlmv1alpha1.JobArray{Indices: "0-99", Throttle: 10})
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\n#SBATCH --array=0-99%10\n#SBATCH --account=physics\nhostname\n", batch)
+
+	// script that already holds the directive is not changed again
+	again, err := arrayBatch(batch, &wlmv1alpha1.JobArray{Indices: "0-99", Throttle: 10})
+	require.NoError(t, err)
+	require.Equal(t, batch, again)
+
+	batch, err = arrayBatch(script, &wlmv1alpha1.JobArray{Indices: "1,3,5-7"})
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\n#SBATCH --array=1,3,5-7\n#SBATCH --account=physics\nhostname\n", batch)
+
+	_, err = arrayBatch(script, &wlmv1alpha1.JobArray{Indices: "5-1"})
+	require.Error(t, err)
+	_, err = arrayBatch(script, &wlmv1alpha1.JobArray{Indices: "1", Throttle: -1})
+	require.Error(t, err)
+	_, err = arrayBatch("#!/bin/sh\n#SBATCH -a 3\nhostname\n", &wlmv1alpha1.JobArray{Indices: "1"})
+	require.Error(t, err)
+}
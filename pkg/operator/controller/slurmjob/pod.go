@@ -75,5 +75,5 @@ func nodeSelectorForSj(sj *wlmv1alpha1.SlurmJob) map[string]string {
 	for k, v := range sj.Spec.NodeSelector {
 		nodeSelector[k] = v
 	}
-	return nodeSelector
+	return controller.RetryNodeSelector(sj.Spec.RetryPolicy, len(sj.Status.Attempts), nodeSelector)
 }
@@ -102,6 +102,24 @@ srun rm lolcow_latest.sif
 									},
 								},
 							},
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/class-1-nodes",
+										Operator: "Gt",
+										Values:   []string{"6"},
+									},
+								},
+							},
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/class-2-nodes",
+										Operator: "Gt",
+										Values:   []string{"6"},
+									},
+								},
+							},
 						},
 					},
 				},
@@ -134,6 +152,24 @@ srun rm lolcow_latest.sif
 									},
 								},
 							},
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/class-1-mem-per-node",
+										Operator: "Gt",
+										Values:   []string{"120059"},
+									},
+								},
+							},
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/class-2-mem-per-node",
+										Operator: "Gt",
+										Values:   []string{"120059"},
+									},
+								},
+							},
 						},
 					},
 				},
@@ -166,6 +202,72 @@ srun rm lolcow_latest.sif
 									},
 								},
 							},
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/class-1-cpu-per-node",
+										Operator: "Gt",
+										Values:   []string{"7"},
+									},
+								},
+							},
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/class-2-cpu-per-node",
+										Operator: "Gt",
+										Values:   []string{"7"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "gpu affinity",
+			sj: &v1alpha1.SlurmJob{
+				Spec: v1alpha1.SlurmJobSpec{
+					Batch: `
+#!/bin/sh
+#SBATCH --gres=gpu:V100:2
+
+srun nvidia-smi
+`,
+				},
+			},
+			expectAffinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/gpu-v100",
+										Operator: "Gt",
+										Values:   []string{"1"},
+									},
+								},
+							},
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/class-1-gpu-v100",
+										Operator: "Gt",
+										Values:   []string{"1"},
+									},
+								},
+							},
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/class-2-gpu-v100",
+										Operator: "Gt",
+										Values:   []string{"1"},
+									},
+								},
+							},
 						},
 					},
 				},
@@ -217,6 +319,54 @@ srun rm lolcow_latest.sif
 									},
 								},
 							},
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/class-1-nodes",
+										Operator: "Gt",
+										Values:   []string{"6"},
+									},
+									{
+										Key:      "wlm.sylabs.io/wall-time",
+										Operator: "Gt",
+										Values:   []string{"86699"},
+									},
+									{
+										Key:      "wlm.sylabs.io/class-1-mem-per-node",
+										Operator: "Gt",
+										Values:   []string{"120059"},
+									},
+									{
+										Key:      "wlm.sylabs.io/class-1-cpu-per-node",
+										Operator: "Gt",
+										Values:   []string{"15"},
+									},
+								},
+							},
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "wlm.sylabs.io/class-2-nodes",
+										Operator: "Gt",
+										Values:   []string{"6"},
+									},
+									{
+										Key:      "wlm.sylabs.io/wall-time",
+										Operator: "Gt",
+										Values:   []string{"86699"},
+									},
+									{
+										Key:      "wlm.sylabs.io/class-2-mem-per-node",
+										Operator: "Gt",
+										Values:   []string{"120059"},
+									},
+									{
+										Key:      "wlm.sylabs.io/class-2-cpu-per-node",
+										Operator: "Gt",
+										Values:   []string{"15"},
+									},
+								},
+							},
 						},
 					},
 				},
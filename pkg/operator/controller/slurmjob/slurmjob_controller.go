@@ -17,13 +17,18 @@ package slurmjob
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/golang/glog"
 	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	wlmcontroller "github.com/dptech-corp/wlm-operator/pkg/operator/controller"
+	"github.com/dptech-corp/wlm-operator/pkg/slurm"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -37,20 +42,25 @@ import (
 type Reconciler struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+
+	cfg wlmcontroller.Config
 
 	jcUID int64
 	jcGID int64
 }
 
 // NewReconciler returns a new SlurmJob controller.
-func NewReconciler(mgr manager.Manager) *Reconciler {
+func NewReconciler(mgr manager.Manager, cfg wlmcontroller.Config) *Reconciler {
 	r := &Reconciler{
-		client: mgr.GetClient(),
-		scheme: mgr.GetScheme(),
-		jcUID:  int64(os.Getuid()),
-		jcGID:  int64(os.Getgid()),
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetRecorder("slurmjob-controller"),
+		cfg:      cfg,
+		jcUID:    int64(os.Getuid()),
+		jcGID:    int64(os.Getgid()),
 	}
 	return r
 }
@@ -85,12 +95,17 @@ func (r *Reconciler) AddToManager(mgr manager.Manager) error {
 
 // Reconcile reads that state of the cluster for a SlurmJob object and makes changes
 // based on the state read and what is in the SlurmJob.Spec.
-func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+func (r *Reconciler) Reconcile(req reconcile.Request) (res reconcile.Result, err error) {
 	glog.Infof("Received reconcile request: %v", req)
+	defer func() {
+		if err != nil {
+			wlmcontroller.ObserveReconcileError(wlmcontroller.KindSlurmJob, req.Namespace)
+		}
+	}()
 
 	// Fetch the SlurmJob instance
 	sj := &wlmv1alpha1.SlurmJob{}
-	err := r.client.Get(context.Background(), req.NamespacedName, sj)
+	err = r.client.Get(context.Background(), req.NamespacedName, sj)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -103,10 +118,43 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 		return reconcile.Result{}, err
 	}
 
+	if sj.DeletionTimestamp != nil {
+		return r.finalize(sj)
+	}
+	if r.cfg.WLM == nil {
+		wlmcontroller.RecordRedBoxRequired(r.recorder, sj, sj.Spec.Suspend, sj.Spec.RetryPolicy, sj.Spec.Array)
+	}
+	if r.cfg.WLM != nil && !wlmcontroller.HasFinalizer(sj, wlmcontroller.CancelJobFinalizer) {
+		wlmcontroller.AddFinalizer(sj, wlmcontroller.CancelJobFinalizer)
+		err = r.client.Update(context.Background(), sj)
+		if err != nil {
+			glog.Errorf("Could not add finalizer to slurm job: %v", err)
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Virtual kubelet submits the batch script as is, so job array
+	// is requested by the directive in the script
+	batch, err := arrayBatch(sj.Spec.Batch, sj.Spec.Array)
+	if err != nil {
+		glog.Errorf("Could not build slurm job array: %v", err)
+		r.recorder.Event(sj, corev1.EventTypeWarning, wlmcontroller.EventInvalidSpec, err.Error())
+		return reconcile.Result{}, err
+	}
+	if batch != sj.Spec.Batch {
+		sj.Spec.Batch = batch
+		err = r.client.Update(context.Background(), sj)
+		if err != nil {
+			glog.Errorf("Could not update slurm job batch: %v", err)
+			return reconcile.Result{}, err
+		}
+	}
+
 	// Translate SlurmJob to Pod
 	sjPod, err := r.newPodForSJ(sj)
 	if err != nil {
 		glog.Errorf("Could not translate slurm job into pod: %v", err)
+		r.recorder.Event(sj, corev1.EventTypeWarning, wlmcontroller.EventInvalidSpec, err.Error())
 		return reconcile.Result{}, err
 	}
 
@@ -122,7 +170,8 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 	key := types.NamespacedName{Name: sjPod.Name, Namespace: sjPod.Namespace}
 	err = r.client.Get(context.Background(), key, sjCurrentPod)
 	if err != nil && errors.IsNotFound(err) {
-		if sj.Status.Status != "" {
+		retrying := sj.Status.Status == wlmcontroller.RetryingStatus
+		if sj.Status.Status != "" && !retrying {
 			glog.Info("Pod will not be created, it was already created once")
 			return reconcile.Result{}, nil
 		}
@@ -133,16 +182,192 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 			glog.Errorf("Could not create new pod: %v", err)
 			return reconcile.Result{}, err
 		}
+		r.recorder.Eventf(sj, corev1.EventTypeNormal, wlmcontroller.EventPodCreated, "Created pod %s", sjPod.Name)
+		if affinity := wlmcontroller.DescribeAffinity(sjPod.Spec.Affinity); affinity != "" {
+			r.recorder.Eventf(sj, corev1.EventTypeNormal, wlmcontroller.EventAffinityComputed,
+				"Pod requires nodes with %s", affinity)
+		}
+		if retrying {
+			sj.Status.Status = string(corev1.PodPending)
+			err = r.client.Status().Update(context.Background(), sj)
+			if err != nil {
+				glog.Errorf("Could not update slurm job: %v", err)
+				return reconcile.Result{}, err
+			}
+		}
 		return reconcile.Result{}, nil
 	}
+	if sjCurrentPod.DeletionTimestamp != nil {
+		glog.Infof("Waiting for pod %q to be deleted", sjCurrentPod.Name)
+		return reconcile.Result{}, nil
+	}
+	if sj.Status.Status == wlmcontroller.RetryingStatus {
+		return r.deletePod(sjCurrentPod)
+	}
 
 	glog.Infof("Updating slurm job %q", sj.Name)
 	// Otherwise smth has changed, need to update things
 	sj.Status.Status = string(sjCurrentPod.Status.Phase)
+	var collect *corev1.Pod
+	if sj.Spec.Results != nil {
+		collect, err = wlmcontroller.CollectPod(context.Background(), r.client, sjCurrentPod)
+		if err != nil {
+			glog.Errorf("Could not get results collection pod: %v", err)
+		}
+	}
+	oldStatus := sj.Status.JobStatus.DeepCopy()
+	finished, err := wlmcontroller.UpdateJobStatus(context.Background(), r.cfg.WLM, sjCurrentPod, collect, &sj.Status.JobStatus)
+	if err != nil {
+		glog.Errorf("Could not get slurm job status: %v", err)
+	}
+	if r.cfg.WLM != nil && err == nil && !finished {
+		r.suspend(sj)
+	}
+	wlmcontroller.RecordStatusEvents(r.recorder, sj, oldStatus, &sj.Status.JobStatus)
 	err = r.client.Status().Update(context.Background(), sj)
 	if err != nil {
 		glog.Errorf("Could not update slurm job: %v", err)
 		return reconcile.Result{}, err
 	}
+	wlmcontroller.ObserveJobStatus(wlmcontroller.KindSlurmJob, sj.Namespace, oldStatus, &sj.Status.JobStatus, time.Now())
+	if r.cfg.WLM != nil && sj.Status.Array != nil {
+		return r.retryTasks(sj, finished)
+	}
+	if retry, wait := wlmcontroller.NextRetry(sj.Spec.RetryPolicy, &sj.Status.JobStatus, time.Now()); retry {
+		if wait > 0 {
+			return reconcile.Result{RequeueAfter: wait}, nil
+		}
+		return r.retry(sj, sjCurrentPod)
+	}
+	if r.cfg.WLM != nil && !finished {
+		return reconcile.Result{RequeueAfter: wlmcontroller.StatusPollInterval}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// suspend holds, suspends or resumes workload manager job of the SlurmJob
+// as requested by its spec or by the namespace annotation.
+func (r *Reconciler) suspend(sj *wlmv1alpha1.SlurmJob) {
+	suspend := sj.Spec.Suspend
+	if !suspend {
+		var err error
+		suspend, err = wlmcontroller.NamespaceSuspended(context.Background(), r.client, sj.Namespace)
+		if err != nil {
+			glog.Errorf("Could not check namespace suspension: %v", err)
+		}
+	}
+	err := wlmcontroller.SuspendJob(context.Background(), r.cfg.WLM, &sj.Status.JobStatus, suspend, sj.Spec.SuspendMode)
+	if err != nil {
+		glog.Errorf("Could not suspend slurm job: %v", err)
+	}
+}
+
+// retry records the failed attempt of the SlurmJob and replaces its pod
+// so that the job is submitted again.
+func (r *Reconciler) retry(sj *wlmv1alpha1.SlurmJob, pod *corev1.Pod) (reconcile.Result, error) {
+	glog.Infof("Retrying slurm job %q after %s", sj.Name, sj.Status.State)
+	r.recorder.Eventf(sj, corev1.EventTypeWarning, wlmcontroller.EventRetrying,
+		"Retrying job %s after %s, attempt %d", sj.Status.JobID, sj.Status.State, len(sj.Status.Attempts)+2)
+	wlmcontroller.RecordAttempt(&sj.Status.JobStatus)
+	sj.Status.Status = wlmcontroller.RetryingStatus
+	err := r.client.Status().Update(context.Background(), sj)
+	if err != nil {
+		glog.Errorf("Could not update slurm job: %v", err)
+		return reconcile.Result{}, err
+	}
+	return r.deletePod(pod)
+}
+
+// retryTasks requeues failed tasks of the SlurmJob array that are due for retry.
+// Unlike retry, the job and its pod are kept, so other tasks are not affected.
+func (r *Reconciler) retryTasks(sj *wlmv1alpha1.SlurmJob, finished bool) (reconcile.Result, error) {
+	requeued, retryErr := wlmcontroller.RetryTasks(context.Background(), r.cfg.WLM,
+		sj.Spec.RetryPolicy, &sj.Status.JobStatus, metav1.Now())
+	if retryErr != nil {
+		glog.Errorf("Could not retry slurm job tasks: %v", retryErr)
+	}
+	if len(requeued) != 0 {
+		indices := make([]int64, len(requeued))
+		for i, index := range requeued {
+			indices[i] = int64(index)
+		}
+		glog.Infof("Retrying tasks %s of slurm job %q", slurm.FormatArrayIndices(indices), sj.Name)
+		r.recorder.Eventf(sj, corev1.EventTypeWarning, wlmcontroller.EventRetrying,
+			"Retrying tasks %s of job %s", slurm.FormatArrayIndices(indices), sj.Status.JobID)
+		err := r.client.Status().Update(context.Background(), sj)
+		if err != nil {
+			glog.Errorf("Could not update slurm job: %v", err)
+			return reconcile.Result{}, err
+		}
+	}
+	if retryErr != nil {
+		return reconcile.Result{}, retryErr
+	}
+
+	retry, wait := wlmcontroller.NextRetry(sj.Spec.RetryPolicy, &sj.Status.JobStatus, time.Now())
+	switch {
+	case len(requeued) != 0 || !finished:
+		if retry && wait > 0 && wait < wlmcontroller.StatusPollInterval {
+			return reconcile.Result{RequeueAfter: wait}, nil
+		}
+		return reconcile.Result{RequeueAfter: wlmcontroller.StatusPollInterval}, nil
+	case retry:
+		return reconcile.Result{RequeueAfter: wait}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// deletePod deletes pod of the failed job attempt, new pod is created
+// once the deletion is observed.
+func (r *Reconciler) deletePod(pod *corev1.Pod) (reconcile.Result, error) {
+	err := r.client.Delete(context.Background(), pod)
+	if err != nil && !errors.IsNotFound(err) {
+		glog.Errorf("Could not delete pod: %v", err)
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// finalize cancels workload manager job of the deleted SlurmJob and removes
+// the finalizer once the job is finished.
+func (r *Reconciler) finalize(sj *wlmv1alpha1.SlurmJob) (reconcile.Result, error) {
+	if !wlmcontroller.HasFinalizer(sj, wlmcontroller.CancelJobFinalizer) {
+		return reconcile.Result{}, nil
+	}
+
+	if r.cfg.WLM != nil {
+		if sj.Status.JobID == "" {
+			// job may be submitted after the last status update
+			pod := &corev1.Pod{}
+			key := types.NamespacedName{Name: sj.Name + "-job", Namespace: sj.Namespace}
+			if err := r.client.Get(context.Background(), key, pod); err == nil {
+				sj.Status.JobID = wlmcontroller.PodJobID(context.Background(), r.cfg.WLM, pod)
+			}
+		}
+
+		glog.Infof("Cancelling slurm job %q", sj.Name)
+		cancelled := wlmcontroller.JobCondition(&sj.Status.JobStatus, wlmv1alpha1.JobCancelRequested) != nil
+		done, err := wlmcontroller.CancelJob(context.Background(), r.cfg.WLM, r.recorder, sj, &sj.Status.JobStatus, r.cfg.CancelTimeout)
+		if err != nil {
+			glog.Errorf("Could not cancel slurm job: %v", err)
+		}
+		if !done {
+			// job is cancelled once, so it must be recorded
+			if !cancelled && wlmcontroller.JobCondition(&sj.Status.JobStatus, wlmv1alpha1.JobCancelRequested) != nil {
+				if err := r.client.Status().Update(context.Background(), sj); err != nil {
+					glog.Errorf("Could not update slurm job: %v", err)
+					return reconcile.Result{}, err
+				}
+			}
+			return reconcile.Result{RequeueAfter: wlmcontroller.CancelPollInterval}, nil
+		}
+	}
+
+	wlmcontroller.RemoveFinalizer(sj, wlmcontroller.CancelJobFinalizer)
+	err := r.client.Update(context.Background(), sj)
+	if err != nil {
+		glog.Errorf("Could not remove finalizer from slurm job: %v", err)
+		return reconcile.Result{}, err
+	}
 	return reconcile.Result{}, nil
 }
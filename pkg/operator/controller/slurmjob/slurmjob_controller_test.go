@@ -0,0 +1,70 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurmjob
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var testScheme = runtime.NewScheme()
+
+func TestMain(m *testing.M) {
+	if err := wlmv1alpha1.AddToScheme(testScheme); err != nil {
+		panic(err)
+	}
+	if err := corev1.AddToScheme(testScheme); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestReconciler_Reconcile_array(t *testing.T) {
+	sj := &wlmv1alpha1.SlurmJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "array", Namespace: "default", UID: "array-uid"},
+		Spec: wlmv1alpha1.SlurmJobSpec{
+			Batch: "#!/bin/sh\n#SBATCH --nodes=1\nhostname\n",
+			Array: &wlmv1alpha1.JobArray{Indices: "0-9", Throttle: 2},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(testScheme, sj)
+	r := &Reconciler{client: c, scheme: testScheme, recorder: record.NewFakeRecorder(10)}
+	key := types.NamespacedName{Namespace: "default", Name: "array"}
+
+	// virtual kubelet submits spec.batch of the job owning the pod
+	for i := 0; i < 2; i++ {
+		_, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+		require.NoError(t, err)
+
+		actual := &wlmv1alpha1.SlurmJob{}
+		require.NoError(t, c.Get(context.Background(), key, actual))
+		require.Equal(t, "#!/bin/sh\n#SBATCH --array=0-9%2\n#SBATCH --nodes=1\nhostname\n", actual.Spec.Batch)
+
+		pod := &corev1.Pod{}
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "array-job"}, pod))
+		require.Equal(t, types.UID("array-uid"), metav1.GetControllerOf(pod).UID)
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurmworkflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	wlmcontroller "github.com/dptech-corp/wlm-operator/pkg/operator/controller"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// stepJob is a SlurmJob or a WlmJob created for a workflow step.
+type stepJob struct {
+	obj     wlmcontroller.Object
+	kind    string
+	status  *wlmv1alpha1.JobStatus
+	retry   *wlmv1alpha1.RetryPolicy
+	results *wlmv1alpha1.JobResults
+}
+
+// newSlurmStepJob wraps the SlurmJob into a stepJob.
+func newSlurmStepJob(sj *wlmv1alpha1.SlurmJob) *stepJob {
+	return &stepJob{
+		obj:     sj,
+		kind:    wlmcontroller.KindSlurmJob,
+		status:  &sj.Status.JobStatus,
+		retry:   sj.Spec.RetryPolicy,
+		results: sj.Spec.Results,
+	}
+}
+
+// newWlmStepJob wraps the WlmJob into a stepJob.
+func newWlmStepJob(wj *wlmv1alpha1.WlmJob) *stepJob {
+	return &stepJob{
+		obj:     wj,
+		kind:    wlmcontroller.KindWlmJob,
+		status:  &wj.Status.JobStatus,
+		retry:   wj.Spec.RetryPolicy,
+		results: wj.Spec.Results,
+	}
+}
+
+// sortSteps validates workflow steps and returns them in topological order,
+// i.e. every step goes after all of its dependencies. Step names should be
+// unique DNS labels, every step should run exactly one job and step dependencies
+// should form a directed acyclic graph.
+func sortSteps(steps []wlmv1alpha1.WorkflowStep) ([]*wlmv1alpha1.WorkflowStep, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("workflow has no steps")
+	}
+
+	index := make(map[string]*wlmv1alpha1.WorkflowStep, len(steps))
+	for i := range steps {
+		s := &steps[i]
+		if errs := validation.IsDNS1123Label(s.Name); len(errs) != 0 {
+			return nil, errors.Errorf("invalid step name %q: %s", s.Name, strings.Join(errs, ", "))
+		}
+		if _, ok := index[s.Name]; ok {
+			return nil, errors.Errorf("duplicate step %q", s.Name)
+		}
+		if (s.SlurmJob == nil) == (s.WlmJob == nil) {
+			return nil, errors.Errorf("step %q should have exactly one of slurmJob and wlmJob", s.Name)
+		}
+		index[s.Name] = s
+	}
+	for _, s := range steps {
+		for _, d := range s.DependsOn {
+			if _, ok := index[d.Step]; !ok {
+				return nil, errors.Errorf("step %q depends on unknown step %q", s.Name, d.Step)
+			}
+			switch d.Condition {
+			case "", wlmv1alpha1.DependencyAfterOK, wlmv1alpha1.DependencyAfterAny:
+			default:
+				return nil, errors.Errorf("step %q has unknown dependency condition %q", s.Name, d.Condition)
+			}
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	marks := make(map[string]int, len(steps))
+	sorted := make([]*wlmv1alpha1.WorkflowStep, 0, len(steps))
+	var visit func(s *wlmv1alpha1.WorkflowStep) error
+	visit = func(s *wlmv1alpha1.WorkflowStep) error {
+		switch marks[s.Name] {
+		case visiting:
+			return errors.Errorf("dependency cycle through step %q", s.Name)
+		case visited:
+			return nil
+		}
+		marks[s.Name] = visiting
+		for _, d := range s.DependsOn {
+			if err := visit(index[d.Step]); err != nil {
+				return err
+			}
+		}
+		marks[s.Name] = visited
+		sorted = append(sorted, s)
+		return nil
+	}
+	for i := range steps {
+		if err := visit(&steps[i]); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// finished tells whether step phase is final.
+func finished(p wlmv1alpha1.WorkflowStepPhase) bool {
+	return p == wlmv1alpha1.StepSucceeded || p == wlmv1alpha1.StepFailed || p == wlmv1alpha1.StepSkipped
+}
+
+// dependencyState tells whether dependencies of the step are satisfied given
+// phases of workflow steps. When some dependency can't be satisfied anymore,
+// its name is returned as unsatisfiable.
+func dependencyState(s *wlmv1alpha1.WorkflowStep, phases map[string]wlmv1alpha1.WorkflowStepPhase) (satisfied bool, unsatisfiable string) {
+	satisfied = true
+	for _, d := range s.DependsOn {
+		p := phases[d.Step]
+		switch {
+		case d.Condition == wlmv1alpha1.DependencyAfterAny && finished(p):
+		case p == wlmv1alpha1.StepSucceeded:
+		case p == wlmv1alpha1.StepFailed || p == wlmv1alpha1.StepSkipped:
+			return false, d.Step
+		default:
+			satisfied = false
+		}
+	}
+	return satisfied, ""
+}
+
+// jobPhase returns step phase according to the step job status. Job with
+// results succeeds only when its results are collected.
+func jobPhase(j *stepJob, now time.Time) wlmv1alpha1.WorkflowStepPhase {
+	if _, ok := wlmcontroller.FinishedAt(j.status, j.retry, j.results != nil, now); ok {
+		c := wlmcontroller.JobCondition(j.status, wlmv1alpha1.JobSucceeded)
+		if c == nil || c.Status != corev1.ConditionTrue {
+			return wlmv1alpha1.StepFailed
+		}
+		r := wlmcontroller.JobCondition(j.status, wlmv1alpha1.JobResultsCollected)
+		if j.results != nil && (r == nil || r.Status != corev1.ConditionTrue) {
+			return wlmv1alpha1.StepFailed
+		}
+		return wlmv1alpha1.StepSucceeded
+	}
+	if c := wlmcontroller.JobCondition(j.status, wlmv1alpha1.JobRunning); c != nil && c.Status == corev1.ConditionTrue {
+		return wlmv1alpha1.StepRunning
+	}
+	return wlmv1alpha1.StepPending
+}
+
+// stepResults returns results collection configuration of the step job.
+func stepResults(s *wlmv1alpha1.WorkflowStep) *wlmv1alpha1.JobResults {
+	if s.SlurmJob != nil {
+		return s.SlurmJob.Results
+	}
+	return s.WlmJob.Results
+}
+
+// inputs returns data preparation configuration that uploads results of the first
+// dependency with results collection configured to the working directory of the step job.
+// Name of the dependency is returned along with the configuration. Inputs are passed only
+// to SlurmJob steps without their own prepare configuration.
+func inputs(s *wlmv1alpha1.WorkflowStep, index map[string]*wlmv1alpha1.WorkflowStep) (string, *wlmv1alpha1.PrepareData) {
+	if s.SlurmJob == nil || s.SlurmJob.Prepare != nil {
+		return "", nil
+	}
+	for _, d := range s.DependsOn {
+		if res := stepResults(index[d.Step]); res != nil {
+			return d.Step, &wlmv1alpha1.PrepareData{Mount: *res.Mount.DeepCopy(), To: "."}
+		}
+	}
+	return "", nil
+}
+
+// dependencyDirective returns an #SBATCH directive that makes Slurm start the
+// step job once its unfinished dependencies are satisfied, e.g.
+// '#SBATCH --dependency=afterok:12:13,afterany:14'. False is returned when some
+// unfinished dependency is not a SlurmJob submitted to Slurm yet or when it may
+// be resubmitted under a different job id. Jobs of finished steps are not required.
+func dependencyDirective(s *wlmv1alpha1.WorkflowStep, phases map[string]wlmv1alpha1.WorkflowStepPhase,
+	jobs map[string]*stepJob) (string, bool) {
+	ids := make(map[wlmv1alpha1.DependencyCondition][]string)
+	for _, d := range s.DependsOn {
+		if finished(phases[d.Step]) {
+			continue
+		}
+		j := jobs[d.Step]
+		if j == nil || j.kind != wlmcontroller.KindSlurmJob || j.retry != nil ||
+			j.status.JobID == "" || j.status.Cluster != "" {
+			return "", false
+		}
+		cond := d.Condition
+		if cond == "" {
+			cond = wlmv1alpha1.DependencyAfterOK
+		}
+		ids[cond] = append(ids[cond], j.status.JobID)
+	}
+
+	var deps []string
+	for _, cond := range []wlmv1alpha1.DependencyCondition{wlmv1alpha1.DependencyAfterOK, wlmv1alpha1.DependencyAfterAny} {
+		if len(ids[cond]) != 0 {
+			deps = append(deps, fmt.Sprintf("%s:%s", cond, strings.Join(ids[cond], ":")))
+		}
+	}
+	if len(deps) == 0 {
+		return "", true
+	}
+	return "#SBATCH --dependency=" + strings.Join(deps, ","), true
+}
+
+// workflowPhase returns workflow phase according to phases of its steps.
+func workflowPhase(steps []wlmv1alpha1.WorkflowStepStatus) wlmv1alpha1.WorkflowPhase {
+	phase := wlmv1alpha1.WorkflowSucceeded
+	for _, s := range steps {
+		switch {
+		case !finished(s.Phase):
+			return wlmv1alpha1.WorkflowRunning
+		case s.Phase != wlmv1alpha1.StepSucceeded:
+			phase = wlmv1alpha1.WorkflowFailed
+		}
+	}
+	return phase
+}
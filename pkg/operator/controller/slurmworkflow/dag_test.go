@@ -0,0 +1,296 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurmworkflow
+
+import (
+	"testing"
+	"time"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	wlmcontroller "github.com/dptech-corp/wlm-operator/pkg/operator/controller"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func slurmStep(name string, deps ...wlmv1alpha1.WorkflowDependency) wlmv1alpha1.WorkflowStep {
+	return wlmv1alpha1.WorkflowStep{
+		Name:      name,
+		DependsOn: deps,
+		SlurmJob:  &wlmv1alpha1.SlurmJobSpec{Batch: "#!/bin/sh\nhostname\n"},
+	}
+}
+
+func afterOK(step string) wlmv1alpha1.WorkflowDependency {
+	return wlmv1alpha1.WorkflowDependency{Step: step}
+}
+
+func afterAny(step string) wlmv1alpha1.WorkflowDependency {
+	return wlmv1alpha1.WorkflowDependency{Step: step, Condition: wlmv1alpha1.DependencyAfterAny}
+}
+
+func TestSortSteps(t *testing.T) {
+	tt := []struct {
+		name  string
+		steps []wlmv1alpha1.WorkflowStep
+		order []string
+		err   string
+	}{
+		{
+			name: "diamond",
+			steps: []wlmv1alpha1.WorkflowStep{
+				slurmStep("post", afterOK("left"), afterAny("right")),
+				slurmStep("left", afterOK("pre")),
+				slurmStep("right", afterOK("pre")),
+				slurmStep("pre"),
+			},
+			order: []string{"pre", "left", "right", "post"},
+		},
+		{
+			name: "no steps",
+			err:  "workflow has no steps",
+		},
+		{
+			name:  "invalid name",
+			steps: []wlmv1alpha1.WorkflowStep{slurmStep("Pre")},
+			err:   `invalid step name "Pre"`,
+		},
+		{
+			name:  "duplicate",
+			steps: []wlmv1alpha1.WorkflowStep{slurmStep("pre"), slurmStep("pre")},
+			err:   `duplicate step "pre"`,
+		},
+		{
+			name:  "no job",
+			steps: []wlmv1alpha1.WorkflowStep{{Name: "pre"}},
+			err:   `step "pre" should have exactly one of slurmJob and wlmJob`,
+		},
+		{
+			name:  "unknown dependency",
+			steps: []wlmv1alpha1.WorkflowStep{slurmStep("post", afterOK("pre"))},
+			err:   `step "post" depends on unknown step "pre"`,
+		},
+		{
+			name: "unknown condition",
+			steps: []wlmv1alpha1.WorkflowStep{
+				slurmStep("pre"),
+				slurmStep("post", wlmv1alpha1.WorkflowDependency{Step: "pre", Condition: "afternotok"}),
+			},
+			err: `step "post" has unknown dependency condition "afternotok"`,
+		},
+		{
+			name: "cycle",
+			steps: []wlmv1alpha1.WorkflowStep{
+				slurmStep("pre", afterOK("post")),
+				slurmStep("post", afterOK("pre")),
+			},
+			err: "dependency cycle through step",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sorted, err := sortSteps(tc.steps)
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			var order []string
+			for _, s := range sorted {
+				order = append(order, s.Name)
+			}
+			require.Equal(t, tc.order, order)
+		})
+	}
+}
+
+func TestDependencyState(t *testing.T) {
+	s := slurmStep("post", afterOK("pre"), afterAny("sim"))
+
+	tt := []struct {
+		name          string
+		phases        map[string]wlmv1alpha1.WorkflowStepPhase
+		satisfied     bool
+		unsatisfiable string
+	}{
+		{
+			name:   "waiting",
+			phases: map[string]wlmv1alpha1.WorkflowStepPhase{"pre": wlmv1alpha1.StepRunning, "sim": wlmv1alpha1.StepWaiting},
+		},
+		{
+			name:      "satisfied",
+			phases:    map[string]wlmv1alpha1.WorkflowStepPhase{"pre": wlmv1alpha1.StepSucceeded, "sim": wlmv1alpha1.StepFailed},
+			satisfied: true,
+		},
+		{
+			name:      "afterany skipped",
+			phases:    map[string]wlmv1alpha1.WorkflowStepPhase{"pre": wlmv1alpha1.StepSucceeded, "sim": wlmv1alpha1.StepSkipped},
+			satisfied: true,
+		},
+		{
+			name:          "afterok failed",
+			phases:        map[string]wlmv1alpha1.WorkflowStepPhase{"pre": wlmv1alpha1.StepFailed, "sim": wlmv1alpha1.StepRunning},
+			unsatisfiable: "pre",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			satisfied, unsatisfiable := dependencyState(&s, tc.phases)
+			require.Equal(t, tc.satisfied, satisfied)
+			require.Equal(t, tc.unsatisfiable, unsatisfiable)
+		})
+	}
+}
+
+func TestJobPhase(t *testing.T) {
+	now := time.Now()
+	status := func(conds ...wlmv1alpha1.JobConditionType) *wlmv1alpha1.JobStatus {
+		st := &wlmv1alpha1.JobStatus{}
+		for _, c := range conds {
+			wlmcontroller.SetJobCondition(st, c, corev1.ConditionTrue, "", "", metav1.NewTime(now))
+		}
+		return st
+	}
+	results := &wlmv1alpha1.JobResults{From: "out"}
+
+	tt := []struct {
+		name     string
+		job      *stepJob
+		expected wlmv1alpha1.WorkflowStepPhase
+	}{
+		{
+			name:     "pending",
+			job:      &stepJob{status: status(wlmv1alpha1.JobSubmitted)},
+			expected: wlmv1alpha1.StepPending,
+		},
+		{
+			name:     "running",
+			job:      &stepJob{status: status(wlmv1alpha1.JobSubmitted, wlmv1alpha1.JobRunning)},
+			expected: wlmv1alpha1.StepRunning,
+		},
+		{
+			name:     "succeeded",
+			job:      &stepJob{status: status(wlmv1alpha1.JobSucceeded)},
+			expected: wlmv1alpha1.StepSucceeded,
+		},
+		{
+			name:     "failed",
+			job:      &stepJob{status: status(wlmv1alpha1.JobFailed)},
+			expected: wlmv1alpha1.StepFailed,
+		},
+		{
+			name:     "collecting results",
+			job:      &stepJob{status: status(wlmv1alpha1.JobSucceeded), results: results},
+			expected: wlmv1alpha1.StepPending,
+		},
+		{
+			name:     "results collected",
+			job:      &stepJob{status: status(wlmv1alpha1.JobSucceeded, wlmv1alpha1.JobResultsCollected), results: results},
+			expected: wlmv1alpha1.StepSucceeded,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, jobPhase(tc.job, now))
+		})
+	}
+}
+
+func TestInputs(t *testing.T) {
+	pre := slurmStep("pre")
+	sim := slurmStep("sim", afterOK("pre"))
+	sim.SlurmJob.Results = &wlmv1alpha1.JobResults{
+		From:  "out",
+		Mount: corev1.Volume{Name: "outputs"},
+	}
+	post := slurmStep("post", afterOK("pre"), afterOK("sim"))
+	index := map[string]*wlmv1alpha1.WorkflowStep{"pre": &pre, "sim": &sim, "post": &post}
+
+	dep, prepare := inputs(&post, index)
+	require.Equal(t, "sim", dep)
+	require.Equal(t, &wlmv1alpha1.PrepareData{Mount: corev1.Volume{Name: "outputs"}, To: "."}, prepare)
+
+	dep, prepare = inputs(&sim, index)
+	require.Empty(t, dep)
+	require.Nil(t, prepare)
+
+	// own prepare configuration is kept
+	post.SlurmJob.Prepare = &wlmv1alpha1.PrepareData{To: "in"}
+	dep, prepare = inputs(&post, index)
+	require.Empty(t, dep)
+	require.Nil(t, prepare)
+}
+
+func TestDependencyDirective(t *testing.T) {
+	s := slurmStep("post", afterOK("pre"), afterOK("sim"), afterAny("check"), afterOK("done"))
+	phases := map[string]wlmv1alpha1.WorkflowStepPhase{
+		"pre":   wlmv1alpha1.StepRunning,
+		"sim":   wlmv1alpha1.StepPending,
+		"check": wlmv1alpha1.StepPending,
+		"done":  wlmv1alpha1.StepSucceeded,
+	}
+	slurmJob := func(id string) *stepJob {
+		return &stepJob{kind: wlmcontroller.KindSlurmJob, status: &wlmv1alpha1.JobStatus{JobID: id}}
+	}
+	jobs := map[string]*stepJob{
+		"pre":   slurmJob("12"),
+		"sim":   slurmJob("13"),
+		"check": slurmJob("14"),
+	}
+
+	directive, ok := dependencyDirective(&s, phases, jobs)
+	require.True(t, ok)
+	require.Equal(t, "#SBATCH --dependency=afterok:12:13,afterany:14", directive)
+
+	// finished dependencies are not required
+	done := slurmStep("post", afterOK("done"))
+	directive, ok = dependencyDirective(&done, phases, jobs)
+	require.True(t, ok)
+	require.Empty(t, directive)
+
+	jobs["sim"] = slurmJob("")
+	_, ok = dependencyDirective(&s, phases, jobs)
+	require.False(t, ok, "dependency is not submitted yet")
+
+	jobs["sim"] = &stepJob{kind: wlmcontroller.KindWlmJob, status: &wlmv1alpha1.JobStatus{JobID: "13"}}
+	_, ok = dependencyDirective(&s, phases, jobs)
+	require.False(t, ok, "dependency is not a slurm job")
+
+	jobs["sim"] = slurmJob("13")
+	jobs["sim"].retry = &wlmv1alpha1.RetryPolicy{BackoffLimit: 1}
+	_, ok = dependencyDirective(&s, phases, jobs)
+	require.False(t, ok, "dependency may be resubmitted")
+}
+
+func TestWorkflowPhase(t *testing.T) {
+	steps := func(phases ...wlmv1alpha1.WorkflowStepPhase) []wlmv1alpha1.WorkflowStepStatus {
+		var st []wlmv1alpha1.WorkflowStepStatus
+		for _, p := range phases {
+			st = append(st, wlmv1alpha1.WorkflowStepStatus{Phase: p})
+		}
+		return st
+	}
+
+	require.Equal(t, wlmv1alpha1.WorkflowRunning,
+		workflowPhase(steps(wlmv1alpha1.StepFailed, wlmv1alpha1.StepWaiting)))
+	require.Equal(t, wlmv1alpha1.WorkflowSucceeded,
+		workflowPhase(steps(wlmv1alpha1.StepSucceeded, wlmv1alpha1.StepSucceeded)))
+	require.Equal(t, wlmv1alpha1.WorkflowFailed,
+		workflowPhase(steps(wlmv1alpha1.StepSucceeded, wlmv1alpha1.StepSkipped)))
+}
@@ -0,0 +1,353 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurmworkflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	wlmcontroller "github.com/dptech-corp/wlm-operator/pkg/operator/controller"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Labels set on jobs created for workflow steps.
+const (
+	WorkflowLabel = "wlm.sylabs.io/workflow"
+	StepLabel     = "wlm.sylabs.io/workflow-step"
+)
+
+// Reasons of events recorded for workflows.
+const (
+	EventSuccessfulCreate = "SuccessfulCreate"
+	EventFailedCreate     = "FailedCreate"
+	EventSuccessfulDelete = "SuccessfulDelete"
+	EventStepSucceeded    = "StepSucceeded"
+	EventStepFailed       = "StepFailed"
+	EventStepSkipped      = "StepSkipped"
+	EventSucceeded        = "Succeeded"
+	EventFailed           = "Failed"
+)
+
+// Reconciler creates SlurmJobs and WlmJobs of SlurmWorkflow steps
+// once their dependencies are satisfied.
+type Reconciler struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+// NewReconciler returns a new SlurmWorkflow controller.
+func NewReconciler(mgr manager.Manager) *Reconciler {
+	return &Reconciler{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetRecorder("slurmworkflow-controller"),
+	}
+}
+
+// AddToManager adds SlurmWorkflow Reconciler to the given Manager.
+func (r *Reconciler) AddToManager(mgr manager.Manager) error {
+	c, err := controller.New("slurmworkflow-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &wlmv1alpha1.SlurmWorkflow{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// SlurmWorkflow is reconciled whenever one of its step jobs changes,
+	// so that dependent steps start as soon as the job finishes
+	for _, t := range []runtime.Object{&wlmv1alpha1.SlurmJob{}, &wlmv1alpha1.WlmJob{}} {
+		err = c.Watch(&source.Kind{Type: t}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &wlmv1alpha1.SlurmWorkflow{},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reconcile observes jobs of SlurmWorkflow steps, creates jobs of steps whose
+// dependencies are satisfied and deletes jobs of steps whose dependencies
+// can't be satisfied anymore. SlurmJob steps depending on submitted SlurmJobs
+// are created right away with Slurm dependencies, so that they are queued
+// together with their dependencies.
+func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	glog.Infof("Received reconcile request: %v", req)
+
+	wf := &wlmv1alpha1.SlurmWorkflow{}
+	err := r.client.Get(context.Background(), req.NamespacedName, wf)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		glog.Errorf("Could not get slurm workflow: %v", err)
+		return reconcile.Result{}, err
+	}
+	if wf.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	steps, err := sortSteps(wf.Spec.Steps)
+	if err != nil {
+		glog.Errorf("Invalid slurm workflow %q: %v", wf.Name, err)
+		if wf.Status.Message != err.Error() {
+			r.recorder.Event(wf, corev1.EventTypeWarning, wlmcontroller.EventInvalidSpec, err.Error())
+		}
+		wf.Status.Phase = wlmv1alpha1.WorkflowFailed
+		wf.Status.Message = err.Error()
+		return reconcile.Result{}, r.updateStatus(wf)
+	}
+
+	index := make(map[string]*wlmv1alpha1.WorkflowStep, len(steps))
+	for _, s := range steps {
+		index[s.Name] = s
+	}
+	old := make(map[string]wlmv1alpha1.WorkflowStepStatus, len(wf.Status.Steps))
+	for _, st := range wf.Status.Steps {
+		old[st.Name] = st
+	}
+
+	now := time.Now()
+	phases := make(map[string]wlmv1alpha1.WorkflowStepPhase, len(steps))
+	jobs := make(map[string]*stepJob, len(steps))
+	statuses := make(map[string]wlmv1alpha1.WorkflowStepStatus, len(steps))
+	for _, s := range steps {
+		// finished steps are never revisited, so that step jobs
+		// may be deleted without the workflow noticing it
+		if st, ok := old[s.Name]; ok && finished(st.Phase) {
+			phases[s.Name] = st.Phase
+			statuses[s.Name] = st
+			continue
+		}
+
+		st, err := r.reconcileStep(wf, s, index, phases, jobs, now)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		phases[s.Name] = st.Phase
+		statuses[s.Name] = st
+		r.recordStepEvent(wf, old[s.Name], st)
+	}
+
+	wf.Status.Message = ""
+	wf.Status.Steps = make([]wlmv1alpha1.WorkflowStepStatus, 0, len(wf.Spec.Steps))
+	for _, s := range wf.Spec.Steps {
+		wf.Status.Steps = append(wf.Status.Steps, statuses[s.Name])
+	}
+	phase := workflowPhase(wf.Status.Steps)
+	if phase != wf.Status.Phase {
+		switch phase {
+		case wlmv1alpha1.WorkflowSucceeded:
+			r.recorder.Event(wf, corev1.EventTypeNormal, EventSucceeded, "All steps succeeded")
+		case wlmv1alpha1.WorkflowFailed:
+			r.recorder.Event(wf, corev1.EventTypeWarning, EventFailed, "Some steps failed or were skipped")
+		}
+	}
+	wf.Status.Phase = phase
+	return reconcile.Result{}, r.updateStatus(wf)
+}
+
+// reconcileStep observes job of the workflow step and creates or deletes it according
+// to the step dependencies. Phases and jobs of the step dependencies should be already
+// observed, job of the step is added to jobs. Current step status is returned.
+func (r *Reconciler) reconcileStep(wf *wlmv1alpha1.SlurmWorkflow, s *wlmv1alpha1.WorkflowStep,
+	index map[string]*wlmv1alpha1.WorkflowStep, phases map[string]wlmv1alpha1.WorkflowStepPhase,
+	jobs map[string]*stepJob, now time.Time) (wlmv1alpha1.WorkflowStepStatus, error) {
+	st := wlmv1alpha1.WorkflowStepStatus{Name: s.Name, Phase: wlmv1alpha1.StepWaiting}
+
+	j, err := r.job(wf, s)
+	if err != nil {
+		glog.Errorf("Could not get job of step %q: %v", s.Name, err)
+		return st, err
+	}
+	if j != nil {
+		if owner := metav1.GetControllerOf(j.obj); owner == nil || owner.UID != wf.UID {
+			st.Phase = wlmv1alpha1.StepFailed
+			st.Message = fmt.Sprintf("%s %s already exists and is not owned by the workflow", j.kind, j.obj.GetName())
+			return st, nil
+		}
+		jobs[s.Name] = j
+		st.Phase = jobPhase(j, now)
+		st.Job = j.obj.GetName()
+		st.JobID = j.status.JobID
+		st.State = j.status.State
+		st.StartTime = j.status.StartTime
+		st.EndTime = j.status.EndTime
+	}
+
+	satisfied, unsatisfiable := dependencyState(s, phases)
+	switch {
+	case unsatisfiable != "":
+		if j != nil && finished(st.Phase) {
+			return st, nil
+		}
+		if j != nil && j.obj.GetDeletionTimestamp() == nil {
+			err = r.client.Delete(context.Background(), j.obj)
+			if err != nil && !errors.IsNotFound(err) {
+				glog.Errorf("Could not delete %s %q: %v", j.kind, j.obj.GetName(), err)
+				return st, err
+			}
+			r.recorder.Eventf(wf, corev1.EventTypeNormal, EventSuccessfulDelete, "Deleted %s %s", j.kind, j.obj.GetName())
+		}
+		st.Phase = wlmv1alpha1.StepSkipped
+		st.Message = fmt.Sprintf("Dependency on step %s can't be satisfied", unsatisfiable)
+		return st, nil
+	case j != nil:
+		return st, nil
+	case satisfied:
+		return r.createJob(wf, s, index, "", st)
+	}
+
+	if s.SlurmJob == nil {
+		return st, nil
+	}
+	if dep, _ := inputs(s, index); dep != "" && !finished(phases[dep]) {
+		st.Message = fmt.Sprintf("Waiting for results of step %s", dep)
+		return st, nil
+	}
+	directive, ok := dependencyDirective(s, phases, jobs)
+	if !ok {
+		return st, nil
+	}
+	return r.createJob(wf, s, index, directive, st)
+}
+
+// job returns job of the workflow step or nil if it doesn't exist.
+func (r *Reconciler) job(wf *wlmv1alpha1.SlurmWorkflow, s *wlmv1alpha1.WorkflowStep) (*stepJob, error) {
+	key := types.NamespacedName{Namespace: wf.Namespace, Name: jobName(wf, s)}
+	var j *stepJob
+	var err error
+	if s.SlurmJob != nil {
+		sj := &wlmv1alpha1.SlurmJob{}
+		err = r.client.Get(context.Background(), key, sj)
+		j = newSlurmStepJob(sj)
+	} else {
+		wj := &wlmv1alpha1.WlmJob{}
+		err = r.client.Get(context.Background(), key, wj)
+		j = newWlmStepJob(wj)
+	}
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// createJob creates job of the workflow step. Results of the step dependency are uploaded
+// to the working directory of the SlurmJob that doesn't prepare data itself. When not empty,
+// directive is added to the batch script of the SlurmJob. Updated step status is returned.
+func (r *Reconciler) createJob(wf *wlmv1alpha1.SlurmWorkflow, s *wlmv1alpha1.WorkflowStep,
+	index map[string]*wlmv1alpha1.WorkflowStep, directive string,
+	st wlmv1alpha1.WorkflowStepStatus) (wlmv1alpha1.WorkflowStepStatus, error) {
+	meta := metav1.ObjectMeta{
+		Name:      jobName(wf, s),
+		Namespace: wf.Namespace,
+		Labels:    map[string]string{WorkflowLabel: wf.Name, StepLabel: s.Name},
+	}
+	for k, v := range wf.Labels {
+		meta.Labels[k] = v
+	}
+
+	var obj wlmcontroller.Object
+	kind := wlmcontroller.KindSlurmJob
+	if s.SlurmJob != nil {
+		sj := &wlmv1alpha1.SlurmJob{ObjectMeta: meta, Spec: *s.SlurmJob.DeepCopy()}
+		if _, prepare := inputs(s, index); prepare != nil {
+			sj.Spec.Prepare = prepare
+		}
+		sj.Spec.Batch = wlmcontroller.WithBatchDirective(sj.Spec.Batch, directive)
+		obj = sj
+	} else {
+		kind = wlmcontroller.KindWlmJob
+		obj = &wlmv1alpha1.WlmJob{ObjectMeta: meta, Spec: *s.WlmJob.DeepCopy()}
+	}
+	err := controllerutil.SetControllerReference(wf, obj, r.scheme)
+	if err != nil {
+		glog.Errorf("Could not set controller reference for %s: %v", kind, err)
+		return st, err
+	}
+
+	glog.Infof("Creating %s %q for step %q of slurm workflow %q", kind, meta.Name, s.Name, wf.Name)
+	err = r.client.Create(context.Background(), obj)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		glog.Errorf("Could not create %s: %v", kind, err)
+		r.recorder.Eventf(wf, corev1.EventTypeWarning, EventFailedCreate, "Could not create %s %s: %v", kind, meta.Name, err)
+		return st, err
+	}
+	if err == nil {
+		r.recorder.Eventf(wf, corev1.EventTypeNormal, EventSuccessfulCreate, "Created %s %s", kind, meta.Name)
+	}
+	st.Phase = wlmv1alpha1.StepPending
+	st.Job = meta.Name
+	if directive != "" {
+		st.Message = "Waiting for Slurm dependencies"
+	}
+	return st, nil
+}
+
+// recordStepEvent records an event when the workflow step is finished.
+func (r *Reconciler) recordStepEvent(wf *wlmv1alpha1.SlurmWorkflow, old, cur wlmv1alpha1.WorkflowStepStatus) {
+	if old.Phase == cur.Phase {
+		return
+	}
+	switch cur.Phase {
+	case wlmv1alpha1.StepSucceeded:
+		r.recorder.Eventf(wf, corev1.EventTypeNormal, EventStepSucceeded, "Step %s succeeded", cur.Name)
+	case wlmv1alpha1.StepFailed:
+		msg := fmt.Sprintf("Step %s failed", cur.Name)
+		if cur.Message != "" {
+			msg += ": " + cur.Message
+		}
+		r.recorder.Event(wf, corev1.EventTypeWarning, EventStepFailed, msg)
+	case wlmv1alpha1.StepSkipped:
+		r.recorder.Eventf(wf, corev1.EventTypeWarning, EventStepSkipped, "Step %s skipped: %s", cur.Name, cur.Message)
+	}
+}
+
+// updateStatus updates SlurmWorkflow status.
+func (r *Reconciler) updateStatus(wf *wlmv1alpha1.SlurmWorkflow) error {
+	err := r.client.Status().Update(context.Background(), wf)
+	if err != nil {
+		glog.Errorf("Could not update slurm workflow: %v", err)
+	}
+	return err
+}
+
+// jobName returns name of the job created for the workflow step.
+func jobName(wf *wlmv1alpha1.SlurmWorkflow, s *wlmv1alpha1.WorkflowStep) string {
+	return wf.Name + "-" + s.Name
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurmworkflow
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var testScheme = runtime.NewScheme()
+
+func TestMain(m *testing.M) {
+	if err := wlmv1alpha1.AddToScheme(testScheme); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+var wfKey = types.NamespacedName{Namespace: "default", Name: "wf"}
+
+func newWorkflow() *wlmv1alpha1.SlurmWorkflow {
+	return &wlmv1alpha1.SlurmWorkflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "wf", Namespace: "default", UID: "wf-uid"},
+		Spec: wlmv1alpha1.SlurmWorkflowSpec{
+			Steps: []wlmv1alpha1.WorkflowStep{slurmStep("a"), slurmStep("b", afterOK("a"))},
+		},
+	}
+}
+
+func reconcileWorkflow(t *testing.T, r *Reconciler, c client.Client) *wlmv1alpha1.SlurmWorkflow {
+	_, err := r.Reconcile(reconcile.Request{NamespacedName: wfKey})
+	require.NoError(t, err)
+	wf := &wlmv1alpha1.SlurmWorkflow{}
+	require.NoError(t, c.Get(context.Background(), wfKey, wf))
+	return wf
+}
+
+func getJob(c client.Client, name string) (*wlmv1alpha1.SlurmJob, error) {
+	sj := &wlmv1alpha1.SlurmJob{}
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: name}, sj)
+	return sj, err
+}
+
+func TestReconciler_Reconcile_dependency(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(testScheme, newWorkflow())
+	rec := record.NewFakeRecorder(10)
+	r := &Reconciler{client: c, scheme: testScheme, recorder: rec}
+
+	// only the step without dependencies is created at first
+	wf := reconcileWorkflow(t, r, c)
+	require.Equal(t, wlmv1alpha1.WorkflowRunning, wf.Status.Phase)
+	require.Equal(t, wlmv1alpha1.StepPending, wf.Status.Steps[0].Phase)
+	require.Equal(t, wlmv1alpha1.StepWaiting, wf.Status.Steps[1].Phase)
+	require.Equal(t, "Normal SuccessfulCreate Created SlurmJob wf-a", <-rec.Events)
+	require.Empty(t, rec.Events)
+	a, err := getJob(c, "wf-a")
+	require.NoError(t, err)
+	require.Equal(t, types.UID("wf-uid"), metav1.GetControllerOf(a).UID)
+	_, err = getJob(c, "wf-b")
+	require.True(t, errors.IsNotFound(err), err)
+
+	// once the dependency is submitted, the step is queued with a Slurm dependency
+	a.Status.JobID = "12"
+	require.NoError(t, c.Update(context.Background(), a))
+	wf = reconcileWorkflow(t, r, c)
+	require.Equal(t, wlmv1alpha1.StepPending, wf.Status.Steps[1].Phase)
+	require.Equal(t, "wf-b", wf.Status.Steps[1].Job)
+	require.Equal(t, "Waiting for Slurm dependencies", wf.Status.Steps[1].Message)
+	require.Equal(t, "Normal SuccessfulCreate Created SlurmJob wf-b", <-rec.Events)
+	b, err := getJob(c, "wf-b")
+	require.NoError(t, err)
+	require.Contains(t, b.Spec.Batch, "#SBATCH --dependency=afterok:12\n")
+}
+
+func TestReconciler_Reconcile_skip(t *testing.T) {
+	wf := newWorkflow()
+	ref := metav1.NewControllerRef(wf, wlmv1alpha1.SchemeGroupVersion.WithKind("SlurmWorkflow"))
+	a := &wlmv1alpha1.SlurmJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "wf-a", Namespace: "default", OwnerReferences: []metav1.OwnerReference{*ref}},
+	}
+	a.Status.JobID = "12"
+	a.Status.Conditions = []wlmv1alpha1.JobCondition{{
+		Type:               wlmv1alpha1.JobFailed,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+	}}
+	b := &wlmv1alpha1.SlurmJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "wf-b", Namespace: "default", OwnerReferences: []metav1.OwnerReference{*ref}},
+	}
+	b.Status.JobID = "13"
+	c := fake.NewFakeClientWithScheme(testScheme, wf, a, b)
+	rec := record.NewFakeRecorder(10)
+	r := &Reconciler{client: c, scheme: testScheme, recorder: rec}
+
+	// job queued with a Slurm dependency that can't be satisfied is deleted
+	wf = reconcileWorkflow(t, r, c)
+	require.Equal(t, wlmv1alpha1.WorkflowFailed, wf.Status.Phase)
+	require.Equal(t, wlmv1alpha1.StepFailed, wf.Status.Steps[0].Phase)
+	require.Equal(t, wlmv1alpha1.StepSkipped, wf.Status.Steps[1].Phase)
+	require.Equal(t, "Dependency on step a can't be satisfied", wf.Status.Steps[1].Message)
+	_, err := getJob(c, "wf-a")
+	require.NoError(t, err)
+	_, err = getJob(c, "wf-b")
+	require.True(t, errors.IsNotFound(err), err)
+
+	require.Equal(t, "Warning StepFailed Step a failed", <-rec.Events)
+	require.Equal(t, "Normal SuccessfulDelete Deleted SlurmJob wf-b", <-rec.Events)
+	require.Equal(t, "Warning StepSkipped Step b skipped: Dependency on step a can't be satisfied", <-rec.Events)
+	require.Equal(t, "Warning Failed Some steps failed or were skipped", <-rec.Events)
+	require.Empty(t, rec.Events)
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// JobIDAnnotation is set by virtual kubelet on a job pod once the job
+// is submitted to a workload manager and holds the submitted job id.
+// When it is not set, the job is looked up in red-box job history by
+// client id, which is expected to be the job pod UID.
+const JobIDAnnotation = "wlm.sylabs.io/job-id"
+
+// StatusPollInterval is an interval job status is polled from red-box
+// at while the job is not finished.
+const StatusPollInterval = 30 * time.Second
+
+// failedStates are workload manager job states that have no matching
+// api.JobStatus, but mean the job has failed.
+var failedStates = map[string]bool{
+	"NODE_FAIL":     true,
+	"PREEMPTED":     true,
+	"BOOT_FAIL":     true,
+	"OUT_OF_MEMORY": true,
+	"DEADLINE":      true,
+}
+
+// UpdateJobStatus fills job status from the job pod and, when wlm is not nil, from
+// the job info reported by red-box. Collect is an optional results collection pod.
+// UpdateJobStatus returns true when job is finished and its status will not change anymore.
+func UpdateJobStatus(ctx context.Context, wlm api.WorkloadManagerClient,
+	pod, collect *corev1.Pod, status *v1alpha1.JobStatus) (bool, error) {
+	now := metav1.Now()
+
+	if id := PodJobID(ctx, wlm, pod); id != "" {
+		status.JobID = id
+	}
+
+	switch {
+	case status.JobID != "":
+		SetJobCondition(status, v1alpha1.JobSubmitted, corev1.ConditionTrue, "", "", now)
+	case wlm != nil && pod.Status.Phase != corev1.PodPending && pod.Status.Phase != "":
+		// job is running or finished, but red-box can't tell anything about it
+		SetJobCondition(status, v1alpha1.JobSubmitted, corev1.ConditionUnknown, reasonJobIDMissing,
+			fmt.Sprintf("Job pod has no %s annotation and no job is recorded for it in red-box job history, "+
+				"job status is derived from the job pod", JobIDAnnotation), now)
+	default:
+		if c := podCondition(pod, corev1.PodScheduled); c != nil &&
+			c.Status == corev1.ConditionFalse && c.Reason == corev1.PodReasonUnschedulable {
+			SetJobCondition(status, v1alpha1.JobSubmitted, corev1.ConditionFalse, reasonUnschedulable, c.Message, now)
+		}
+	}
+
+	finished := false
+	if wlm != nil && status.JobID != "" {
+		id, err := strconv.ParseInt(status.JobID, 10, 64)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid job id %q", status.JobID)
+		}
+		resp, err := wlm.JobInfo(ctx, &api.JobInfoRequest{JobId: id})
+		if err != nil {
+			return false, errors.Wrapf(err, "could not get job %d info", id)
+		}
+		switch {
+		case isArray(resp.Info):
+			finished = arrayStatusFromInfo(status, resp.Info, now)
+		case len(resp.Info) != 0:
+			finished = statusFromInfo(status, resp.Info[0], now)
+		}
+	} else {
+		finished = statusFromPod(status, pod, now)
+	}
+
+	if collect != nil {
+		switch collect.Status.Phase {
+		case corev1.PodSucceeded:
+			SetJobCondition(status, v1alpha1.JobResultsCollected, corev1.ConditionTrue, "", "", now)
+		case corev1.PodFailed:
+			SetJobCondition(status, v1alpha1.JobResultsCollected, corev1.ConditionFalse,
+				reasonCollectionFailed, collect.Status.Message, now)
+		default:
+			SetJobCondition(status, v1alpha1.JobResultsCollected, corev1.ConditionFalse, reasonCollecting, "", now)
+		}
+	}
+	return finished, nil
+}
+
+// PodJobID returns id of the job submitted for the job pod. It is taken from
+// JobIDAnnotation, or, when wlm is not nil, from the latest job submitted with
+// the pod UID as client id. Empty string is returned if the job is not known.
+// Job history is optional in red-box, so its errors are treated as no job.
+func PodJobID(ctx context.Context, wlm api.WorkloadManagerClient, pod *corev1.Pod) string {
+	if id, ok := pod.Annotations[JobIDAnnotation]; ok {
+		return id
+	}
+	if wlm == nil || pod.Spec.NodeName == "" {
+		return ""
+	}
+
+	resp, err := wlm.JobHistory(ctx, &api.JobHistoryRequest{ClientId: string(pod.UID)})
+	if err != nil || len(resp.Jobs) == 0 {
+		return ""
+	}
+	return strconv.FormatInt(resp.Jobs[len(resp.Jobs)-1].JobId, 10)
+}
+
+func podCondition(pod *corev1.Pod, t corev1.PodConditionType) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == t {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// CollectPod returns results collection pod virtual kubelet created for the given
+// job pod, or nil if there is no such pod.
+func CollectPod(ctx context.Context, c client.Client, pod *corev1.Pod) (*corev1.Pod, error) {
+	collect := &corev1.Pod{}
+	key := types.NamespacedName{Name: pod.Name + "-collect", Namespace: pod.Namespace}
+	err := c.Get(ctx, key, collect)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get results collection pod")
+	}
+	return collect, nil
+}
+
+func statusFromInfo(status *v1alpha1.JobStatus, info *api.JobInfo, now metav1.Time) bool {
+	status.Cluster = ""
+	status.Partition = info.Partition
+	if i := strings.Index(info.Partition, "/"); i != -1 {
+		status.Cluster = info.Partition[:i]
+		status.Partition = info.Partition[i+1:]
+	}
+	status.State = info.State
+	if status.State == "" {
+		status.State = info.Status.String()
+	}
+	status.Reason = info.Reason
+	status.SubmitTime = protoTime(info.SubmitTime)
+	status.StartTime = protoTime(info.StartTime)
+	status.EndTime = protoTime(info.EndTime)
+	status.ExitCode = info.ExitCode
+	status.NodeList = info.NodeList
+
+	switch info.Status {
+	case api.JobStatus_COMPLETED:
+		setFinished(status, true, info.Status.String(), now)
+		return true
+	case api.JobStatus_CANCELLED, api.JobStatus_FAILED, api.JobStatus_TIMEOUT:
+		setFinished(status, false, info.Status.String(), now)
+		return true
+	case api.JobStatus_PENDING:
+		SetJobCondition(status, v1alpha1.JobRunning, corev1.ConditionFalse, info.Reason, "", now)
+	default:
+		if failedStates[info.State] {
+			setFinished(status, false, info.State, now)
+			return true
+		}
+		if info.State == stateSuspended {
+			SetJobCondition(status, v1alpha1.JobRunning, corev1.ConditionFalse, info.State, "", now)
+			break
+		}
+		if status.StartTime != nil {
+			SetJobCondition(status, v1alpha1.JobRunning, corev1.ConditionTrue, "", "", now)
+		}
+	}
+	return false
+}
+
+func statusFromPod(status *v1alpha1.JobStatus, pod *corev1.Pod, now metav1.Time) bool {
+	status.State = strings.ToUpper(string(pod.Status.Phase))
+	status.Reason = pod.Status.Reason
+	status.StartTime = pod.Status.StartTime
+	for _, cs := range pod.Status.ContainerStatuses {
+		if t := cs.State.Terminated; t != nil {
+			status.ExitCode = strconv.Itoa(int(t.ExitCode))
+			status.EndTime = t.FinishedAt.DeepCopy()
+		}
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodRunning:
+		SetJobCondition(status, v1alpha1.JobRunning, corev1.ConditionTrue, "", "", now)
+	case corev1.PodSucceeded:
+		setFinished(status, true, pod.Status.Reason, now)
+		return true
+	case corev1.PodFailed:
+		setFinished(status, false, pod.Status.Reason, now)
+		return true
+	}
+	return false
+}
+
+func setFinished(status *v1alpha1.JobStatus, succeeded bool, reason string, now metav1.Time) {
+	SetJobCondition(status, v1alpha1.JobRunning, corev1.ConditionFalse, reason, "", now)
+	if succeeded {
+		SetJobCondition(status, v1alpha1.JobSucceeded, corev1.ConditionTrue, reason, "", now)
+		return
+	}
+	SetJobCondition(status, v1alpha1.JobFailed, corev1.ConditionTrue, reason, "", now)
+}
+
+// SetJobCondition sets condition of the given type. Transition time is
+// updated only when condition status changes.
+func SetJobCondition(status *v1alpha1.JobStatus, t v1alpha1.JobConditionType,
+	s corev1.ConditionStatus, reason, message string, now metav1.Time) {
+	for i := range status.Conditions {
+		c := &status.Conditions[i]
+		if c.Type != t {
+			continue
+		}
+		if c.Status != s {
+			c.LastTransitionTime = now
+		}
+		c.Status = s
+		c.Reason = reason
+		c.Message = message
+		return
+	}
+	status.Conditions = append(status.Conditions, v1alpha1.JobCondition{
+		Type:               t,
+		Status:             s,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// JobCondition returns condition of the given type or nil if there is no such condition.
+func JobCondition(status *v1alpha1.JobStatus, t v1alpha1.JobConditionType) *v1alpha1.JobCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == t {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func protoTime(ts *timestamp.Timestamp) *metav1.Time {
+	if ts == nil {
+		return nil
+	}
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return nil
+	}
+	mt := metav1.NewTime(t)
+	return &mt
+}
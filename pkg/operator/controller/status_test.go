@@ -0,0 +1,245 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeWLM struct {
+	api.WorkloadManagerClient
+	info *api.JobInfo
+	// tasks are reported instead of info for job arrays
+	tasks     []*api.JobInfo
+	cancelled []int64
+	// controlled records hold, release, suspend, resume and requeue calls
+	controlled []string
+	// controlErr fails suspend and resume calls
+	controlErr error
+	// history is reported for job history requests, red-box without
+	// state is simulated if it is nil
+	history map[string][]*api.JobRecord
+}
+
+func (f *fakeWLM) JobInfo(_ context.Context, r *api.JobInfoRequest, _ ...grpc.CallOption) (*api.JobInfoResponse, error) {
+	if f.tasks != nil {
+		return &api.JobInfoResponse{Info: f.tasks}, nil
+	}
+	if f.info == nil {
+		return nil, status.Errorf(codes.NotFound, "no job %d", r.JobId)
+	}
+	return &api.JobInfoResponse{Info: []*api.JobInfo{f.info}}, nil
+}
+
+func (f *fakeWLM) JobHistory(_ context.Context, r *api.JobHistoryRequest, _ ...grpc.CallOption) (*api.JobHistoryResponse, error) {
+	if f.history == nil {
+		return nil, status.Error(codes.FailedPrecondition, "job history is not recorded without red-box state")
+	}
+	return &api.JobHistoryResponse{Jobs: f.history[r.ClientId]}, nil
+}
+
+func (f *fakeWLM) CancelJob(_ context.Context, r *api.CancelJobRequest, _ ...grpc.CallOption) (*api.CancelJobResponse, error) {
+	f.cancelled = append(f.cancelled, r.JobId)
+	return &api.CancelJobResponse{}, nil
+}
+
+func (f *fakeWLM) HoldJob(_ context.Context, r *api.HoldJobRequest, _ ...grpc.CallOption) (*api.HoldJobResponse, error) {
+	f.controlled = append(f.controlled, fmt.Sprintf("hold %d", r.JobId))
+	return &api.HoldJobResponse{}, nil
+}
+
+func (f *fakeWLM) ReleaseJob(_ context.Context, r *api.ReleaseJobRequest, _ ...grpc.CallOption) (*api.ReleaseJobResponse, error) {
+	f.controlled = append(f.controlled, fmt.Sprintf("release %d", r.JobId))
+	return &api.ReleaseJobResponse{}, nil
+}
+
+func (f *fakeWLM) SuspendJob(_ context.Context, r *api.SuspendJobRequest, _ ...grpc.CallOption) (*api.SuspendJobResponse, error) {
+	if f.controlErr != nil {
+		return nil, f.controlErr
+	}
+	f.controlled = append(f.controlled, fmt.Sprintf("suspend %d", r.JobId))
+	return &api.SuspendJobResponse{}, nil
+}
+
+func (f *fakeWLM) ResumeJob(_ context.Context, r *api.ResumeJobRequest, _ ...grpc.CallOption) (*api.ResumeJobResponse, error) {
+	if f.controlErr != nil {
+		return nil, f.controlErr
+	}
+	f.controlled = append(f.controlled, fmt.Sprintf("resume %d", r.JobId))
+	return &api.ResumeJobResponse{}, nil
+}
+
+func (f *fakeWLM) RequeueJob(_ context.Context, r *api.RequeueJobRequest, _ ...grpc.CallOption) (*api.RequeueJobResponse, error) {
+	if r.ArrayTaskId != "" {
+		f.controlled = append(f.controlled, fmt.Sprintf("requeue %d_%s", r.JobId, r.ArrayTaskId))
+		return &api.RequeueJobResponse{}, nil
+	}
+	f.controlled = append(f.controlled, fmt.Sprintf("requeue %d hold=%t", r.JobId, r.Hold))
+	return &api.RequeueJobResponse{}, nil
+}
+
+func TestUpdateJobStatus(t *testing.T) {
+	submitted := time.Now().Add(-time.Minute).Truncate(time.Second)
+	submitTime, err := ptypes.TimestampProto(submitted)
+	require.NoError(t, err)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-job",
+			Annotations: map[string]string{JobIDAnnotation: "42"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	wlm := &fakeWLM{info: &api.JobInfo{
+		Id:         "42",
+		Partition:  "east/debug",
+		Status:     api.JobStatus_PENDING,
+		State:      "PENDING",
+		Reason:     "Resources",
+		SubmitTime: submitTime,
+	}}
+
+	var status v1alpha1.JobStatus
+	finished, err := UpdateJobStatus(context.Background(), wlm, pod, nil, &status)
+	require.NoError(t, err)
+	require.False(t, finished)
+	require.Equal(t, "42", status.JobID)
+	require.Equal(t, "east", status.Cluster)
+	require.Equal(t, "debug", status.Partition)
+	require.Equal(t, "PENDING", status.State)
+	require.Equal(t, "Resources", status.Reason)
+	require.True(t, status.SubmitTime.Time.Equal(submitted))
+	require.Equal(t, corev1.ConditionTrue, JobCondition(&status, v1alpha1.JobSubmitted).Status)
+	require.Equal(t, corev1.ConditionFalse, JobCondition(&status, v1alpha1.JobRunning).Status)
+
+	wlm.info = &api.JobInfo{
+		Id:         "42",
+		Partition:  "debug",
+		Status:     api.JobStatus_FAILED,
+		State:      "FAILED",
+		ExitCode:   "1:0",
+		NodeList:   "node1",
+		SubmitTime: submitTime,
+		StartTime:  submitTime,
+		EndTime:    submitTime,
+	}
+	collect := &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}}
+	finished, err = UpdateJobStatus(context.Background(), wlm, pod, collect, &status)
+	require.NoError(t, err)
+	require.True(t, finished)
+	require.Empty(t, status.Cluster)
+	require.Equal(t, "FAILED", status.State)
+	require.Equal(t, "1:0", status.ExitCode)
+	require.Equal(t, "node1", status.NodeList)
+	require.NotNil(t, status.EndTime)
+	require.Equal(t, corev1.ConditionTrue, JobCondition(&status, v1alpha1.JobFailed).Status)
+	require.Equal(t, corev1.ConditionTrue, JobCondition(&status, v1alpha1.JobResultsCollected).Status)
+	require.Nil(t, JobCondition(&status, v1alpha1.JobSucceeded))
+}
+
+func TestUpdateJobStatus_pod(t *testing.T) {
+	finishedAt := metav1.NewTime(time.Now().Truncate(time.Second))
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{FinishedAt: finishedAt},
+				},
+			}},
+		},
+	}
+
+	var status v1alpha1.JobStatus
+	finished, err := UpdateJobStatus(context.Background(), nil, pod, nil, &status)
+	require.NoError(t, err)
+	require.True(t, finished)
+	require.Empty(t, status.JobID)
+	require.Equal(t, "SUCCEEDED", status.State)
+	require.Equal(t, "0", status.ExitCode)
+	require.True(t, status.EndTime.Equal(&finishedAt))
+	require.Nil(t, JobCondition(&status, v1alpha1.JobSubmitted))
+	require.Equal(t, corev1.ConditionTrue, JobCondition(&status, v1alpha1.JobSucceeded).Status)
+}
+
+func TestUpdateJobStatus_jobID(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job", UID: "uid"},
+		Spec:       corev1.PodSpec{NodeName: "slurm-debug"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	wlm := &fakeWLM{info: &api.JobInfo{Id: "43", Status: api.JobStatus_UNKNOWN, State: "RUNNING"}}
+
+	// no annotation and no job history
+	var status v1alpha1.JobStatus
+	_, err := UpdateJobStatus(context.Background(), wlm, pod, nil, &status)
+	require.NoError(t, err)
+	require.Empty(t, status.JobID)
+	require.Equal(t, "RUNNING", status.State)
+	c := JobCondition(&status, v1alpha1.JobSubmitted)
+	require.Equal(t, corev1.ConditionUnknown, c.Status)
+	require.Equal(t, reasonJobIDMissing, c.Reason)
+
+	// job is looked up by pod uid
+	wlm.history = map[string][]*api.JobRecord{"uid": {{JobId: 42}, {JobId: 43}}}
+	_, err = UpdateJobStatus(context.Background(), wlm, pod, nil, &status)
+	require.NoError(t, err)
+	require.Equal(t, "43", status.JobID)
+	require.Equal(t, corev1.ConditionTrue, JobCondition(&status, v1alpha1.JobSubmitted).Status)
+
+	// pending pod may be not submitted yet
+	pod.Status.Phase = corev1.PodPending
+	status = v1alpha1.JobStatus{}
+	wlm.history = nil
+	_, err = UpdateJobStatus(context.Background(), wlm, pod, nil, &status)
+	require.NoError(t, err)
+	require.Nil(t, JobCondition(&status, v1alpha1.JobSubmitted))
+}
+
+func TestUpdateJobStatus_nodeFail(t *testing.T) {
+	var st v1alpha1.JobStatus
+	finished := statusFromInfo(&st, &api.JobInfo{Status: api.JobStatus_UNKNOWN, State: "NODE_FAIL"}, metav1.Now())
+	require.True(t, finished)
+	require.Equal(t, corev1.ConditionTrue, JobCondition(&st, v1alpha1.JobFailed).Status)
+	require.True(t, isTerminal(&api.JobInfo{State: "NODE_FAIL"}))
+}
+
+func TestSetJobCondition(t *testing.T) {
+	var status v1alpha1.JobStatus
+	first := metav1.NewTime(time.Now().Add(-time.Minute))
+	SetJobCondition(&status, v1alpha1.JobRunning, corev1.ConditionTrue, "", "", first)
+	SetJobCondition(&status, v1alpha1.JobRunning, corev1.ConditionTrue, "foo", "", metav1.Now())
+	require.Len(t, status.Conditions, 1)
+	require.Equal(t, first, status.Conditions[0].LastTransitionTime)
+	require.Equal(t, "foo", status.Conditions[0].Reason)
+
+	second := metav1.Now()
+	SetJobCondition(&status, v1alpha1.JobRunning, corev1.ConditionFalse, "", "", second)
+	require.Len(t, status.Conditions, 1)
+	require.Equal(t, second, status.Conditions[0].LastTransitionTime)
+}
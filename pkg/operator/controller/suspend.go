@@ -0,0 +1,142 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/dptech-corp/wlm-operator/pkg/workload/api"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SuspendAnnotation may be set to "true" on a namespace to suspend
+// all jobs in it, e.g. during a maintenance window.
+const SuspendAnnotation = "wlm.sylabs.io/suspend"
+
+// Workload manager job states suspension depends on.
+const (
+	statePending   = "PENDING"
+	stateRunning   = "RUNNING"
+	stateSuspended = "SUSPENDED"
+)
+
+// Reasons of the Suspended condition.
+const (
+	reasonHeld      = "Held"
+	reasonSuspended = "Suspended"
+	reasonRequeued  = "Requeued"
+	reasonResumed   = "Resumed"
+
+	reasonSuspendFailed = "SuspendFailed"
+	reasonResumeFailed  = "ResumeFailed"
+)
+
+// NamespaceSuspended checks whether jobs in the namespace
+// are suspended with SuspendAnnotation.
+func NamespaceSuspended(ctx context.Context, c client.Client, namespace string) (bool, error) {
+	ns := &corev1.Namespace{}
+	err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not get namespace %s", namespace)
+	}
+	return ns.Annotations[SuspendAnnotation] == "true", nil
+}
+
+// SuspendJob brings workload manager job in line with the requested suspension.
+// Pending job is held, running job is suspended or requeued and held depending on mode.
+// Once suspension is no longer requested, jobs suspended this way are released or resumed.
+// The Suspended condition reflects the outcome, failures are recorded in it as well.
+// Running jobs are requeued unless mode is SuspendModeSuspend, as Slurm allows suspending
+// jobs to operators and administrators only. SuspendJob expects job status to be freshly
+// updated and the job to be not finished.
+func SuspendJob(ctx context.Context, wlm api.WorkloadManagerClient, st *v1alpha1.JobStatus,
+	suspend bool, mode v1alpha1.SuspendMode) error {
+	if st.JobID == "" {
+		return nil
+	}
+	id, err := strconv.ParseInt(st.JobID, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid job id %q", st.JobID)
+	}
+
+	now := metav1.Now()
+	var reason string
+	c := JobCondition(st, v1alpha1.JobSuspended)
+	suspended := c != nil && c.Status == corev1.ConditionTrue
+	if suspended {
+		reason = c.Reason
+	}
+	held := strings.HasPrefix(st.Reason, "JobHeld")
+
+	if !suspend {
+		if !suspended {
+			return nil
+		}
+		switch {
+		case st.State == stateSuspended:
+			_, err = wlm.ResumeJob(ctx, &api.ResumeJobRequest{JobId: id})
+		case st.State == statePending && held:
+			_, err = wlm.ReleaseJob(ctx, &api.ReleaseJobRequest{JobId: id})
+		}
+		if err != nil {
+			err = errors.Wrapf(err, "could not resume job %d", id)
+			SetJobCondition(st, v1alpha1.JobSuspended, corev1.ConditionTrue, reasonResumeFailed, err.Error(), now)
+			return err
+		}
+		SetJobCondition(st, v1alpha1.JobSuspended, corev1.ConditionFalse, reasonResumed, "", now)
+		return nil
+	}
+
+	switch st.State {
+	case stateSuspended:
+		reason = reasonSuspended
+	case statePending:
+		if !held {
+			_, err = wlm.HoldJob(ctx, &api.HoldJobRequest{JobId: id})
+		}
+		if reason == "" || reason == reasonResumeFailed {
+			reason = reasonHeld
+		}
+	case stateRunning:
+		// job info may be taken before the job was suspended
+		if suspended {
+			return nil
+		}
+		if mode == v1alpha1.SuspendModeSuspend {
+			_, err = wlm.SuspendJob(ctx, &api.SuspendJobRequest{JobId: id})
+			reason = reasonSuspended
+		} else {
+			_, err = wlm.RequeueJob(ctx, &api.RequeueJobRequest{JobId: id, Hold: true})
+			reason = reasonRequeued
+		}
+	default:
+		// job is in transition, e.g. configuring or completing
+		return nil
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "could not suspend job %d", id)
+		SetJobCondition(st, v1alpha1.JobSuspended, corev1.ConditionFalse, reasonSuspendFailed, err.Error(), now)
+		return err
+	}
+	SetJobCondition(st, v1alpha1.JobSuspended, corev1.ConditionTrue, reason, "", now)
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSuspendJob(t *testing.T) {
+	wlm := &fakeWLM{}
+	suspended := func(st *v1alpha1.JobStatus) (corev1.ConditionStatus, string) {
+		c := JobCondition(st, v1alpha1.JobSuspended)
+		if c == nil {
+			return "", ""
+		}
+		return c.Status, c.Reason
+	}
+
+	// nothing to do until job is submitted or suspension is requested
+	st := &v1alpha1.JobStatus{}
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, true, ""))
+	st = &v1alpha1.JobStatus{JobID: "42", State: "RUNNING"}
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, false, ""))
+	require.Empty(t, wlm.controlled)
+	require.Nil(t, JobCondition(st, v1alpha1.JobSuspended))
+
+	// pending job is held and released
+	st = &v1alpha1.JobStatus{JobID: "42", State: "PENDING", Reason: "Resources"}
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, true, v1alpha1.SuspendModeRequeue))
+	s, reason := suspended(st)
+	require.Equal(t, corev1.ConditionTrue, s)
+	require.Equal(t, reasonHeld, reason)
+	st.Reason = "JobHeldUser"
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, true, v1alpha1.SuspendModeRequeue))
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, false, v1alpha1.SuspendModeRequeue))
+	s, reason = suspended(st)
+	require.Equal(t, corev1.ConditionFalse, s)
+	require.Equal(t, reasonResumed, reason)
+	require.Equal(t, []string{"hold 42", "release 42"}, wlm.controlled)
+
+	// running job is suspended and resumed in suspend mode
+	wlm.controlled = nil
+	st = &v1alpha1.JobStatus{JobID: "43", State: "RUNNING"}
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, true, v1alpha1.SuspendModeSuspend))
+	// stale job info doesn't suspend the job twice
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, true, v1alpha1.SuspendModeSuspend))
+	st.State = "SUSPENDED"
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, true, v1alpha1.SuspendModeSuspend))
+	s, reason = suspended(st)
+	require.Equal(t, corev1.ConditionTrue, s)
+	require.Equal(t, reasonSuspended, reason)
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, false, v1alpha1.SuspendModeSuspend))
+	require.Equal(t, []string{"suspend 43", "resume 43"}, wlm.controlled)
+
+	// running job is requeued and held by default
+	wlm.controlled = nil
+	st = &v1alpha1.JobStatus{JobID: "44", State: "RUNNING"}
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, true, ""))
+	st.State, st.Reason = "PENDING", "JobHeldUser"
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, true, ""))
+	s, reason = suspended(st)
+	require.Equal(t, corev1.ConditionTrue, s)
+	require.Equal(t, reasonRequeued, reason)
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, false, ""))
+	require.Equal(t, []string{"requeue 44 hold=true", "release 44"}, wlm.controlled)
+
+	// failures are recorded in the condition
+	wlm.controlled = nil
+	wlm.controlErr = status.Error(codes.PermissionDenied, "Access/permission denied")
+	st = &v1alpha1.JobStatus{JobID: "45", State: "RUNNING"}
+	require.Error(t, SuspendJob(context.Background(), wlm, st, true, v1alpha1.SuspendModeSuspend))
+	s, reason = suspended(st)
+	require.Equal(t, corev1.ConditionFalse, s)
+	require.Equal(t, reasonSuspendFailed, reason)
+	require.Contains(t, JobCondition(st, v1alpha1.JobSuspended).Message, "permission denied")
+
+	wlm.controlErr = nil
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, true, v1alpha1.SuspendModeSuspend))
+	st.State = "SUSPENDED"
+	wlm.controlErr = status.Error(codes.PermissionDenied, "Access/permission denied")
+	require.Error(t, SuspendJob(context.Background(), wlm, st, false, v1alpha1.SuspendModeSuspend))
+	s, reason = suspended(st)
+	require.Equal(t, corev1.ConditionTrue, s)
+	require.Equal(t, reasonResumeFailed, reason)
+	wlm.controlErr = nil
+	require.NoError(t, SuspendJob(context.Background(), wlm, st, false, v1alpha1.SuspendModeSuspend))
+	s, reason = suspended(st)
+	require.Equal(t, corev1.ConditionFalse, s)
+	require.Equal(t, reasonResumed, reason)
+	require.Equal(t, []string{"suspend 45", "resume 45"}, wlm.controlled)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+// TTLDefaults holds time finished jobs are kept for by namespace, it is used
+// for jobs that don't set ttlSecondsAfterFinished. Default for all namespaces
+// is stored with an empty key.
+type TTLDefaults map[string]time.Duration
+
+// String implements flag.Value.
+func (d TTLDefaults) String() string {
+	var values []string
+	for ns, ttl := range d {
+		if ns == "" {
+			values = append(values, ttl.String())
+			continue
+		}
+		values = append(values, fmt.Sprintf("%s=%s", ns, ttl))
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
+// Set implements flag.Value. Value is either a duration that applies to all
+// namespaces, e.g. 24h, or a namespace default, e.g. dev=1h.
+func (d TTLDefaults) Set(v string) error {
+	ns, value := "", v
+	if i := strings.Index(v, "="); i != -1 {
+		ns, value = v[:i], v[i+1:]
+		if ns == "" {
+			return errors.Errorf("empty namespace in %q", v)
+		}
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid ttl %q", v)
+	}
+	if ttl < 0 {
+		return errors.Errorf("negative ttl %q", v)
+	}
+	d[ns] = ttl
+	return nil
+}
+
+// TTL returns time the finished job in the given namespace should be kept for.
+// False is returned when job should be kept forever.
+func (d TTLDefaults) TTL(namespace string, ttlSeconds *int32) (time.Duration, bool) {
+	if ttlSeconds != nil {
+		return time.Duration(*ttlSeconds) * time.Second, true
+	}
+	if ttl, ok := d[namespace]; ok {
+		return ttl, true
+	}
+	ttl, ok := d[""]
+	return ttl, ok
+}
+
+// FinishedAt returns time the job was finished at. False is returned when
+// job is not finished yet, results of the succeeded job are not collected yet
+// or job is about to be retried. Results tells whether job results are collected.
+func FinishedAt(st *v1alpha1.JobStatus, p *v1alpha1.RetryPolicy, results bool, now time.Time) (time.Time, bool) {
+	c := finishedCondition(st)
+	if c == nil {
+		return time.Time{}, false
+	}
+	r := JobCondition(st, v1alpha1.JobResultsCollected)
+	if r != nil && r.Reason == reasonCollecting {
+		return time.Time{}, false
+	}
+	if results && r == nil && c.Type == v1alpha1.JobSucceeded {
+		return time.Time{}, false
+	}
+	if r != nil && r.LastTransitionTime.After(c.LastTransitionTime.Time) {
+		c = r
+	}
+	if retry, _ := NextRetry(p, st, now); retry {
+		return time.Time{}, false
+	}
+	return c.LastTransitionTime.Time, true
+}
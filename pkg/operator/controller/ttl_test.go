@@ -0,0 +1,83 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTTLDefaults(t *testing.T) {
+	d := TTLDefaults{}
+	_, ok := d.TTL("dev", nil)
+	require.False(t, ok)
+
+	require.NoError(t, d.Set("24h"))
+	require.NoError(t, d.Set("dev=1h"))
+	require.Error(t, d.Set("=1h"))
+	require.Error(t, d.Set("dev=foo"))
+	require.Error(t, d.Set("-1h"))
+	require.Equal(t, "24h0m0s,dev=1h0m0s", d.String())
+
+	ttl, ok := d.TTL("dev", nil)
+	require.True(t, ok)
+	require.Equal(t, time.Hour, ttl)
+	ttl, ok = d.TTL("prod", nil)
+	require.True(t, ok)
+	require.Equal(t, 24*time.Hour, ttl)
+	ttl, ok = d.TTL("dev", &[]int32{60}[0])
+	require.True(t, ok)
+	require.Equal(t, time.Minute, ttl)
+}
+
+func TestFinishedAt(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	finished := metav1.NewTime(now.Add(-time.Hour))
+	collected := metav1.NewTime(now.Add(-time.Minute))
+
+	var st v1alpha1.JobStatus
+	_, ok := FinishedAt(&st, nil, false, now)
+	require.False(t, ok)
+
+	SetJobCondition(&st, v1alpha1.JobSucceeded, corev1.ConditionTrue, "", "", finished)
+	at, ok := FinishedAt(&st, nil, false, now)
+	require.True(t, ok)
+	require.Equal(t, finished.Time, at)
+
+	// results are not collected yet
+	_, ok = FinishedAt(&st, nil, true, now)
+	require.False(t, ok)
+	SetJobCondition(&st, v1alpha1.JobResultsCollected, corev1.ConditionFalse, reasonCollecting, "", finished)
+	_, ok = FinishedAt(&st, nil, true, now)
+	require.False(t, ok)
+	SetJobCondition(&st, v1alpha1.JobResultsCollected, corev1.ConditionTrue, "", "", collected)
+	at, ok = FinishedAt(&st, nil, true, now)
+	require.True(t, ok)
+	require.Equal(t, collected.Time, at)
+
+	// failed job is about to be retried
+	failed := v1alpha1.JobStatus{State: "NODE_FAIL"}
+	SetJobCondition(&failed, v1alpha1.JobFailed, corev1.ConditionTrue, "", "", finished)
+	_, ok = FinishedAt(&failed, &v1alpha1.RetryPolicy{BackoffLimit: 1}, true, now)
+	require.False(t, ok)
+	at, ok = FinishedAt(&failed, nil, true, now)
+	require.True(t, ok)
+	require.Equal(t, finished.Time, at)
+}
@@ -70,5 +70,5 @@ func nodeSelectorForWj(wj *wlmv1alpha1.WlmJob) map[string]string {
 	for k, v := range wj.Spec.NodeSelector {
 		nodeSelector[k] = v
 	}
-	return nodeSelector
+	return controller.RetryNodeSelector(wj.Spec.RetryPolicy, len(wj.Status.Attempts), nodeSelector)
 }
@@ -17,6 +17,7 @@ package wlmjob
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/golang/glog"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -25,32 +26,39 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	wlmv1alpha1 "github.com/dptech-corp/wlm-operator/pkg/operator/apis/wlm/v1alpha1"
+	wlmcontroller "github.com/dptech-corp/wlm-operator/pkg/operator/controller"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 // Reconciler reconciles a WlmJob object.
 type Reconciler struct {
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+
+	cfg wlmcontroller.Config
 
 	jcUID int64
 	jcGID int64
 }
 
 // NewReconciler returns a new WlmJob controller.
-func NewReconciler(mgr manager.Manager) *Reconciler {
+func NewReconciler(mgr manager.Manager, cfg wlmcontroller.Config) *Reconciler {
 	r := &Reconciler{
-		client: mgr.GetClient(),
-		scheme: mgr.GetScheme(),
-		jcUID:  int64(os.Getuid()),
-		jcGID:  int64(os.Getgid()),
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetRecorder("wlmjob-controller"),
+		cfg:      cfg,
+		jcUID:    int64(os.Getuid()),
+		jcGID:    int64(os.Getgid()),
 	}
 	return r
 }
@@ -83,12 +91,17 @@ func (r *Reconciler) AddToManager(mgr manager.Manager) error {
 
 // Reconcile reads that state of the cluster for a WlmJob object and makes changes
 // based on the state read and what is in the WlmJob.Spec.
-func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+func (r *Reconciler) Reconcile(req reconcile.Request) (res reconcile.Result, err error) {
 	glog.Infof("Received reconcile request: %v", req)
+	defer func() {
+		if err != nil {
+			wlmcontroller.ObserveReconcileError(wlmcontroller.KindWlmJob, req.Namespace)
+		}
+	}()
 
 	// Fetch the WlmJob instance
 	wj := &wlmv1alpha1.WlmJob{}
-	err := r.client.Get(context.Background(), req.NamespacedName, wj)
+	err = r.client.Get(context.Background(), req.NamespacedName, wj)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -101,10 +114,26 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 		return reconcile.Result{}, err
 	}
 
+	if wj.DeletionTimestamp != nil {
+		return r.finalize(wj)
+	}
+	if r.cfg.WLM == nil {
+		wlmcontroller.RecordRedBoxRequired(r.recorder, wj, wj.Spec.Suspend, wj.Spec.RetryPolicy, nil)
+	}
+	if r.cfg.WLM != nil && !wlmcontroller.HasFinalizer(wj, wlmcontroller.CancelJobFinalizer) {
+		wlmcontroller.AddFinalizer(wj, wlmcontroller.CancelJobFinalizer)
+		err = r.client.Update(context.Background(), wj)
+		if err != nil {
+			glog.Errorf("Could not add finalizer to wlm job: %v", err)
+			return reconcile.Result{}, err
+		}
+	}
+
 	// Translate WlmJob to Pod
 	sjPod, err := r.newPodForWJ(wj)
 	if err != nil {
 		glog.Errorf("Could not translate wlm job into pod: %v", err)
+		r.recorder.Event(wj, corev1.EventTypeWarning, wlmcontroller.EventInvalidSpec, err.Error())
 		return reconcile.Result{}, err
 	}
 
@@ -120,7 +149,8 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 	key := types.NamespacedName{Name: sjPod.Name, Namespace: sjPod.Namespace}
 	err = r.client.Get(context.Background(), key, wjCurrentPod)
 	if err != nil && errors.IsNotFound(err) {
-		if wj.Status.Status != "" {
+		retrying := wj.Status.Status == wlmcontroller.RetryingStatus
+		if wj.Status.Status != "" && !retrying {
 			glog.Info("Pod will not be created, it was already created once")
 			return reconcile.Result{}, nil
 		}
@@ -131,16 +161,150 @@ func (r *Reconciler) Reconcile(req reconcile.Request) (reconcile.Result, error)
 			glog.Errorf("Could not create new pod: %v", err)
 			return reconcile.Result{}, err
 		}
+		r.recorder.Eventf(wj, corev1.EventTypeNormal, wlmcontroller.EventPodCreated, "Created pod %s", sjPod.Name)
+		if affinity := wlmcontroller.DescribeAffinity(sjPod.Spec.Affinity); affinity != "" {
+			r.recorder.Eventf(wj, corev1.EventTypeNormal, wlmcontroller.EventAffinityComputed,
+				"Pod requires nodes with %s", affinity)
+		}
+		if retrying {
+			wj.Status.Status = string(corev1.PodPending)
+			err = r.client.Status().Update(context.Background(), wj)
+			if err != nil {
+				glog.Errorf("Could not update wlm job: %v", err)
+				return reconcile.Result{}, err
+			}
+		}
 		return reconcile.Result{}, nil
 	}
+	if wjCurrentPod.DeletionTimestamp != nil {
+		glog.Infof("Waiting for pod %q to be deleted", wjCurrentPod.Name)
+		return reconcile.Result{}, nil
+	}
+	if wj.Status.Status == wlmcontroller.RetryingStatus {
+		return r.deletePod(wjCurrentPod)
+	}
 
 	glog.Infof("Updating wlm job %q", wj.Name)
 	// Otherwise smth has changed, need to update things
 	wj.Status.Status = string(wjCurrentPod.Status.Phase)
+	var collect *corev1.Pod
+	if wj.Spec.Results != nil {
+		collect, err = wlmcontroller.CollectPod(context.Background(), r.client, wjCurrentPod)
+		if err != nil {
+			glog.Errorf("Could not get results collection pod: %v", err)
+		}
+	}
+	oldStatus := wj.Status.JobStatus.DeepCopy()
+	finished, err := wlmcontroller.UpdateJobStatus(context.Background(), r.cfg.WLM, wjCurrentPod, collect, &wj.Status.JobStatus)
+	if err != nil {
+		glog.Errorf("Could not get wlm job status: %v", err)
+	}
+	if r.cfg.WLM != nil && err == nil && !finished {
+		r.suspend(wj)
+	}
+	wlmcontroller.RecordStatusEvents(r.recorder, wj, oldStatus, &wj.Status.JobStatus)
 	err = r.client.Status().Update(context.Background(), wj)
 	if err != nil {
 		glog.Errorf("Could not update wlm job: %v", err)
 		return reconcile.Result{}, err
 	}
+	wlmcontroller.ObserveJobStatus(wlmcontroller.KindWlmJob, wj.Namespace, oldStatus, &wj.Status.JobStatus, time.Now())
+	if retry, wait := wlmcontroller.NextRetry(wj.Spec.RetryPolicy, &wj.Status.JobStatus, time.Now()); retry {
+		if wait > 0 {
+			return reconcile.Result{RequeueAfter: wait}, nil
+		}
+		return r.retry(wj, wjCurrentPod)
+	}
+	if r.cfg.WLM != nil && !finished {
+		return reconcile.Result{RequeueAfter: wlmcontroller.StatusPollInterval}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// suspend holds, suspends or resumes workload manager job of the WlmJob
+// as requested by its spec or by the namespace annotation.
+func (r *Reconciler) suspend(wj *wlmv1alpha1.WlmJob) {
+	suspend := wj.Spec.Suspend
+	if !suspend {
+		var err error
+		suspend, err = wlmcontroller.NamespaceSuspended(context.Background(), r.client, wj.Namespace)
+		if err != nil {
+			glog.Errorf("Could not check namespace suspension: %v", err)
+		}
+	}
+	err := wlmcontroller.SuspendJob(context.Background(), r.cfg.WLM, &wj.Status.JobStatus, suspend, wj.Spec.SuspendMode)
+	if err != nil {
+		glog.Errorf("Could not suspend wlm job: %v", err)
+	}
+}
+
+// retry records the failed attempt of the WlmJob and replaces its pod
+// so that the job is submitted again.
+func (r *Reconciler) retry(wj *wlmv1alpha1.WlmJob, pod *corev1.Pod) (reconcile.Result, error) {
+	glog.Infof("Retrying wlm job %q after %s", wj.Name, wj.Status.State)
+	r.recorder.Eventf(wj, corev1.EventTypeWarning, wlmcontroller.EventRetrying,
+		"Retrying job %s after %s, attempt %d", wj.Status.JobID, wj.Status.State, len(wj.Status.Attempts)+2)
+	wlmcontroller.RecordAttempt(&wj.Status.JobStatus)
+	wj.Status.Status = wlmcontroller.RetryingStatus
+	err := r.client.Status().Update(context.Background(), wj)
+	if err != nil {
+		glog.Errorf("Could not update wlm job: %v", err)
+		return reconcile.Result{}, err
+	}
+	return r.deletePod(pod)
+}
+
+// deletePod deletes pod of the failed job attempt, new pod is created
+// once the deletion is observed.
+func (r *Reconciler) deletePod(pod *corev1.Pod) (reconcile.Result, error) {
+	err := r.client.Delete(context.Background(), pod)
+	if err != nil && !errors.IsNotFound(err) {
+		glog.Errorf("Could not delete pod: %v", err)
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// finalize cancels workload manager job of the deleted WlmJob and removes
+// the finalizer once the job is finished.
+func (r *Reconciler) finalize(wj *wlmv1alpha1.WlmJob) (reconcile.Result, error) {
+	if !wlmcontroller.HasFinalizer(wj, wlmcontroller.CancelJobFinalizer) {
+		return reconcile.Result{}, nil
+	}
+
+	if r.cfg.WLM != nil {
+		if wj.Status.JobID == "" {
+			// job may be submitted after the last status update
+			pod := &corev1.Pod{}
+			key := types.NamespacedName{Name: wj.Name + "-wlm-job", Namespace: wj.Namespace}
+			if err := r.client.Get(context.Background(), key, pod); err == nil {
+				wj.Status.JobID = wlmcontroller.PodJobID(context.Background(), r.cfg.WLM, pod)
+			}
+		}
+
+		glog.Infof("Cancelling wlm job %q", wj.Name)
+		cancelled := wlmcontroller.JobCondition(&wj.Status.JobStatus, wlmv1alpha1.JobCancelRequested) != nil
+		done, err := wlmcontroller.CancelJob(context.Background(), r.cfg.WLM, r.recorder, wj, &wj.Status.JobStatus, r.cfg.CancelTimeout)
+		if err != nil {
+			glog.Errorf("Could not cancel wlm job: %v", err)
+		}
+		if !done {
+			// job is cancelled once, so it must be recorded
+			if !cancelled && wlmcontroller.JobCondition(&wj.Status.JobStatus, wlmv1alpha1.JobCancelRequested) != nil {
+				if err := r.client.Status().Update(context.Background(), wj); err != nil {
+					glog.Errorf("Could not update wlm job: %v", err)
+					return reconcile.Result{}, err
+				}
+			}
+			return reconcile.Result{RequeueAfter: wlmcontroller.CancelPollInterval}, nil
+		}
+	}
+
+	wlmcontroller.RemoveFinalizer(wj, wlmcontroller.CancelJobFinalizer)
+	err := r.client.Update(context.Background(), wj)
+	if err != nil {
+		glog.Errorf("Could not remove finalizer from wlm job: %v", err)
+		return reconcile.Result{}, err
+	}
 	return reconcile.Result{}, nil
 }
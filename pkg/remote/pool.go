@@ -0,0 +1,320 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remote implements command execution and file operations
+// on a remote host over SSH, e.g. on a Slurm login node.
+package remote
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+type (
+	// Config describes how to connect to a remote host.
+	Config struct {
+		// Addr is a host:port pair of SSH server.
+		Addr string
+		// User to log in as.
+		User string
+		// KeyPath is a path to a private key used for authentication.
+		KeyPath string
+		// KnownHostsPath is a path to a known_hosts file used to verify
+		// host key. Host key is not verified if it is empty.
+		KnownHostsPath string
+		// PoolSize is a maximum number of simultaneously open connections.
+		PoolSize int
+		// Timeout is a connection timeout.
+		Timeout time.Duration
+	}
+
+	// Pool maintains a pool of SSH connections to a remote host.
+	// Connections are opened lazily and reused in round-robin manner,
+	// broken connections are dropped and reopened on demand.
+	// Each connection may serve multiple sessions simultaneously.
+	Pool struct {
+		addr   string
+		size   int
+		config *ssh.ClientConfig
+
+		mu    sync.Mutex
+		conns []*conn
+		next  int
+		// dialing is the number of pool slots reserved by connections being
+		// opened, dialed is signalled once any of them is open or failed
+		dialing int
+		dialed  *sync.Cond
+		closed  bool
+	}
+
+	conn struct {
+		client *ssh.Client
+
+		mu   sync.Mutex
+		sftp *sftp.Client
+	}
+)
+
+// NewPool creates a new connection pool with key-based authentication.
+func NewPool(cfg Config) (*Pool, error) {
+	key, err := ioutil.ReadFile(cfg.KeyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read private key")
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse private key")
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if cfg.KnownHostsPath != "" {
+		hostKeyCallback, err = knownhosts.New(cfg.KnownHostsPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read known hosts")
+		}
+	} else {
+		log.Printf("Host key of %s will not be verified", cfg.Addr)
+	}
+
+	size := cfg.PoolSize
+	if size < 1 {
+		size = 1
+	}
+
+	p := &Pool{
+		addr: cfg.Addr,
+		size: size,
+		config: &ssh.ClientConfig{
+			User:            cfg.User,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         cfg.Timeout,
+		},
+	}
+	p.dialed = sync.NewCond(&p.mu)
+	return p, nil
+}
+
+// Run executes a command on the remote host and returns its standard output.
+// Arguments are quoted, so they are passed to the command as is. When command fails
+// returned error contains its standard error output.
+func (p *Pool) Run(stdin io.Reader, name string, args ...string) ([]byte, error) {
+	cmd := make([]string, 0, len(args)+1)
+	for _, a := range append([]string{name}, args...) {
+		cmd = append(cmd, quote(a))
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := p.withSession(func(s *ssh.Session) error {
+		s.Stdin = stdin
+		s.Stdout = &stdout
+		s.Stderr = &stderr
+		return s.Run(strings.Join(cmd, " "))
+	})
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.Bytes(), errors.Wrap(err, msg)
+		}
+		return stdout.Bytes(), err
+	}
+	return stdout.Bytes(), nil
+}
+
+// LookPath checks whether a command is available on the remote host.
+func (p *Pool) LookPath(name string) error {
+	_, err := p.Run(nil, "command", "-v", name)
+	return err
+}
+
+// Close closes all open connections.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, c := range p.conns {
+		c.close()
+	}
+	p.conns = nil
+	p.closed = true
+	return nil
+}
+
+// withSession runs f within a new session. Session is opened on a pooled
+// connection, if that fails the connection is dropped and a fresh one is tried.
+func (p *Pool) withSession(f func(s *ssh.Session) error) error {
+	s, err := p.session()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	return f(s)
+}
+
+func (p *Pool) session() (*ssh.Session, error) {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var c *conn
+		c, err = p.get()
+		if err != nil {
+			return nil, err
+		}
+
+		var s *ssh.Session
+		s, err = c.client.NewSession()
+		if err == nil {
+			return s, nil
+		}
+		p.drop(c)
+	}
+	return nil, errors.Wrap(err, "could not open ssh session")
+}
+
+// sftp returns SFTP client working over a pooled connection.
+func (p *Pool) sftp() (*sftp.Client, error) {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var c *conn
+		c, err = p.get()
+		if err != nil {
+			return nil, err
+		}
+
+		var s *sftp.Client
+		s, err = c.sftpClient()
+		if err == nil {
+			return s, nil
+		}
+		p.drop(c)
+	}
+	return nil, errors.Wrap(err, "could not start sftp")
+}
+
+// get returns a connection from the pool, opening a new one if pool is not full yet.
+// Callers wait for connections being opened only when there is no open one to reuse.
+func (p *Pool) get() (*conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		if p.closed {
+			return nil, errors.New("connection pool is closed")
+		}
+		if len(p.conns)+p.dialing < p.size {
+			return p.dial()
+		}
+		if len(p.conns) != 0 {
+			c := p.conns[p.next%len(p.conns)]
+			p.next++
+			return c, nil
+		}
+		p.dialed.Wait()
+	}
+}
+
+// dial opens a new connection in a reserved pool slot, the mutex is released
+// while connecting so that a slow host doesn't block reuse of open connections.
+// Must be called with mutex held.
+func (p *Pool) dial() (*conn, error) {
+	p.dialing++
+	p.mu.Unlock()
+	client, err := ssh.Dial("tcp", p.addr, p.config)
+	p.mu.Lock()
+	p.dialing--
+	p.dialed.Broadcast()
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not connect to %s", p.addr)
+	}
+	if p.closed {
+		_ = client.Close()
+		return nil, errors.New("connection pool is closed")
+	}
+
+	c := &conn{client: client}
+	p.conns = append(p.conns, c)
+	go p.watch(c)
+	return c, nil
+}
+
+// watch drops connection from the pool once it is closed.
+func (p *Pool) watch(c *conn) {
+	if err := c.client.Wait(); err != nil {
+		log.Printf("Connection to %s closed: %v", p.addr, err)
+	}
+	p.drop(c)
+}
+
+func (p *Pool) drop(c *conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, pc := range p.conns {
+		if pc == c {
+			p.conns = append(p.conns[:i], p.conns[i+1:]...)
+			c.close()
+			return
+		}
+	}
+}
+
+func (c *conn) sftpClient() (*sftp.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sftp == nil {
+		s, err := sftp.NewClient(c.client)
+		if err != nil {
+			return nil, err
+		}
+		c.sftp = s
+	}
+	return c.sftp, nil
+}
+
+func (c *conn) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sftp != nil {
+		_ = c.sftp.Close()
+	}
+	_ = c.client.Close()
+}
+
+// quote quotes s for POSIX shell.
+func quote(s string) string {
+	if s != "" && strings.IndexFunc(s, needsQuoting) == -1 {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func needsQuoting(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_=/.,:@%+", r)
+}
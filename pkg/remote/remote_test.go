@@ -0,0 +1,369 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/files"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer is an in-process SSH server stand-in. It executes commands
+// with local shell and serves SFTP subsystem on the local file system.
+type testServer struct {
+	ln     net.Listener
+	config *ssh.ServerConfig
+
+	mu    sync.Mutex
+	conns []*ssh.ServerConn
+	// new connections are accepted, but never answered when stall is set
+	stall   bool
+	stalled []net.Conn
+}
+
+func newTestKey(t *testing.T) (*rsa.PrivateKey, ssh.Signer) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	return key, signer
+}
+
+// newTestServer starts SSH server that accepts the returned client key.
+func newTestServer(t *testing.T, dir string) (*testServer, string) {
+	_, hostSigner := newTestKey(t)
+	clientKey, clientSigner := newTestKey(t)
+
+	keyPath := filepath.Join(dir, "id_rsa")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(clientKey)})
+	require.NoError(t, ioutil.WriteFile(keyPath, keyPEM, 0600))
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), clientSigner.PublicKey().Marshal()) {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &testServer{ln: ln, config: config}
+	go s.serve()
+	return s, keyPath
+}
+
+func (s *testServer) serve() {
+	for {
+		nConn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.stall {
+			s.stalled = append(s.stalled, nConn)
+			s.mu.Unlock()
+			continue
+		}
+		s.mu.Unlock()
+
+		go func() {
+			conn, chans, reqs, err := ssh.NewServerConn(nConn, s.config)
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, conn)
+			s.mu.Unlock()
+
+			go ssh.DiscardRequests(reqs)
+			for ch := range chans {
+				go handleSession(ch)
+			}
+		}()
+	}
+}
+
+// dropConnections closes all accepted connections.
+func (s *testServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+	for _, c := range s.stalled {
+		c.Close()
+	}
+	s.stalled = nil
+}
+
+func (s *testServer) connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
+
+func (s *testServer) close() {
+	s.ln.Close()
+	s.dropConnections()
+}
+
+func handleSession(newCh ssh.NewChannel) {
+	if newCh.ChannelType() != "session" {
+		_ = newCh.Reject(ssh.UnknownChannelType, "unknown channel type")
+		return
+	}
+	ch, reqs, err := newCh.Accept()
+	if err != nil {
+		return
+	}
+	defer ch.Close()
+
+	for req := range reqs {
+		// payload is a single ssh string: uint32 length followed by data
+		payload := string(req.Payload[4:])
+		switch req.Type {
+		case "exec":
+			_ = req.Reply(true, nil)
+
+			cmd := exec.Command("/bin/sh", "-c", payload)
+			cmd.Stdin = ch
+			cmd.Stdout = ch
+			cmd.Stderr = ch.Stderr()
+			status := make([]byte, 4)
+			if err := cmd.Run(); err != nil {
+				binary.BigEndian.PutUint32(status, 1)
+				if ee, ok := err.(*exec.ExitError); ok {
+					binary.BigEndian.PutUint32(status, uint32(ee.ExitCode()))
+				}
+			}
+			_, _ = ch.SendRequest("exit-status", false, status)
+			return
+		case "subsystem":
+			if payload != "sftp" {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+
+			server, err := sftp.NewServer(ch)
+			if err != nil {
+				return
+			}
+			_ = server.Serve()
+			return
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
+
+func newTestPool(t *testing.T) (*Pool, *testServer, string, func()) {
+	dir, err := ioutil.TempDir("", "remote")
+	require.NoError(t, err)
+
+	s, keyPath := newTestServer(t, dir)
+	p, err := NewPool(Config{
+		Addr:     s.ln.Addr().String(),
+		User:     "slurm",
+		KeyPath:  keyPath,
+		PoolSize: 2,
+		Timeout:  5 * time.Second,
+	})
+	require.NoError(t, err)
+
+	return p, s, dir, func() {
+		p.Close()
+		s.close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPool_Run(t *testing.T) {
+	p, s, _, cleanup := newTestPool(t)
+	defer cleanup()
+
+	out, err := p.Run(nil, "echo", "it's", "$HOME")
+	require.NoError(t, err)
+	require.Equal(t, "it's $HOME\n", string(out))
+
+	out, err = p.Run(bytes.NewBufferString("#!/bin/sh"), "cat")
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh", string(out))
+
+	_, err = p.Run(nil, "ls", "/nonexistent")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "nonexistent")
+
+	require.NoError(t, p.LookPath("sh"))
+	require.Error(t, p.LookPath("nonexistent-binary"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.Run(nil, "true")
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 2, s.connections())
+
+	// pool should recover from broken connections
+	s.dropConnections()
+	time.Sleep(100 * time.Millisecond)
+	out, err = p.Run(nil, "echo", "ok")
+	require.NoError(t, err)
+	require.Equal(t, "ok\n", string(out))
+}
+
+func TestPool_slowDial(t *testing.T) {
+	p, s, _, cleanup := newTestPool(t)
+	defer cleanup()
+
+	_, err := p.Run(nil, "true")
+	require.NoError(t, err)
+
+	// the second pool slot is taken by a connection that never opens
+	s.mu.Lock()
+	s.stall = true
+	s.mu.Unlock()
+	dialed := make(chan error)
+	go func() {
+		_, err := p.Run(nil, "true")
+		dialed <- err
+	}()
+	for i := 0; ; i++ {
+		p.mu.Lock()
+		dialing := p.dialing
+		p.mu.Unlock()
+		if dialing == 1 {
+			break
+		}
+		require.True(t, i < 100, "connection is not being opened")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := p.Run(nil, "true")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("open connection was not reused while dialing")
+	}
+
+	s.dropConnections()
+	require.Error(t, <-dialed)
+}
+
+func TestSFTP(t *testing.T) {
+	p, _, dir, cleanup := newTestPool(t)
+	defer cleanup()
+	fs := NewSFTP(p)
+
+	_, err := fs.Open(filepath.Join(dir, "nonexistent"))
+	require.Equal(t, files.ErrFileNotFound, err)
+
+	path := filepath.Join(dir, "data", "in", "file.txt")
+	w, err := fs.Create(path)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	r, err := fs.Open(path)
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "hello", string(content))
+
+	target := filepath.Join(dir, "data.zip")
+	require.NoError(t, fs.Zip(filepath.Join(dir, "data"), target))
+	zr, err := zip.OpenReader(target)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	zr.Close()
+	require.Equal(t, []string{"data/", "data/in/", "data/in/file.txt"}, names)
+
+	require.NoError(t, fs.Unzip(target, filepath.Join(dir, "out")))
+	content, err = ioutil.ReadFile(filepath.Join(dir, "out", "data", "in", "file.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(content))
+}
+
+func TestSFTP_Tail(t *testing.T) {
+	p, _, dir, cleanup := newTestPool(t)
+	defer cleanup()
+
+	path := filepath.Join(dir, "slurm-1.out")
+	tr, err := NewSFTP(p).Tail(path)
+	require.NoError(t, err)
+
+	buf := make([]byte, 64)
+	n, err := tr.Read(buf)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("line 1\n"), 0644))
+	n, err = tr.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "line 1\n", string(buf[:n]))
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("line 2\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, tr.Close())
+	n, err = tr.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "line 2\n", string(buf[:n]))
+	_, err = tr.Read(buf)
+	require.Equal(t, io.EOF, err)
+}
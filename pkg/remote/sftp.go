@@ -0,0 +1,309 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/dptech-corp/wlm-operator/pkg/files"
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+)
+
+type (
+	// SFTP performs file operations on a remote host over SFTP.
+	SFTP struct {
+		pool *Pool
+	}
+
+	// tailReader follows a remote file by polling it for new content.
+	tailReader struct {
+		client *sftp.Client
+		path   string
+
+		mu     sync.Mutex
+		file   *sftp.File
+		closed bool
+		eof    bool
+	}
+
+	// readerAt implements io.ReaderAt on top of a remote file.
+	readerAt struct {
+		file *sftp.File
+	}
+)
+
+// NewSFTP returns SFTP file system that works over pooled connections.
+func NewSFTP(p *Pool) *SFTP {
+	return &SFTP{pool: p}
+}
+
+// Open opens arbitrary file at path in a read-only mode.
+func (s *SFTP) Open(p string) (io.ReadCloser, error) {
+	c, err := s.pool.sftp()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := c.Open(p)
+	if os.IsNotExist(err) {
+		return nil, files.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", p)
+	}
+	return file, nil
+}
+
+// Create opens a file at path in write mode.
+func (s *SFTP) Create(p string) (io.WriteCloser, error) {
+	c, err := s.pool.sftp()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.MkdirAll(path.Dir(p)); err != nil {
+		return nil, errors.Wrapf(err, "could not create directory for %s", p)
+	}
+	file, err := c.Create(p)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create %s", p)
+	}
+	return file, nil
+}
+
+// Tail opens arbitrary file at path in a read-only mode.
+// Unlike Open, Tail will watch file changes. File does not have to exist,
+// Read returns no data until it is created. Read returns EOF only after
+// invoking Close and reaching the end of the file.
+func (s *SFTP) Tail(p string) (io.ReadCloser, error) {
+	c, err := s.pool.sftp()
+	if err != nil {
+		return nil, err
+	}
+	return &tailReader{client: c, path: p}, nil
+}
+
+// Zip file or directory.
+func (s *SFTP) Zip(p string, target string) error {
+	c, err := s.pool.sftp()
+	if err != nil {
+		return err
+	}
+
+	if err := zipRemote(c, p, target); err != nil {
+		return errors.Wrap(err, "could not zip file or directory")
+	}
+	return nil
+}
+
+// Unzip file or directory.
+func (s *SFTP) Unzip(source string, p string) error {
+	c, err := s.pool.sftp()
+	if err != nil {
+		return err
+	}
+
+	if err := unzipRemote(c, source, p); err != nil {
+		return errors.Wrap(err, "could not unzip file")
+	}
+	return nil
+}
+
+func (t *tailReader) Read(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.eof {
+		return 0, io.EOF
+	}
+	if t.file == nil {
+		file, err := t.client.Open(t.path)
+		if os.IsNotExist(err) {
+			if t.closed {
+				return 0, io.EOF
+			}
+			return 0, nil
+		}
+		if err != nil {
+			return 0, errors.Wrapf(err, "could not open %s", t.path)
+		}
+		t.file = file
+	}
+
+	n, err := t.file.Read(p)
+	if err != io.EOF {
+		return n, err
+	}
+	if !t.closed || n > 0 {
+		return n, nil
+	}
+
+	t.eof = true
+	_ = t.file.Close()
+	return 0, io.EOF
+}
+
+func (t *tailReader) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	return nil
+}
+
+func (r readerAt) ReadAt(p []byte, off int64) (int, error) {
+	if _, err := r.file.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(r.file, p)
+}
+
+func zipRemote(c *sftp.Client, source, target string) error {
+	f, err := c.Create(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	writer := zip.NewWriter(f)
+	defer writer.Close()
+
+	base := path.Dir(source)
+	walker := c.Walk(source)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return err
+		}
+
+		info := walker.Stat()
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Method = zip.Deflate
+		header.Name = relPath(base, walker.Path())
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		headerWriter, err := writer.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		if err := copyFrom(c, walker.Path(), headerWriter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFrom(c *sftp.Client, p string, w io.Writer) error {
+	f, err := c.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func unzipRemote(c *sftp.Client, source, destination string) error {
+	f, err := c.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	reader, err := zip.NewReader(readerAt{file: f}, info.Size())
+	if err != nil {
+		return err
+	}
+
+	if !path.IsAbs(destination) {
+		wd, err := c.Getwd()
+		if err != nil {
+			return err
+		}
+		destination = path.Join(wd, destination)
+	}
+
+	for _, zf := range reader.File {
+		if err := unzipRemoteFile(c, zf, destination); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unzipRemoteFile(c *sftp.Client, f *zip.File, destination string) error {
+	// check if file paths are not vulnerable to Zip Slip
+	filePath := path.Join(destination, f.Name)
+	if !strings.HasPrefix(filePath, path.Clean(destination)+"/") {
+		return errors.Errorf("invalid file path: %s", filePath)
+	}
+
+	if f.FileInfo().IsDir() {
+		return c.MkdirAll(filePath)
+	}
+	if err := c.MkdirAll(path.Dir(filePath)); err != nil {
+		return err
+	}
+
+	destinationFile, err := c.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	defer destinationFile.Close()
+
+	if err := c.Chmod(filePath, f.Mode()); err != nil {
+		return err
+	}
+
+	zippedFile, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer zippedFile.Close()
+
+	_, err = io.Copy(destinationFile, zippedFile)
+	return err
+}
+
+// relPath returns p relative to base directory.
+func relPath(base, p string) string {
+	if base == "." {
+		return p
+	}
+	if base != "/" {
+		base += "/"
+	}
+	return strings.TrimPrefix(p, base)
+}
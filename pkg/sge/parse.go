@@ -0,0 +1,462 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sge
+
+import (
+	"bufio"
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	infinity = "INFINITY"
+
+	// job array task states as defined by Grid Engine, see sge_jobL.h
+	taskRunning      = 0x80
+	taskSuspended    = 0x100
+	taskTransferring = 0x200
+
+	statePending   = "PENDING"
+	stateRunning   = "RUNNING"
+	stateSuspended = "SUSPENDED"
+	stateCompleted = "COMPLETED"
+	stateFailed    = "FAILED"
+)
+
+type (
+	qstatResponse struct {
+		Jobs []qstatJob `xml:"djob_info>element"`
+	}
+
+	qstatJob struct {
+		Number         string   `xml:"JB_job_number"`
+		Name           string   `xml:"JB_job_name"`
+		Owner          string   `xml:"JB_owner"`
+		SubmissionTime string   `xml:"JB_submission_time"`
+		Cwd            string   `xml:"JB_cwd"`
+		StdOut         []string `xml:"JB_stdout_path_list>path_list>PN_path"`
+		StdErr         []string `xml:"JB_stderr_path_list>path_list>PN_path"`
+		Queues         []string `xml:"JB_hard_queue_list>destin_ident_list>QR_name"`
+
+		// depending on Grid Engine version tasks are listed
+		// either as ulong_sublist or as element
+		Tasks        []qstatTask `xml:"JB_ja_tasks>ulong_sublist"`
+		ElementTasks []qstatTask `xml:"JB_ja_tasks>element"`
+	}
+
+	qstatTask struct {
+		Number    string `xml:"JAT_task_number"`
+		Status    int64  `xml:"JAT_status"`
+		StartTime string `xml:"JAT_start_time"`
+		Granted   []struct {
+			QueueName string `xml:"JG_qname"`
+			Host      string `xml:"JG_qhostname"`
+			Slots     int64  `xml:"JG_slots"`
+		} `xml:"JAT_granted_destin_identifier_list>element"`
+	}
+
+	qhostResponse struct {
+		Hosts []struct {
+			Name   string `xml:"name,attr"`
+			Values []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"hostvalue"`
+			Queues []struct {
+				Name   string `xml:"name,attr"`
+				Values []struct {
+					Name  string `xml:"name,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"queuevalue"`
+			} `xml:"queue"`
+		} `xml:"host"`
+	}
+)
+
+// parseJobID parses qsub -terse output. For array jobs
+// the output is in form '<id>.<first>-<last>:<step>'.
+func parseJobID(out string) (int64, error) {
+	out = strings.TrimSpace(out)
+	if i := strings.IndexByte(out, '.'); i != -1 {
+		out = out[:i]
+	}
+	return strconv.ParseInt(out, 10, 0)
+}
+
+// parseQstatJob parses qstat -xml -j output. Unknown jobs are reported
+// by qstat in a separate document and result in an empty slice.
+// In case of job array the first element is a root.
+func parseQstatJob(raw []byte) ([]*JobInfo, error) {
+	var resp qstatResponse
+	if err := xml.Unmarshal(raw, &resp); err != nil {
+		return nil, err
+	}
+
+	var infos []*JobInfo
+	for _, j := range resp.Jobs {
+		submitTime, err := parseQstatTime(j.SubmissionTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse submission time")
+		}
+
+		root := &JobInfo{
+			ID:         j.Number,
+			Name:       j.Name,
+			Owner:      j.Owner,
+			State:      statePending,
+			SubmitTime: submitTime,
+			WorkDir:    j.Cwd,
+		}
+		if len(j.StdOut) != 0 {
+			root.StdOut = j.StdOut[0]
+		}
+		if len(j.StdErr) != 0 {
+			root.StdErr = j.StdErr[0]
+		}
+		if len(j.Queues) != 0 {
+			root.Queue = j.Queues[0]
+		}
+
+		tasks := append(j.Tasks, j.ElementTasks...)
+		taskInfos := make([]*JobInfo, len(tasks))
+		for i, t := range tasks {
+			ti := *root
+			ti.Hosts = nil
+			if err := ti.fillFromTask(t); err != nil {
+				return nil, err
+			}
+			taskInfos[i] = &ti
+		}
+
+		switch len(taskInfos) {
+		case 0:
+			infos = append(infos, root)
+		case 1:
+			infos = append(infos, taskInfos[0])
+		default:
+			for _, ti := range taskInfos {
+				if ti.State == stateRunning {
+					root.State = stateRunning
+				}
+				ti.ID = j.Number + "." + ti.TaskID
+			}
+			infos = append(infos, root)
+			infos = append(infos, taskInfos...)
+		}
+	}
+	return infos, nil
+}
+
+func (ji *JobInfo) fillFromTask(t qstatTask) error {
+	startTime, err := parseQstatTime(t.StartTime)
+	if err != nil {
+		return errors.Wrap(err, "could not parse task start time")
+	}
+
+	ji.TaskID = t.Number
+	ji.StartTime = startTime
+	switch {
+	case t.Status&taskSuspended != 0:
+		ji.State = stateSuspended
+	case t.Status&(taskRunning|taskTransferring) != 0:
+		ji.State = stateRunning
+	default:
+		ji.State = statePending
+	}
+
+	for _, g := range t.Granted {
+		if ji.Queue == "" {
+			ji.Queue = strings.SplitN(g.QueueName, "@", 2)[0]
+		}
+		ji.Hosts = append(ji.Hosts, g.Host)
+		ji.Slots += g.Slots
+	}
+	return nil
+}
+
+// isQacctNotFound checks whether qacct failed since a job is not
+// in the accounting file, e.g. 'error: job id 42 not found'.
+func isQacctNotFound(stderr []byte) bool {
+	return strings.Contains(string(stderr), "not found")
+}
+
+// parseQacct parses qacct -j output. Since Grid Engine reuses job numbers
+// qacct may return several unrelated jobs, only the last one is taken then.
+// Job array tasks are all returned.
+func parseQacct(raw string) ([]*JobInfo, error) {
+	const separator = "======"
+
+	var records []map[string]string
+	var cur map[string]string
+	s := bufio.NewScanner(strings.NewReader(raw))
+	for s.Scan() {
+		line := s.Text()
+		if strings.HasPrefix(line, separator) {
+			cur = make(map[string]string)
+			records = append(records, cur)
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		cur[f[0]] = strings.TrimSpace(strings.TrimPrefix(line, f[0]))
+	}
+
+	var infos []*JobInfo
+	for _, r := range records {
+		ji, err := jobInfoFromQacctRecord(r)
+		if err != nil {
+			return nil, err
+		}
+		if ji.TaskID == "" {
+			infos = []*JobInfo{ji}
+			continue
+		}
+		ji.ID = ji.ID + "." + ji.TaskID
+		infos = append(infos, ji)
+	}
+	return infos, nil
+}
+
+func jobInfoFromQacctRecord(r map[string]string) (*JobInfo, error) {
+	ji := &JobInfo{
+		ID:      r["jobnumber"],
+		Name:    r["jobname"],
+		Owner:   r["owner"],
+		Queue:   r["qname"],
+		WorkDir: r["cwd"],
+	}
+	if r["taskid"] != "undefined" {
+		ji.TaskID = r["taskid"]
+	}
+	if h := r["hostname"]; h != "" {
+		ji.Hosts = []string{h}
+	}
+
+	var err error
+	if ji.SubmitTime, err = parseQacctTime(r["qsub_time"]); err != nil {
+		return nil, errors.Wrap(err, "could not parse submit time")
+	}
+	if ji.StartTime, err = parseQacctTime(r["start_time"]); err != nil {
+		return nil, errors.Wrap(err, "could not parse start time")
+	}
+	if ji.EndTime, err = parseQacctTime(r["end_time"]); err != nil {
+		return nil, errors.Wrap(err, "could not parse end time")
+	}
+	if slots, ok := r["slots"]; ok {
+		if ji.Slots, err = strconv.ParseInt(slots, 10, 0); err != nil {
+			return nil, errors.Wrap(err, "could not parse slots")
+		}
+	}
+	if exit, ok := r["exit_status"]; ok {
+		// exit status may be followed by a description, e.g '137 (Killed)'
+		if ji.ExitCode, err = strconv.Atoi(strings.Fields(exit)[0]); err != nil {
+			return nil, errors.Wrap(err, "could not parse exit status")
+		}
+	}
+
+	// failed is a code followed by a description, e.g '100 : assumedly after job'
+	failed := strings.Fields(r["failed"])
+	ji.State = stateCompleted
+	if ji.ExitCode != 0 || (len(failed) != 0 && failed[0] != "0") {
+		ji.State = stateFailed
+	}
+	if ji.StartTime != nil && ji.EndTime != nil {
+		d := ji.EndTime.Sub(*ji.StartTime)
+		ji.WallTime = &d
+	}
+	return ji, nil
+}
+
+// parseQhost parses qhost -xml -q output and aggregates resources
+// of all hosts where the queue is configured.
+func parseQhost(raw []byte, queue string) (*Resources, error) {
+	var resp qhostResponse
+	if err := xml.Unmarshal(raw, &resp); err != nil {
+		return nil, err
+	}
+
+	var r Resources
+	for _, h := range resp.Hosts {
+		var slots int64 = -1
+		for _, q := range h.Queues {
+			if q.Name != queue {
+				continue
+			}
+			slots = 0
+			for _, v := range q.Values {
+				if v.Name != "slots" {
+					continue
+				}
+				n, err := strconv.ParseInt(strings.TrimSpace(v.Value), 10, 0)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not parse slots of %s", h.Name)
+				}
+				slots = n
+			}
+		}
+		if slots == -1 {
+			continue
+		}
+
+		r.Nodes++
+		if slots > r.CPUPerNode {
+			r.CPUPerNode = slots
+		}
+		for _, v := range h.Values {
+			if v.Name != "mem_total" {
+				continue
+			}
+			mem, err := parseMemory(v.Value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not parse memory of %s", h.Name)
+			}
+			if mem > r.MemPerNode {
+				r.MemPerNode = mem
+			}
+		}
+	}
+	return &r, nil
+}
+
+// parseQueueWallTime extracts h_rt limit from qconf -sq output.
+// Unlimited wall time is returned as -1.
+func parseQueueWallTime(raw string) (time.Duration, error) {
+	const hardRunTime = "h_rt"
+
+	s := bufio.NewScanner(strings.NewReader(raw))
+	for s.Scan() {
+		f := strings.Fields(s.Text())
+		if len(f) != 2 || f[0] != hardRunTime {
+			continue
+		}
+		if f[1] == infinity {
+			return time.Duration(-1), nil
+		}
+		return parseDuration(f[1])
+	}
+	return time.Duration(-1), nil
+}
+
+// parseDuration parses Grid Engine time specifier: [[hours:]minutes:]seconds.
+func parseDuration(raw string) (time.Duration, error) {
+	var d time.Duration
+	parts := strings.Split(raw, ":")
+	if len(parts) > 3 {
+		return 0, errors.Errorf("invalid duration format %s", raw)
+	}
+	for _, p := range parts {
+		n := int64(0)
+		if p != "" {
+			var err error
+			n, err = strconv.ParseInt(p, 10, 0)
+			if err != nil {
+				return 0, errors.Wrapf(err, "invalid duration %s", raw)
+			}
+		}
+		d = d*60 + time.Duration(n)
+	}
+	return d * time.Second, nil
+}
+
+// parseMemory parses Grid Engine memory value, e.g. '3.7G', and
+// returns it in megabytes. Absent value '-' is returned as 0.
+func parseMemory(raw string) (int64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "-" {
+		return 0, nil
+	}
+
+	multiplier := 1.0 / (1024 * 1024) // plain value is in bytes
+	suffixes := map[byte]float64{
+		'K': 1.0 / 1024, 'k': 1.0 / 1024,
+		'M': 1, 'm': 1,
+		'G': 1024, 'g': 1024,
+		'T': 1024 * 1024, 't': 1024 * 1024,
+	}
+	if m, ok := suffixes[raw[len(raw)-1]]; ok {
+		multiplier = m
+		raw = raw[:len(raw)-1]
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(v * multiplier)), nil
+}
+
+// parseQstatTime parses time from qstat xml output. Depending on
+// Grid Engine version it is either unix time or a timestamp.
+func parseQstatTime(raw string) (*time.Time, error) {
+	const qstatTimeLayout = "2006-01-02T15:04:05"
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	if sec, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if sec == 0 {
+			return nil, nil
+		}
+		// some versions report time in milliseconds
+		if sec > 1e12 {
+			sec /= 1000
+		}
+		t := time.Unix(sec, 0).UTC()
+		return &t, nil
+	}
+
+	t, err := time.Parse(qstatTimeLayout, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseQacctTime parses time from qacct output. Older Grid Engine versions
+// use ctime format while newer ones print time with milliseconds.
+func parseQacctTime(raw string) (*time.Time, error) {
+	layouts := []string{
+		"Mon Jan _2 15:04:05 2006",
+		"01/02/2006 15:04:05.000",
+		"01/02/2006 15:04:05",
+	}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "-/-" || strings.HasPrefix(raw, "01/01/1970") || strings.HasPrefix(raw, "Thu Jan  1") {
+		return nil, nil
+	}
+
+	var err error
+	for _, l := range layouts {
+		var t time.Time
+		t, err = time.Parse(l, raw)
+		if err == nil {
+			return &t, nil
+		}
+	}
+	return nil, err
+}
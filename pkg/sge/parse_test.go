@@ -0,0 +1,322 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testQstatRunning = `<?xml version='1.0'?>
+<detailed_job_info  xmlns:xsd="http://arc.liv.ac.uk/repos/darcs/sge/source/dist/util/resources/schemas/qstat/detailed_job_info.xsd">
+  <djob_info>
+    <element>
+      <JB_job_number>53</JB_job_number>
+      <JB_ar>0</JB_ar>
+      <JB_exec_file>job_scripts/53</JB_exec_file>
+      <JB_submission_time>1555415359</JB_submission_time>
+      <JB_owner>vagrant</JB_owner>
+      <JB_uid>1000</JB_uid>
+      <JB_group>vagrant</JB_group>
+      <JB_gid>1000</JB_gid>
+      <JB_account>sge</JB_account>
+      <JB_cwd>/home/vagrant</JB_cwd>
+      <JB_stdout_path_list>
+        <path_list>
+          <PN_path>/home/vagrant/job.out</PN_path>
+          <PN_host></PN_host>
+          <PN_file_host></PN_file_host>
+          <PN_file_staging>false</PN_file_staging>
+        </path_list>
+      </JB_stdout_path_list>
+      <JB_job_name>STDIN</JB_job_name>
+      <JB_hard_queue_list>
+        <destin_ident_list>
+          <QR_name>all.q</QR_name>
+        </destin_ident_list>
+      </JB_hard_queue_list>
+      <JB_ja_tasks>
+        <ulong_sublist>
+          <JAT_task_number>1</JAT_task_number>
+          <JAT_status>128</JAT_status>
+          <JAT_start_time>1555415360</JAT_start_time>
+          <JAT_granted_destin_identifier_list>
+            <element>
+              <JG_qname>all.q@node1</JG_qname>
+              <JG_qhostname>node1</JG_qhostname>
+              <JG_slots>2</JG_slots>
+            </element>
+          </JAT_granted_destin_identifier_list>
+        </ulong_sublist>
+      </JB_ja_tasks>
+    </element>
+  </djob_info>
+</detailed_job_info>`
+
+	testQstatPending = `<?xml version='1.0'?>
+<detailed_job_info  xmlns:xsd="http://arc.liv.ac.uk/repos/darcs/sge/source/dist/util/resources/schemas/qstat/detailed_job_info.xsd">
+  <djob_info>
+    <element>
+      <JB_job_number>54</JB_job_number>
+      <JB_submission_time>1555415359</JB_submission_time>
+      <JB_owner>vagrant</JB_owner>
+      <JB_cwd>/home/vagrant</JB_cwd>
+      <JB_job_name>STDIN</JB_job_name>
+    </element>
+  </djob_info>
+</detailed_job_info>`
+
+	testQstatUnknown = `<?xml version='1.0'?>
+<unknown_jobs  xmlns:xsd="http://arc.liv.ac.uk/repos/darcs/sge/source/dist/util/resources/schemas/qstat/detailed_job_info.xsd">
+  <element>
+    <ST_name>55</ST_name>
+  </element>
+</unknown_jobs>`
+
+	testQacct = `==============================================================
+qname        all.q
+hostname     node1
+group        vagrant
+owner        vagrant
+project      NONE
+department   defaultdepartment
+jobname      STDIN
+jobnumber    53
+taskid       undefined
+account      sge
+priority     0
+qsub_time    Tue Apr 16 11:49:19 2019
+start_time   Tue Apr 16 11:49:20 2019
+end_time     Tue Apr 16 11:49:50 2019
+granted_pe   NONE
+slots        1
+failed       0
+exit_status  0
+ru_wallclock 30s
+==============================================================
+qname        all.q
+hostname     node2
+group        vagrant
+owner        vagrant
+project      NONE
+department   defaultdepartment
+jobname      test
+jobnumber    53
+taskid       undefined
+account      sge
+priority     0
+qsub_time    04/17/2019 11:49:19.120
+start_time   04/17/2019 11:49:20.001
+end_time     04/17/2019 11:49:50.003
+granted_pe   NONE
+slots        2
+failed       100 : assumedly after job
+exit_status  137                  (Killed)
+ru_wallclock 30s`
+
+	testQhost = `<?xml version='1.0'?>
+<qhost xmlns:xsd="http://arc.liv.ac.uk/repos/darcs/sge/source/dist/util/resources/schemas/qhost/qhost.xsd">
+ <host name='global'>
+   <hostvalue name='arch_string'>-</hostvalue>
+   <hostvalue name='num_proc'>-</hostvalue>
+   <hostvalue name='mem_total'>-</hostvalue>
+ </host>
+ <host name='node1'>
+   <hostvalue name='arch_string'>lx-amd64</hostvalue>
+   <hostvalue name='num_proc'>4</hostvalue>
+   <hostvalue name='mem_total'>3.7G</hostvalue>
+   <queue name='all.q'>
+     <queuevalue qname='all.q' name='qtype_string'>BIP</queuevalue>
+     <queuevalue qname='all.q' name='slots_used'>0</queuevalue>
+     <queuevalue qname='all.q' name='slots'>4</queuevalue>
+     <queuevalue qname='all.q' name='slots_resv'>0</queuevalue>
+     <queuevalue qname='all.q' name='state_string'></queuevalue>
+   </queue>
+ </host>
+ <host name='node2'>
+   <hostvalue name='arch_string'>lx-amd64</hostvalue>
+   <hostvalue name='num_proc'>8</hostvalue>
+   <hostvalue name='mem_total'>7.8G</hostvalue>
+   <queue name='all.q'>
+     <queuevalue qname='all.q' name='slots'>8</queuevalue>
+   </queue>
+   <queue name='gpu.q'>
+     <queuevalue qname='gpu.q' name='slots'>2</queuevalue>
+   </queue>
+ </host>
+</qhost>`
+
+	testQconfQueue = `qname                 all.q
+hostlist              @allhosts
+seq_no                0
+load_thresholds       np_load_avg=1.75
+slots                 1,[node1=4],[node2=8]
+tmpdir                /tmp
+shell                 /bin/sh
+s_rt                  INFINITY
+h_rt                  01:30:00
+s_vmem                INFINITY
+h_vmem                INFINITY`
+)
+
+func TestParseJobID(t *testing.T) {
+	for in, want := range map[string]int64{
+		"53\n":          53,
+		"54.1-10:1\n":   54,
+		" 55 ":          55,
+		"56.1-100:10\n": 56,
+	} {
+		got, err := parseJobID(in)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err := parseJobID("Your job 53 has been submitted")
+	require.Error(t, err)
+}
+
+func TestParseQstatJob(t *testing.T) {
+	submitTime := time.Unix(1555415359, 0).UTC()
+	startTime := time.Unix(1555415360, 0).UTC()
+
+	tt := []struct {
+		name string
+		in   string
+		want []*JobInfo
+	}{
+		{
+			name: "running",
+			in:   testQstatRunning,
+			want: []*JobInfo{
+				{
+					ID:         "53",
+					Name:       "STDIN",
+					Owner:      "vagrant",
+					State:      "RUNNING",
+					SubmitTime: &submitTime,
+					StartTime:  &startTime,
+					WorkDir:    "/home/vagrant",
+					StdOut:     "/home/vagrant/job.out",
+					Queue:      "all.q",
+					Hosts:      []string{"node1"},
+					Slots:      2,
+					TaskID:     "1",
+				},
+			},
+		},
+		{
+			name: "pending",
+			in:   testQstatPending,
+			want: []*JobInfo{
+				{
+					ID:         "54",
+					Name:       "STDIN",
+					Owner:      "vagrant",
+					State:      "PENDING",
+					SubmitTime: &submitTime,
+					WorkDir:    "/home/vagrant",
+				},
+			},
+		},
+		{
+			name: "unknown",
+			in:   testQstatUnknown,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseQstatJob([]byte(tc.in))
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParseQacct(t *testing.T) {
+	got, err := parseQacct(testQacct)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	submitTime := time.Date(2019, 4, 17, 11, 49, 19, 120000000, time.UTC)
+	startTime := time.Date(2019, 4, 17, 11, 49, 20, 1000000, time.UTC)
+	endTime := time.Date(2019, 4, 17, 11, 49, 50, 3000000, time.UTC)
+	wallTime := endTime.Sub(startTime)
+	require.Equal(t, &JobInfo{
+		ID:         "53",
+		Name:       "test",
+		Owner:      "vagrant",
+		State:      "FAILED",
+		ExitCode:   137,
+		SubmitTime: &submitTime,
+		StartTime:  &startTime,
+		EndTime:    &endTime,
+		WallTime:   &wallTime,
+		Queue:      "all.q",
+		Hosts:      []string{"node2"},
+		Slots:      2,
+	}, got[0])
+}
+
+func TestIsQacctNotFound(t *testing.T) {
+	require.True(t, isQacctNotFound([]byte("error: job id 42 not found\n")))
+	require.False(t, isQacctNotFound([]byte("error: can't open accounting file: Permission denied\n")))
+	require.False(t, isQacctNotFound(nil))
+}
+
+func TestParseQhost(t *testing.T) {
+	r, err := parseQhost([]byte(testQhost), "all.q")
+	require.NoError(t, err)
+	require.Equal(t, &Resources{Nodes: 2, CPUPerNode: 8, MemPerNode: 7987}, r)
+
+	r, err = parseQhost([]byte(testQhost), "gpu.q")
+	require.NoError(t, err)
+	require.Equal(t, &Resources{Nodes: 1, CPUPerNode: 2, MemPerNode: 7987}, r)
+
+	r, err = parseQhost([]byte(testQhost), "none.q")
+	require.NoError(t, err)
+	require.Equal(t, &Resources{}, r)
+}
+
+func TestParseQueueWallTime(t *testing.T) {
+	d, err := parseQueueWallTime(testQconfQueue)
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Minute, d)
+
+	d, err = parseQueueWallTime("qname all.q\nh_rt INFINITY\n")
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(-1), d)
+}
+
+func TestParseMemory(t *testing.T) {
+	for in, want := range map[string]int64{
+		"-":        0,
+		"3.7G":     3789,
+		"512.0M":   512,
+		"1T":       1048576,
+		"2048K":    2,
+		"1048576":  1,
+		"15.500G":  15872,
+		"128.000M": 128,
+	} {
+		got, err := parseMemory(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, got, in)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sge implements a client for Son of Grid Engine and compatible
+// (SGE, UGE) workload managers by calling Grid Engine binaries directly.
+package sge
+
+import (
+	"bytes"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/files"
+	"github.com/pkg/errors"
+)
+
+const (
+	qsubBinaryName  = "qsub"
+	qdelBinaryName  = "qdel"
+	qstatBinaryName = "qstat"
+	qacctBinaryName = "qacct"
+	qconfBinaryName = "qconf"
+	qhostBinaryName = "qhost"
+)
+
+// ErrJobNotFound is returned when neither qstat nor qacct know a job.
+var ErrJobNotFound = errors.New("job is not found")
+
+type (
+	// Client implements communication with a local Grid Engine cluster
+	// by calling Grid Engine binaries directly. File operations are
+	// performed on the local file system.
+	Client struct {
+		files.Local
+	}
+
+	// JobInfo contains information about a Grid Engine job.
+	JobInfo struct {
+		ID         string
+		Name       string
+		Owner      string
+		State      string
+		ExitCode   int
+		SubmitTime *time.Time
+		StartTime  *time.Time
+		EndTime    *time.Time
+		WallTime   *time.Duration
+		WorkDir    string
+		StdOut     string
+		StdErr     string
+		Queue      string
+		Hosts      []string
+		Slots      int64
+		TaskID     string
+	}
+
+	// Resources contain a list of available resources on a Grid Engine queue.
+	Resources struct {
+		Nodes      int64
+		MemPerNode int64
+		CPUPerNode int64
+		WallTime   time.Duration
+	}
+)
+
+// NewClient returns new local client.
+func NewClient() (*Client, error) {
+	var missing []string
+	for _, bin := range []string{
+		qsubBinaryName,
+		qdelBinaryName,
+		qstatBinaryName,
+		qacctBinaryName,
+		qconfBinaryName,
+		qhostBinaryName,
+	} {
+		_, err := exec.LookPath(bin)
+		if err != nil {
+			missing = append(missing, bin)
+		}
+	}
+	if len(missing) != 0 {
+		return nil, errors.Errorf("no grid engine binaries found: %s", strings.Join(missing, ", "))
+	}
+	return &Client{}, nil
+}
+
+// QSub submits batch job and returns job id if succeeded.
+func (*Client) QSub(script, queue string) (int64, error) {
+	args := []string{"-terse"}
+	if queue != "" {
+		args = append(args, "-q", queue)
+	}
+	cmd := exec.Command(qsubBinaryName, args...)
+	cmd.Stdin = bytes.NewBufferString(script)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if out != nil {
+			log.Println(string(out))
+		}
+		return 0, errors.Wrap(err, "failed to execute qsub")
+	}
+
+	id, err := parseJobID(string(out))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not parse job id")
+	}
+	return id, nil
+}
+
+// QDel deletes batch job.
+func (*Client) QDel(jobID int64) error {
+	cmd := exec.Command(qdelBinaryName, strconv.FormatInt(jobID, 10))
+
+	out, err := cmd.CombinedOutput()
+	if err != nil && out != nil {
+		log.Println(string(out))
+	}
+	return errors.Wrap(err, "failed to execute qdel")
+}
+
+// JobInfo returns information about a particular job by ID. Pending and
+// running jobs are queried with qstat, finished ones are looked up in the
+// accounting file with qacct. ErrJobNotFound is returned when both are empty.
+func (c *Client) JobInfo(jobID int64) ([]*JobInfo, error) {
+	id := strconv.FormatInt(jobID, 10)
+
+	// qstat exits with non-zero code for unknown jobs, but still
+	// prints a valid xml listing them, so output is parsed anyway.
+	out, err := exec.Command(qstatBinaryName, "-xml", "-j", id).Output()
+	if err != nil && len(out) == 0 {
+		return nil, errors.Wrapf(err, "failed to get info for job: %d", jobID)
+	}
+
+	infos, err := parseQstatJob(out)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse qstat response")
+	}
+	if len(infos) != 0 {
+		return infos, nil
+	}
+
+	out, err = exec.Command(qacctBinaryName, "-j", id).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && isQacctNotFound(ee.Stderr) {
+			return nil, ErrJobNotFound
+		}
+		return nil, errors.Wrapf(err, "failed to get accounting info for job: %d", jobID)
+	}
+
+	infos, err = parseQacct(string(out))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse qacct response")
+	}
+	if len(infos) == 0 {
+		return nil, ErrJobNotFound
+	}
+	return infos, nil
+}
+
+// Queues returns a list of cluster queue names.
+func (*Client) Queues() ([]string, error) {
+	out, err := exec.Command(qconfBinaryName, "-sql").Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get queue list")
+	}
+
+	return strings.Fields(string(out)), nil
+}
+
+// Resources returns available resources for a queue.
+func (*Client) Resources(queue string) (*Resources, error) {
+	out, err := exec.Command(qhostBinaryName, "-xml", "-q").Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get host info")
+	}
+	r, err := parseQhost(out, queue)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse qhost response")
+	}
+
+	out, err = exec.Command(qconfBinaryName, "-sq", queue).Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get queue %s configuration", queue)
+	}
+	wallTime, err := parseQueueWallTime(string(out))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse queue configuration")
+	}
+	r.WallTime = wallTime
+
+	return r, nil
+}
+
+// Version returns grid engine version.
+func (*Client) Version() (string, error) {
+	// qstat -help prints version in the first line and exits with 0,
+	// e.g. 'SGE 8.1.9' or 'UGE 8.6.4'
+	out, err := exec.Command(qstatBinaryName, "-help").Output()
+	if err != nil {
+		return "", errors.Wrap(err, "could not get grid engine info")
+	}
+
+	lines := strings.SplitN(string(out), "\n", 2)
+	s := strings.Fields(lines[0])
+	if len(s) != 2 {
+		return "", errors.Errorf("could not parse qstat response %s", lines[0])
+	}
+	return s[1], nil
+}
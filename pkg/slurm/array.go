@@ -0,0 +1,113 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxArrayIndex is the largest job array index Slurm accepts.
+const MaxArrayIndex = 4000000
+
+// ParseArrayIndices parses job array indices in sbatch --array format, e.g. 0-15,
+// 1,3,5-7 or 0-15:4, optionally followed by a limit of simultaneously running tasks,
+// e.g. 0-99%10. It returns sorted unique indices along with the limit, which is zero
+// when not set.
+func ParseArrayIndices(spec string) ([]int64, int64, error) {
+	var throttle int64
+	if i := strings.IndexByte(spec, '%'); i != -1 {
+		var err error
+		throttle, err = strconv.ParseInt(spec[i+1:], 10, 64)
+		if err != nil || throttle < 1 {
+			return nil, 0, errors.Errorf("invalid task limit %q", spec[i+1:])
+		}
+		spec = spec[:i]
+	}
+	if spec == "" {
+		return nil, 0, errors.New("no array indices")
+	}
+
+	seen := make(map[int64]bool)
+	for _, r := range strings.Split(spec, ",") {
+		step := int64(1)
+		if i := strings.IndexByte(r, ':'); i != -1 {
+			var err error
+			step, err = strconv.ParseInt(r[i+1:], 10, 64)
+			if err != nil || step < 1 {
+				return nil, 0, errors.Errorf("invalid step in %q", r)
+			}
+			r = r[:i]
+		}
+
+		bounds := strings.SplitN(r, "-", 2)
+		first, err := parseArrayIndex(bounds[0])
+		if err != nil {
+			return nil, 0, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			last, err = parseArrayIndex(bounds[1])
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		if last < first {
+			return nil, 0, errors.Errorf("invalid range %q", r)
+		}
+		for i := first; i <= last; i += step {
+			seen[i] = true
+		}
+	}
+
+	indices := make([]int64, 0, len(seen))
+	for i := range seen {
+		indices = append(indices, i)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	return indices, throttle, nil
+}
+
+func parseArrayIndex(s string) (int64, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || i < 0 || i > MaxArrayIndex {
+		return 0, errors.Errorf("invalid array index %q", s)
+	}
+	return i, nil
+}
+
+// FormatArrayIndices formats sorted array indices in sbatch --array
+// format collapsing consecutive indices into ranges, e.g. 1,3,5-7.
+func FormatArrayIndices(indices []int64) string {
+	var b strings.Builder
+	for i := 0; i < len(indices); i++ {
+		first := indices[i]
+		for i+1 < len(indices) && indices[i+1] == indices[i]+1 {
+			i++
+		}
+		if b.Len() != 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatInt(first, 10))
+		if indices[i] != first {
+			b.WriteByte('-')
+			b.WriteString(strconv.FormatInt(indices[i], 10))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArrayIndices(t *testing.T) {
+	tt := []struct {
+		spec     string
+		indices  []int64
+		throttle int64
+		err      string
+	}{
+		{spec: "3", indices: []int64{3}},
+		{spec: "0-4", indices: []int64{0, 1, 2, 3, 4}},
+		{spec: "0-9:4", indices: []int64{0, 4, 8}},
+		{spec: "7,1,3-5,4", indices: []int64{1, 3, 4, 5, 7}},
+		{spec: "5-8%2", indices: []int64{5, 6, 7, 8}, throttle: 2},
+		{spec: "", err: "no array indices"},
+		{spec: "1-", err: `invalid array index ""`},
+		{spec: "5-1", err: `invalid range "5-1"`},
+		{spec: "0-9:0", err: `invalid step in "0-9:0"`},
+		{spec: "0-9%0", err: `invalid task limit "0"`},
+		{spec: "4000001", err: `invalid array index "4000001"`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.spec, func(t *testing.T) {
+			indices, throttle, err := ParseArrayIndices(tc.spec)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.indices, indices)
+			require.Equal(t, tc.throttle, throttle)
+		})
+	}
+}
+
+func TestFormatArrayIndices(t *testing.T) {
+	require.Equal(t, "", FormatArrayIndices(nil))
+	require.Equal(t, "3", FormatArrayIndices([]int64{3}))
+	require.Equal(t, "1,3-5,7", FormatArrayIndices([]int64{1, 3, 4, 5, 7}))
+	require.Equal(t, "0-2,9-10", FormatArrayIndices([]int64{0, 1, 2, 9, 10}))
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Clock tells the simulator what time it is now.
+	Clock interface {
+		Now() time.Time
+	}
+
+	// ManualClock is a Clock that moves only when it is told to.
+	// It allows tests to drive job state transitions deterministically.
+	ManualClock struct {
+		mu  sync.Mutex
+		now time.Time
+	}
+
+	realClock struct{}
+)
+
+// NewManualClock returns a clock that is stopped at the passed time.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now returns the current clock time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,955 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fake provides an in-memory Slurm simulator. It can be used
+// in place of a real Slurm cluster to exercise red-box and everything
+// built on top of it in tests and demos.
+package fake
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/files"
+	"github.com/dptech-corp/wlm-operator/pkg/slurm"
+	"github.com/pkg/errors"
+)
+
+// Version is reported as Slurm version by the simulator.
+const Version = "fake"
+
+const (
+	statePending   = "PENDING"
+	stateRunning   = "RUNNING"
+	stateSuspended = "SUSPENDED"
+	stateCompleted = "COMPLETED"
+	stateFailed    = "FAILED"
+	stateCancelled = "CANCELLED"
+	stateTimeout   = "TIMEOUT"
+
+	// sigterm is reported as a signal that terminated
+	// cancelled and timed out jobs.
+	sigterm = 15
+
+	defaultOutput      = "slurm-%j.out"
+	defaultArrayOutput = "slurm-%A_%a.out"
+
+	// noTask is substituted for %a in output of a job that is not an array.
+	noTask = "4294967294"
+)
+
+type (
+	// Partition describes a simulated Slurm partition.
+	Partition struct {
+		Nodes      int64
+		CPUPerNode int64
+		MemPerNode int64
+		// WallTime is the partition time limit, zero means unlimited.
+		WallTime time.Duration
+	}
+
+	// Config configures the simulator.
+	Config struct {
+		// Partitions available in the simulated cluster by name.
+		Partitions map[string]Partition
+		// DefaultPartition is used when a job doesn't request a partition.
+		// The first partition in alphabetical order is used if it is empty.
+		DefaultPartition string
+		// JobDuration is how long a job runs unless its script
+		// sets duration with #FAKE directive.
+		JobDuration time.Duration
+		// PendingTime is how long a job stays pending before it
+		// becomes eligible for scheduling.
+		PendingTime time.Duration
+		// Dir is a working directory of simulated jobs,
+		// their output files are written there.
+		Dir string
+		// Clock is used to drive the simulation, system time is used if it is nil.
+		Clock Clock
+	}
+
+	// Slurm simulates a Slurm cluster in memory. Submitted scripts are never
+	// executed, instead each job is scheduled on free nodes of its partition,
+	// runs for a configured duration and finishes with a configured exit code.
+	// Job state is advanced lazily with respect to the clock whenever
+	// the simulator is queried. File operations are performed on
+	// the local file system.
+	Slurm struct {
+		files.Local
+
+		cfg   Config
+		clock Clock
+		user  string
+		nodes map[string][]string
+
+		mu     sync.Mutex
+		now    time.Time
+		lastID int64
+		jobs   map[int64]*job
+		queue  []*job
+		busy   map[string]bool
+	}
+
+	job struct {
+		id        int64
+		name      string
+		partition string
+		nodes     int64
+		cpus      int64
+		timeLimit *time.Duration
+		duration  time.Duration
+		exitCode  int
+		stdOut    string
+		script    string
+
+		// arrayID is set for job array tasks, the array job is
+		// identified by the id of its first task; throttle limits
+		// the number of simultaneously running tasks of the array
+		arrayID  int64
+		taskID   int64
+		throttle int64
+
+		state    string
+		signal   int
+		submit   time.Time
+		eligible time.Time
+		start    *time.Time
+		end      *time.Time
+		nodeList []string
+
+		// held job is never scheduled until it is released
+		held bool
+		// suspendedAt is set while job is suspended, remaining is
+		// its run time left at that moment and suspended is the total
+		// time the job spent suspended, which doesn't count as run time
+		suspendedAt *time.Time
+		remaining   time.Duration
+		suspended   time.Duration
+	}
+)
+
+// NewSlurm creates a simulated Slurm cluster.
+func NewSlurm(cfg Config) (*Slurm, error) {
+	if len(cfg.Partitions) == 0 {
+		return nil, errors.New("at least one partition should be configured")
+	}
+	if cfg.DefaultPartition == "" {
+		names := partitionNames(cfg.Partitions)
+		cfg.DefaultPartition = names[0]
+	}
+	if _, ok := cfg.Partitions[cfg.DefaultPartition]; !ok {
+		return nil, errors.Errorf("default partition %s is not configured", cfg.DefaultPartition)
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = realClock{}
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "could not create working directory")
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get current user")
+	}
+
+	nodes := make(map[string][]string, len(cfg.Partitions))
+	for name, p := range cfg.Partitions {
+		for i := int64(1); i <= p.Nodes; i++ {
+			nodes[name] = append(nodes[name], fmt.Sprintf("%s-%d", name, i))
+		}
+	}
+
+	return &Slurm{
+		cfg:   cfg,
+		clock: cfg.Clock,
+		user:  fmt.Sprintf("%s(%s)", u.Username, u.Uid),
+		nodes: nodes,
+		now:   cfg.Clock.Now(),
+		jobs:  make(map[int64]*job),
+		busy:  make(map[string]bool),
+	}, nil
+}
+
+// SBatch submits batch job and returns job id if succeeded.
+// Partition, if set, overrides the one requested in the script.
+// Job array is submitted as a job per task with consecutive ids,
+// id of the first task identifies the array job.
+func (s *Slurm) SBatch(script, partition string) (int64, error) {
+	opts, partition, timeLimit, err := s.check(script, partition)
+	if err != nil {
+		return 0, err
+	}
+
+	duration := s.cfg.JobDuration
+	if opts.duration != nil {
+		duration = *opts.duration
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance()
+
+	submit := func(arrayID, taskID int64, exitCode int) *job {
+		s.lastID++
+		j := &job{
+			id:        s.lastID,
+			name:      opts.name,
+			partition: partition,
+			nodes:     opts.nodes,
+			cpus:      opts.cpus(),
+			timeLimit: timeLimit,
+			duration:  duration,
+			exitCode:  exitCode,
+			script:    script,
+			state:     statePending,
+			submit:    s.now,
+			eligible:  s.now.Add(s.cfg.PendingTime),
+			arrayID:   arrayID,
+			taskID:    taskID,
+			throttle:  opts.throttle,
+		}
+		if j.name == "" {
+			j.name = "sbatch"
+		}
+		j.stdOut = s.outputPath(j, opts.output)
+
+		s.jobs[j.id] = j
+		s.queue = append(s.queue, j)
+		return j
+	}
+
+	id := s.lastID + 1
+	if len(opts.array) == 0 {
+		submit(0, 0, opts.exitCode)
+	}
+	for _, i := range opts.array {
+		submit(id, i, opts.taskExitCode(i))
+	}
+	s.schedule()
+
+	return id, nil
+}
+
+// SBatchTest validates batch script like SBatch does without submitting it.
+// Job is estimated to start once pending time passes on the first nodes of its partition.
+func (s *Slurm) SBatchTest(script, partition string) (*slurm.JobEstimate, error) {
+	opts, partition, _, err := s.check(script, partition)
+	if err != nil {
+		return &slurm.JobEstimate{Errors: []string{err.Error()}}, nil
+	}
+
+	s.mu.Lock()
+	start := s.now.Add(s.cfg.PendingTime)
+	s.mu.Unlock()
+
+	return &slurm.JobEstimate{
+		StartTime: &start,
+		CPUs:      opts.cpus(),
+		NodeList:  strings.Join(s.nodes[partition][:opts.nodes], ","),
+		Partition: partition,
+	}, nil
+}
+
+// check validates batch script against partition limits. It returns
+// parsed options along with the partition and time limit of the job.
+func (s *Slurm) check(script, partition string) (*batchOptions, string, *time.Duration, error) {
+	opts, err := parseScript(script)
+	if err != nil {
+		return nil, "", nil, errors.Wrap(err, "could not parse batch script")
+	}
+	if partition == "" {
+		partition = opts.partition
+	}
+	if partition == "" {
+		partition = s.cfg.DefaultPartition
+	}
+
+	p, ok := s.cfg.Partitions[partition]
+	if !ok {
+		return nil, "", nil, errors.Errorf("invalid partition name specified: %s", partition)
+	}
+	if opts.nodes > p.Nodes ||
+		(p.CPUPerNode != 0 && opts.cpuPerTask > p.CPUPerNode) ||
+		(p.MemPerNode != 0 && opts.mem > p.MemPerNode) {
+		return nil, "", nil, errors.New("requested node configuration is not available")
+	}
+
+	timeLimit := opts.timeLimit
+	if p.WallTime != 0 {
+		if timeLimit != nil && *timeLimit > p.WallTime {
+			return nil, "", nil, errors.New("requested time limit is invalid (exceeds partition limit)")
+		}
+		if timeLimit == nil {
+			timeLimit = &p.WallTime
+		}
+	}
+
+	return opts, partition, timeLimit, nil
+}
+
+// SCancel cancels batch job. Cancelling an array job
+// cancels all of its unfinished tasks.
+func (s *Slurm) SCancel(jobID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance()
+
+	j, ok := s.jobs[jobID]
+	if !ok {
+		return errors.Errorf("invalid job id specified: %d", jobID)
+	}
+	jobs := []*job{j}
+	if j.arrayID == jobID {
+		jobs = s.tasks(jobID)
+	}
+
+	cancelled := false
+	for _, j := range jobs {
+		if s.cancel(j) {
+			cancelled = true
+		}
+	}
+	if !cancelled {
+		return errors.Errorf("job %d has already finished", jobID)
+	}
+
+	// cancellation may free nodes for the pending jobs
+	s.schedule()
+	return nil
+}
+
+// cancel cancels an unfinished job, false is returned if the job has already finished.
+func (s *Slurm) cancel(j *job) bool {
+	switch j.state {
+	case statePending:
+		s.dequeue(j)
+		now := s.now
+		j.end = &now
+	case stateRunning, stateSuspended:
+		s.resume(j)
+		now := s.now
+		j.end = &now
+		s.release(j)
+	default:
+		return false
+	}
+	j.state = stateCancelled
+	j.signal = sigterm
+	s.appendOutput(j, fmt.Sprintf("slurmstepd: *** JOB %d CANCELLED AT %s ***\n", j.id, s.now.Format(time.RFC3339)))
+	return true
+}
+
+// SHold prevents a pending job from being scheduled.
+func (s *Slurm) SHold(jobID int64) error {
+	return s.update(jobID, func(j *job) error {
+		if j.state != statePending {
+			return errors.Errorf("job %d is no longer pending execution", jobID)
+		}
+		j.held = true
+		return nil
+	})
+}
+
+// SRelease releases a held job.
+func (s *Slurm) SRelease(jobID int64) error {
+	return s.update(jobID, func(j *job) error {
+		if j.state != statePending {
+			return errors.Errorf("job %d is no longer pending execution", jobID)
+		}
+		j.held = false
+		return nil
+	})
+}
+
+// SSuspend suspends a running job. Suspended job keeps its nodes
+// and its run time stops until the job is resumed.
+func (s *Slurm) SSuspend(jobID int64) error {
+	return s.update(jobID, func(j *job) error {
+		if j.state != stateRunning {
+			return errors.Errorf("job %d is not running", jobID)
+		}
+		now := s.now
+		j.state = stateSuspended
+		j.suspendedAt = &now
+		j.remaining = j.end.Sub(now)
+		j.end = nil
+		return nil
+	})
+}
+
+// SResume resumes a suspended job.
+func (s *Slurm) SResume(jobID int64) error {
+	return s.update(jobID, func(j *job) error {
+		if j.state != stateSuspended {
+			return errors.Errorf("job %d is not suspended", jobID)
+		}
+		s.resume(j)
+		return nil
+	})
+}
+
+// SRequeue puts a running or suspended job back to the queue
+// releasing its nodes. Requeued job is held when hold is true.
+func (s *Slurm) SRequeue(jobID int64, hold bool) error {
+	return s.update(jobID, func(j *job) error {
+		if j.state != stateRunning && j.state != stateSuspended {
+			return errors.Errorf("job %d is not running", jobID)
+		}
+		s.requeue(j, hold)
+		return nil
+	})
+}
+
+// SRequeueTask puts a task of a job array back to the queue.
+// Unlike SRequeue, it requeues finished tasks as well.
+func (s *Slurm) SRequeueTask(jobID, taskID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance()
+
+	var task *job
+	for _, j := range s.tasks(jobID) {
+		if j.taskID == taskID {
+			task = j
+		}
+	}
+	if task == nil {
+		return errors.Errorf("invalid job id specified: %d_%d", jobID, taskID)
+	}
+	if task.state == statePending {
+		return errors.Errorf("job %d_%d is pending execution", jobID, taskID)
+	}
+
+	s.requeue(task, false)
+	s.schedule()
+	return nil
+}
+
+// requeue puts a job back to the queue releasing its nodes
+// if it is still running. Requeued job is held when hold is true.
+func (s *Slurm) requeue(j *job, hold bool) {
+	if j.state == stateRunning || j.state == stateSuspended {
+		s.release(j)
+	}
+	s.appendOutput(j, fmt.Sprintf("slurmstepd: *** JOB %d REQUEUED AT %s ***\n", j.id, s.now.Format(time.RFC3339)))
+
+	j.state = statePending
+	j.signal = 0
+	j.held = hold
+	j.eligible = s.now
+	j.start, j.end, j.nodeList = nil, nil, nil
+	j.suspendedAt, j.remaining, j.suspended = nil, 0, 0
+	s.queue = append(s.queue, j)
+}
+
+// tasks returns tasks of the job array in index order,
+// nil is returned if the job is not an array.
+func (s *Slurm) tasks(arrayID int64) []*job {
+	var tasks []*job
+	for id := arrayID; ; id++ {
+		j, ok := s.jobs[id]
+		if !ok || j.arrayID != arrayID {
+			return tasks
+		}
+		tasks = append(tasks, j)
+	}
+}
+
+// runningTasks returns the number of running or suspended tasks of the job array.
+func (s *Slurm) runningTasks(arrayID int64) int64 {
+	var n int64
+	for _, j := range s.tasks(arrayID) {
+		if j.state == stateRunning || j.state == stateSuspended {
+			n++
+		}
+	}
+	return n
+}
+
+// update applies f to a job and reschedules pending
+// jobs since the change may free or claim nodes.
+func (s *Slurm) update(jobID int64, f func(j *job) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance()
+
+	j, ok := s.jobs[jobID]
+	if !ok {
+		return errors.Errorf("invalid job id specified: %d", jobID)
+	}
+	if err := f(j); err != nil {
+		return err
+	}
+
+	s.schedule()
+	return nil
+}
+
+// resume continues a suspended job, time spent
+// suspended postpones the job end.
+func (s *Slurm) resume(j *job) {
+	if j.state != stateSuspended {
+		return
+	}
+	end := s.now.Add(j.remaining)
+	j.state = stateRunning
+	j.suspended += s.now.Sub(*j.suspendedAt)
+	j.suspendedAt = nil
+	j.end = &end
+}
+
+// SJobInfo returns information about a particular slurm job by ID.
+// Like scontrol, info of an array job contains all of its tasks.
+func (s *Slurm) SJobInfo(jobID int64) ([]*slurm.JobInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance()
+
+	j, ok := s.jobs[jobID]
+	if !ok {
+		return nil, errors.Wrapf(slurm.ErrJobNotFound, "invalid job id specified: %d", jobID)
+	}
+	if j.arrayID != jobID {
+		return []*slurm.JobInfo{s.jobInfo(j)}, nil
+	}
+
+	var infos []*slurm.JobInfo
+	for _, t := range s.tasks(jobID) {
+		infos = append(infos, s.jobInfo(t))
+	}
+	return infos, nil
+}
+
+// SJobsInfo returns information about all simulated jobs ordered by ID.
+func (s *Slurm) SJobsInfo() ([]*slurm.JobInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance()
+
+	ids := make([]int64, 0, len(s.jobs))
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	infos := make([]*slurm.JobInfo, len(ids))
+	for i, id := range ids {
+		infos[i] = s.jobInfo(s.jobs[id])
+	}
+	return infos, nil
+}
+
+func (s *Slurm) jobInfo(j *job) *slurm.JobInfo {
+	var runTime time.Duration
+	if j.start != nil {
+		end := s.now
+		switch {
+		case j.state == stateSuspended:
+			end = *j.suspendedAt
+		case j.state != stateRunning:
+			end = *j.end
+		}
+		runTime = end.Sub(*j.start) - j.suspended
+	}
+
+	var batchHost string
+	if len(j.nodeList) != 0 {
+		batchHost = j.nodeList[0]
+	}
+
+	submit := j.submit
+	info := &slurm.JobInfo{
+		ID:         strconv.FormatInt(j.id, 10),
+		UserID:     s.user,
+		Name:       j.name,
+		ExitCode:   fmt.Sprintf("%d:%d", j.exitCodeSoFar(), j.signal),
+		State:      j.state,
+		SubmitTime: &submit,
+		StartTime:  j.start,
+		EndTime:    j.end,
+		RunTime:    &runTime,
+		TimeLimit:  j.timeLimit,
+		WorkDir:    s.cfg.Dir,
+		StdOut:     j.stdOut,
+		StdErr:     j.stdOut,
+		Partition:  j.partition,
+		NodeList:   strings.Join(j.nodeList, ","),
+		BatchHost:  batchHost,
+		NumNodes:   strconv.FormatInt(j.nodes, 10),
+		NumCPUs:    j.cpus,
+		Reason:     "None",
+		Priority:   1,
+		Account:    "(null)",
+		QOS:        "normal",
+		TRES: map[string]string{
+			"cpu":  strconv.FormatInt(j.cpus, 10),
+			"node": strconv.FormatInt(j.nodes, 10),
+		},
+	}
+	// like the backfill scheduler, the simulator only
+	// knows when a job becomes eligible for scheduling
+	if j.arrayID != 0 {
+		info.ArrayJobID = strconv.FormatInt(j.arrayID, 10)
+		info.ArrayTaskID = strconv.FormatInt(j.taskID, 10)
+	}
+	if j.state == statePending {
+		info.Reason = "Resources"
+		switch {
+		case j.held:
+			info.Reason = "JobHeldUser"
+		case j.throttle != 0 && s.runningTasks(j.arrayID) >= j.throttle:
+			info.Reason = "JobArrayTaskLimit"
+		case s.now.Before(j.eligible):
+			eligible := j.eligible
+			info.Reason = "BeginTime"
+			info.EstimatedStart = &eligible
+		}
+	}
+	return info
+}
+
+// SJobSteps returns information about a submitted batch job.
+// Like sacct, it returns the job allocation followed by the batch
+// step once the job is started.
+func (s *Slurm) SJobSteps(jobID int64) ([]*slurm.JobStepInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance()
+
+	j, ok := s.jobs[jobID]
+	if !ok {
+		return nil, errors.Wrapf(slurm.ErrJobNotFound, "invalid job id specified: %d", jobID)
+	}
+
+	id := strconv.FormatInt(j.id, 10)
+	steps := []*slurm.JobStepInfo{{
+		ID:         id,
+		Name:       j.name,
+		StartedAt:  j.start,
+		FinishedAt: j.end,
+		ExitCode:   j.exitCodeSoFar(),
+		State:      j.state,
+	}}
+	if j.state == statePending || j.state == stateRunning || j.state == stateSuspended {
+		steps[0].FinishedAt = nil
+	}
+	if j.start != nil {
+		batch := *steps[0]
+		batch.ID = id + ".batch"
+		batch.Name = "batch"
+		if j.state == stateTimeout {
+			batch.State = stateCancelled
+		}
+		steps = append(steps, &batch)
+	}
+	return steps, nil
+}
+
+// SJobScript returns the submitted batch script. The simulator
+// doesn't capture submission environment, so it is always empty.
+func (s *Slurm) SJobScript(jobID int64) (*slurm.JobScript, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[jobID]
+	if !ok {
+		return nil, errors.Wrapf(slurm.ErrJobNotFound, "invalid job id specified: %d", jobID)
+	}
+	return &slurm.JobScript{Script: j.script}, nil
+}
+
+// Resources returns available resources for a partition.
+func (s *Slurm) Resources(partition string) (*slurm.Resources, error) {
+	p, ok := s.cfg.Partitions[partition]
+	if !ok {
+		return nil, errors.Errorf("partition %s not found", partition)
+	}
+
+	wallTime := p.WallTime
+	if wallTime == 0 {
+		wallTime = time.Duration(-1)
+	}
+	return &slurm.Resources{
+		Nodes:      p.Nodes,
+		CPUPerNode: p.CPUPerNode,
+		MemPerNode: p.MemPerNode,
+		WallTime:   wallTime,
+		// simulated partitions are homogeneous
+		NodeClasses: []slurm.NodeClass{{
+			Nodes:      p.Nodes,
+			CPUPerNode: p.CPUPerNode,
+			MemPerNode: p.MemPerNode,
+		}},
+		TRES: map[string]string{
+			"cpu":  strconv.FormatInt(p.Nodes*p.CPUPerNode, 10),
+			"mem":  strconv.FormatInt(p.Nodes*p.MemPerNode, 10) + "M",
+			"node": strconv.FormatInt(p.Nodes, 10),
+		},
+	}, nil
+}
+
+// Capacity returns current usage of a simulated partition.
+// Jobs allocate their nodes exclusively.
+func (s *Slurm) Capacity(partition string) (*slurm.Capacity, error) {
+	p, ok := s.cfg.Partitions[partition]
+	if !ok {
+		return nil, errors.Errorf("partition %s not found", partition)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advance()
+
+	var c slurm.Capacity
+	for _, n := range s.nodes[partition] {
+		if s.busy[n] {
+			c.AllocNodes++
+		} else {
+			c.IdleNodes++
+		}
+	}
+	c.IdleCPUs, c.AllocCPUs = c.IdleNodes*p.CPUPerNode, c.AllocNodes*p.CPUPerNode
+	c.IdleMem, c.AllocMem = c.IdleNodes*p.MemPerNode, c.AllocNodes*p.MemPerNode
+	return &c, nil
+}
+
+// Partitions returns a list of partition names.
+func (s *Slurm) Partitions() ([]string, error) {
+	return partitionNames(s.cfg.Partitions), nil
+}
+
+// Clusters returns nil since the simulator serves a single default cluster.
+func (s *Slurm) Clusters() ([]string, error) {
+	return nil, nil
+}
+
+// Version returns slurm version.
+func (s *Slurm) Version() (string, error) {
+	return Version, nil
+}
+
+// advance moves the simulation up to the current clock time processing
+// all job starts and completions that should have happened in between.
+func (s *Slurm) advance() {
+	now := s.clock.Now()
+	for {
+		s.schedule()
+
+		next, ok := s.nextEvent()
+		if !ok || next.After(now) {
+			break
+		}
+		s.now = next
+		s.finish()
+	}
+	if now.After(s.now) {
+		s.now = now
+	}
+}
+
+// nextEvent returns the closest point in time when a running job
+// ends or a pending job becomes eligible for scheduling.
+func (s *Slurm) nextEvent() (time.Time, bool) {
+	var next time.Time
+	var found bool
+	consider := func(t time.Time) {
+		if !found || t.Before(next) {
+			next, found = t, true
+		}
+	}
+
+	for _, j := range s.queue {
+		if !j.held && j.eligible.After(s.now) {
+			consider(j.eligible)
+		}
+	}
+	for _, j := range s.jobs {
+		if j.state == stateRunning {
+			consider(*j.end)
+		}
+	}
+	return next, found
+}
+
+// schedule starts eligible pending jobs in submission order
+// as long as their partitions have enough free nodes. Tasks of
+// a throttled job array wait for running tasks to finish.
+func (s *Slurm) schedule() {
+	running := make(map[int64]int64)
+	for _, j := range s.jobs {
+		if j.arrayID != 0 && (j.state == stateRunning || j.state == stateSuspended) {
+			running[j.arrayID]++
+		}
+	}
+
+	blocked := make(map[string]bool)
+	pending := s.queue[:0]
+	for _, j := range s.queue {
+		if j.held || blocked[j.partition] || j.eligible.After(s.now) ||
+			(j.throttle != 0 && running[j.arrayID] >= j.throttle) {
+			pending = append(pending, j)
+			continue
+		}
+
+		var free []string
+		for _, n := range s.nodes[j.partition] {
+			if !s.busy[n] {
+				free = append(free, n)
+			}
+		}
+		if int64(len(free)) < j.nodes {
+			// keep FIFO order within a partition
+			blocked[j.partition] = true
+			pending = append(pending, j)
+			continue
+		}
+
+		s.run(j, free[:j.nodes])
+		if j.arrayID != 0 {
+			running[j.arrayID]++
+		}
+	}
+	s.queue = pending
+}
+
+func (s *Slurm) run(j *job, nodes []string) {
+	for _, n := range nodes {
+		s.busy[n] = true
+	}
+
+	start := s.now
+	end := start.Add(j.duration)
+	if j.timeLimit != nil && j.duration > *j.timeLimit {
+		end = start.Add(*j.timeLimit)
+	}
+
+	j.state = stateRunning
+	j.nodeList = nodes
+	j.start = &start
+	j.end = &end
+	s.appendOutput(j, fmt.Sprintf("fake job %d started at %s on %s\n",
+		j.id, start.Format(time.RFC3339), strings.Join(nodes, ",")))
+}
+
+// finish completes running jobs that should have ended by now.
+func (s *Slurm) finish() {
+	for _, j := range s.jobs {
+		if j.state != stateRunning || j.end.After(s.now) {
+			continue
+		}
+
+		switch {
+		case j.timeLimit != nil && j.duration > *j.timeLimit:
+			j.state = stateTimeout
+			j.signal = sigterm
+			s.appendOutput(j, fmt.Sprintf("slurmstepd: *** JOB %d CANCELLED AT %s DUE TO TIME LIMIT ***\n",
+				j.id, j.end.Format(time.RFC3339)))
+		case j.exitCode != 0:
+			j.state = stateFailed
+		default:
+			j.state = stateCompleted
+		}
+		s.release(j)
+		s.appendOutput(j, fmt.Sprintf("fake job %d finished at %s with exit code %d\n",
+			j.id, j.end.Format(time.RFC3339), j.exitCodeSoFar()))
+	}
+}
+
+func (s *Slurm) release(j *job) {
+	for _, n := range j.nodeList {
+		delete(s.busy, n)
+	}
+}
+
+func (s *Slurm) dequeue(j *job) {
+	for i, q := range s.queue {
+		if q == j {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// outputPath returns job output file path with substituted %j (job id),
+// %x (job name), %A (array job id) and %a (array task index) patterns,
+// relative paths are resolved against the simulator working directory.
+func (s *Slurm) outputPath(j *job, output string) string {
+	arrayID, taskID := strconv.FormatInt(j.id, 10), noTask
+	if j.arrayID != 0 {
+		arrayID, taskID = strconv.FormatInt(j.arrayID, 10), strconv.FormatInt(j.taskID, 10)
+	}
+	if output == "" {
+		output = defaultOutput
+		if j.arrayID != 0 {
+			output = defaultArrayOutput
+		}
+	}
+	output = strings.NewReplacer(
+		"%j", strconv.FormatInt(j.id, 10),
+		"%x", j.name,
+		"%A", arrayID,
+		"%a", taskID,
+	).Replace(output)
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(s.cfg.Dir, output)
+	}
+	return output
+}
+
+func (s *Slurm) appendOutput(j *job, line string) {
+	f, err := os.OpenFile(j.stdOut, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("Could not open job %d output: %v", j.id, err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(line); err != nil {
+		log.Printf("Could not write job %d output: %v", j.id, err)
+	}
+}
+
+// exitCodeSoFar returns job exit code if the job has finished on its own
+// and zero otherwise, which is what Slurm reports.
+func (j *job) exitCodeSoFar() int {
+	if j.state == stateFailed {
+		return j.exitCode
+	}
+	return 0
+}
+
+func partitionNames(partitions map[string]Partition) []string {
+	names := make([]string, 0, len(partitions))
+	for name := range partitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSlurm(t *testing.T) (*Slurm, *ManualClock, func()) {
+	dir, err := ioutil.TempDir("", "fake-slurm")
+	require.NoError(t, err)
+
+	clock := NewManualClock(time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC))
+	s, err := NewSlurm(Config{
+		Partitions: map[string]Partition{
+			"debug": {Nodes: 2, CPUPerNode: 4, MemPerNode: 1024, WallTime: time.Hour},
+			"long":  {Nodes: 1},
+		},
+		JobDuration: time.Minute,
+		PendingTime: 10 * time.Second,
+		Dir:         dir,
+		Clock:       clock,
+	})
+	require.NoError(t, err)
+	return s, clock, func() { os.RemoveAll(dir) }
+}
+
+func requireState(t *testing.T, s *Slurm, id int64, state, exitCode string) {
+	t.Helper()
+
+	info, err := s.SJobInfo(id)
+	require.NoError(t, err)
+	require.Len(t, info, 1)
+	require.Equal(t, state, info[0].State)
+	require.Equal(t, exitCode, info[0].ExitCode)
+}
+
+func TestSlurm_lifecycle(t *testing.T) {
+	s, clock, cleanup := newTestSlurm(t)
+	defer cleanup()
+
+	completed, err := s.SBatch("#!/bin/sh\n#SBATCH -J test\nsrun hostname", "")
+	require.NoError(t, err)
+	failed, err := s.SBatch("#!/bin/sh\n#FAKE duration=2m exit_code=3\nexit 3", "debug")
+	require.NoError(t, err)
+	timeout, err := s.SBatch("#!/bin/sh\n#SBATCH --time=0:30\nsleep 60", "debug")
+	require.NoError(t, err)
+
+	requireState(t, s, completed, statePending, "0:0")
+	info, err := s.SJobInfo(completed)
+	require.NoError(t, err)
+	require.Equal(t, "BeginTime", info[0].Reason)
+	require.Equal(t, time.Date(2019, 3, 1, 12, 0, 10, 0, time.UTC), *info[0].EstimatedStart)
+	steps, err := s.SJobSteps(completed)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+
+	// first two jobs occupy both nodes, the third one waits
+	clock.Advance(10 * time.Second)
+	requireState(t, s, completed, stateRunning, "0:0")
+	requireState(t, s, failed, stateRunning, "0:0")
+	requireState(t, s, timeout, statePending, "0:0")
+
+	info, err = s.SJobInfo(completed)
+	require.NoError(t, err)
+	require.Equal(t, "test", info[0].Name)
+	require.Equal(t, "debug-1", info[0].BatchHost)
+	require.Equal(t, time.Hour, *info[0].TimeLimit)
+
+	// the third job starts on the node freed by the first one and times out
+	clock.Advance(5 * time.Minute)
+	requireState(t, s, completed, stateCompleted, "0:0")
+	requireState(t, s, failed, stateFailed, "3:0")
+	requireState(t, s, timeout, stateTimeout, "0:15")
+
+	info, err = s.SJobInfo(timeout)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2019, 3, 1, 12, 1, 10, 0, time.UTC), *info[0].StartTime)
+	require.Equal(t, 30*time.Second, *info[0].RunTime)
+
+	steps, err = s.SJobSteps(failed)
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	require.Equal(t, "batch", steps[1].Name)
+	require.Equal(t, 3, steps[1].ExitCode)
+	require.Equal(t, 2*time.Minute, steps[1].FinishedAt.Sub(*steps[1].StartedAt))
+
+	out, err := ioutil.ReadFile(info[0].StdOut)
+	require.NoError(t, err)
+	require.Contains(t, string(out), "DUE TO TIME LIMIT")
+}
+
+func TestSlurm_SCancel(t *testing.T) {
+	s, clock, cleanup := newTestSlurm(t)
+	defer cleanup()
+
+	running, err := s.SBatch("#!/bin/sh\n#SBATCH --nodes=1", "long")
+	require.NoError(t, err)
+	pending, err := s.SBatch("#!/bin/sh", "long")
+	require.NoError(t, err)
+
+	clock.Advance(20 * time.Second)
+	requireState(t, s, running, stateRunning, "0:0")
+	requireState(t, s, pending, statePending, "0:0")
+
+	require.NoError(t, s.SCancel(pending))
+	require.NoError(t, s.SCancel(running))
+	require.Error(t, s.SCancel(running))
+	require.Error(t, s.SCancel(100))
+	requireState(t, s, running, stateCancelled, "0:15")
+	requireState(t, s, pending, stateCancelled, "0:15")
+}
+
+func TestSlurm_SHold(t *testing.T) {
+	s, clock, cleanup := newTestSlurm(t)
+	defer cleanup()
+
+	held, err := s.SBatch("#!/bin/sh", "long")
+	require.NoError(t, err)
+	next, err := s.SBatch("#!/bin/sh", "long")
+	require.NoError(t, err)
+	require.NoError(t, s.SHold(held))
+
+	// held job doesn't block the partition
+	clock.Advance(20 * time.Second)
+	requireState(t, s, held, statePending, "0:0")
+	requireState(t, s, next, stateRunning, "0:0")
+	require.Error(t, s.SHold(next))
+	info, err := s.SJobInfo(held)
+	require.NoError(t, err)
+	require.Equal(t, "JobHeldUser", info[0].Reason)
+
+	require.NoError(t, s.SRelease(held))
+	clock.Advance(time.Minute)
+	requireState(t, s, held, stateRunning, "0:0")
+	require.Error(t, s.SRelease(held))
+	require.Error(t, s.SHold(100))
+}
+
+func TestSlurm_SSuspend(t *testing.T) {
+	s, clock, cleanup := newTestSlurm(t)
+	defer cleanup()
+
+	id, err := s.SBatch("#!/bin/sh", "long")
+	require.NoError(t, err)
+	require.Error(t, s.SSuspend(id))
+
+	clock.Advance(40 * time.Second)
+	require.NoError(t, s.SSuspend(id))
+	require.Error(t, s.SSuspend(id))
+
+	// suspended job keeps its node and doesn't run
+	clock.Advance(time.Hour)
+	requireState(t, s, id, stateSuspended, "0:0")
+	capacity, err := s.Capacity("long")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, capacity.AllocNodes)
+	info, err := s.SJobInfo(id)
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, *info[0].RunTime)
+
+	require.NoError(t, s.SResume(id))
+	require.Error(t, s.SResume(id))
+	clock.Advance(20 * time.Second)
+	requireState(t, s, id, stateRunning, "0:0")
+	clock.Advance(20 * time.Second)
+	requireState(t, s, id, stateCompleted, "0:0")
+	info, err = s.SJobInfo(id)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, *info[0].RunTime)
+}
+
+func TestSlurm_SRequeue(t *testing.T) {
+	s, clock, cleanup := newTestSlurm(t)
+	defer cleanup()
+
+	id, err := s.SBatch("#!/bin/sh", "long")
+	require.NoError(t, err)
+	require.Error(t, s.SRequeue(id, true))
+
+	clock.Advance(40 * time.Second)
+	require.NoError(t, s.SRequeue(id, true))
+	requireState(t, s, id, statePending, "0:0")
+	capacity, err := s.Capacity("long")
+	require.NoError(t, err)
+	require.EqualValues(t, 0, capacity.AllocNodes)
+
+	clock.Advance(time.Hour)
+	requireState(t, s, id, statePending, "0:0")
+	require.NoError(t, s.SRelease(id))
+	requireState(t, s, id, stateRunning, "0:0")
+	clock.Advance(time.Minute)
+	requireState(t, s, id, stateCompleted, "0:0")
+}
+
+func TestSlurm_array(t *testing.T) {
+	s, clock, cleanup := newTestSlurm(t)
+	defer cleanup()
+
+	id, err := s.SBatch("#!/bin/sh\n#SBATCH --array=0-4%1\n#FAKE failed_tasks=2\nsrun hostname", "debug")
+	require.NoError(t, err)
+
+	states := func() map[string]string {
+		info, err := s.SJobInfo(id)
+		require.NoError(t, err)
+		states := make(map[string]string)
+		for _, i := range info {
+			require.Equal(t, strconv.FormatInt(id, 10), i.ArrayJobID)
+			states[i.ArrayTaskID] = i.State + " " + i.Reason
+		}
+		return states
+	}
+
+	// throttled tasks wait for the running one even though there is a free node
+	clock.Advance(10 * time.Second)
+	require.Equal(t, map[string]string{
+		"0": "RUNNING None",
+		"1": "PENDING JobArrayTaskLimit",
+		"2": "PENDING JobArrayTaskLimit",
+		"3": "PENDING JobArrayTaskLimit",
+		"4": "PENDING JobArrayTaskLimit",
+	}, states())
+
+	clock.Advance(3 * time.Minute)
+	require.Equal(t, map[string]string{
+		"0": "COMPLETED None",
+		"1": "COMPLETED None",
+		"2": "FAILED None",
+		"3": "RUNNING None",
+		"4": "PENDING JobArrayTaskLimit",
+	}, states())
+
+	// a finished task is requeued alone
+	require.Error(t, s.SRequeueTask(id, 4))
+	require.NoError(t, s.SRequeueTask(id, 2))
+	require.Equal(t, "PENDING JobArrayTaskLimit", states()["2"])
+
+	// cancelling the array job cancels all unfinished tasks
+	require.NoError(t, s.SCancel(id))
+	require.Equal(t, map[string]string{
+		"0": "COMPLETED None",
+		"1": "COMPLETED None",
+		"2": "CANCELLED None",
+		"3": "CANCELLED None",
+		"4": "CANCELLED None",
+	}, states())
+	require.Error(t, s.SCancel(id))
+}
+
+func TestSlurm_SBatch(t *testing.T) {
+	s, _, cleanup := newTestSlurm(t)
+	defer cleanup()
+
+	tt := []struct {
+		name   string
+		script string
+		part   string
+	}{
+		{name: "unknown partition", script: "#!/bin/sh", part: "gpu"},
+		{name: "too many nodes", script: "#!/bin/sh\n#SBATCH --nodes=3", part: "debug"},
+		{name: "too much memory", script: "#!/bin/sh\n#SBATCH --mem=2048", part: "debug"},
+		{name: "too long", script: "#!/bin/sh\n#SBATCH --time=2:00:00", part: "debug"},
+		{name: "invalid fake directive", script: "#!/bin/sh\n#FAKE duration=soon", part: "debug"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.SBatch(tc.script, tc.part)
+			require.Error(t, err)
+		})
+	}
+
+	e, err := s.SBatchTest("#!/bin/sh\n#SBATCH --nodes=3", "debug")
+	require.NoError(t, err)
+	require.Equal(t, []string{"requested node configuration is not available"}, e.Errors)
+
+	e, err = s.SBatchTest("#!/bin/sh\n#SBATCH --nodes=2", "debug")
+	require.NoError(t, err)
+	require.Empty(t, e.Errors)
+	require.Equal(t, "debug-1,debug-2", e.NodeList)
+	require.Equal(t, time.Date(2019, 3, 1, 12, 0, 10, 0, time.UTC), *e.StartTime)
+
+	partitions, err := s.Partitions()
+	require.NoError(t, err)
+	require.Equal(t, []string{"debug", "long"}, partitions)
+
+	r, err := s.Resources("long")
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(-1), r.WallTime)
+}
+
+func TestParseScript(t *testing.T) {
+	opts, err := parseScript("#!/bin/sh\n" +
+		"#SBATCH --exclusive -N 2 --requeue --mem 2G\n" +
+		"#SBATCH -J test --hold -c 4\n" +
+		"hostname\n")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, opts.nodes)
+	require.EqualValues(t, 2048, opts.mem)
+	require.EqualValues(t, 4, opts.cpuPerTask)
+	require.Equal(t, "test", opts.name)
+
+	for value, mem := range map[string]int64{"512": 512, "512M": 512, "1g": 1024, "1T": 1 << 20, "1536K": 2} {
+		opts, err = parseScript("#SBATCH --mem=" + value)
+		require.NoError(t, err, value)
+		require.Equal(t, mem, opts.mem, value)
+	}
+	_, err = parseScript("#SBATCH --mem=1X")
+	require.Error(t, err)
+	_, err = parseScript("#SBATCH --mem=-1G")
+	require.Error(t, err)
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2019 Sylabs, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dptech-corp/wlm-operator/pkg/slurm"
+	"github.com/pkg/errors"
+)
+
+const (
+	sbatchDirective = "#SBATCH"
+	fakeDirective   = "#FAKE"
+)
+
+// batchOptions holds job parameters that are read from a batch script.
+type batchOptions struct {
+	name       string
+	partition  string
+	output     string
+	nodes      int64
+	cpuPerTask int64
+	mem        int64
+	timeLimit  *time.Duration
+	// array holds sorted indices of job array tasks, throttle limits
+	// the number of simultaneously running tasks when it is not zero
+	array    []int64
+	throttle int64
+
+	// Simulation parameters that are set with #FAKE directive.
+	duration    *time.Duration
+	exitCode    int
+	failedTasks map[int64]bool
+}
+
+// taskExitCode returns exit code of the job array task with the given index.
+// When failed tasks are set, the rest of the tasks succeed.
+func (o *batchOptions) taskExitCode(index int64) int {
+	if o.failedTasks == nil {
+		return o.exitCode
+	}
+	if !o.failedTasks[index] {
+		return 0
+	}
+	if o.exitCode == 0 {
+		return 1
+	}
+	return o.exitCode
+}
+
+// cpus returns the number of cpus allocated to a job, one task per node is assumed.
+func (o *batchOptions) cpus() int64 {
+	if o.cpuPerTask == 0 {
+		return o.nodes
+	}
+	return o.nodes * o.cpuPerTask
+}
+
+// parseScript reads #SBATCH and #FAKE directives from the script header.
+// Like sbatch, it stops at the first line that is neither a comment nor empty.
+// Supported #FAKE parameters are duration (Go duration format), exit_code and
+// failed_tasks, which limits exit code to the given job array tasks,
+// e.g. '#FAKE duration=5m exit_code=1 failed_tasks=3,7-9'.
+func parseScript(script string) (*batchOptions, error) {
+	opts := &batchOptions{nodes: 1}
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+
+		fields := strings.Fields(line)
+		var err error
+		switch fields[0] {
+		case sbatchDirective:
+			err = opts.parseSbatch(fields[1:])
+		case fakeDirective:
+			err = opts.parseFake(fields[1:])
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid directive %q", line)
+		}
+	}
+	return opts, nil
+}
+
+// sbatchFlags are sbatch options that take no value, so the argument
+// that follows them is an option of its own.
+var sbatchFlags = map[string]bool{
+	"--contiguous":    true,
+	"--exclusive":     true,
+	"-H":              true,
+	"--hold":          true,
+	"--ignore-pbs":    true,
+	"-O":              true,
+	"--overcommit":    true,
+	"--parsable":      true,
+	"-Q":              true,
+	"--quiet":         true,
+	"--requeue":       true,
+	"--no-requeue":    true,
+	"-s":              true,
+	"--oversubscribe": true,
+	"--spread-job":    true,
+	"--test-only":     true,
+	"--use-min-nodes": true,
+	"-v":              true,
+	"--verbose":       true,
+	"-W":              true,
+	"--wait":          true,
+}
+
+func (o *batchOptions) parseSbatch(args []string) error {
+	for i := 0; i < len(args); i++ {
+		key, value := args[i], ""
+		if j := strings.IndexByte(key, '='); j != -1 {
+			key, value = key[:j], key[j+1:]
+		} else if i+1 < len(args) && !sbatchFlags[key] && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			value = args[i]
+		}
+
+		var err error
+		switch key {
+		case "-J", "--job-name":
+			o.name = value
+		case "-p", "--partition":
+			o.partition = value
+		case "-o", "--output":
+			o.output = value
+		case "-N", "--nodes":
+			// min-max node count, fake scheduler always allocates min
+			o.nodes, err = strconv.ParseInt(strings.SplitN(value, "-", 2)[0], 10, 0)
+		case "-c", "--cpus-per-task":
+			o.cpuPerTask, err = strconv.ParseInt(value, 10, 0)
+		case "--mem":
+			o.mem, err = parseMemory(value)
+		case "-a", "--array":
+			o.array, o.throttle, err = slurm.ParseArrayIndices(value)
+		case "-t", "--time":
+			o.timeLimit, err = slurm.ParseDuration(value)
+			if err == slurm.ErrDurationIsUnlimited {
+				o.timeLimit, err = nil, nil
+			}
+		}
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s value", key)
+		}
+	}
+	return nil
+}
+
+// parseMemory parses sbatch memory size with an optional K, M, G or T
+// suffix and returns it in megabytes, megabytes are the default unit.
+func parseMemory(value string) (int64, error) {
+	shifts := map[string]uint{"K": 0, "M": 10, "G": 20, "T": 30}
+	shift := shifts["M"]
+	if n := len(value); n != 0 {
+		if sh, ok := shifts[strings.ToUpper(value[n-1:])]; ok {
+			shift, value = sh, value[:n-1]
+		}
+	}
+	size, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, err
+	}
+	if size < 0 {
+		return 0, errors.Errorf("negative memory size %d", size)
+	}
+	// kilobytes are rounded up to whole megabytes
+	return (size<<shift + 1<<10 - 1) >> 10, nil
+}
+
+func (o *batchOptions) parseFake(args []string) error {
+	for _, arg := range args {
+		s := strings.SplitN(arg, "=", 2)
+		if len(s) != 2 {
+			return errors.Errorf("%s is not a key=value pair", arg)
+		}
+
+		switch s[0] {
+		case "duration":
+			d, err := time.ParseDuration(s[1])
+			if err != nil {
+				return errors.Wrap(err, "invalid duration")
+			}
+			o.duration = &d
+		case "exit_code":
+			code, err := strconv.Atoi(s[1])
+			if err != nil {
+				return errors.Wrap(err, "invalid exit code")
+			}
+			o.exitCode = code
+		case "failed_tasks":
+			indices, _, err := slurm.ParseArrayIndices(s[1])
+			if err != nil {
+				return errors.Wrap(err, "invalid failed tasks")
+			}
+			o.failedTasks = make(map[int64]bool, len(indices))
+			for _, i := range indices {
+				o.failedTasks[i] = true
+			}
+		default:
+			return errors.Errorf("unknown parameter %s", s[0])
+		}
+	}
+	return nil
+}
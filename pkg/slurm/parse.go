@@ -15,6 +15,7 @@
 package slurm
 
 import (
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -31,6 +32,19 @@ const (
 	maxCPUsPerNode = "MaxCPUsPerNode"
 	totalCPUs      = "TotalCPUs"
 	maxMemPerNode  = "MaxMemPerNode"
+
+	tres               = "TRES"
+	tresBillingWeights = "TRESBillingWeights"
+
+	nodeGres       = "Gres"
+	nodePartitions = "Partitions"
+	nodeCPUs       = "CPUTot"
+	nodeMemory     = "RealMemory"
+	nodeFeatures   = "AvailableFeatures"
+	nodeState      = "State"
+	nodeCPUsAlloc  = "CPUAlloc"
+	nodeMemAlloc   = "AllocMem"
+	nodeAllocTRES  = "AllocTRES"
 )
 
 // ParseDuration parses slurm duration string. Possible formats are:
@@ -166,6 +180,18 @@ func parseResources(partitionInfo string) (*Resources, error) {
 			resources.MemPerNode = mem
 		}
 	}
+	for _, f := range fields {
+		s := strings.SplitN(f, "=", 2)
+		if len(s) != 2 {
+			continue
+		}
+		switch s[0] {
+		case tres:
+			resources.TRES = parseTRES(s[1])
+		case tresBillingWeights:
+			resources.TRESBillingWeights = parseTRES(s[1])
+		}
+	}
 	if maxNodes, ok := fMap[maxNodes]; ok {
 		if maxNodes[0] == unlimited {
 			resources.Nodes = -1
@@ -189,6 +215,242 @@ func parseResources(partitionInfo string) (*Resources, error) {
 	return &resources, nil
 }
 
+// parseTRES parses a comma separated list of trackable resources,
+// e.g. cpu=4,mem=8G,gres/gpu=2, into a map.
+func parseTRES(raw string) map[string]string {
+	if raw == "" || raw == "(null)" {
+		return nil
+	}
+
+	res := make(map[string]string)
+	for _, r := range strings.Split(raw, ",") {
+		s := strings.SplitN(r, "=", 2)
+		if len(s) != 2 {
+			continue
+		}
+		res[s[0]] = s[1]
+	}
+	return res
+}
+
+// parseGres parses node generic resources, e.g. gpu:v100:8(S:0-1),mps:200,
+// into features. Feature version holds gres type and quantity holds gres count.
+// Entries with a count that can't be parsed are skipped.
+func parseGres(raw string) []Feature {
+	if raw == "" || raw == "(null)" {
+		return nil
+	}
+
+	var features []Feature
+	for _, g := range splitGres(raw) {
+		// drop socket or index affinity, e.g. (S:0-1)
+		if i := strings.IndexByte(g, '('); i != -1 {
+			g = g[:i]
+		}
+
+		s := strings.Split(g, ":")
+		f := Feature{Name: s[0], Quantity: 1}
+		switch len(s) {
+		case 1:
+		case 2:
+			f.Quantity = parseGresCount(s[1])
+		default:
+			f.Version = s[1]
+			f.Quantity = parseGresCount(s[2])
+		}
+		if f.Quantity <= 0 {
+			continue
+		}
+		features = append(features, f)
+	}
+	return features
+}
+
+// splitGres splits gres list by commas that are not enclosed in parentheses,
+// e.g. gpu:2(IDX:0,1),mps:100 is split into gpu:2(IDX:0,1) and mps:100.
+func splitGres(raw string) []string {
+	var res []string
+	depth, start := 0, 0
+	for i, r := range raw {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				res = append(res, raw[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(res, raw[start:])
+}
+
+func parseGresCount(raw string) int64 {
+	multipliers := map[string]int64{
+		"K": 1 << 10,
+		"M": 1 << 20,
+		"G": 1 << 30,
+	}
+
+	m := int64(1)
+	for suffix, v := range multipliers {
+		if strings.HasSuffix(raw, suffix) {
+			raw = strings.TrimSuffix(raw, suffix)
+			m = v
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(raw, 10, 0)
+	if err != nil {
+		return 0
+	}
+	return n * m
+}
+
+// parsePartitionGres extracts generic resources available on nodes of a partition
+// from scontrol show node response. Since jobs request generic resources per node,
+// the largest amount a single node provides is returned for each gres name and type.
+func parsePartitionGres(raw, partition string) []Feature {
+	var features []Feature
+	for _, node := range parsePartitionNodes(raw, partition) {
+	nextGres:
+		for _, g := range parseGres(node[nodeGres]) {
+			for i := range features {
+				if features[i].Name == g.Name && features[i].Version == g.Version {
+					if g.Quantity > features[i].Quantity {
+						features[i].Quantity = g.Quantity
+					}
+					continue nextGres
+				}
+			}
+			features = append(features, g)
+		}
+	}
+	return features
+}
+
+// parseNodeClasses groups nodes of a partition from scontrol show node response
+// by their cpus, memory, gres and features. Classes are sorted by size, so that
+// the class with the most cpus and memory goes first.
+func parseNodeClasses(raw, partition string) []NodeClass {
+	var classes []NodeClass
+	var keys []string
+	for _, node := range parsePartitionNodes(raw, partition) {
+		cpus, _ := strconv.ParseInt(node[nodeCPUs], 10, 0)
+		mem, _ := strconv.ParseInt(node[nodeMemory], 10, 0)
+		key := strings.Join([]string{node[nodeCPUs], node[nodeMemory], node[nodeGres], node[nodeFeatures]}, " ")
+
+		i := 0
+		for ; i < len(keys); i++ {
+			if keys[i] == key {
+				break
+			}
+		}
+		if i == len(keys) {
+			var features []string
+			if f := node[nodeFeatures]; f != "" && f != "(null)" {
+				features = strings.Split(f, ",")
+			}
+			keys = append(keys, key)
+			classes = append(classes, NodeClass{
+				CPUPerNode: cpus,
+				MemPerNode: mem,
+				Gres:       parseGres(node[nodeGres]),
+				Features:   features,
+			})
+		}
+		classes[i].Nodes++
+	}
+
+	sort.SliceStable(classes, func(i, j int) bool {
+		if classes[i].CPUPerNode != classes[j].CPUPerNode {
+			return classes[i].CPUPerNode > classes[j].CPUPerNode
+		}
+		return classes[i].MemPerNode > classes[j].MemPerNode
+	})
+	return classes
+}
+
+// parseCapacity aggregates current usage of partition nodes from scontrol show node response.
+func parseCapacity(raw, partition string) *Capacity {
+	var c Capacity
+	for _, node := range parsePartitionNodes(raw, partition) {
+		// state is a base state followed by flags, e.g. IDLE+DRAIN,
+		// asterisk means that node is not responding
+		state := node[nodeState]
+		if strings.HasSuffix(state, "*") {
+			continue
+		}
+		flags := strings.Split(state, "+")
+		unavailable := false
+		for _, f := range flags {
+			switch f {
+			case "DOWN", "DRAIN", "DRAINED", "DRAINING", "FAIL", "FAILING", "MAINT", "FUTURE", "POWERED_DOWN", "POWER_DOWN":
+				unavailable = true
+			}
+		}
+		if unavailable {
+			continue
+		}
+
+		switch flags[0] {
+		case "IDLE":
+			c.IdleNodes++
+		case "ALLOCATED", "MIXED", "COMPLETING":
+			c.AllocNodes++
+		default:
+			continue
+		}
+
+		cpus, _ := strconv.ParseInt(node[nodeCPUs], 10, 0)
+		allocCPUs, _ := strconv.ParseInt(node[nodeCPUsAlloc], 10, 0)
+		mem, _ := strconv.ParseInt(node[nodeMemory], 10, 0)
+		allocMem, _ := strconv.ParseInt(node[nodeMemAlloc], 10, 0)
+		var gpus, allocGPUs int64
+		for _, g := range parseGres(node[nodeGres]) {
+			if g.Name == "gpu" {
+				gpus += g.Quantity
+			}
+		}
+		if n, ok := parseTRES(node[nodeAllocTRES])["gres/gpu"]; ok {
+			allocGPUs = parseGresCount(n)
+		}
+
+		c.IdleCPUs += cpus - allocCPUs
+		c.AllocCPUs += allocCPUs
+		c.IdleMem += mem - allocMem
+		c.AllocMem += allocMem
+		c.IdleGPUs += gpus - allocGPUs
+		c.AllocGPUs += allocGPUs
+	}
+	return &c
+}
+
+// parsePartitionNodes splits scontrol show node response into
+// node fields and returns nodes belonging to the partition only.
+func parsePartitionNodes(raw, partition string) []map[string]string {
+	var nodes []map[string]string
+	for _, node := range strings.Split(strings.TrimSpace(raw), "\n\n") {
+		fields := make(map[string]string)
+		for _, f := range strings.Fields(node) {
+			if s := strings.SplitN(f, "=", 2); len(s) == 2 {
+				fields[s[0]] = s[1]
+			}
+		}
+
+		for _, p := range strings.Split(fields[nodePartitions], ",") {
+			if p == partition {
+				nodes = append(nodes, fields)
+				break
+			}
+		}
+	}
+	return nodes
+}
+
 // parsePartitionsNames extracts names from scontrol show partitions response.
 func parsePartitionsNames(raw string) []string {
 	const partitionNameF = "PartitionName"
@@ -252,6 +514,77 @@ func parseSacctResponse(raw string) ([]*JobStepInfo, error) {
 	return infos, nil
 }
 
+// sacctJobInfoFormat lists sacct fields parseSacctJobInfo expects.
+const sacctJobInfoFormat = "jobid,jobname,user,uid,state,exitcode,submit,start,end,elapsed,timelimit,workdir,partition,nodelist,nnodes"
+
+// parseSacctJobInfo parses sacct response with sacctJobInfoFormat fields into job info.
+func parseSacctJobInfo(raw string) ([]*JobInfo, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var infos []*JobInfo
+	for _, l := range strings.Split(raw, "\n") {
+		f := strings.Split(l, "|")
+		if len(f) != 15 {
+			return nil, errors.New("output must contain 15 sections")
+		}
+
+		submitTime, err := parseTime(f[6])
+		if err != nil {
+			return nil, err
+		}
+		startTime, err := parseTime(f[7])
+		if err != nil {
+			return nil, err
+		}
+		endTime, err := parseTime(f[8])
+		if err != nil {
+			return nil, err
+		}
+		runTime, err := ParseDuration(f[9])
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse elapsed time: %s", f[9])
+		}
+		// time limit may be UNLIMITED or Partition_Limit
+		timeLimit, _ := ParseDuration(f[10])
+
+		// array jobs are reported as jobid_taskid, tasks
+		// that never started as jobid_[range]
+		arrayJobID, arrayTaskID := "", ""
+		if i := strings.IndexByte(f[0], '_'); i != -1 {
+			arrayJobID = f[0][:i]
+			arrayTaskID = strings.Trim(f[0][i+1:], "[]")
+		}
+		// cancelled jobs are reported as CANCELLED by uid
+		state := f[4]
+		if i := strings.IndexByte(state, ' '); i != -1 {
+			state = state[:i]
+		}
+
+		infos = append(infos, &JobInfo{
+			ID:          f[0],
+			ArrayJobID:  arrayJobID,
+			ArrayTaskID: arrayTaskID,
+			Name:        f[1],
+			UserID:      f[2] + "(" + f[3] + ")",
+			State:       state,
+			ExitCode:    f[5],
+			SubmitTime:  submitTime,
+			StartTime:   startTime,
+			EndTime:     endTime,
+			RunTime:     runTime,
+			TimeLimit:   timeLimit,
+			WorkDir:     f[11],
+			Partition:   f[12],
+			NodeList:    f[13],
+			NumNodes:    f[14],
+		})
+	}
+	return infos, nil
+}
+
 func parseTime(timeStr string) (*time.Time, error) {
 	const slurmTimeLayout = "2006-01-02T15:04:05"
 
@@ -266,3 +599,79 @@ func parseTime(timeStr string) (*time.Time, error) {
 
 	return &t, nil
 }
+
+// sacctRecord strips the header sacct prints before a batch script
+// or an environment of a job. Output without a header is considered empty,
+// e.g. when script is not stored in accounting.
+func sacctRecord(out string) string {
+	const separator = "\n----"
+
+	i := strings.Index(out, separator)
+	if i < 0 {
+		return ""
+	}
+	out = out[i+len(separator):]
+	i = strings.IndexByte(out, '\n')
+	if i < 0 {
+		return ""
+	}
+	return out[i+1:]
+}
+
+// parseSBatchTest parses 'sbatch --test-only' output, e.g.
+// 'sbatch: Job 42 to start at 2019-02-20T11:16:55 using 4 processors on nodes node[1-2] in partition debug'.
+func parseSBatchTest(out string) (*JobEstimate, error) {
+	const (
+		prefix  = "sbatch: "
+		errorP  = "error: "
+		warnP   = "warning: "
+		startAt = " to start at "
+	)
+
+	e := &JobEstimate{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), prefix)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, errorP):
+			e.Errors = append(e.Errors, strings.TrimPrefix(line, errorP))
+		case strings.HasPrefix(line, warnP):
+			e.Warnings = append(e.Warnings, strings.TrimPrefix(line, warnP))
+		case strings.HasPrefix(line, "Job ") && strings.Contains(line, startAt):
+			f := strings.Fields(line[strings.Index(line, startAt)+len(startAt):])
+			// <time> using <cpus> processors on nodes <nodes> in partition <partition>
+			if len(f) != 10 || f[1] != "using" || f[3] != "processors" {
+				return nil, errors.Errorf("unexpected line %q", line)
+			}
+
+			var err error
+			if e.StartTime, err = parseTime(f[0]); err != nil {
+				return nil, errors.Wrap(err, "could not parse start time")
+			}
+			if e.CPUs, err = strconv.ParseInt(f[2], 10, 0); err != nil {
+				return nil, errors.Wrap(err, "could not parse processors")
+			}
+			e.NodeList = f[6]
+			e.Partition = f[9]
+		}
+	}
+	return e, nil
+}
+
+// parseSqueueStart parses 'squeue --start -o %A|%S' output into
+// expected start times by job id. Jobs without an estimate are skipped.
+func parseSqueueStart(out string) map[string]*time.Time {
+	starts := make(map[string]*time.Time)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		f := strings.SplitN(line, "|", 2)
+		if len(f) != 2 {
+			continue
+		}
+		t, err := parseTime(strings.TrimSpace(f[1]))
+		if err != nil || t == nil {
+			continue
+		}
+		starts[strings.TrimSpace(f[0])] = t
+	}
+	return starts
+}
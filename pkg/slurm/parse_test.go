@@ -247,6 +247,28 @@ func Test_parseResources(t *testing.T) {
 				WallTime:   -1,
 				Features:   nil,
 			}},
+		{
+			name: "tres",
+			in:   testScontrolShowGPUPartition,
+			want: &Resources{
+				Nodes:      2,
+				MemPerNode: -1,
+				CPUPerNode: 64,
+				WallTime:   -1,
+				TRES: map[string]string{
+					"cpu":           "128",
+					"mem":           "500G",
+					"node":          "2",
+					"billing":       "128",
+					"gres/gpu":      "10",
+					"gres/gpu:v100": "8",
+				},
+				TRESBillingWeights: map[string]string{
+					"CPU":      "1.0",
+					"Mem":      "0.25G",
+					"GRES/gpu": "2.0",
+				},
+			}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -312,3 +334,200 @@ func Test_parsePartitionsNames(t *testing.T) {
 		})
 	}
 }
+
+const testScontrolShowGPUPartition = `
+PartitionName=gpu
+   AllowGroups=ALL AllowAccounts=ALL AllowQos=ALL
+   AllocNodes=ALL Default=NO QoS=N/A
+   DefaultTime=NONE DisableRootJobs=NO ExclusiveUser=NO GraceTime=0 Hidden=NO
+   MaxNodes=UNLIMITED MaxTime=UNLIMITED MinNodes=0 LLN=NO MaxCPUsPerNode=UNLIMITED
+   Nodes=gpu[01-02]
+   PriorityJobFactor=1 PriorityTier=1 RootOnly=NO ReqResv=NO OverSubscribe=NO
+   OverTimeLimit=NONE PreemptMode=OFF
+   State=UP TotalCPUs=64 TotalNodes=2 SelectTypeParameters=NONE
+   JobDefaults=(null)
+   DefMemPerNode=UNLIMITED MaxMemPerNode=UNLIMITED
+   TRES=cpu=128,mem=500G,node=2,billing=128,gres/gpu=10,gres/gpu:v100=8
+   TRESBillingWeights=CPU=1.0,Mem=0.25G,GRES/gpu=2.0
+`
+
+const testScontrolShowNodes = `
+NodeName=gpu03 Arch=x86_64 CoresPerSocket=8
+   CPUAlloc=0 CPUTot=32 CPULoad=0.01
+   AvailableFeatures=ib,skylake
+   ActiveFeatures=ib,skylake
+   Gres=gpu:v100:8(S:0-1)
+   RealMemory=128000 AllocMem=0 FreeMem=120000 Sockets=2 Boards=1
+   Partitions=gpu
+
+NodeName=gpu01 Arch=x86_64 CoresPerSocket=16
+   CPUAlloc=0 CPUTot=64 CPULoad=0.01
+   AvailableFeatures=(null)
+   ActiveFeatures=(null)
+   Gres=gpu:v100:8(S:0-1),mps:400
+   NodeAddr=gpu01 NodeHostName=gpu01 Version=19.05.5
+   RealMemory=256000 AllocMem=0 FreeMem=250000 Sockets=2 Boards=1
+   State=IDLE ThreadsPerCore=2 TmpDisk=0 Weight=1 Owner=N/A MCS_label=N/A
+   Partitions=gpu,all
+
+NodeName=gpu02 Arch=x86_64 CoresPerSocket=16
+   CPUAlloc=0 CPUTot=64 CPULoad=0.01
+   Gres=gpu:k80:2,gpu:v100:4
+   RealMemory=256000 AllocMem=0 FreeMem=250000 Sockets=2 Boards=1
+   Partitions=gpu,all
+
+NodeName=node01 Arch=x86_64 CoresPerSocket=8
+   CPUAlloc=0 CPUTot=16 CPULoad=0.01
+   Gres=(null)
+   Partitions=debug,all
+`
+
+func Test_parseGres(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Feature
+	}{
+		{in: "(null)", want: nil},
+		{in: "gpu", want: []Feature{{Name: "gpu", Quantity: 1}}},
+		{in: "gpu:4", want: []Feature{{Name: "gpu", Quantity: 4}}},
+		{in: "gpu:2(IDX:0,1),mps:1K", want: []Feature{
+			{Name: "gpu", Quantity: 2},
+			{Name: "mps", Quantity: 1024},
+		}},
+		{in: "gpu:v100:8(S:0-1),gpu:k80:x", want: []Feature{
+			{Name: "gpu", Version: "v100", Quantity: 8},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			require.Equal(t, tt.want, parseGres(tt.in))
+		})
+	}
+}
+
+func Test_parsePartitionGres(t *testing.T) {
+	require.Equal(t, []Feature{
+		{Name: "gpu", Version: "v100", Quantity: 8},
+		{Name: "mps", Quantity: 400},
+		{Name: "gpu", Version: "k80", Quantity: 2},
+	}, parsePartitionGres(testScontrolShowNodes, "gpu"))
+	require.Nil(t, parsePartitionGres(testScontrolShowNodes, "debug"))
+}
+
+func Test_parseNodeClasses(t *testing.T) {
+	require.Equal(t, []NodeClass{
+		{
+			Nodes:      1,
+			CPUPerNode: 64,
+			MemPerNode: 256000,
+			Gres: []Feature{
+				{Name: "gpu", Version: "v100", Quantity: 8},
+				{Name: "mps", Quantity: 400},
+			},
+		},
+		{
+			Nodes:      1,
+			CPUPerNode: 64,
+			MemPerNode: 256000,
+			Gres: []Feature{
+				{Name: "gpu", Version: "k80", Quantity: 2},
+				{Name: "gpu", Version: "v100", Quantity: 4},
+			},
+		},
+		{
+			Nodes:      1,
+			CPUPerNode: 32,
+			MemPerNode: 128000,
+			Gres:       []Feature{{Name: "gpu", Version: "v100", Quantity: 8}},
+			Features:   []string{"ib", "skylake"},
+		},
+	}, parseNodeClasses(testScontrolShowNodes, "gpu"))
+
+	require.Equal(t, []NodeClass{
+		{Nodes: 1, CPUPerNode: 16},
+	}, parseNodeClasses(testScontrolShowNodes, "debug"))
+}
+
+const testScontrolShowNodesUsage = `
+NodeName=node01 CPUAlloc=0 CPUTot=16
+   Gres=gpu:4
+   RealMemory=64000 AllocMem=0
+   State=IDLE Partitions=debug
+   AllocTRES=
+
+NodeName=node02 CPUAlloc=4 CPUTot=16
+   Gres=gpu:4
+   RealMemory=64000 AllocMem=16000
+   State=MIXED Partitions=debug
+   AllocTRES=cpu=4,mem=16000M,gres/gpu=1
+
+NodeName=node03 CPUAlloc=0 CPUTot=16
+   Gres=gpu:4
+   RealMemory=64000 AllocMem=0
+   State=IDLE+DRAIN Partitions=debug
+
+NodeName=node04 CPUAlloc=0 CPUTot=16
+   RealMemory=64000 AllocMem=0
+   State=IDLE* Partitions=debug
+
+NodeName=node05 CPUAlloc=8 CPUTot=8
+   RealMemory=32000 AllocMem=32000
+   State=ALLOCATED Partitions=long
+`
+
+func Test_parseCapacity(t *testing.T) {
+	require.Equal(t, &Capacity{
+		IdleNodes:  1,
+		AllocNodes: 1,
+		IdleCPUs:   28,
+		AllocCPUs:  4,
+		IdleMem:    112000,
+		AllocMem:   16000,
+		IdleGPUs:   7,
+		AllocGPUs:  1,
+	}, parseCapacity(testScontrolShowNodesUsage, "debug"))
+	require.Equal(t, &Capacity{AllocNodes: 1, AllocCPUs: 8, AllocMem: 32000}, parseCapacity(testScontrolShowNodesUsage, "long"))
+}
+
+func Test_parseSBatchTest(t *testing.T) {
+	start := time.Date(2019, 2, 20, 11, 16, 55, 0, time.UTC)
+	tt := []struct {
+		name   string
+		out    string
+		expect *JobEstimate
+	}{
+		{
+			name: "estimate",
+			out: "sbatch: warning: can't honor --ntasks-per-node set to 2\n" +
+				"sbatch: Job 42 to start at 2019-02-20T11:16:55 using 4 processors on nodes node[1-2] in partition debug\n",
+			expect: &JobEstimate{
+				Warnings:  []string{"can't honor --ntasks-per-node set to 2"},
+				StartTime: &start,
+				CPUs:      4,
+				NodeList:  "node[1-2]",
+				Partition: "debug",
+			},
+		},
+		{
+			name: "errors",
+			out: "sbatch: error: invalid partition specified: gpu\n" +
+				"sbatch: error: Batch job submission failed: Invalid partition name specified\n",
+			expect: &JobEstimate{
+				Errors: []string{
+					"invalid partition specified: gpu",
+					"Batch job submission failed: Invalid partition name specified",
+				},
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := parseSBatchTest(tc.out)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, e)
+		})
+	}
+
+	_, err := parseSBatchTest("sbatch: Job 42 to start at soon\n")
+	require.Error(t, err)
+}
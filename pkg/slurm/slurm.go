@@ -15,20 +15,18 @@
 package slurm
 
 import (
-	"archive/zip"
 	"bytes"
 	"io"
-	"log"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/dptech-corp/wlm-operator/pkg/files"
+	"github.com/dptech-corp/wlm-operator/pkg/remote"
 	"github.com/pkg/errors"
-	"github.com/dptech-corp/wlm-operator/pkg/tail"
 )
 
 const (
@@ -37,11 +35,25 @@ const (
 	scontrolBinaryName = "scontrol"
 	sacctBinaryName    = "sacct"
 	sinfoBinaryName    = "sinfo"
+	sacctmgrBinaryName = "sacctmgr"
+	squeueBinaryName   = "squeue"
 
 	submitTime = "SubmitTime"
 	startTime  = "StartTime"
+	endTime    = "EndTime"
 	runTime    = "RunTime"
 	timeLimit  = "TimeLimit"
+	priority   = "Priority"
+	numCPUs    = "NumCPUs"
+
+	statePending = "PENDING"
+
+	// scontrolInvalidJobID is reported by scontrol for unknown
+	// jobs as well as for finished jobs purged by slurmctld.
+	scontrolInvalidJobID = "Invalid job id specified"
+
+	// maxKnownJobs limits the number of jobs which clusters are remembered.
+	maxKnownJobs = 10000
 )
 
 var (
@@ -53,33 +65,94 @@ var (
 	ErrInvalidSacctResponse = errors.New("unable to parse sacct response")
 
 	// ErrFileNotFound is returned when Open fails to find a file.
-	ErrFileNotFound = errors.New("file is not found")
+	ErrFileNotFound = files.ErrFileNotFound
+
+	// ErrJobNotFound is returned when neither scontrol
+	// nor accounting know about a requested job.
+	ErrJobNotFound = errors.New("job is not found")
 )
 
 type (
+	// Slurm is a set of operations red-box performs on a Slurm cluster.
+	Slurm interface {
+		files.FileSystem
+
+		SBatch(script, partition string) (int64, error)
+		SBatchTest(script, partition string) (*JobEstimate, error)
+		SCancel(jobID int64) error
+		SHold(jobID int64) error
+		SRelease(jobID int64) error
+		SSuspend(jobID int64) error
+		SResume(jobID int64) error
+		SRequeue(jobID int64, hold bool) error
+		SRequeueTask(jobID, taskID int64) error
+		SJobInfo(jobID int64) ([]*JobInfo, error)
+		SJobsInfo() ([]*JobInfo, error)
+		SJobSteps(jobID int64) ([]*JobStepInfo, error)
+		SJobScript(jobID int64) (*JobScript, error)
+		Resources(partition string) (*Resources, error)
+		Capacity(partition string) (*Capacity, error)
+		Partitions() ([]string, error)
+		Clusters() ([]string, error)
+		Version() (string, error)
+	}
+
+	// Runner runs Slurm binaries.
+	Runner interface {
+		// Run executes a command and returns its standard output.
+		// When command fails returned error should contain its standard error output.
+		Run(stdin io.Reader, name string, args ...string) ([]byte, error)
+		// LookPath checks whether a command is available.
+		LookPath(name string) error
+	}
+
 	// Client implements Slurm interface for communicating with
-	// a local Slurm cluster by calling Slurm binaries directly.
-	Client struct{}
+	// a Slurm cluster by calling Slurm binaries with a Runner.
+	// File operations are performed on the file system
+	// Slurm binaries are called on.
+	Client struct {
+		files.FileSystem
+
+		runner   Runner
+		clusters []string
+		squeue   bool // whether squeue is available for start estimates
+
+		mu   sync.Mutex
+		jobs map[int64]string // job id to cluster
+	}
+
+	// localRunner runs Slurm binaries on the local host.
+	localRunner struct{}
 
 	// JobInfo contains information about a Slurm job.
 	JobInfo struct {
-		ID         string         `json:"id" slurm:"JobId"`
-		UserID     string         `json:"user_id" slurm:"UserId"`
-		ArrayJobID string         `json:"array_job_id" slurm:"ArrayJobId"`
-		Name       string         `json:"name" slurm:"JobName"`
-		ExitCode   string         `json:"exit_code" slurm:"ExitCode"`
-		State      string         `json:"state" slurm:"JobState"`
-		SubmitTime *time.Time     `json:"submit_time" slurm:"SubmitTime"`
-		StartTime  *time.Time     `json:"start_time" slurm:"StartTime"`
-		RunTime    *time.Duration `json:"run_time" slurm:"RunTime"`
-		TimeLimit  *time.Duration `json:"time_limit" slurm:"TimeLimit"`
-		WorkDir    string         `json:"work_dir" slurm:"WorkDir"`
-		StdOut     string         `json:"std_out" slurm:"StdOut"`
-		StdErr     string         `json:"std_err" slurm:"StdErr"`
-		Partition  string         `json:"partition" slurm:"Partition"`
-		NodeList   string         `json:"node_list" slurm:"NodeList"`
-		BatchHost  string         `json:"batch_host" slurm:"BatchHost"`
-		NumNodes   string         `json:"num_nodes" slurm:"NumNodes"`
+		ID          string            `json:"id" slurm:"JobId"`
+		UserID      string            `json:"user_id" slurm:"UserId"`
+		ArrayJobID  string            `json:"array_job_id" slurm:"ArrayJobId"`
+		ArrayTaskID string            `json:"array_task_id" slurm:"ArrayTaskId"`
+		Name        string            `json:"name" slurm:"JobName"`
+		ExitCode    string            `json:"exit_code" slurm:"ExitCode"`
+		State       string            `json:"state" slurm:"JobState"`
+		SubmitTime  *time.Time        `json:"submit_time" slurm:"SubmitTime"`
+		StartTime   *time.Time        `json:"start_time" slurm:"StartTime"`
+		EndTime     *time.Time        `json:"end_time" slurm:"EndTime"`
+		RunTime     *time.Duration    `json:"run_time" slurm:"RunTime"`
+		TimeLimit   *time.Duration    `json:"time_limit" slurm:"TimeLimit"`
+		WorkDir     string            `json:"work_dir" slurm:"WorkDir"`
+		StdOut      string            `json:"std_out" slurm:"StdOut"`
+		StdErr      string            `json:"std_err" slurm:"StdErr"`
+		Partition   string            `json:"partition" slurm:"Partition"`
+		NodeList    string            `json:"node_list" slurm:"NodeList"`
+		BatchHost   string            `json:"batch_host" slurm:"BatchHost"`
+		NumNodes    string            `json:"num_nodes" slurm:"NumNodes"`
+		NumCPUs     int64             `json:"num_cpus" slurm:"NumCPUs"`
+		Reason      string            `json:"reason" slurm:"Reason"`
+		Priority    int64             `json:"priority" slurm:"Priority"`
+		TRES        map[string]string `json:"tres" slurm:"TRES"`
+		Account     string            `json:"account" slurm:"Account"`
+		QOS         string            `json:"qos" slurm:"QOS"`
+		// EstimatedStart is expected start time of a pending job reported by 'squeue --start'.
+		EstimatedStart *time.Time `json:"estimated_start"`
 	}
 
 	// JobStepInfo contains information about a single Slurm job step.
@@ -92,14 +165,57 @@ type (
 		State      string     `json:"state"`
 	}
 
-	// Feature represents a single feature enabled on a Slurm partition.
-	// TODO use it.
+	// JobEstimate is a result of a test submission of a job. Job would be
+	// rejected if there are any errors, otherwise it is estimated when and
+	// where it would start.
+	JobEstimate struct {
+		Errors    []string
+		Warnings  []string
+		StartTime *time.Time
+		CPUs      int64
+		NodeList  string
+		Partition string
+	}
+
+	// JobScript is a batch script of a job as Slurm stored it.
+	JobScript struct {
+		Script string
+		// Environment the job was submitted with, in KEY=value form.
+		// It is empty when Slurm doesn't store job environment.
+		Environment []string
+	}
+
+	// Feature represents a single feature enabled on a Slurm partition,
+	// e.g. a generic resource such as GPU. For generic resources Version
+	// holds resource type and Quantity is the amount available on a node.
 	Feature struct {
 		Name     string
 		Version  string
 		Quantity int64
 	}
 
+	// NodeClass describes a group of identical nodes in a Slurm partition.
+	NodeClass struct {
+		Nodes      int64
+		CPUPerNode int64
+		MemPerNode int64
+		Gres       []Feature
+		Features   []string
+	}
+
+	// Capacity contains current usage of a Slurm partition. Nodes that are down,
+	// drained or not responding are counted neither as idle nor as allocated.
+	Capacity struct {
+		IdleNodes  int64
+		AllocNodes int64
+		IdleCPUs   int64
+		AllocCPUs  int64
+		IdleMem    int64
+		AllocMem   int64
+		IdleGPUs   int64
+		AllocGPUs  int64
+	}
+
 	// Resources contain a list of available resources on a Slurm partition.
 	Resources struct {
 		Nodes      int64
@@ -107,123 +223,314 @@ type (
 		CPUPerNode int64
 		WallTime   time.Duration
 		Features   []Feature
+
+		// TRES contains trackable resources configured
+		// for a partition, e.g. cpu, mem or gres/gpu.
+		TRES map[string]string
+		// TRESBillingWeights contains billing weights of trackable resources.
+		TRESBillingWeights map[string]string
+
+		// NodeClasses contains groups of identical partition
+		// nodes, the largest class goes first.
+		NodeClasses []NodeClass
 	}
 )
 
-// NewClient returns new local client.
-func NewClient() (*Client, error) {
-	var missing []string
-	for _, bin := range []string{
+// NewClient returns new local client. If clusters are passed, client
+// serves all of them and partitions are named as cluster/partition.
+func NewClient(clusters []string) (*Client, error) {
+	return newClient(localRunner{}, files.Local{}, clusters)
+}
+
+// NewRemoteClient returns new client that calls Slurm binaries and
+// performs file operations on a remote host over SSH.
+func NewRemoteClient(p *remote.Pool, clusters []string) (*Client, error) {
+	return newClient(p, remote.NewSFTP(p), clusters)
+}
+
+func newClient(r Runner, fs files.FileSystem, clusters []string) (*Client, error) {
+	bins := []string{
 		sacctBinaryName,
 		sbatchBinaryName,
 		scancelBinaryName,
 		scontrolBinaryName,
 		sinfoBinaryName,
-	} {
-		_, err := exec.LookPath(bin)
-		if err != nil {
+	}
+	if len(clusters) != 0 {
+		bins = append(bins, sacctmgrBinaryName)
+	}
+
+	var missing []string
+	for _, bin := range bins {
+		if err := r.LookPath(bin); err != nil {
 			missing = append(missing, bin)
 		}
 	}
 	if len(missing) != 0 {
 		return nil, errors.Errorf("no slurm binaries found: %s", strings.Join(missing, ", "))
 	}
-	return &Client{}, nil
+
+	c := &Client{
+		FileSystem: fs,
+		runner:     r,
+		clusters:   clusters,
+		squeue:     r.LookPath(squeueBinaryName) == nil,
+		jobs:       make(map[int64]string),
+	}
+	if err := c.checkClusters(); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 // SBatch submits batch job and returns job id if succeeded.
-func (*Client) SBatch(script, partition string) (int64, error) {
-	var partitionOpt string
+func (c *Client) SBatch(script, partition string) (int64, error) {
+	cluster, partition, err := c.splitPartition(partition)
+	if err != nil {
+		return 0, err
+	}
+
+	args := clusterArgs(cluster, "--parsable")
 	if partition != "" {
-		partitionOpt = "--partition=" + partition
+		args = append(args, "--partition="+partition)
 	}
-	cmd := exec.Command(sbatchBinaryName, "--parsable", partitionOpt)
-	cmd.Stdin = bytes.NewBufferString(script)
 
-	out, err := cmd.CombinedOutput()
+	out, err := c.runner.Run(bytes.NewBufferString(script), sbatchBinaryName, args...)
 	if err != nil {
-		if out != nil {
-			log.Println(string(out))
-		}
 		return 0, errors.Wrap(err, "failed to execute sbatch")
 	}
 
-	id, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	// with --clusters sbatch reports job id as id;cluster
+	rawID := strings.SplitN(strings.TrimSpace(string(out)), ";", 2)[0]
+	id, err := strconv.ParseInt(rawID, 10, 0)
 	if err != nil {
 		return 0, errors.Wrap(err, "could not parse job id")
 	}
 
-	return int64(id), nil
+	if cluster != "" {
+		c.rememberCluster(id, cluster)
+	}
+	return id, nil
 }
 
-// SCancel cancels batch job.
-func (*Client) SCancel(jobID int64) error {
-	cmd := exec.Command(scancelBinaryName, strconv.FormatInt(jobID, 10))
+// SBatchTest validates batch script with 'sbatch --test-only' without submitting it.
+// Errors reported by sbatch are returned in the estimate, the error is returned
+// only if sbatch could not be run.
+func (c *Client) SBatchTest(script, partition string) (*JobEstimate, error) {
+	cluster, partition, err := c.splitPartition(partition)
+	if err != nil {
+		return nil, err
+	}
 
-	out, err := cmd.CombinedOutput()
-	if err != nil && out != nil {
-		log.Println(string(out))
+	args := clusterArgs(cluster, "--test-only")
+	if partition != "" {
+		args = append(args, "--partition="+partition)
+	}
+
+	// sbatch reports the estimate to stderr, which runners only return on failure
+	shArgs := append([]string{"-c", sbatchBinaryName + ` "$@" 2>&1`, sbatchBinaryName}, args...)
+	out, runErr := c.runner.Run(bytes.NewBufferString(script), "sh", shArgs...)
+
+	e, err := parseSBatchTest(string(out))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse sbatch response")
+	}
+	if runErr != nil && len(e.Errors) == 0 {
+		return nil, errors.Wrapf(runErr, "failed to execute sbatch: %s", strings.TrimSpace(string(out)))
 	}
+	if cluster != "" && e.Partition != "" {
+		e.Partition = cluster + "/" + e.Partition
+	}
+	return e, nil
+}
+
+// SCancel cancels batch job.
+func (c *Client) SCancel(jobID int64) error {
+	cluster, err := c.jobCluster(jobID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runner.Run(nil, scancelBinaryName, clusterArgs(cluster, strconv.FormatInt(jobID, 10))...)
 	return errors.Wrap(err, "failed to execute scancel")
 }
 
-// Open opens arbitrary file at path in a read-only mode.
-func (*Client) Open(path string) (io.ReadCloser, error) {
-	file, err := os.Open(path)
-	if os.IsNotExist(err) {
-		return nil, ErrFileNotFound
+// SHold prevents a pending job from being started.
+func (c *Client) SHold(jobID int64) error {
+	return c.scontrol(jobID, "hold")
+}
+
+// SRelease releases a previously held job.
+func (c *Client) SRelease(jobID int64) error {
+	return c.scontrol(jobID, "release")
+}
+
+// SSuspend suspends a running job keeping its allocation.
+func (c *Client) SSuspend(jobID int64) error {
+	return c.scontrol(jobID, "suspend")
+}
+
+// SResume resumes a previously suspended job.
+func (c *Client) SResume(jobID int64) error {
+	return c.scontrol(jobID, "resume")
+}
+
+// SRequeue puts a running job back to the queue releasing its allocation.
+// Requeued job is held when hold is true.
+func (c *Client) SRequeue(jobID int64, hold bool) error {
+	command := "requeue"
+	if hold {
+		command = "requeuehold"
 	}
-	return file, errors.Wrapf(err, "could not open %s", path)
+	return c.scontrol(jobID, command)
 }
 
-// Create opens a file at path in write mode.
-func (*Client) Create(path string) (io.WriteCloser, error) {
-	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
-		return nil, err
+// SRequeueTask puts a single task of a job array back to the queue.
+// Unlike the whole job, the task may be requeued after it has finished.
+func (c *Client) SRequeueTask(jobID, taskID int64) error {
+	cluster, err := c.jobCluster(jobID)
+	if err != nil {
+		return err
 	}
-	file, err := os.Create(path)
+
+	task := strconv.FormatInt(jobID, 10) + "_" + strconv.FormatInt(taskID, 10)
+	_, err = c.runner.Run(nil, scontrolBinaryName, clusterArgs(cluster, "requeue", task)...)
+	return errors.Wrapf(err, "failed to requeue task %s", task)
+}
+
+// scontrol runs scontrol command that takes job id as its only argument.
+func (c *Client) scontrol(jobID int64, command string) error {
+	cluster, err := c.jobCluster(jobID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return file, errors.Wrapf(err, "could not create %s", path)
+
+	_, err = c.runner.Run(nil, scontrolBinaryName, clusterArgs(cluster, command, strconv.FormatInt(jobID, 10))...)
+	return errors.Wrapf(err, "failed to execute scontrol %s", command)
 }
 
-// Tail opens arbitrary file at path in a read-only mode.
-// Unlike Open, Tail will watch file changes in a real-time.
-func (*Client) Tail(path string) (io.ReadCloser, error) {
-	tr, err := tail.NewReader(path)
+// SJobInfo returns information about a particular slurm job by ID.
+func (c *Client) SJobInfo(jobID int64) ([]*JobInfo, error) {
+	cluster, err := c.jobCluster(jobID)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not create tail reader")
+		return nil, err
 	}
 
-	return tr, nil
+	return c.sJobInfo(cluster, jobID)
+}
+
+func (c *Client) sJobInfo(cluster string, jobID int64) ([]*JobInfo, error) {
+	out, err := c.runner.Run(nil, scontrolBinaryName, clusterArgs(cluster, "show", "jobid", strconv.FormatInt(jobID, 10))...)
+	if err != nil && !strings.Contains(err.Error(), scontrolInvalidJobID) {
+		return nil, errors.Wrapf(err, "failed to get info for jobid: %d", jobID)
+	}
+
+	var ji []*JobInfo
+	if err == nil {
+		ji, err = jobInfoFromScontrolResponse(string(out))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse scontrol response")
+		}
+	} else {
+		// slurmctld forgets finished jobs after MinJobAge,
+		// accounting is the only source of their info then
+		ji, err = c.sacctJobInfo(cluster, jobID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.estimateStart(cluster, strconv.FormatInt(jobID, 10), ji)
+	if cluster != "" {
+		for _, info := range ji {
+			info.Partition = cluster + "/" + info.Partition
+		}
+	}
+	return ji, nil
 }
 
-// Zip file or directory
-func (*Client) Zip(path string, target string) error {
-	err := zipFile(path, target)
+// estimateStart sets expected start time of pending jobs with 'squeue --start',
+// jobID limits the query to a single job. Estimate is optional, so it is skipped
+// when squeue is not available and squeue failures are ignored.
+func (c *Client) estimateStart(cluster, jobID string, infos []*JobInfo) {
+	if !c.squeue {
+		return
+	}
+
+	pending := false
+	for _, info := range infos {
+		pending = pending || info.State == statePending
+	}
+	if !pending {
+		return
+	}
+
+	args := clusterArgs(cluster, "--start", "-h", "-o", "%A|%S")
+	if jobID != "" {
+		args = append(args, "-j", jobID)
+	}
+	out, err := c.runner.Run(nil, squeueBinaryName, args...)
 	if err != nil {
-		return errors.Wrap(err, "could not zip file or directory")
+		return
+	}
+
+	starts := parseSqueueStart(string(out))
+	for _, info := range infos {
+		if info.State == statePending {
+			info.EstimatedStart = starts[info.ID]
+		}
 	}
-	return nil
 }
 
-// Unzip file or directory
-func (*Client) Unzip(source string, path string) error {
-	err := unzip(source, path)
+// sacctJobInfo builds job info from accounting records.
+// ErrJobNotFound is returned when there are no records for the job.
+func (c *Client) sacctJobInfo(cluster string, jobID int64) ([]*JobInfo, error) {
+	out, err := c.runner.Run(nil, sacctBinaryName, clusterArgs(cluster,
+		"-P",
+		"-n",
+		"-X",
+		"-j",
+		strconv.FormatInt(jobID, 10),
+		"-o", sacctJobInfoFormat,
+	)...)
 	if err != nil {
-		return errors.Wrap(err, "could not unzip file")
+		return nil, errors.Wrap(err, "failed to execute sacct")
 	}
-	return nil
+
+	ji, err := parseSacctJobInfo(string(out))
+	if err != nil {
+		return nil, errors.Wrap(err, ErrInvalidSacctResponse.Error())
+	}
+	if len(ji) == 0 {
+		return nil, errors.Wrapf(ErrJobNotFound, "no info for jobid: %d", jobID)
+	}
+	return ji, nil
 }
 
-// SJobInfo returns information about a particular slurm job by ID.
-func (*Client) SJobInfo(jobID int64) ([]*JobInfo, error) {
-	cmd := exec.Command(scontrolBinaryName, "show", "jobid", strconv.FormatInt(jobID, 10))
+// SJobsInfo returns information about all jobs slurmctld knows about. Unlike
+// SJobInfo it doesn't look up finished jobs in accounting, so it is cheap
+// enough to take a snapshot of the whole queue.
+func (c *Client) SJobsInfo() ([]*JobInfo, error) {
+	if len(c.clusters) == 0 {
+		return c.sJobsInfo("")
+	}
 
-	out, err := cmd.Output()
+	var infos []*JobInfo
+	for _, cluster := range c.clusters {
+		ji, err := c.sJobsInfo(cluster)
+		if err != nil {
+			return nil, err
+		}
+
+		infos = append(infos, ji...)
+	}
+	return infos, nil
+}
+
+func (c *Client) sJobsInfo(cluster string) ([]*JobInfo, error) {
+	out, err := c.runner.Run(nil, scontrolBinaryName, clusterArgs(cluster, "show", "job")...)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get info for jobid: %d", jobID)
+		return nil, errors.Wrap(err, "failed to get jobs info")
 	}
 
 	ji, err := jobInfoFromScontrolResponse(string(out))
@@ -231,25 +538,37 @@ func (*Client) SJobInfo(jobID int64) ([]*JobInfo, error) {
 		return nil, errors.Wrap(err, "could not parse scontrol response")
 	}
 
-	return ji, nil
+	c.estimateStart(cluster, "", ji)
+
+	// empty queue is reported as 'No jobs in the system'
+	infos := ji[:0]
+	for _, info := range ji {
+		if info.ID == "" {
+			continue
+		}
+		if cluster != "" {
+			info.Partition = cluster + "/" + info.Partition
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
 }
 
 // SJobSteps returns information about a submitted batch job.
-func (*Client) SJobSteps(jobID int64) ([]*JobStepInfo, error) {
-	cmd := exec.Command(sacctBinaryName,
+func (c *Client) SJobSteps(jobID int64) ([]*JobStepInfo, error) {
+	cluster, err := c.jobCluster(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := c.runner.Run(nil, sacctBinaryName, clusterArgs(cluster,
 		"-p",
 		"-n",
 		"-j",
 		strconv.FormatInt(jobID, 10),
-		"-o start,end,exitcode,state,jobid,jobname",
-	)
-
-	out, err := cmd.Output()
+		"-o", "start,end,exitcode,state,jobid,jobname",
+	)...)
 	if err != nil {
-		ee, ok := err.(*exec.ExitError)
-		if ok {
-			return nil, errors.Wrapf(err, "failed to execute sacct: %s", ee.Stderr)
-		}
 		return nil, errors.Wrap(err, "failed to execute sacct")
 	}
 
@@ -261,10 +580,53 @@ func (*Client) SJobSteps(jobID int64) ([]*JobStepInfo, error) {
 	return jInfo, nil
 }
 
+// SJobScript returns batch script of a job with 'scontrol write batch_script'.
+// Scripts of jobs purged by slurmctld and job environment are looked up in
+// accounting, they are only there when AccountingStoreFlags has job_script
+// and job_env.
+func (c *Client) SJobScript(jobID int64) (*JobScript, error) {
+	cluster, err := c.jobCluster(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strconv.FormatInt(jobID, 10)
+	out, err := c.runner.Run(nil, scontrolBinaryName, clusterArgs(cluster, "write", "batch_script", id, "-")...)
+	if err != nil && !strings.Contains(err.Error(), scontrolInvalidJobID) {
+		return nil, errors.Wrapf(err, "failed to get batch script for jobid: %d", jobID)
+	}
+
+	js := &JobScript{Script: string(out)}
+	if err != nil {
+		out, err = c.runner.Run(nil, sacctBinaryName, clusterArgs(cluster, "-j", id, "--batch-script")...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to execute sacct")
+		}
+		js.Script = sacctRecord(string(out))
+		if js.Script == "" {
+			return nil, errors.Wrapf(ErrJobNotFound, "no batch script for jobid: %d", jobID)
+		}
+	}
+
+	// older sacct has no --env-vars, so environment is optional
+	out, err = c.runner.Run(nil, sacctBinaryName, clusterArgs(cluster, "-j", id, "--env-vars")...)
+	if err == nil {
+		env := strings.TrimSuffix(sacctRecord(string(out)), "\n")
+		if env != "" {
+			js.Environment = strings.Split(env, "\n")
+		}
+	}
+	return js, nil
+}
+
 // Resources returns available resources for a partition.
-func (*Client) Resources(partition string) (*Resources, error) {
-	cmd := exec.Command(scontrolBinaryName, "show", "partition", partition)
-	out, err := cmd.Output()
+func (c *Client) Resources(partition string) (*Resources, error) {
+	cluster, partition, err := c.splitPartition(partition)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := c.runner.Run(nil, scontrolBinaryName, clusterArgs(cluster, "show", "partition", partition)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get partition info")
 	}
@@ -274,23 +636,70 @@ func (*Client) Resources(partition string) (*Resources, error) {
 		return nil, errors.Wrap(err, "could not parse partition resources")
 	}
 
+	out, err = c.runner.Run(nil, scontrolBinaryName, clusterArgs(cluster, "show", "node")...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get node info")
+	}
+	r.Features = parsePartitionGres(string(out), partition)
+	r.NodeClasses = parseNodeClasses(string(out), partition)
+	// nodes of a mixed partition are not the same, so
+	// report the largest class instead of partition maxima
+	if len(r.NodeClasses) != 0 {
+		largest := r.NodeClasses[0]
+		r.Nodes, r.CPUPerNode, r.MemPerNode = largest.Nodes, largest.CPUPerNode, largest.MemPerNode
+	}
+
 	return r, nil
 }
 
-// Partitions returns a list of partition names.
-func (*Client) Partitions() ([]string, error) {
-	cmd := exec.Command(scontrolBinaryName, "show", "partition")
-	out, err := cmd.Output()
+// Capacity returns current usage of a partition aggregated over its nodes.
+func (c *Client) Capacity(partition string) (*Capacity, error) {
+	cluster, partition, err := c.splitPartition(partition)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not get partition info")
+		return nil, err
+	}
+
+	out, err := c.runner.Run(nil, scontrolBinaryName, clusterArgs(cluster, "show", "node")...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get node info")
+	}
+
+	return parseCapacity(string(out), partition), nil
+}
+
+// Partitions returns a list of partition names. When client serves
+// multiple clusters partition names are prefixed with a cluster name.
+func (c *Client) Partitions() ([]string, error) {
+	if len(c.clusters) == 0 {
+		out, err := c.runner.Run(nil, scontrolBinaryName, "show", "partition")
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get partition info")
+		}
+		return parsePartitionsNames(string(out)), nil
 	}
-	return parsePartitionsNames(string(out)), nil
+
+	var names []string
+	for _, cluster := range c.clusters {
+		out, err := c.runner.Run(nil, scontrolBinaryName, clusterArgs(cluster, "show", "partition")...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get %s partition info", cluster)
+		}
+		for _, name := range parsePartitionsNames(string(out)) {
+			names = append(names, cluster+"/"+name)
+		}
+	}
+	return names, nil
+}
+
+// Clusters returns names of the served clusters, it is empty
+// when only the default cluster is served.
+func (c *Client) Clusters() ([]string, error) {
+	return c.clusters, nil
 }
 
 // Version returns slurm version
-func (*Client) Version() (string, error) {
-	cmd := exec.Command(sinfoBinaryName, "-V")
-	out, err := cmd.Output()
+func (c *Client) Version() (string, error) {
+	out, err := c.runner.Run(nil, sinfoBinaryName, "-V")
 	if err != nil {
 		return "", errors.Wrap(err, "could not get slurm info")
 	}
@@ -303,6 +712,153 @@ func (*Client) Version() (string, error) {
 	return s[1], nil
 }
 
+// checkClusters makes sure all served clusters are known to slurmdbd.
+func (c *Client) checkClusters() error {
+	if len(c.clusters) == 0 {
+		return nil
+	}
+
+	out, err := c.runner.Run(nil, sacctmgrBinaryName, "-n", "-P", "show", "cluster", "format=cluster")
+	if err != nil {
+		return errors.Wrap(err, "could not get cluster list")
+	}
+
+	known := strings.Fields(string(out))
+	var unknown []string
+	for _, cluster := range c.clusters {
+		found := false
+		for _, k := range known {
+			if k == cluster {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, cluster)
+		}
+	}
+	if len(unknown) != 0 {
+		return errors.Errorf("unknown clusters: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// PartitionLabelValue returns partition name in the form allowed in Kubernetes
+// label values. Slash is not allowed there, so partitions of multiple clusters
+// named as cluster/partition are labeled as cluster.partition.
+func PartitionLabelValue(partition string) string {
+	return strings.Replace(partition, "/", ".", 1)
+}
+
+// PartitionFromLabel turns partition label value back into the partition
+// name. Values prefixed with one of the clusters followed by a dot are
+// converted into cluster/partition, other values are returned as is.
+func PartitionFromLabel(value string, clusters []string) string {
+	if strings.Contains(value, "/") {
+		return value
+	}
+	for _, cluster := range clusters {
+		if strings.HasPrefix(value, cluster+".") {
+			return cluster + "/" + value[len(cluster)+1:]
+		}
+	}
+	return value
+}
+
+// splitPartition splits cluster/partition name into cluster and partition.
+// Empty partition is routed to the first cluster where the default partition is used.
+func (c *Client) splitPartition(partition string) (string, string, error) {
+	if len(c.clusters) == 0 {
+		return "", partition, nil
+	}
+	if partition == "" {
+		return c.clusters[0], "", nil
+	}
+
+	s := strings.SplitN(partition, "/", 2)
+	if len(s) != 2 {
+		return "", "", errors.Errorf("partition %s should be prefixed with a cluster name", partition)
+	}
+	for _, cluster := range c.clusters {
+		if cluster == s[0] {
+			return s[0], s[1], nil
+		}
+	}
+	return "", "", errors.Errorf("unknown cluster %s", s[0])
+}
+
+// jobCluster returns a cluster the job was submitted to. Jobs submitted by
+// other red-box instances are looked up on all served clusters in order.
+func (c *Client) jobCluster(jobID int64) (string, error) {
+	if len(c.clusters) == 0 {
+		return "", nil
+	}
+
+	c.mu.Lock()
+	cluster, ok := c.jobs[jobID]
+	c.mu.Unlock()
+	if ok {
+		return cluster, nil
+	}
+
+	for _, cluster := range c.clusters {
+		if _, err := c.sJobInfo(cluster, jobID); err != nil {
+			continue
+		}
+
+		c.rememberCluster(jobID, cluster)
+		return cluster, nil
+	}
+	return "", errors.Wrapf(ErrJobNotFound, "job %d on any cluster", jobID)
+}
+
+// rememberCluster records the cluster of the job. The number of recorded jobs
+// is limited with maxKnownJobs, an arbitrary job is forgotten beyond the limit
+// and is looked up again when needed.
+func (c *Client) rememberCluster(jobID int64, cluster string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.jobs[jobID]; !ok && len(c.jobs) >= maxKnownJobs {
+		for id := range c.jobs {
+			delete(c.jobs, id)
+			break
+		}
+	}
+	c.jobs[jobID] = cluster
+}
+
+// clusterArgs prepends args with --clusters flag if cluster is set.
+func clusterArgs(cluster string, args ...string) []string {
+	if cluster == "" {
+		return args
+	}
+	return append([]string{"--clusters=" + cluster}, args...)
+}
+
+// Run executes a command on the local host and returns its standard output.
+func (localRunner) Run(stdin io.Reader, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = stdin
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, errors.Wrap(err, msg)
+		}
+		return out, err
+	}
+	return out, nil
+}
+
+// LookPath checks whether a command is available on the local host.
+func (localRunner) LookPath(name string) error {
+	_, err := exec.LookPath(name)
+	return err
+}
+
 func jobInfoFromScontrolResponse(jobInfo string) ([]*JobInfo, error) {
 	jobInfo = strings.TrimSpace(jobInfo)
 	rawInfos := strings.Split(jobInfo, "\n\n")
@@ -312,7 +868,8 @@ func jobInfoFromScontrolResponse(jobInfo string) ([]*JobInfo, error) {
 		rFields := strings.Fields(raw)
 		slurmFields := make(map[string]string)
 		for _, f := range rFields {
-			s := strings.Split(f, "=")
+			// values such as TRES may contain '=' themselves
+			s := strings.SplitN(f, "=", 2)
 			if len(s) != 2 {
 				// just skipping empty fields
 				continue
@@ -344,12 +901,20 @@ func (ji *JobInfo) fillFromSlurmFields(fields map[string]string) error {
 
 		var val reflect.Value
 		switch tagV {
-		case submitTime, startTime:
+		case submitTime, startTime, endTime:
 			t, err := parseTime(sField)
 			if err != nil {
 				return errors.Wrapf(err, "could not parse time: %s", sField)
 			}
 			val = reflect.ValueOf(t)
+		case priority, numCPUs:
+			n, err := strconv.ParseInt(sField, 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "could not parse %s: %s", tagV, sField)
+			}
+			val = reflect.ValueOf(n)
+		case tres:
+			val = reflect.ValueOf(parseTRES(sField))
 		case runTime, timeLimit:
 			d, err := ParseDuration(sField)
 			if err != nil {
@@ -369,123 +934,3 @@ func (ji *JobInfo) fillFromSlurmFields(fields map[string]string) error {
 
 	return nil
 }
-
-func zipFile(source, target string) error {
-    // 1. Create a ZIP file and zip.Writer
-    f, err := os.Create(target)
-    if err != nil {
-        return err
-    }
-    defer f.Close()
-
-    writer := zip.NewWriter(f)
-    defer writer.Close()
-
-    // 2. Go through all the files of the source
-    return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
-        if err != nil {
-            return err
-        }
-
-        // 3. Create a local file header
-        header, err := zip.FileInfoHeader(info)
-        if err != nil {
-            return err
-        }
-
-        // set compression
-        header.Method = zip.Deflate
-
-        // 4. Set relative path of a file as the header name
-        header.Name, err = filepath.Rel(filepath.Dir(source), path)
-        if err != nil {
-            return err
-        }
-        if info.IsDir() {
-            header.Name += "/"
-        }
-
-        // 5. Create writer for the file header and save content of the file
-        headerWriter, err := writer.CreateHeader(header)
-        if err != nil {
-            return err
-        }
-
-        if info.IsDir() {
-            return nil
-        }
-
-        f, err := os.Open(path)
-        if err != nil {
-            return err
-        }
-        defer f.Close()
-
-        _, err = io.Copy(headerWriter, f)
-        return err
-    })
-}
-
-func unzip(source, destination string) error {
-    // 1. Open the zip file
-    reader, err := zip.OpenReader(source)
-    if err != nil {
-        return err
-    }
-    defer reader.Close()
-
-    // 2. Get the absolute destination path
-    destination, err = filepath.Abs(destination)
-    if err != nil {
-        return err
-    }
-
-    // 3. Iterate over zip files inside the archive and unzip each of them
-    for _, f := range reader.File {
-        err := unzipFile(f, destination)
-        if err != nil {
-            return err
-        }
-    }
-
-    return nil
-}
-
-func unzipFile(f *zip.File, destination string) error {
-    // 4. Check if file paths are not vulnerable to Zip Slip
-    filePath := filepath.Join(destination, f.Name)
-    if !strings.HasPrefix(filePath, filepath.Clean(destination)+string(os.PathSeparator)) {
-        return errors.Errorf("invalid file path: %s", filePath)
-    }
-
-    // 5. Create directory tree
-    if f.FileInfo().IsDir() {
-        if err := os.MkdirAll(filePath, os.ModePerm); err != nil {
-            return err
-        }
-        return nil
-    }
-
-    if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-        return err
-    }
-
-    // 6. Create a destination file for unzipped content
-    destinationFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-    if err != nil {
-        return err
-    }
-    defer destinationFile.Close()
-
-    // 7. Unzip the content of a file and copy it to the destination file
-    zippedFile, err := f.Open()
-    if err != nil {
-        return err
-    }
-    defer zippedFile.Close()
-
-    if _, err := io.Copy(destinationFile, zippedFile); err != nil {
-        return err
-    }
-    return nil
-}
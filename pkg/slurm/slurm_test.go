@@ -15,9 +15,15 @@
 package slurm
 
 import (
+	"io"
+	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dptech-corp/wlm-operator/pkg/files"
+	"github.com/pkg/errors"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -161,6 +167,7 @@ JobId=196 ArrayJobId=192 ArrayTaskId=4 JobName=sbatch
 var (
 	testSubmitTime  = time.Date(2019, 04, 16, 11, 49, 19, 0, time.UTC)
 	testStartTime   = time.Date(2019, 04, 16, 11, 49, 20, 0, time.UTC)
+	testEndTime     = time.Date(2019, 04, 16, 12, 49, 20, 0, time.UTC)
 	testRunTime     = 30 * time.Second
 	testLimitTime   = 25 * time.Hour
 	testZeroRunTime = time.Duration(0)
@@ -184,6 +191,7 @@ func TestJobInfoFromScontrolResponse(t *testing.T) {
 					State:      "RUNNING",
 					SubmitTime: &testSubmitTime,
 					StartTime:  &testStartTime,
+					EndTime:    &testEndTime,
 					RunTime:    &testRunTime,
 					TimeLimit:  &testLimitTime,
 					WorkDir:    "/home/vagrant",
@@ -193,6 +201,12 @@ func TestJobInfoFromScontrolResponse(t *testing.T) {
 					NodeList:   "vagrant",
 					BatchHost:  "vagrant",
 					NumNodes:   "1",
+					NumCPUs:    2,
+					Reason:     "None",
+					Priority:   4294901743,
+					TRES:       map[string]string{"cpu": "2", "node": "1", "billing": "2"},
+					Account:    "(null)",
+					QOS:        "(null)",
 					ArrayJobID: "",
 				},
 			},
@@ -218,6 +232,12 @@ func TestJobInfoFromScontrolResponse(t *testing.T) {
 					NodeList:   "(null)",
 					BatchHost:  "",
 					NumNodes:   "1",
+					NumCPUs:    1,
+					Reason:     "None",
+					Priority:   4294901744,
+					TRES:       map[string]string{"cpu": "1", "node": "1"},
+					Account:    "(null)",
+					QOS:        "(null)",
 					ArrayJobID: "",
 				},
 			},
@@ -227,42 +247,57 @@ func TestJobInfoFromScontrolResponse(t *testing.T) {
 			in:   testJobArrayScontrolResponse,
 			want: []*JobInfo{
 				{
-					ID:         "192",
-					UserID:     "vagrant(1000)",
-					Name:       "sbatch",
-					ExitCode:   "0:0",
-					State:      "PENDING",
-					SubmitTime: &testSubmitTime,
-					StartTime:  &testStartTime,
-					RunTime:    &testRunTime,
-					TimeLimit:  &testLimitTime,
-					WorkDir:    "/home/vagrant",
-					StdOut:     "/home/vagrant/slurm-192_4294967294.out",
-					StdErr:     "/home/vagrant/slurm-192_4294967294.out",
-					Partition:  "debug",
-					NodeList:   "(null)",
-					BatchHost:  "",
-					NumNodes:   "1-1",
-					ArrayJobID: "192",
+					ID:          "192",
+					UserID:      "vagrant(1000)",
+					Name:        "sbatch",
+					ExitCode:    "0:0",
+					State:       "PENDING",
+					SubmitTime:  &testSubmitTime,
+					StartTime:   &testStartTime,
+					RunTime:     &testRunTime,
+					TimeLimit:   &testLimitTime,
+					WorkDir:     "/home/vagrant",
+					StdOut:      "/home/vagrant/slurm-192_4294967294.out",
+					StdErr:      "/home/vagrant/slurm-192_4294967294.out",
+					Partition:   "debug",
+					NodeList:    "(null)",
+					BatchHost:   "",
+					NumNodes:    "1-1",
+					NumCPUs:     1,
+					Reason:      "Resources",
+					Priority:    4294901702,
+					TRES:        map[string]string{"cpu": "1", "node": "1"},
+					Account:     "(null)",
+					QOS:         "(null)",
+					ArrayJobID:  "192",
+					ArrayTaskID: "5-8",
 				},
 				{
-					ID:         "196",
-					UserID:     "vagrant(1000)",
-					Name:       "sbatch",
-					ExitCode:   "0:0",
-					State:      "RUNNING",
-					SubmitTime: &testSubmitTime,
-					StartTime:  &testStartTime,
-					RunTime:    &testRunTime,
-					TimeLimit:  &testLimitTime,
-					WorkDir:    "/home/vagrant",
-					StdOut:     "/home/vagrant/slurm-192_4.out",
-					StdErr:     "/home/vagrant/slurm-192_4.out",
-					Partition:  "debug",
-					NodeList:   "vagrant",
-					BatchHost:  "vagrant",
-					NumNodes:   "1",
-					ArrayJobID: "192",
+					ID:          "196",
+					UserID:      "vagrant(1000)",
+					Name:        "sbatch",
+					ExitCode:    "0:0",
+					State:       "RUNNING",
+					SubmitTime:  &testSubmitTime,
+					StartTime:   &testStartTime,
+					EndTime:     &testEndTime,
+					RunTime:     &testRunTime,
+					TimeLimit:   &testLimitTime,
+					WorkDir:     "/home/vagrant",
+					StdOut:      "/home/vagrant/slurm-192_4.out",
+					StdErr:      "/home/vagrant/slurm-192_4.out",
+					Partition:   "debug",
+					NodeList:    "vagrant",
+					BatchHost:   "vagrant",
+					NumNodes:    "1",
+					NumCPUs:     2,
+					Reason:      "None",
+					Priority:    4294901702,
+					TRES:        map[string]string{"cpu": "2", "node": "1", "billing": "2"},
+					Account:     "(null)",
+					QOS:         "(null)",
+					ArrayJobID:  "192",
+					ArrayTaskID: "4",
 				},
 			},
 		},
@@ -275,3 +310,318 @@ func TestJobInfoFromScontrolResponse(t *testing.T) {
 		})
 	}
 }
+
+// stubRunner records executed commands and replies with a canned output.
+type stubRunner struct {
+	missing  string
+	reply    func(cmd string) (string, error)
+	commands []string
+	stdin    string
+}
+
+func (r *stubRunner) Run(stdin io.Reader, name string, args ...string) ([]byte, error) {
+	if stdin != nil {
+		in, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return nil, err
+		}
+		r.stdin = string(in)
+	}
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	r.commands = append(r.commands, cmd)
+	out, err := r.reply(cmd)
+	return []byte(out), err
+}
+
+func (r *stubRunner) LookPath(name string) error {
+	if name == r.missing {
+		return errors.New("not found")
+	}
+	return nil
+}
+
+func TestClient(t *testing.T) {
+	_, err := newClient(&stubRunner{missing: sacctBinaryName}, files.Local{}, nil)
+	require.EqualError(t, err, "no slurm binaries found: sacct")
+
+	out := "42\n"
+	r := &stubRunner{reply: func(string) (string, error) { return out, nil }}
+	c, err := newClient(r, files.Local{}, nil)
+	require.NoError(t, err)
+
+	id, err := c.SBatch("#!/bin/sh\nsrun hostname", "debug")
+	require.NoError(t, err)
+	require.EqualValues(t, 42, id)
+	require.Equal(t, "#!/bin/sh\nsrun hostname", r.stdin)
+
+	out = testScontrolResponse
+	info, err := c.SJobInfo(53)
+	require.NoError(t, err)
+	require.Len(t, info, 1)
+	require.Equal(t, "RUNNING", info[0].State)
+
+	require.NoError(t, c.SCancel(53))
+	require.NoError(t, c.SHold(53))
+	require.NoError(t, c.SRequeue(53, true))
+	require.NoError(t, c.SRequeueTask(53, 7))
+
+	info, err = c.SJobsInfo()
+	require.NoError(t, err)
+	require.Len(t, info, 1)
+
+	out = "No jobs in the system\n"
+	info, err = c.SJobsInfo()
+	require.NoError(t, err)
+	require.Empty(t, info)
+
+	require.Equal(t, []string{
+		"sbatch --parsable --partition=debug",
+		"scontrol show jobid 53",
+		"scancel 53",
+		"scontrol hold 53",
+		"scontrol requeuehold 53",
+		"scontrol requeue 53_7",
+		"scontrol show job",
+		"scontrol show job",
+	}, r.commands)
+}
+
+func TestClient_estimateStart(t *testing.T) {
+	r := &stubRunner{reply: func(cmd string) (string, error) {
+		switch cmd {
+		case "scontrol show jobid 52":
+			return testPendingScontrolRsponse, nil
+		case "scontrol show job":
+			return testScontrolResponse + "\n\n" + testPendingScontrolRsponse, nil
+		case "squeue --start -h -o %A|%S -j 52", "squeue --start -h -o %A|%S":
+			return "52|2019-04-16T12:49:20\n51|N/A\n", nil
+		}
+		return "", errors.New("unexpected command")
+	}}
+	c, err := newClient(r, files.Local{}, nil)
+	require.NoError(t, err)
+
+	info, err := c.SJobInfo(52)
+	require.NoError(t, err)
+	require.Equal(t, &testEndTime, info[0].EstimatedStart)
+
+	info, err = c.SJobsInfo()
+	require.NoError(t, err)
+	require.Len(t, info, 2)
+	require.Nil(t, info[0].EstimatedStart)
+	require.Equal(t, &testEndTime, info[1].EstimatedStart)
+
+	// squeue is optional
+	r.missing = squeueBinaryName
+	c, err = newClient(r, files.Local{}, nil)
+	require.NoError(t, err)
+	r.commands = nil
+	info, err = c.SJobInfo(52)
+	require.NoError(t, err)
+	require.Nil(t, info[0].EstimatedStart)
+	require.Equal(t, []string{"scontrol show jobid 52"}, r.commands)
+}
+
+func TestClient_SJobInfoSacct(t *testing.T) {
+	r := &stubRunner{reply: func(cmd string) (string, error) {
+		switch cmd {
+		case "sacct -P -n -X -j 53 -o " + sacctJobInfoFormat:
+			return "53|test|vagrant|1000|CANCELLED by 1000|0:15|2019-02-20T11:16:30|2019-02-20T11:16:55|" +
+				"2019-02-20T11:17:55|00:01:00|00:05:00|/home/vagrant|debug|node1|1\n", nil
+		case "sacct -P -n -X -j 54 -o " + sacctJobInfoFormat:
+			return "", nil
+		case "sacct -P -n -X -j 56 -o " + sacctJobInfoFormat:
+			return "56_3|test|vagrant|1000|FAILED|1:0|2019-02-20T11:16:30|2019-02-20T11:16:55|" +
+				"2019-02-20T11:17:55|00:01:00|00:05:00|/home/vagrant|debug|node1|1\n" +
+				"56_[4-9%2]|test|vagrant|1000|CANCELLED by 1000|0:0|2019-02-20T11:16:30|Unknown|" +
+				"2019-02-20T11:17:55|00:00:00|00:05:00|/home/vagrant|debug|None assigned|1\n", nil
+		}
+		return "", errors.New("slurm_load_jobs error: Invalid job id specified")
+	}}
+	c, err := newClient(r, files.Local{}, nil)
+	require.NoError(t, err)
+
+	info, err := c.SJobInfo(53)
+	require.NoError(t, err)
+	submit := time.Date(2019, 2, 20, 11, 16, 30, 0, time.UTC)
+	start := time.Date(2019, 2, 20, 11, 16, 55, 0, time.UTC)
+	end := time.Date(2019, 2, 20, 11, 17, 55, 0, time.UTC)
+	runTime := time.Minute
+	timeLimit := 5 * time.Minute
+	require.Equal(t, []*JobInfo{{
+		ID:         "53",
+		UserID:     "vagrant(1000)",
+		Name:       "test",
+		ExitCode:   "0:15",
+		State:      "CANCELLED",
+		SubmitTime: &submit,
+		StartTime:  &start,
+		EndTime:    &end,
+		RunTime:    &runTime,
+		TimeLimit:  &timeLimit,
+		WorkDir:    "/home/vagrant",
+		Partition:  "debug",
+		NodeList:   "node1",
+		NumNodes:   "1",
+	}}, info)
+
+	// array tasks are reported as jobid_taskid
+	info, err = c.SJobInfo(56)
+	require.NoError(t, err)
+	require.Len(t, info, 2)
+	require.Equal(t, "56", info[0].ArrayJobID)
+	require.Equal(t, "3", info[0].ArrayTaskID)
+	require.Equal(t, "56", info[1].ArrayJobID)
+	require.Equal(t, "4-9%2", info[1].ArrayTaskID)
+
+	_, err = c.SJobInfo(54)
+	require.Equal(t, ErrJobNotFound, errors.Cause(err))
+
+	_, err = c.SJobInfo(55)
+	require.Error(t, err)
+	require.NotEqual(t, ErrJobNotFound, errors.Cause(err))
+}
+
+func TestClient_SBatchTest(t *testing.T) {
+	r := &stubRunner{reply: func(cmd string) (string, error) {
+		switch cmd {
+		case `sh -c sbatch "$@" 2>&1 sbatch --test-only --partition=debug`:
+			return "sbatch: Job 42 to start at 2019-02-20T11:16:55 using 1 processors on nodes node1 in partition debug\n", nil
+		case `sh -c sbatch "$@" 2>&1 sbatch --test-only --partition=gpu`:
+			return "sbatch: error: invalid partition specified: gpu\n", errors.New("exit status 1")
+		}
+		return "", errors.New("sh: sbatch: not found")
+	}}
+	c, err := newClient(r, files.Local{}, nil)
+	require.NoError(t, err)
+
+	e, err := c.SBatchTest("#!/bin/sh", "debug")
+	require.NoError(t, err)
+	require.Empty(t, e.Errors)
+	require.Equal(t, "node1", e.NodeList)
+	require.Equal(t, "#!/bin/sh", r.stdin)
+
+	e, err = c.SBatchTest("#!/bin/sh", "gpu")
+	require.NoError(t, err)
+	require.Equal(t, []string{"invalid partition specified: gpu"}, e.Errors)
+
+	_, err = c.SBatchTest("#!/bin/sh", "")
+	require.Error(t, err)
+}
+
+func TestClient_SJobScript(t *testing.T) {
+	const script = "#!/bin/sh\n#SBATCH --nodes=1\nsrun hostname\n"
+	r := &stubRunner{reply: func(cmd string) (string, error) {
+		switch cmd {
+		case "scontrol write batch_script 53 -":
+			return script, nil
+		case "sacct -j 53 --env-vars":
+			return "Environment used for 53\n" +
+				"--------------------------------------------------------------------------------\n" +
+				"HOME=/home/vagrant\nPATH=/usr/bin\n", nil
+		case "sacct -j 54 --batch-script":
+			return "Batch Script for 54\n" +
+				"--------------------------------------------------------------------------------\n" + script, nil
+		case "sacct -j 55 --batch-script":
+			return "", nil
+		case "scontrol write batch_script 56 -":
+			return "", errors.New("slurm_load_jobs error: Access/permission denied")
+		}
+		if strings.HasPrefix(cmd, "sacct") {
+			return "", errors.New("sacct: unrecognized option")
+		}
+		return "", errors.New("slurm_load_jobs error: Invalid job id specified")
+	}}
+	c, err := newClient(r, files.Local{}, nil)
+	require.NoError(t, err)
+
+	js, err := c.SJobScript(53)
+	require.NoError(t, err)
+	require.Equal(t, &JobScript{Script: script, Environment: []string{"HOME=/home/vagrant", "PATH=/usr/bin"}}, js)
+
+	// purged job is looked up in accounting
+	js, err = c.SJobScript(54)
+	require.NoError(t, err)
+	require.Equal(t, &JobScript{Script: script}, js)
+
+	_, err = c.SJobScript(55)
+	require.Equal(t, ErrJobNotFound, errors.Cause(err))
+
+	_, err = c.SJobScript(56)
+	require.Error(t, err)
+	require.NotEqual(t, ErrJobNotFound, errors.Cause(err))
+}
+
+func TestClient_clusters(t *testing.T) {
+	r := &stubRunner{reply: func(cmd string) (string, error) {
+		switch cmd {
+		case "sacctmgr -n -P show cluster format=cluster":
+			return "east\nwest\n", nil
+		case "sbatch --clusters=west --parsable --partition=debug":
+			return "42;west\n", nil
+		case "scontrol --clusters=east show partition":
+			return "CLUSTER: east\nPartitionName=debug\n\nPartitionName=gpu\n", nil
+		case "scontrol --clusters=west show partition":
+			return "CLUSTER: west\nPartitionName=debug\n", nil
+		case "scontrol --clusters=west show jobid 53":
+			return "CLUSTER: west\n" + testScontrolResponse, nil
+		case "scancel --clusters=west 42":
+			return "", nil
+		case "scontrol --clusters=west suspend 42", "scontrol --clusters=west resume 42":
+			return "", nil
+		case "sacct --clusters=west -p -n -j 53 -o start,end,exitcode,state,jobid,jobname":
+			return "2019-02-20T11:16:55|2019-02-20T11:16:55|0:0|COMPLETED|53|test|\n", nil
+		}
+		return "", errors.New("Invalid job id specified")
+	}}
+
+	_, err := newClient(r, files.Local{}, []string{"east", "north"})
+	require.EqualError(t, err, "unknown clusters: north")
+
+	c, err := newClient(r, files.Local{}, []string{"east", "west"})
+	require.NoError(t, err)
+
+	partitions, err := c.Partitions()
+	require.NoError(t, err)
+	require.Equal(t, []string{"east/debug", "east/gpu", "west/debug"}, partitions)
+
+	_, err = c.SBatch("#!/bin/sh", "debug")
+	require.Error(t, err)
+	id, err := c.SBatch("#!/bin/sh", "west/debug")
+	require.NoError(t, err)
+	require.EqualValues(t, 42, id)
+	require.NoError(t, c.SCancel(42))
+	require.NoError(t, c.SSuspend(42))
+	require.NoError(t, c.SResume(42))
+
+	// job submitted elsewhere is looked up on all clusters
+	info, err := c.SJobInfo(53)
+	require.NoError(t, err)
+	require.Equal(t, "west/debug", info[0].Partition)
+
+	r.commands = nil
+	_, err = c.SJobSteps(53)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"sacct --clusters=west -p -n -j 53 -o start,end,exitcode,state,jobid,jobname",
+	}, r.commands)
+}
+
+func TestClient_rememberCluster(t *testing.T) {
+	c := &Client{jobs: make(map[int64]string)}
+	for i := int64(0); i < maxKnownJobs+10; i++ {
+		c.rememberCluster(i, "west")
+	}
+	require.Len(t, c.jobs, maxKnownJobs)
+	require.Equal(t, "west", c.jobs[maxKnownJobs+9])
+}
+
+func TestPartitionLabel(t *testing.T) {
+	clusters := []string{"east", "west"}
+	require.Equal(t, "debug", PartitionLabelValue("debug"))
+	require.Equal(t, "east.debug", PartitionLabelValue("east/debug"))
+	require.Equal(t, "east/debug", PartitionFromLabel("east.debug", clusters))
+	require.Equal(t, "east/debug", PartitionFromLabel("east/debug", clusters))
+	require.Equal(t, "gpu.a100", PartitionFromLabel("gpu.a100", clusters))
+	require.Equal(t, "east.debug", PartitionFromLabel("east.debug", nil))
+}
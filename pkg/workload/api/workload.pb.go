@@ -7,9 +7,9 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
-	grpc "google.golang.org/grpc"
 	duration "github.com/golang/protobuf/ptypes/duration"
 	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
 	math "math"
 )
 
@@ -92,7 +92,11 @@ type SubmitJobRequest struct {
 	// Partition where job should be submitted.
 	Partition string `protobuf:"bytes,2,opt,name=partition,proto3" json:"partition,omitempty"`
 	// ID of a client who submitted this job.
-	ClientId             string   `protobuf:"bytes,3,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ClientId string `protobuf:"bytes,3,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	// Key to make submission idempotent, job submitted by the client with
+	// the same key earlier is returned instead of submitting a new one.
+	// Replaying the key with another script is an invalid argument.
+	IdempotencyKey       string   `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -144,6 +148,13 @@ func (m *SubmitJobRequest) GetClientId() string {
 	return ""
 }
 
+func (m *SubmitJobRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
 type SubmitJobResponse struct {
 	// Job ID to track submitted job.
 	JobId                int64    `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
@@ -184,6 +195,100 @@ func (m *SubmitJobResponse) GetJobId() int64 {
 	return 0
 }
 
+type ValidateJobResponse struct {
+	// Whether job would be accepted.
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// Errors job would be rejected with.
+	Errors []string `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	// Warnings reported by workload manager.
+	Warnings []string `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// Estimated job start time.
+	StartTime *timestamp.Timestamp `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// Number of cpus job would be allocated.
+	Cpus int64 `protobuf:"varint,5,opt,name=cpus,proto3" json:"cpus,omitempty"`
+	// List of nodes job would be allocated.
+	NodeList string `protobuf:"bytes,6,opt,name=node_list,json=nodeList,proto3" json:"node_list,omitempty"`
+	// Partition job would run in.
+	Partition            string   `protobuf:"bytes,7,opt,name=partition,proto3" json:"partition,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ValidateJobResponse) Reset()         { *m = ValidateJobResponse{} }
+func (m *ValidateJobResponse) String() string { return proto.CompactTextString(m) }
+func (*ValidateJobResponse) ProtoMessage()    {}
+func (*ValidateJobResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{2}
+}
+
+func (m *ValidateJobResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ValidateJobResponse.Unmarshal(m, b)
+}
+func (m *ValidateJobResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ValidateJobResponse.Marshal(b, m, deterministic)
+}
+func (m *ValidateJobResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValidateJobResponse.Merge(m, src)
+}
+func (m *ValidateJobResponse) XXX_Size() int {
+	return xxx_messageInfo_ValidateJobResponse.Size(m)
+}
+func (m *ValidateJobResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValidateJobResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValidateJobResponse proto.InternalMessageInfo
+
+func (m *ValidateJobResponse) GetValid() bool {
+	if m != nil {
+		return m.Valid
+	}
+	return false
+}
+
+func (m *ValidateJobResponse) GetErrors() []string {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+func (m *ValidateJobResponse) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+func (m *ValidateJobResponse) GetStartTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.StartTime
+	}
+	return nil
+}
+
+func (m *ValidateJobResponse) GetCpus() int64 {
+	if m != nil {
+		return m.Cpus
+	}
+	return 0
+}
+
+func (m *ValidateJobResponse) GetNodeList() string {
+	if m != nil {
+		return m.NodeList
+	}
+	return ""
+}
+
+func (m *ValidateJobResponse) GetPartition() string {
+	if m != nil {
+		return m.Partition
+	}
+	return ""
+}
+
 type CancelJobRequest struct {
 	// ID of a job to be cancelled.
 	JobId                int64    `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
@@ -196,7 +301,7 @@ func (m *CancelJobRequest) Reset()         { *m = CancelJobRequest{} }
 func (m *CancelJobRequest) String() string { return proto.CompactTextString(m) }
 func (*CancelJobRequest) ProtoMessage()    {}
 func (*CancelJobRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{2}
+	return fileDescriptor_5a3bd06263c8633f, []int{3}
 }
 
 func (m *CancelJobRequest) XXX_Unmarshal(b []byte) error {
@@ -234,7 +339,7 @@ func (m *CancelJobResponse) Reset()         { *m = CancelJobResponse{} }
 func (m *CancelJobResponse) String() string { return proto.CompactTextString(m) }
 func (*CancelJobResponse) ProtoMessage()    {}
 func (*CancelJobResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{3}
+	return fileDescriptor_5a3bd06263c8633f, []int{4}
 }
 
 func (m *CancelJobResponse) XXX_Unmarshal(b []byte) error {
@@ -255,6 +360,380 @@ func (m *CancelJobResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_CancelJobResponse proto.InternalMessageInfo
 
+type HoldJobRequest struct {
+	// ID of a job to be held.
+	JobId                int64    `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HoldJobRequest) Reset()         { *m = HoldJobRequest{} }
+func (m *HoldJobRequest) String() string { return proto.CompactTextString(m) }
+func (*HoldJobRequest) ProtoMessage()    {}
+func (*HoldJobRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{5}
+}
+
+func (m *HoldJobRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HoldJobRequest.Unmarshal(m, b)
+}
+func (m *HoldJobRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HoldJobRequest.Marshal(b, m, deterministic)
+}
+func (m *HoldJobRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HoldJobRequest.Merge(m, src)
+}
+func (m *HoldJobRequest) XXX_Size() int {
+	return xxx_messageInfo_HoldJobRequest.Size(m)
+}
+func (m *HoldJobRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_HoldJobRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HoldJobRequest proto.InternalMessageInfo
+
+func (m *HoldJobRequest) GetJobId() int64 {
+	if m != nil {
+		return m.JobId
+	}
+	return 0
+}
+
+type HoldJobResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HoldJobResponse) Reset()         { *m = HoldJobResponse{} }
+func (m *HoldJobResponse) String() string { return proto.CompactTextString(m) }
+func (*HoldJobResponse) ProtoMessage()    {}
+func (*HoldJobResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{6}
+}
+
+func (m *HoldJobResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HoldJobResponse.Unmarshal(m, b)
+}
+func (m *HoldJobResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HoldJobResponse.Marshal(b, m, deterministic)
+}
+func (m *HoldJobResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HoldJobResponse.Merge(m, src)
+}
+func (m *HoldJobResponse) XXX_Size() int {
+	return xxx_messageInfo_HoldJobResponse.Size(m)
+}
+func (m *HoldJobResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_HoldJobResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HoldJobResponse proto.InternalMessageInfo
+
+type ReleaseJobRequest struct {
+	// ID of a job to be released.
+	JobId                int64    `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReleaseJobRequest) Reset()         { *m = ReleaseJobRequest{} }
+func (m *ReleaseJobRequest) String() string { return proto.CompactTextString(m) }
+func (*ReleaseJobRequest) ProtoMessage()    {}
+func (*ReleaseJobRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{7}
+}
+
+func (m *ReleaseJobRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReleaseJobRequest.Unmarshal(m, b)
+}
+func (m *ReleaseJobRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReleaseJobRequest.Marshal(b, m, deterministic)
+}
+func (m *ReleaseJobRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReleaseJobRequest.Merge(m, src)
+}
+func (m *ReleaseJobRequest) XXX_Size() int {
+	return xxx_messageInfo_ReleaseJobRequest.Size(m)
+}
+func (m *ReleaseJobRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReleaseJobRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReleaseJobRequest proto.InternalMessageInfo
+
+func (m *ReleaseJobRequest) GetJobId() int64 {
+	if m != nil {
+		return m.JobId
+	}
+	return 0
+}
+
+type ReleaseJobResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReleaseJobResponse) Reset()         { *m = ReleaseJobResponse{} }
+func (m *ReleaseJobResponse) String() string { return proto.CompactTextString(m) }
+func (*ReleaseJobResponse) ProtoMessage()    {}
+func (*ReleaseJobResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{8}
+}
+
+func (m *ReleaseJobResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReleaseJobResponse.Unmarshal(m, b)
+}
+func (m *ReleaseJobResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReleaseJobResponse.Marshal(b, m, deterministic)
+}
+func (m *ReleaseJobResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReleaseJobResponse.Merge(m, src)
+}
+func (m *ReleaseJobResponse) XXX_Size() int {
+	return xxx_messageInfo_ReleaseJobResponse.Size(m)
+}
+func (m *ReleaseJobResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReleaseJobResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReleaseJobResponse proto.InternalMessageInfo
+
+type SuspendJobRequest struct {
+	// ID of a job to be suspended.
+	JobId                int64    `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SuspendJobRequest) Reset()         { *m = SuspendJobRequest{} }
+func (m *SuspendJobRequest) String() string { return proto.CompactTextString(m) }
+func (*SuspendJobRequest) ProtoMessage()    {}
+func (*SuspendJobRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{9}
+}
+
+func (m *SuspendJobRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SuspendJobRequest.Unmarshal(m, b)
+}
+func (m *SuspendJobRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SuspendJobRequest.Marshal(b, m, deterministic)
+}
+func (m *SuspendJobRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SuspendJobRequest.Merge(m, src)
+}
+func (m *SuspendJobRequest) XXX_Size() int {
+	return xxx_messageInfo_SuspendJobRequest.Size(m)
+}
+func (m *SuspendJobRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SuspendJobRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SuspendJobRequest proto.InternalMessageInfo
+
+func (m *SuspendJobRequest) GetJobId() int64 {
+	if m != nil {
+		return m.JobId
+	}
+	return 0
+}
+
+type SuspendJobResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SuspendJobResponse) Reset()         { *m = SuspendJobResponse{} }
+func (m *SuspendJobResponse) String() string { return proto.CompactTextString(m) }
+func (*SuspendJobResponse) ProtoMessage()    {}
+func (*SuspendJobResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{10}
+}
+
+func (m *SuspendJobResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SuspendJobResponse.Unmarshal(m, b)
+}
+func (m *SuspendJobResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SuspendJobResponse.Marshal(b, m, deterministic)
+}
+func (m *SuspendJobResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SuspendJobResponse.Merge(m, src)
+}
+func (m *SuspendJobResponse) XXX_Size() int {
+	return xxx_messageInfo_SuspendJobResponse.Size(m)
+}
+func (m *SuspendJobResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SuspendJobResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SuspendJobResponse proto.InternalMessageInfo
+
+type ResumeJobRequest struct {
+	// ID of a job to be resumed.
+	JobId                int64    `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResumeJobRequest) Reset()         { *m = ResumeJobRequest{} }
+func (m *ResumeJobRequest) String() string { return proto.CompactTextString(m) }
+func (*ResumeJobRequest) ProtoMessage()    {}
+func (*ResumeJobRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{11}
+}
+
+func (m *ResumeJobRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResumeJobRequest.Unmarshal(m, b)
+}
+func (m *ResumeJobRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResumeJobRequest.Marshal(b, m, deterministic)
+}
+func (m *ResumeJobRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResumeJobRequest.Merge(m, src)
+}
+func (m *ResumeJobRequest) XXX_Size() int {
+	return xxx_messageInfo_ResumeJobRequest.Size(m)
+}
+func (m *ResumeJobRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResumeJobRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResumeJobRequest proto.InternalMessageInfo
+
+func (m *ResumeJobRequest) GetJobId() int64 {
+	if m != nil {
+		return m.JobId
+	}
+	return 0
+}
+
+type ResumeJobResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResumeJobResponse) Reset()         { *m = ResumeJobResponse{} }
+func (m *ResumeJobResponse) String() string { return proto.CompactTextString(m) }
+func (*ResumeJobResponse) ProtoMessage()    {}
+func (*ResumeJobResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{12}
+}
+
+func (m *ResumeJobResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResumeJobResponse.Unmarshal(m, b)
+}
+func (m *ResumeJobResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResumeJobResponse.Marshal(b, m, deterministic)
+}
+func (m *ResumeJobResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResumeJobResponse.Merge(m, src)
+}
+func (m *ResumeJobResponse) XXX_Size() int {
+	return xxx_messageInfo_ResumeJobResponse.Size(m)
+}
+func (m *ResumeJobResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResumeJobResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResumeJobResponse proto.InternalMessageInfo
+
+type RequeueJobRequest struct {
+	// ID of a job to be requeued.
+	JobId int64 `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Whether requeued job should be held.
+	Hold bool `protobuf:"varint,2,opt,name=hold,proto3" json:"hold,omitempty"`
+	// Index of a job array task to be requeued, the whole job is requeued
+	// when empty. Unlike the whole job, a finished task may be requeued.
+	ArrayTaskId          string   `protobuf:"bytes,3,opt,name=array_task_id,json=arrayTaskId,proto3" json:"array_task_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequeueJobRequest) Reset()         { *m = RequeueJobRequest{} }
+func (m *RequeueJobRequest) String() string { return proto.CompactTextString(m) }
+func (*RequeueJobRequest) ProtoMessage()    {}
+func (*RequeueJobRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{13}
+}
+
+func (m *RequeueJobRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequeueJobRequest.Unmarshal(m, b)
+}
+func (m *RequeueJobRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequeueJobRequest.Marshal(b, m, deterministic)
+}
+func (m *RequeueJobRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequeueJobRequest.Merge(m, src)
+}
+func (m *RequeueJobRequest) XXX_Size() int {
+	return xxx_messageInfo_RequeueJobRequest.Size(m)
+}
+func (m *RequeueJobRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequeueJobRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequeueJobRequest proto.InternalMessageInfo
+
+func (m *RequeueJobRequest) GetJobId() int64 {
+	if m != nil {
+		return m.JobId
+	}
+	return 0
+}
+
+func (m *RequeueJobRequest) GetHold() bool {
+	if m != nil {
+		return m.Hold
+	}
+	return false
+}
+
+func (m *RequeueJobRequest) GetArrayTaskId() string {
+	if m != nil {
+		return m.ArrayTaskId
+	}
+	return ""
+}
+
+type RequeueJobResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequeueJobResponse) Reset()         { *m = RequeueJobResponse{} }
+func (m *RequeueJobResponse) String() string { return proto.CompactTextString(m) }
+func (*RequeueJobResponse) ProtoMessage()    {}
+func (*RequeueJobResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{14}
+}
+
+func (m *RequeueJobResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequeueJobResponse.Unmarshal(m, b)
+}
+func (m *RequeueJobResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequeueJobResponse.Marshal(b, m, deterministic)
+}
+func (m *RequeueJobResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequeueJobResponse.Merge(m, src)
+}
+func (m *RequeueJobResponse) XXX_Size() int {
+	return xxx_messageInfo_RequeueJobResponse.Size(m)
+}
+func (m *RequeueJobResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequeueJobResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequeueJobResponse proto.InternalMessageInfo
+
 type JobInfoRequest struct {
 	// ID of a job to fetch info of.
 	JobId                int64    `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
@@ -267,7 +746,7 @@ func (m *JobInfoRequest) Reset()         { *m = JobInfoRequest{} }
 func (m *JobInfoRequest) String() string { return proto.CompactTextString(m) }
 func (*JobInfoRequest) ProtoMessage()    {}
 func (*JobInfoRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{4}
+	return fileDescriptor_5a3bd06263c8633f, []int{15}
 }
 
 func (m *JobInfoRequest) XXX_Unmarshal(b []byte) error {
@@ -307,7 +786,7 @@ func (m *JobInfoResponse) Reset()         { *m = JobInfoResponse{} }
 func (m *JobInfoResponse) String() string { return proto.CompactTextString(m) }
 func (*JobInfoResponse) ProtoMessage()    {}
 func (*JobInfoResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{5}
+	return fileDescriptor_5a3bd06263c8633f, []int{16}
 }
 
 func (m *JobInfoResponse) XXX_Unmarshal(b []byte) error {
@@ -335,6 +814,87 @@ func (m *JobInfoResponse) GetInfo() []*JobInfo {
 	return nil
 }
 
+type JobsInfoRequest struct {
+	// IDs of jobs to fetch info of.
+	JobIds               []int64  `protobuf:"varint,1,rep,packed,name=job_ids,json=jobIds,proto3" json:"job_ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobsInfoRequest) Reset()         { *m = JobsInfoRequest{} }
+func (m *JobsInfoRequest) String() string { return proto.CompactTextString(m) }
+func (*JobsInfoRequest) ProtoMessage()    {}
+func (*JobsInfoRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{17}
+}
+
+func (m *JobsInfoRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobsInfoRequest.Unmarshal(m, b)
+}
+func (m *JobsInfoRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobsInfoRequest.Marshal(b, m, deterministic)
+}
+func (m *JobsInfoRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobsInfoRequest.Merge(m, src)
+}
+func (m *JobsInfoRequest) XXX_Size() int {
+	return xxx_messageInfo_JobsInfoRequest.Size(m)
+}
+func (m *JobsInfoRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobsInfoRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobsInfoRequest proto.InternalMessageInfo
+
+func (m *JobsInfoRequest) GetJobIds() []int64 {
+	if m != nil {
+		return m.JobIds
+	}
+	return nil
+}
+
+type JobsInfoResponse struct {
+	// Job information in order of requested job ids,
+	// info of a job that is not found is empty.
+	Jobs                 []*JobInfoResponse `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *JobsInfoResponse) Reset()         { *m = JobsInfoResponse{} }
+func (m *JobsInfoResponse) String() string { return proto.CompactTextString(m) }
+func (*JobsInfoResponse) ProtoMessage()    {}
+func (*JobsInfoResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{18}
+}
+
+func (m *JobsInfoResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobsInfoResponse.Unmarshal(m, b)
+}
+func (m *JobsInfoResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobsInfoResponse.Marshal(b, m, deterministic)
+}
+func (m *JobsInfoResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobsInfoResponse.Merge(m, src)
+}
+func (m *JobsInfoResponse) XXX_Size() int {
+	return xxx_messageInfo_JobsInfoResponse.Size(m)
+}
+func (m *JobsInfoResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobsInfoResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobsInfoResponse proto.InternalMessageInfo
+
+func (m *JobsInfoResponse) GetJobs() []*JobInfoResponse {
+	if m != nil {
+		return m.Jobs
+	}
+	return nil
+}
+
 type JobStepsRequest struct {
 	// ID of a job to fetch steps of.
 	JobId                int64    `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
@@ -347,7 +907,7 @@ func (m *JobStepsRequest) Reset()         { *m = JobStepsRequest{} }
 func (m *JobStepsRequest) String() string { return proto.CompactTextString(m) }
 func (*JobStepsRequest) ProtoMessage()    {}
 func (*JobStepsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{6}
+	return fileDescriptor_5a3bd06263c8633f, []int{19}
 }
 
 func (m *JobStepsRequest) XXX_Unmarshal(b []byte) error {
@@ -387,32 +947,396 @@ func (m *JobStepsResponse) Reset()         { *m = JobStepsResponse{} }
 func (m *JobStepsResponse) String() string { return proto.CompactTextString(m) }
 func (*JobStepsResponse) ProtoMessage()    {}
 func (*JobStepsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{7}
+	return fileDescriptor_5a3bd06263c8633f, []int{20}
+}
+
+func (m *JobStepsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobStepsResponse.Unmarshal(m, b)
+}
+func (m *JobStepsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobStepsResponse.Marshal(b, m, deterministic)
+}
+func (m *JobStepsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobStepsResponse.Merge(m, src)
+}
+func (m *JobStepsResponse) XXX_Size() int {
+	return xxx_messageInfo_JobStepsResponse.Size(m)
+}
+func (m *JobStepsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobStepsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobStepsResponse proto.InternalMessageInfo
+
+func (m *JobStepsResponse) GetJobSteps() []*JobStepInfo {
+	if m != nil {
+		return m.JobSteps
+	}
+	return nil
+}
+
+type JobScriptRequest struct {
+	// ID of a job to fetch script of.
+	JobId                int64    `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobScriptRequest) Reset()         { *m = JobScriptRequest{} }
+func (m *JobScriptRequest) String() string { return proto.CompactTextString(m) }
+func (*JobScriptRequest) ProtoMessage()    {}
+func (*JobScriptRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{21}
+}
+
+func (m *JobScriptRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobScriptRequest.Unmarshal(m, b)
+}
+func (m *JobScriptRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobScriptRequest.Marshal(b, m, deterministic)
+}
+func (m *JobScriptRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobScriptRequest.Merge(m, src)
+}
+func (m *JobScriptRequest) XXX_Size() int {
+	return xxx_messageInfo_JobScriptRequest.Size(m)
+}
+func (m *JobScriptRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobScriptRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobScriptRequest proto.InternalMessageInfo
+
+func (m *JobScriptRequest) GetJobId() int64 {
+	if m != nil {
+		return m.JobId
+	}
+	return 0
+}
+
+type JobScriptResponse struct {
+	// Batch script of the job.
+	Script string `protobuf:"bytes,1,opt,name=script,proto3" json:"script,omitempty"`
+	// Environment the job was submitted with in KEY=value form,
+	// empty when workload manager doesn't keep it.
+	Environment          []string `protobuf:"bytes,2,rep,name=environment,proto3" json:"environment,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobScriptResponse) Reset()         { *m = JobScriptResponse{} }
+func (m *JobScriptResponse) String() string { return proto.CompactTextString(m) }
+func (*JobScriptResponse) ProtoMessage()    {}
+func (*JobScriptResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{22}
+}
+
+func (m *JobScriptResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobScriptResponse.Unmarshal(m, b)
+}
+func (m *JobScriptResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobScriptResponse.Marshal(b, m, deterministic)
+}
+func (m *JobScriptResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobScriptResponse.Merge(m, src)
+}
+func (m *JobScriptResponse) XXX_Size() int {
+	return xxx_messageInfo_JobScriptResponse.Size(m)
+}
+func (m *JobScriptResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobScriptResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobScriptResponse proto.InternalMessageInfo
+
+func (m *JobScriptResponse) GetScript() string {
+	if m != nil {
+		return m.Script
+	}
+	return ""
+}
+
+func (m *JobScriptResponse) GetEnvironment() []string {
+	if m != nil {
+		return m.Environment
+	}
+	return nil
+}
+
+type JobHistoryRequest struct {
+	// Return only jobs submitted by this client.
+	ClientId string `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	// Return only jobs submitted to this partition.
+	Partition string `protobuf:"bytes,2,opt,name=partition,proto3" json:"partition,omitempty"`
+	// Return only jobs that finished with this status.
+	Status JobStatus `protobuf:"varint,3,opt,name=status,proto3,enum=api.JobStatus" json:"status,omitempty"`
+	// Whether status filter is set, since COMPLETED is a zero value.
+	FilterStatus bool `protobuf:"varint,4,opt,name=filter_status,json=filterStatus,proto3" json:"filter_status,omitempty"`
+	// Return only jobs submitted after this time.
+	SubmittedAfter *timestamp.Timestamp `protobuf:"bytes,5,opt,name=submitted_after,json=submittedAfter,proto3" json:"submitted_after,omitempty"`
+	// Return only jobs submitted before this time.
+	SubmittedBefore *timestamp.Timestamp `protobuf:"bytes,6,opt,name=submitted_before,json=submittedBefore,proto3" json:"submitted_before,omitempty"`
+	// Maximum number of records to return, 100 by default.
+	PageSize int32 `protobuf:"varint,7,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Token returned with the previous page.
+	PageToken            string   `protobuf:"bytes,8,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobHistoryRequest) Reset()         { *m = JobHistoryRequest{} }
+func (m *JobHistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*JobHistoryRequest) ProtoMessage()    {}
+func (*JobHistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{23}
+}
+
+func (m *JobHistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobHistoryRequest.Unmarshal(m, b)
+}
+func (m *JobHistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobHistoryRequest.Marshal(b, m, deterministic)
+}
+func (m *JobHistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobHistoryRequest.Merge(m, src)
+}
+func (m *JobHistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_JobHistoryRequest.Size(m)
+}
+func (m *JobHistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobHistoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobHistoryRequest proto.InternalMessageInfo
+
+func (m *JobHistoryRequest) GetClientId() string {
+	if m != nil {
+		return m.ClientId
+	}
+	return ""
+}
+
+func (m *JobHistoryRequest) GetPartition() string {
+	if m != nil {
+		return m.Partition
+	}
+	return ""
+}
+
+func (m *JobHistoryRequest) GetStatus() JobStatus {
+	if m != nil {
+		return m.Status
+	}
+	return JobStatus_COMPLETED
+}
+
+func (m *JobHistoryRequest) GetFilterStatus() bool {
+	if m != nil {
+		return m.FilterStatus
+	}
+	return false
+}
+
+func (m *JobHistoryRequest) GetSubmittedAfter() *timestamp.Timestamp {
+	if m != nil {
+		return m.SubmittedAfter
+	}
+	return nil
+}
+
+func (m *JobHistoryRequest) GetSubmittedBefore() *timestamp.Timestamp {
+	if m != nil {
+		return m.SubmittedBefore
+	}
+	return nil
+}
+
+func (m *JobHistoryRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *JobHistoryRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type JobHistoryResponse struct {
+	// Job records in order of submission.
+	Jobs []*JobRecord `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	// Token to fetch the next page, empty if there are no more records.
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobHistoryResponse) Reset()         { *m = JobHistoryResponse{} }
+func (m *JobHistoryResponse) String() string { return proto.CompactTextString(m) }
+func (*JobHistoryResponse) ProtoMessage()    {}
+func (*JobHistoryResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{24}
+}
+
+func (m *JobHistoryResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobHistoryResponse.Unmarshal(m, b)
+}
+func (m *JobHistoryResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobHistoryResponse.Marshal(b, m, deterministic)
+}
+func (m *JobHistoryResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobHistoryResponse.Merge(m, src)
+}
+func (m *JobHistoryResponse) XXX_Size() int {
+	return xxx_messageInfo_JobHistoryResponse.Size(m)
+}
+func (m *JobHistoryResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobHistoryResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobHistoryResponse proto.InternalMessageInfo
+
+func (m *JobHistoryResponse) GetJobs() []*JobRecord {
+	if m != nil {
+		return m.Jobs
+	}
+	return nil
+}
+
+func (m *JobHistoryResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+// JobRecord is a history record of a submitted job.
+type JobRecord struct {
+	// ID of a job.
+	JobId int64 `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// ID of a client who submitted the job.
+	ClientId string `protobuf:"bytes,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	// Idempotency key the job was submitted with.
+	IdempotencyKey string `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Partition where job was submitted.
+	Partition string `protobuf:"bytes,4,opt,name=partition,proto3" json:"partition,omitempty"`
+	// Submit request encoded as JSON.
+	Request string `protobuf:"bytes,5,opt,name=request,proto3" json:"request,omitempty"`
+	// SHA-256 of the submitted script.
+	ScriptHash string `protobuf:"bytes,6,opt,name=script_hash,json=scriptHash,proto3" json:"script_hash,omitempty"`
+	// Job submit time.
+	SubmitTime *timestamp.Timestamp `protobuf:"bytes,7,opt,name=submit_time,json=submitTime,proto3" json:"submit_time,omitempty"`
+	// Time the final job status was observed, not set while job is not finished.
+	EndTime *timestamp.Timestamp `protobuf:"bytes,8,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// Final job status, UNKNOWN while job is not finished.
+	Status JobStatus `protobuf:"varint,9,opt,name=status,proto3,enum=api.JobStatus" json:"status,omitempty"`
+	// Final job exit code.
+	ExitCode             string   `protobuf:"bytes,10,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobRecord) Reset()         { *m = JobRecord{} }
+func (m *JobRecord) String() string { return proto.CompactTextString(m) }
+func (*JobRecord) ProtoMessage()    {}
+func (*JobRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{25}
+}
+
+func (m *JobRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobRecord.Unmarshal(m, b)
+}
+func (m *JobRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobRecord.Marshal(b, m, deterministic)
+}
+func (m *JobRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobRecord.Merge(m, src)
+}
+func (m *JobRecord) XXX_Size() int {
+	return xxx_messageInfo_JobRecord.Size(m)
+}
+func (m *JobRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobRecord proto.InternalMessageInfo
+
+func (m *JobRecord) GetJobId() int64 {
+	if m != nil {
+		return m.JobId
+	}
+	return 0
+}
+
+func (m *JobRecord) GetClientId() string {
+	if m != nil {
+		return m.ClientId
+	}
+	return ""
+}
+
+func (m *JobRecord) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
+func (m *JobRecord) GetPartition() string {
+	if m != nil {
+		return m.Partition
+	}
+	return ""
 }
 
-func (m *JobStepsResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_JobStepsResponse.Unmarshal(m, b)
-}
-func (m *JobStepsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_JobStepsResponse.Marshal(b, m, deterministic)
+func (m *JobRecord) GetRequest() string {
+	if m != nil {
+		return m.Request
+	}
+	return ""
 }
-func (m *JobStepsResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_JobStepsResponse.Merge(m, src)
+
+func (m *JobRecord) GetScriptHash() string {
+	if m != nil {
+		return m.ScriptHash
+	}
+	return ""
 }
-func (m *JobStepsResponse) XXX_Size() int {
-	return xxx_messageInfo_JobStepsResponse.Size(m)
+
+func (m *JobRecord) GetSubmitTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.SubmitTime
+	}
+	return nil
 }
-func (m *JobStepsResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_JobStepsResponse.DiscardUnknown(m)
+
+func (m *JobRecord) GetEndTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.EndTime
+	}
+	return nil
 }
 
-var xxx_messageInfo_JobStepsResponse proto.InternalMessageInfo
+func (m *JobRecord) GetStatus() JobStatus {
+	if m != nil {
+		return m.Status
+	}
+	return JobStatus_COMPLETED
+}
 
-func (m *JobStepsResponse) GetJobSteps() []*JobStepInfo {
+func (m *JobRecord) GetExitCode() string {
 	if m != nil {
-		return m.JobSteps
+		return m.ExitCode
 	}
-	return nil
+	return ""
 }
 
 type OpenFileRequest struct {
@@ -427,7 +1351,7 @@ func (m *OpenFileRequest) Reset()         { *m = OpenFileRequest{} }
 func (m *OpenFileRequest) String() string { return proto.CompactTextString(m) }
 func (*OpenFileRequest) ProtoMessage()    {}
 func (*OpenFileRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{8}
+	return fileDescriptor_5a3bd06263c8633f, []int{26}
 }
 
 func (m *OpenFileRequest) XXX_Unmarshal(b []byte) error {
@@ -468,7 +1392,7 @@ func (m *CreateFileRequest) Reset()         { *m = CreateFileRequest{} }
 func (m *CreateFileRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateFileRequest) ProtoMessage()    {}
 func (*CreateFileRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{9}
+	return fileDescriptor_5a3bd06263c8633f, []int{27}
 }
 
 func (m *CreateFileRequest) XXX_Unmarshal(b []byte) error {
@@ -513,7 +1437,7 @@ func (m *CreateFileResponse) Reset()         { *m = CreateFileResponse{} }
 func (m *CreateFileResponse) String() string { return proto.CompactTextString(m) }
 func (*CreateFileResponse) ProtoMessage()    {}
 func (*CreateFileResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{10}
+	return fileDescriptor_5a3bd06263c8633f, []int{28}
 }
 
 func (m *CreateFileResponse) XXX_Unmarshal(b []byte) error {
@@ -546,7 +1470,7 @@ func (m *ResourcesRequest) Reset()         { *m = ResourcesRequest{} }
 func (m *ResourcesRequest) String() string { return proto.CompactTextString(m) }
 func (*ResourcesRequest) ProtoMessage()    {}
 func (*ResourcesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{11}
+	return fileDescriptor_5a3bd06263c8633f, []int{29}
 }
 
 func (m *ResourcesRequest) XXX_Unmarshal(b []byte) error {
@@ -584,17 +1508,28 @@ type ResourcesResponse struct {
 	// Wall time setting for the partition.
 	WallTime int64 `protobuf:"varint,4,opt,name=wallTime,proto3" json:"wallTime,omitempty"`
 	// Set of features of the partition.
-	Features             []*Feature `protobuf:"bytes,5,rep,name=features,proto3" json:"features,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+	Features []*Feature `protobuf:"bytes,5,rep,name=features,proto3" json:"features,omitempty"`
+	// Groups of identical nodes in the partition, the largest class goes first.
+	// Nodes, cpuPerNode and memPerNode above describe the largest class
+	// when classes are discovered.
+	NodeClasses []*NodeClass `protobuf:"bytes,6,rep,name=nodeClasses,proto3" json:"nodeClasses,omitempty"`
+	// Current usage of the partition, cached by red-box.
+	// Not set if workload manager doesn't report it.
+	Capacity *Capacity `protobuf:"bytes,7,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// Trackable resources configured for the partition, e.g. cpu=128,gres/gpu=10.
+	Tres map[string]string `protobuf:"bytes,8,rep,name=tres,proto3" json:"tres,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Billing weights of the trackable resources of the partition.
+	TresBillingWeights   map[string]string `protobuf:"bytes,9,rep,name=tresBillingWeights,proto3" json:"tresBillingWeights,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *ResourcesResponse) Reset()         { *m = ResourcesResponse{} }
 func (m *ResourcesResponse) String() string { return proto.CompactTextString(m) }
 func (*ResourcesResponse) ProtoMessage()    {}
 func (*ResourcesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{12}
+	return fileDescriptor_5a3bd06263c8633f, []int{30}
 }
 
 func (m *ResourcesResponse) XXX_Unmarshal(b []byte) error {
@@ -650,6 +1585,34 @@ func (m *ResourcesResponse) GetFeatures() []*Feature {
 	return nil
 }
 
+func (m *ResourcesResponse) GetNodeClasses() []*NodeClass {
+	if m != nil {
+		return m.NodeClasses
+	}
+	return nil
+}
+
+func (m *ResourcesResponse) GetCapacity() *Capacity {
+	if m != nil {
+		return m.Capacity
+	}
+	return nil
+}
+
+func (m *ResourcesResponse) GetTres() map[string]string {
+	if m != nil {
+		return m.Tres
+	}
+	return nil
+}
+
+func (m *ResourcesResponse) GetTresBillingWeights() map[string]string {
+	if m != nil {
+		return m.TresBillingWeights
+	}
+	return nil
+}
+
 type PartitionsRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -660,7 +1623,7 @@ func (m *PartitionsRequest) Reset()         { *m = PartitionsRequest{} }
 func (m *PartitionsRequest) String() string { return proto.CompactTextString(m) }
 func (*PartitionsRequest) ProtoMessage()    {}
 func (*PartitionsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{13}
+	return fileDescriptor_5a3bd06263c8633f, []int{31}
 }
 
 func (m *PartitionsRequest) XXX_Unmarshal(b []byte) error {
@@ -692,7 +1655,7 @@ func (m *PartitionsResponse) Reset()         { *m = PartitionsResponse{} }
 func (m *PartitionsResponse) String() string { return proto.CompactTextString(m) }
 func (*PartitionsResponse) ProtoMessage()    {}
 func (*PartitionsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{14}
+	return fileDescriptor_5a3bd06263c8633f, []int{32}
 }
 
 func (m *PartitionsResponse) XXX_Unmarshal(b []byte) error {
@@ -730,7 +1693,7 @@ func (m *WorkloadInfoRequest) Reset()         { *m = WorkloadInfoRequest{} }
 func (m *WorkloadInfoRequest) String() string { return proto.CompactTextString(m) }
 func (*WorkloadInfoRequest) ProtoMessage()    {}
 func (*WorkloadInfoRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{15}
+	return fileDescriptor_5a3bd06263c8633f, []int{33}
 }
 
 func (m *WorkloadInfoRequest) XXX_Unmarshal(b []byte) error {
@@ -752,9 +1715,12 @@ func (m *WorkloadInfoRequest) XXX_DiscardUnknown() {
 var xxx_messageInfo_WorkloadInfoRequest proto.InternalMessageInfo
 
 type WorkloadInfoResponse struct {
-	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Version              string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Uid                  int64    `protobuf:"varint,3,opt,name=uid,proto3" json:"uid,omitempty"`
+	Name    string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Uid     int64  `protobuf:"varint,3,opt,name=uid,proto3" json:"uid,omitempty"`
+	// Clusters served by a workload manager, empty when
+	// only the default cluster is served.
+	Clusters             []string `protobuf:"bytes,4,rep,name=clusters,proto3" json:"clusters,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -764,7 +1730,7 @@ func (m *WorkloadInfoResponse) Reset()         { *m = WorkloadInfoResponse{} }
 func (m *WorkloadInfoResponse) String() string { return proto.CompactTextString(m) }
 func (*WorkloadInfoResponse) ProtoMessage()    {}
 func (*WorkloadInfoResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{16}
+	return fileDescriptor_5a3bd06263c8633f, []int{34}
 }
 
 func (m *WorkloadInfoResponse) XXX_Unmarshal(b []byte) error {
@@ -806,6 +1772,13 @@ func (m *WorkloadInfoResponse) GetUid() int64 {
 	return 0
 }
 
+func (m *WorkloadInfoResponse) GetClusters() []string {
+	if m != nil {
+		return m.Clusters
+	}
+	return nil
+}
+
 type SubmitJobContainerRequest struct {
 	// Job image name
 	ImageName string `protobuf:"bytes,1,opt,name=imageName,proto3" json:"imageName,omitempty"`
@@ -820,18 +1793,22 @@ type SubmitJobContainerRequest struct {
 	// Partition where job should be submitted.
 	Partition string `protobuf:"bytes,6,opt,name=partition,proto3" json:"partition,omitempty"`
 	// ID of a client who submitted this job.
-	ClientId             string              `protobuf:"bytes,7,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
-	Options              *SingularityOptions `protobuf:"bytes,8,opt,name=options,proto3" json:"options,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
-	XXX_unrecognized     []byte              `json:"-"`
-	XXX_sizecache        int32               `json:"-"`
+	ClientId string              `protobuf:"bytes,7,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	Options  *SingularityOptions `protobuf:"bytes,8,opt,name=options,proto3" json:"options,omitempty"`
+	// Key to make submission idempotent, job submitted by the client with
+	// the same key earlier is returned instead of submitting a new one.
+	// Replaying the key with another script is an invalid argument.
+	IdempotencyKey       string   `protobuf:"bytes,9,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *SubmitJobContainerRequest) Reset()         { *m = SubmitJobContainerRequest{} }
 func (m *SubmitJobContainerRequest) String() string { return proto.CompactTextString(m) }
 func (*SubmitJobContainerRequest) ProtoMessage()    {}
 func (*SubmitJobContainerRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{17}
+	return fileDescriptor_5a3bd06263c8633f, []int{35}
 }
 
 func (m *SubmitJobContainerRequest) XXX_Unmarshal(b []byte) error {
@@ -908,6 +1885,13 @@ func (m *SubmitJobContainerRequest) GetOptions() *SingularityOptions {
 	return nil
 }
 
+func (m *SubmitJobContainerRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
 type SingularityOptions struct {
 	App                  string   `protobuf:"bytes,1,opt,name=app,proto3" json:"app,omitempty"`
 	AllowUnsigned        bool     `protobuf:"varint,2,opt,name=allowUnsigned,proto3" json:"allowUnsigned,omitempty"`
@@ -928,7 +1912,7 @@ func (m *SingularityOptions) Reset()         { *m = SingularityOptions{} }
 func (m *SingularityOptions) String() string { return proto.CompactTextString(m) }
 func (*SingularityOptions) ProtoMessage()    {}
 func (*SingularityOptions) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{18}
+	return fileDescriptor_5a3bd06263c8633f, []int{36}
 }
 
 func (m *SingularityOptions) XXX_Unmarshal(b []byte) error {
@@ -1031,7 +2015,7 @@ func (m *SubmitJobContainerResponse) Reset()         { *m = SubmitJobContainerRe
 func (m *SubmitJobContainerResponse) String() string { return proto.CompactTextString(m) }
 func (*SubmitJobContainerResponse) ProtoMessage()    {}
 func (*SubmitJobContainerResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{19}
+	return fileDescriptor_5a3bd06263c8633f, []int{37}
 }
 
 func (m *SubmitJobContainerResponse) XXX_Unmarshal(b []byte) error {
@@ -1072,7 +2056,7 @@ func (m *TailFileRequest) Reset()         { *m = TailFileRequest{} }
 func (m *TailFileRequest) String() string { return proto.CompactTextString(m) }
 func (*TailFileRequest) ProtoMessage()    {}
 func (*TailFileRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{20}
+	return fileDescriptor_5a3bd06263c8633f, []int{38}
 }
 
 func (m *TailFileRequest) XXX_Unmarshal(b []byte) error {
@@ -1119,7 +2103,7 @@ func (m *ZipRequest) Reset()         { *m = ZipRequest{} }
 func (m *ZipRequest) String() string { return proto.CompactTextString(m) }
 func (*ZipRequest) ProtoMessage()    {}
 func (*ZipRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{21}
+	return fileDescriptor_5a3bd06263c8633f, []int{39}
 }
 
 func (m *ZipRequest) XXX_Unmarshal(b []byte) error {
@@ -1164,7 +2148,7 @@ func (m *ZipResponse) Reset()         { *m = ZipResponse{} }
 func (m *ZipResponse) String() string { return proto.CompactTextString(m) }
 func (*ZipResponse) ProtoMessage()    {}
 func (*ZipResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{22}
+	return fileDescriptor_5a3bd06263c8633f, []int{40}
 }
 
 func (m *ZipResponse) XXX_Unmarshal(b []byte) error {
@@ -1197,7 +2181,7 @@ func (m *UnzipRequest) Reset()         { *m = UnzipRequest{} }
 func (m *UnzipRequest) String() string { return proto.CompactTextString(m) }
 func (*UnzipRequest) ProtoMessage()    {}
 func (*UnzipRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{23}
+	return fileDescriptor_5a3bd06263c8633f, []int{41}
 }
 
 func (m *UnzipRequest) XXX_Unmarshal(b []byte) error {
@@ -1242,7 +2226,7 @@ func (m *UnzipResponse) Reset()         { *m = UnzipResponse{} }
 func (m *UnzipResponse) String() string { return proto.CompactTextString(m) }
 func (*UnzipResponse) ProtoMessage()    {}
 func (*UnzipResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{24}
+	return fileDescriptor_5a3bd06263c8633f, []int{42}
 }
 
 func (m *UnzipResponse) XXX_Unmarshal(b []byte) error {
@@ -1298,7 +2282,29 @@ type JobInfo struct {
 	// Number of nodes requested by job.
 	NumNodes string `protobuf:"bytes,16,opt,name=num_nodes,json=numNodes,proto3" json:"num_nodes,omitempty"`
 	// Job array id.
-	ArrayId              string   `protobuf:"bytes,17,opt,name=array_id,json=arrayId,proto3" json:"array_id,omitempty"`
+	ArrayId string `protobuf:"bytes,17,opt,name=array_id,json=arrayId,proto3" json:"array_id,omitempty"`
+	// Job end time, for a running job it is the time limit expiration.
+	EndTime *timestamp.Timestamp `protobuf:"bytes,18,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// Reason job is pending or failed, e.g. Resources.
+	Reason string `protobuf:"bytes,19,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Expected start time of a pending job, not set if unknown.
+	EstimatedStartTime *timestamp.Timestamp `protobuf:"bytes,20,opt,name=estimated_start_time,json=estimatedStartTime,proto3" json:"estimated_start_time,omitempty"`
+	// Job priority.
+	Priority int64 `protobuf:"varint,21,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Number of cpus allocated or requested by job.
+	NumCpus int64 `protobuf:"varint,22,opt,name=num_cpus,json=numCpus,proto3" json:"num_cpus,omitempty"`
+	// Trackable resources of job, e.g. cpu=4, mem=8G, gres/gpu=1.
+	Tres map[string]string `protobuf:"bytes,23,rep,name=tres,proto3" json:"tres,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Account job is charged to.
+	Account string `protobuf:"bytes,24,opt,name=account,proto3" json:"account,omitempty"`
+	// Job quality of service.
+	Qos string `protobuf:"bytes,25,opt,name=qos,proto3" json:"qos,omitempty"`
+	// Job state as reported by workload manager, e.g. RUNNING or NODE_FAIL,
+	// unlike status it is not limited to the states known to JobStatus.
+	State string `protobuf:"bytes,26,opt,name=state,proto3" json:"state,omitempty"`
+	// Index of a job array task, tasks that haven't started
+	// yet may be reported as a range of indices, e.g. 5-99%10.
+	ArrayTaskId          string   `protobuf:"bytes,27,opt,name=array_task_id,json=arrayTaskId,proto3" json:"array_task_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -1308,7 +2314,7 @@ func (m *JobInfo) Reset()         { *m = JobInfo{} }
 func (m *JobInfo) String() string { return proto.CompactTextString(m) }
 func (*JobInfo) ProtoMessage()    {}
 func (*JobInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{25}
+	return fileDescriptor_5a3bd06263c8633f, []int{43}
 }
 
 func (m *JobInfo) XXX_Unmarshal(b []byte) error {
@@ -1448,6 +2454,76 @@ func (m *JobInfo) GetArrayId() string {
 	return ""
 }
 
+func (m *JobInfo) GetEndTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.EndTime
+	}
+	return nil
+}
+
+func (m *JobInfo) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *JobInfo) GetEstimatedStartTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.EstimatedStartTime
+	}
+	return nil
+}
+
+func (m *JobInfo) GetPriority() int64 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *JobInfo) GetNumCpus() int64 {
+	if m != nil {
+		return m.NumCpus
+	}
+	return 0
+}
+
+func (m *JobInfo) GetTres() map[string]string {
+	if m != nil {
+		return m.Tres
+	}
+	return nil
+}
+
+func (m *JobInfo) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *JobInfo) GetQos() string {
+	if m != nil {
+		return m.Qos
+	}
+	return ""
+}
+
+func (m *JobInfo) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *JobInfo) GetArrayTaskId() string {
+	if m != nil {
+		return m.ArrayTaskId
+	}
+	return ""
+}
+
 // JobStepInfo represents information about a single job step.
 type JobStepInfo struct {
 	// ID od a job step.
@@ -1462,16 +2538,16 @@ type JobStepInfo struct {
 	StartTime *timestamp.Timestamp `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
 	// Job step end time.
 	EndTime              *timestamp.Timestamp `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
-	XXX_unrecognized     []byte                 `json:"-"`
-	XXX_sizecache        int32                  `json:"-"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *JobStepInfo) Reset()         { *m = JobStepInfo{} }
 func (m *JobStepInfo) String() string { return proto.CompactTextString(m) }
 func (*JobStepInfo) ProtoMessage()    {}
 func (*JobStepInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{26}
+	return fileDescriptor_5a3bd06263c8633f, []int{44}
 }
 
 func (m *JobStepInfo) XXX_Unmarshal(b []byte) error {
@@ -1546,7 +2622,7 @@ func (m *Chunk) Reset()         { *m = Chunk{} }
 func (m *Chunk) String() string { return proto.CompactTextString(m) }
 func (*Chunk) ProtoMessage()    {}
 func (*Chunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{27}
+	return fileDescriptor_5a3bd06263c8633f, []int{45}
 }
 
 func (m *Chunk) XXX_Unmarshal(b []byte) error {
@@ -1561,90 +2637,291 @@ func (m *Chunk) XXX_Merge(src proto.Message) {
 func (m *Chunk) XXX_Size() int {
 	return xxx_messageInfo_Chunk.Size(m)
 }
-func (m *Chunk) XXX_DiscardUnknown() {
-	xxx_messageInfo_Chunk.DiscardUnknown(m)
+func (m *Chunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_Chunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Chunk proto.InternalMessageInfo
+
+func (m *Chunk) GetContent() []byte {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+type Feature struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version              string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Quantity             int64    `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Feature) Reset()         { *m = Feature{} }
+func (m *Feature) String() string { return proto.CompactTextString(m) }
+func (*Feature) ProtoMessage()    {}
+func (*Feature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{46}
+}
+
+func (m *Feature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Feature.Unmarshal(m, b)
+}
+func (m *Feature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Feature.Marshal(b, m, deterministic)
+}
+func (m *Feature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Feature.Merge(m, src)
+}
+func (m *Feature) XXX_Size() int {
+	return xxx_messageInfo_Feature.Size(m)
+}
+func (m *Feature) XXX_DiscardUnknown() {
+	xxx_messageInfo_Feature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Feature proto.InternalMessageInfo
+
+func (m *Feature) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Feature) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Feature) GetQuantity() int64 {
+	if m != nil {
+		return m.Quantity
+	}
+	return 0
+}
+
+type Capacity struct {
+	IdleNodes  int64 `protobuf:"varint,1,opt,name=idleNodes,proto3" json:"idleNodes,omitempty"`
+	AllocNodes int64 `protobuf:"varint,2,opt,name=allocNodes,proto3" json:"allocNodes,omitempty"`
+	IdleCpus   int64 `protobuf:"varint,3,opt,name=idleCpus,proto3" json:"idleCpus,omitempty"`
+	AllocCpus  int64 `protobuf:"varint,4,opt,name=allocCpus,proto3" json:"allocCpus,omitempty"`
+	// Idle and allocated memory in MBs.
+	IdleMem   int64 `protobuf:"varint,5,opt,name=idleMem,proto3" json:"idleMem,omitempty"`
+	AllocMem  int64 `protobuf:"varint,6,opt,name=allocMem,proto3" json:"allocMem,omitempty"`
+	IdleGpus  int64 `protobuf:"varint,7,opt,name=idleGpus,proto3" json:"idleGpus,omitempty"`
+	AllocGpus int64 `protobuf:"varint,8,opt,name=allocGpus,proto3" json:"allocGpus,omitempty"`
+	// Time capacity was queried at.
+	UpdatedAt            *timestamp.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Capacity) Reset()         { *m = Capacity{} }
+func (m *Capacity) String() string { return proto.CompactTextString(m) }
+func (*Capacity) ProtoMessage()    {}
+func (*Capacity) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{47}
+}
+
+func (m *Capacity) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Capacity.Unmarshal(m, b)
+}
+func (m *Capacity) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Capacity.Marshal(b, m, deterministic)
+}
+func (m *Capacity) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Capacity.Merge(m, src)
+}
+func (m *Capacity) XXX_Size() int {
+	return xxx_messageInfo_Capacity.Size(m)
+}
+func (m *Capacity) XXX_DiscardUnknown() {
+	xxx_messageInfo_Capacity.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Capacity proto.InternalMessageInfo
+
+func (m *Capacity) GetIdleNodes() int64 {
+	if m != nil {
+		return m.IdleNodes
+	}
+	return 0
+}
+
+func (m *Capacity) GetAllocNodes() int64 {
+	if m != nil {
+		return m.AllocNodes
+	}
+	return 0
+}
+
+func (m *Capacity) GetIdleCpus() int64 {
+	if m != nil {
+		return m.IdleCpus
+	}
+	return 0
+}
+
+func (m *Capacity) GetAllocCpus() int64 {
+	if m != nil {
+		return m.AllocCpus
+	}
+	return 0
+}
+
+func (m *Capacity) GetIdleMem() int64 {
+	if m != nil {
+		return m.IdleMem
+	}
+	return 0
+}
+
+func (m *Capacity) GetAllocMem() int64 {
+	if m != nil {
+		return m.AllocMem
+	}
+	return 0
+}
+
+func (m *Capacity) GetIdleGpus() int64 {
+	if m != nil {
+		return m.IdleGpus
+	}
+	return 0
 }
 
-var xxx_messageInfo_Chunk proto.InternalMessageInfo
+func (m *Capacity) GetAllocGpus() int64 {
+	if m != nil {
+		return m.AllocGpus
+	}
+	return 0
+}
 
-func (m *Chunk) GetContent() []byte {
+func (m *Capacity) GetUpdatedAt() *timestamp.Timestamp {
 	if m != nil {
-		return m.Content
+		return m.UpdatedAt
 	}
 	return nil
 }
 
-type Feature struct {
-	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Version              string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Quantity             int64    `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+type NodeClass struct {
+	// Number of nodes in the class.
+	Nodes int64 `protobuf:"varint,1,opt,name=nodes,proto3" json:"nodes,omitempty"`
+	// Number of cpus on each node.
+	CpuPerNode int64 `protobuf:"varint,2,opt,name=cpuPerNode,proto3" json:"cpuPerNode,omitempty"`
+	// Amount of memory on each node.
+	MemPerNode int64 `protobuf:"varint,3,opt,name=memPerNode,proto3" json:"memPerNode,omitempty"`
+	// Generic resources on each node, e.g. GPUs.
+	Gres []*Feature `protobuf:"bytes,4,rep,name=gres,proto3" json:"gres,omitempty"`
+	// Node features, e.g. cpu architecture.
+	Features             []string `protobuf:"bytes,5,rep,name=features,proto3" json:"features,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Feature) Reset()         { *m = Feature{} }
-func (m *Feature) String() string { return proto.CompactTextString(m) }
-func (*Feature) ProtoMessage()    {}
-func (*Feature) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5a3bd06263c8633f, []int{28}
+func (m *NodeClass) Reset()         { *m = NodeClass{} }
+func (m *NodeClass) String() string { return proto.CompactTextString(m) }
+func (*NodeClass) ProtoMessage()    {}
+func (*NodeClass) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5a3bd06263c8633f, []int{48}
 }
 
-func (m *Feature) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Feature.Unmarshal(m, b)
+func (m *NodeClass) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NodeClass.Unmarshal(m, b)
 }
-func (m *Feature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Feature.Marshal(b, m, deterministic)
+func (m *NodeClass) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NodeClass.Marshal(b, m, deterministic)
 }
-func (m *Feature) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Feature.Merge(m, src)
+func (m *NodeClass) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeClass.Merge(m, src)
 }
-func (m *Feature) XXX_Size() int {
-	return xxx_messageInfo_Feature.Size(m)
+func (m *NodeClass) XXX_Size() int {
+	return xxx_messageInfo_NodeClass.Size(m)
 }
-func (m *Feature) XXX_DiscardUnknown() {
-	xxx_messageInfo_Feature.DiscardUnknown(m)
+func (m *NodeClass) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeClass.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Feature proto.InternalMessageInfo
+var xxx_messageInfo_NodeClass proto.InternalMessageInfo
 
-func (m *Feature) GetName() string {
+func (m *NodeClass) GetNodes() int64 {
 	if m != nil {
-		return m.Name
+		return m.Nodes
 	}
-	return ""
+	return 0
 }
 
-func (m *Feature) GetVersion() string {
+func (m *NodeClass) GetCpuPerNode() int64 {
 	if m != nil {
-		return m.Version
+		return m.CpuPerNode
 	}
-	return ""
+	return 0
 }
 
-func (m *Feature) GetQuantity() int64 {
+func (m *NodeClass) GetMemPerNode() int64 {
 	if m != nil {
-		return m.Quantity
+		return m.MemPerNode
 	}
 	return 0
 }
 
+func (m *NodeClass) GetGres() []*Feature {
+	if m != nil {
+		return m.Gres
+	}
+	return nil
+}
+
+func (m *NodeClass) GetFeatures() []string {
+	if m != nil {
+		return m.Features
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("api.TailAction", TailAction_name, TailAction_value)
 	proto.RegisterEnum("api.JobStatus", JobStatus_name, JobStatus_value)
 	proto.RegisterType((*SubmitJobRequest)(nil), "api.SubmitJobRequest")
 	proto.RegisterType((*SubmitJobResponse)(nil), "api.SubmitJobResponse")
+	proto.RegisterType((*ValidateJobResponse)(nil), "api.ValidateJobResponse")
 	proto.RegisterType((*CancelJobRequest)(nil), "api.CancelJobRequest")
 	proto.RegisterType((*CancelJobResponse)(nil), "api.CancelJobResponse")
+	proto.RegisterType((*HoldJobRequest)(nil), "api.HoldJobRequest")
+	proto.RegisterType((*HoldJobResponse)(nil), "api.HoldJobResponse")
+	proto.RegisterType((*ReleaseJobRequest)(nil), "api.ReleaseJobRequest")
+	proto.RegisterType((*ReleaseJobResponse)(nil), "api.ReleaseJobResponse")
+	proto.RegisterType((*SuspendJobRequest)(nil), "api.SuspendJobRequest")
+	proto.RegisterType((*SuspendJobResponse)(nil), "api.SuspendJobResponse")
+	proto.RegisterType((*ResumeJobRequest)(nil), "api.ResumeJobRequest")
+	proto.RegisterType((*ResumeJobResponse)(nil), "api.ResumeJobResponse")
+	proto.RegisterType((*RequeueJobRequest)(nil), "api.RequeueJobRequest")
+	proto.RegisterType((*RequeueJobResponse)(nil), "api.RequeueJobResponse")
 	proto.RegisterType((*JobInfoRequest)(nil), "api.JobInfoRequest")
 	proto.RegisterType((*JobInfoResponse)(nil), "api.JobInfoResponse")
+	proto.RegisterType((*JobsInfoRequest)(nil), "api.JobsInfoRequest")
+	proto.RegisterType((*JobsInfoResponse)(nil), "api.JobsInfoResponse")
 	proto.RegisterType((*JobStepsRequest)(nil), "api.JobStepsRequest")
 	proto.RegisterType((*JobStepsResponse)(nil), "api.JobStepsResponse")
+	proto.RegisterType((*JobScriptRequest)(nil), "api.JobScriptRequest")
+	proto.RegisterType((*JobScriptResponse)(nil), "api.JobScriptResponse")
+	proto.RegisterType((*JobHistoryRequest)(nil), "api.JobHistoryRequest")
+	proto.RegisterType((*JobHistoryResponse)(nil), "api.JobHistoryResponse")
+	proto.RegisterType((*JobRecord)(nil), "api.JobRecord")
 	proto.RegisterType((*OpenFileRequest)(nil), "api.OpenFileRequest")
 	proto.RegisterType((*CreateFileRequest)(nil), "api.CreateFileRequest")
 	proto.RegisterType((*CreateFileResponse)(nil), "api.CreateFileResponse")
 	proto.RegisterType((*ResourcesRequest)(nil), "api.ResourcesRequest")
 	proto.RegisterType((*ResourcesResponse)(nil), "api.ResourcesResponse")
+	proto.RegisterMapType((map[string]string)(nil), "api.ResourcesResponse.TresBillingWeightsEntry")
+	proto.RegisterMapType((map[string]string)(nil), "api.ResourcesResponse.TresEntry")
 	proto.RegisterType((*PartitionsRequest)(nil), "api.PartitionsRequest")
 	proto.RegisterType((*PartitionsResponse)(nil), "api.PartitionsResponse")
 	proto.RegisterType((*WorkloadInfoRequest)(nil), "api.WorkloadInfoRequest")
@@ -1658,108 +2935,174 @@ func init() {
 	proto.RegisterType((*UnzipRequest)(nil), "api.UnzipRequest")
 	proto.RegisterType((*UnzipResponse)(nil), "api.UnzipResponse")
 	proto.RegisterType((*JobInfo)(nil), "api.JobInfo")
+	proto.RegisterMapType((map[string]string)(nil), "api.JobInfo.TresEntry")
 	proto.RegisterType((*JobStepInfo)(nil), "api.JobStepInfo")
 	proto.RegisterType((*Chunk)(nil), "api.Chunk")
 	proto.RegisterType((*Feature)(nil), "api.Feature")
+	proto.RegisterType((*Capacity)(nil), "api.Capacity")
+	proto.RegisterType((*NodeClass)(nil), "api.NodeClass")
 }
 
 func init() { proto.RegisterFile("pkg/workload/api/workload.proto", fileDescriptor_5a3bd06263c8633f) }
 
 var fileDescriptor_5a3bd06263c8633f = []byte{
-	// 1475 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x56, 0xeb, 0x6e, 0xdb, 0xc6,
-	0x12, 0x8e, 0xee, 0xe4, 0xc8, 0x17, 0x7a, 0x63, 0x27, 0xb4, 0x72, 0x4e, 0xec, 0x43, 0x9c, 0x73,
-	0xa2, 0x13, 0xe0, 0xc8, 0xae, 0x93, 0xa2, 0x69, 0x8a, 0xa2, 0x30, 0x64, 0xa5, 0x55, 0xea, 0xc8,
-	0x06, 0x6d, 0x37, 0x40, 0x50, 0x40, 0x58, 0x89, 0x6b, 0x79, 0x63, 0x6a, 0xc9, 0x2c, 0x97, 0x49,
-	0xd3, 0x67, 0xe8, 0x8f, 0xfe, 0xed, 0x53, 0xf4, 0x75, 0xfa, 0x08, 0x7d, 0x8c, 0x62, 0x97, 0xcb,
-	0x8b, 0x24, 0x5f, 0x9a, 0x7f, 0x3b, 0xdf, 0xcc, 0xec, 0x65, 0x66, 0x76, 0xe6, 0x83, 0xad, 0xf0,
-	0x72, 0xb2, 0xf3, 0x21, 0xe0, 0x97, 0x7e, 0x80, 0xbd, 0x1d, 0x1c, 0xd2, 0x4c, 0xe8, 0x84, 0x3c,
-	0x10, 0x01, 0xaa, 0xe0, 0x90, 0xb6, 0xb6, 0x26, 0x41, 0x30, 0xf1, 0xc9, 0x8e, 0x82, 0x46, 0xf1,
-	0xf9, 0x8e, 0xa0, 0x53, 0x12, 0x09, 0x3c, 0x0d, 0x13, 0xab, 0xd6, 0xc3, 0x79, 0x03, 0x2f, 0xe6,
-	0x58, 0xd0, 0x80, 0x25, 0x7a, 0x87, 0x80, 0x75, 0x12, 0x8f, 0xa6, 0x54, 0xbc, 0x0c, 0x46, 0x2e,
-	0x79, 0x17, 0x93, 0x48, 0xa0, 0x7b, 0x50, 0x8f, 0xc6, 0x9c, 0x86, 0xc2, 0x2e, 0x6d, 0x97, 0xda,
-	0xa6, 0xab, 0x25, 0xf4, 0x0f, 0x30, 0x43, 0xcc, 0x05, 0x95, 0xee, 0x76, 0x59, 0xa9, 0x72, 0x00,
-	0x3d, 0x00, 0x73, 0xec, 0x53, 0xc2, 0xc4, 0x90, 0x7a, 0x76, 0x45, 0x69, 0x8d, 0x04, 0xe8, 0x7b,
-	0xce, 0x63, 0x58, 0x2b, 0x1c, 0x13, 0x85, 0x01, 0x8b, 0x08, 0xda, 0x80, 0xfa, 0xdb, 0x60, 0x24,
-	0xcd, 0xe5, 0x39, 0x15, 0xb7, 0xf6, 0x36, 0x18, 0xf5, 0x3d, 0xe7, 0x7f, 0x60, 0x75, 0x31, 0x1b,
-	0x13, 0xbf, 0x70, 0xa5, 0x6b, 0x4c, 0xef, 0xc2, 0x5a, 0xc1, 0x34, 0xd9, 0xd6, 0x79, 0x04, 0x2b,
-	0x2f, 0x83, 0x51, 0x9f, 0x9d, 0x07, 0xb7, 0x78, 0x3f, 0x81, 0xd5, 0xcc, 0x50, 0x5f, 0x69, 0x1b,
-	0xaa, 0x94, 0x9d, 0x07, 0x76, 0x69, 0xbb, 0xd2, 0x6e, 0xee, 0x2d, 0x75, 0x70, 0x48, 0x3b, 0xa9,
-	0x8d, 0xd2, 0x38, 0x6d, 0xe5, 0x74, 0x22, 0x48, 0x18, 0xdd, 0xb2, 0xfd, 0x3e, 0x58, 0xb9, 0xa5,
-	0xde, 0xff, 0xff, 0x60, 0x4a, 0xd3, 0x48, 0x82, 0xfa, 0x10, 0x2b, 0x3d, 0x44, 0x5a, 0xaa, 0x83,
-	0x8c, 0xb7, 0xda, 0xcd, 0xf9, 0x0f, 0xac, 0x1e, 0x85, 0x84, 0xbd, 0xa0, 0x3e, 0x49, 0x0f, 0x43,
-	0x50, 0x0d, 0xb1, 0xb8, 0xd0, 0xa9, 0x51, 0x6b, 0x67, 0x1f, 0xd6, 0xba, 0x9c, 0x60, 0x41, 0x6e,
-	0x31, 0x44, 0x36, 0x34, 0xc6, 0x01, 0x13, 0x84, 0x09, 0x95, 0xbf, 0x25, 0x37, 0x15, 0x9d, 0x75,
-	0x40, 0xc5, 0x2d, 0x74, 0x28, 0x77, 0xc1, 0x72, 0x49, 0x14, 0xc4, 0x7c, 0x4c, 0xb2, 0xd7, 0xce,
-	0x54, 0x41, 0x69, 0xae, 0x0a, 0x9c, 0xdf, 0x4b, 0xb0, 0x56, 0x70, 0xd1, 0xcf, 0x5e, 0x87, 0x1a,
-	0x0b, 0x3c, 0x12, 0xa5, 0x01, 0x52, 0x02, 0x7a, 0x08, 0x30, 0x0e, 0xe3, 0x63, 0xc2, 0x07, 0x81,
-	0x47, 0xd4, 0x85, 0x2a, 0x6e, 0x01, 0x91, 0xfa, 0x29, 0x99, 0xa6, 0xfa, 0x4a, 0xa2, 0xcf, 0x11,
-	0xd4, 0x02, 0xe3, 0x03, 0xf6, 0xfd, 0x53, 0x3a, 0x25, 0x76, 0x55, 0x69, 0x33, 0x19, 0xb5, 0xc1,
-	0x38, 0x27, 0x58, 0xc4, 0x9c, 0x44, 0x76, 0xad, 0x90, 0xcc, 0x17, 0x09, 0xe8, 0x66, 0x5a, 0x59,
-	0x43, 0xc7, 0xe9, 0xf5, 0xd3, 0x47, 0x3a, 0x7b, 0x80, 0x8a, 0xa0, 0x7e, 0xc6, 0xdc, 0xd3, 0x2b,
-	0xb3, 0x4f, 0xdf, 0x80, 0xbb, 0xaf, 0xf5, 0x17, 0x2d, 0x14, 0x9f, 0xf3, 0x03, 0xac, 0xcf, 0xc2,
-	0x7a, 0x33, 0x04, 0x55, 0x86, 0xa7, 0x24, 0xcd, 0x8f, 0x5c, 0xcb, 0xfc, 0xbc, 0x27, 0x3c, 0xca,
-	0xff, 0x57, 0x2a, 0x22, 0x0b, 0x2a, 0xb1, 0xfe, 0x57, 0x15, 0x57, 0x2e, 0x9d, 0xdf, 0xca, 0xb0,
-	0x99, 0xfd, 0xa9, 0x6e, 0xc0, 0x04, 0xa6, 0x8c, 0xf0, 0x42, 0x96, 0xe8, 0x14, 0x4f, 0xc8, 0x20,
-	0x3f, 0x22, 0x07, 0xf2, 0x7c, 0x94, 0xaf, 0xcf, 0x47, 0xe5, 0x96, 0x7c, 0x54, 0x6f, 0xcc, 0x47,
-	0x6d, 0x2e, 0x1f, 0x33, 0xa1, 0xab, 0xdf, 0xd8, 0x3b, 0x1a, 0xb3, 0xbd, 0x03, 0x7d, 0x06, 0x8d,
-	0x20, 0x54, 0x89, 0xb0, 0x8d, 0xed, 0x52, 0xbb, 0xb9, 0x77, 0x5f, 0x65, 0xf2, 0x84, 0xb2, 0x49,
-	0xec, 0x63, 0x4e, 0xc5, 0xc7, 0xa3, 0x44, 0xed, 0xa6, 0x76, 0xce, 0xaf, 0x65, 0x40, 0x8b, 0x7a,
-	0x19, 0x44, 0x1c, 0x86, 0x3a, 0x1c, 0x72, 0x89, 0xfe, 0x0d, 0xcb, 0xd8, 0xf7, 0x83, 0x0f, 0x67,
-	0x2c, 0xa2, 0x13, 0x46, 0x3c, 0x15, 0x10, 0xc3, 0x9d, 0x05, 0x65, 0xb8, 0x46, 0x94, 0x79, 0x91,
-	0x5d, 0x51, 0x39, 0x4f, 0x04, 0xf9, 0xdc, 0xb1, 0x4f, 0x30, 0xef, 0xb1, 0xf7, 0x2a, 0x18, 0x86,
-	0x9b, 0xc9, 0x52, 0x77, 0x8e, 0x2f, 0x89, 0x1b, 0x04, 0x42, 0x85, 0xc2, 0x70, 0x33, 0x59, 0xea,
-	0x2e, 0x82, 0x48, 0xa8, 0xcc, 0x24, 0x91, 0xc8, 0x64, 0x79, 0x43, 0x1a, 0x8e, 0x55, 0x08, 0x0c,
-	0x57, 0x2e, 0x25, 0x12, 0x52, 0x4f, 0xbd, 0xdc, 0x70, 0xe5, 0x52, 0x16, 0x09, 0x0b, 0x8e, 0x39,
-	0x7d, 0x1f, 0xd9, 0xa6, 0x42, 0x53, 0x51, 0x25, 0x80, 0x53, 0x81, 0x47, 0x3e, 0xb1, 0x21, 0x39,
-	0x35, 0x95, 0x9d, 0x27, 0xd0, 0xba, 0xaa, 0x5a, 0x6e, 0x6e, 0xc5, 0x03, 0x58, 0x3d, 0xc5, 0xd4,
-	0x2f, 0xb6, 0x95, 0x47, 0x50, 0xc7, 0xe3, 0xec, 0xef, 0xaf, 0xec, 0xad, 0xaa, 0x64, 0x48, 0xab,
-	0x7d, 0x05, 0xbb, 0x5a, 0x9d, 0xf5, 0x9f, 0x72, 0xa1, 0x51, 0x3d, 0x03, 0x78, 0x43, 0xc3, 0x9b,
-	0x3a, 0xd4, 0x3d, 0xa8, 0x0b, 0xcc, 0x27, 0x44, 0x68, 0x3f, 0x2d, 0x39, 0xcb, 0xd0, 0x54, 0x9e,
-	0xba, 0x31, 0x3d, 0x87, 0xa5, 0x33, 0xf6, 0x73, 0xbe, 0x95, 0x1c, 0x59, 0xaa, 0xe7, 0x64, 0x23,
-	0x4b, 0x49, 0x57, 0x5e, 0x62, 0x15, 0x96, 0xb5, 0xaf, 0xde, 0xec, 0x8f, 0x2a, 0x34, 0x74, 0x93,
-	0x47, 0x2b, 0x50, 0xd6, 0x41, 0x30, 0xdd, 0x32, 0xf5, 0xd0, 0x7d, 0x68, 0xc4, 0x11, 0xe1, 0x32,
-	0x32, 0xfa, 0x42, 0x52, 0xec, 0x7b, 0xd9, 0xf7, 0xad, 0x14, 0xbe, 0xef, 0x03, 0x30, 0xc9, 0x4f,
-	0x54, 0x0c, 0xc7, 0xe9, 0xff, 0x30, 0x5d, 0x43, 0x02, 0x5d, 0xf9, 0x3b, 0xfe, 0x0b, 0xf5, 0x48,
-	0x60, 0x11, 0x47, 0xaa, 0x20, 0x56, 0xf6, 0x56, 0xf2, 0xbe, 0x2f, 0x51, 0x57, 0x6b, 0xd1, 0x57,
-	0xd0, 0x8c, 0x54, 0xa2, 0x86, 0x72, 0x96, 0xab, 0x0a, 0x69, 0xee, 0xb5, 0x3a, 0xc9, 0x1c, 0xef,
-	0xa4, 0x73, 0xbc, 0x73, 0x9a, 0x0e, 0x7a, 0x17, 0x12, 0x73, 0xf5, 0xcd, 0xbe, 0x04, 0x88, 0x04,
-	0xe6, 0xda, 0xb7, 0x71, 0xab, 0xaf, 0xa9, 0xac, 0x95, 0xeb, 0x53, 0x30, 0x78, 0xcc, 0x12, 0xc7,
-	0xe4, 0x9f, 0x6d, 0x2e, 0x38, 0x1e, 0x68, 0xf2, 0xe0, 0x36, 0x78, 0xcc, 0x94, 0xd7, 0x33, 0x00,
-	0xe9, 0x31, 0xf4, 0xe9, 0x94, 0x0a, 0x55, 0x8f, 0x37, 0xfa, 0x99, 0xd2, 0xf8, 0x50, 0xda, 0xa2,
-	0x2d, 0x68, 0x4a, 0x46, 0x43, 0xd9, 0x64, 0xe8, 0x51, 0xae, 0xea, 0xd5, 0x74, 0x41, 0x43, 0x07,
-	0x94, 0xcb, 0xd0, 0x47, 0xc2, 0x1b, 0x06, 0xb1, 0xb0, 0x9b, 0x3a, 0xa9, 0xc2, 0x3b, 0x8a, 0x45,
-	0xaa, 0x20, 0x9c, 0xdb, 0x4b, 0x99, 0xa2, 0xc7, 0xf9, 0x6c, 0x93, 0x59, 0xbe, 0xa2, 0xc9, 0xc8,
-	0x3e, 0x37, 0xf4, 0x69, 0x24, 0xec, 0x95, 0x24, 0x3b, 0x12, 0x38, 0xa4, 0x91, 0x40, 0xff, 0x04,
-	0x18, 0x61, 0x31, 0xbe, 0x18, 0xca, 0xaf, 0x68, 0xaf, 0x26, 0xbe, 0x0a, 0xf9, 0x2e, 0x88, 0x84,
-	0xf2, 0x8d, 0xa7, 0xc3, 0xa4, 0x69, 0x5a, 0xda, 0x37, 0x9e, 0x0e, 0x54, 0xdf, 0xdc, 0x04, 0x03,
-	0x73, 0x8e, 0x3f, 0xca, 0x22, 0x59, 0x4b, 0xda, 0xb6, 0x92, 0xfb, 0x9e, 0xf3, 0x67, 0x09, 0x9a,
-	0x85, 0xd1, 0xbe, 0x50, 0x5e, 0x69, 0x15, 0x95, 0xaf, 0xab, 0x22, 0x59, 0x5e, 0xb5, 0x2b, 0xab,
-	0xa8, 0x7a, 0x63, 0x15, 0xcd, 0x16, 0x42, 0xed, 0x53, 0x0a, 0xe1, 0x73, 0x30, 0x08, 0xf3, 0xfe,
-	0x6e, 0xf5, 0x35, 0x08, 0xf3, 0xa4, 0xe4, 0xfc, 0x0b, 0x6a, 0xdd, 0x8b, 0x98, 0x5d, 0x16, 0x49,
-	0x46, 0x69, 0x96, 0x64, 0x9c, 0x40, 0x43, 0xcf, 0xdf, 0x4f, 0x9c, 0x7e, 0x2d, 0x30, 0xde, 0xc5,
-	0x98, 0x09, 0x2a, 0x3e, 0xea, 0xb9, 0x94, 0xc9, 0x8f, 0x3b, 0x00, 0x79, 0xf7, 0x41, 0x26, 0xd4,
-	0x4e, 0xe4, 0x4b, 0xac, 0x3b, 0x68, 0x43, 0x32, 0x11, 0xec, 0x9d, 0x06, 0x3d, 0xe6, 0xed, 0x33,
-	0xaf, 0xeb, 0x07, 0x11, 0xb1, 0x4a, 0x8f, 0x7f, 0x04, 0x33, 0x0b, 0x17, 0x5a, 0x06, 0xb3, 0x7b,
-	0xf4, 0xea, 0xf8, 0xb0, 0x77, 0xda, 0x3b, 0xb0, 0xee, 0x28, 0x71, 0x7f, 0xd0, 0xed, 0x1d, 0x1e,
-	0xf6, 0x0e, 0xac, 0x12, 0x02, 0xa8, 0xbf, 0xd8, 0xef, 0xcb, 0x75, 0x19, 0x35, 0xa1, 0x71, 0xda,
-	0x7f, 0xd5, 0x3b, 0x3a, 0x3b, 0xb5, 0x2a, 0x52, 0x38, 0xee, 0x0d, 0x0e, 0xfa, 0x83, 0x6f, 0xad,
-	0xaa, 0x14, 0xce, 0x06, 0xdf, 0x0f, 0x8e, 0x5e, 0x0f, 0x2c, 0xd8, 0xfb, 0xa5, 0x0e, 0xab, 0xe9,
-	0xb8, 0x7f, 0x85, 0x19, 0x9e, 0x10, 0x8e, 0x9e, 0x83, 0x99, 0xb5, 0x5e, 0xb4, 0x91, 0x0c, 0xaf,
-	0x39, 0xce, 0xdd, 0xba, 0x37, 0x0f, 0xeb, 0xc6, 0x7c, 0x06, 0x68, 0xb1, 0x6d, 0xa3, 0x87, 0xb3,
-	0xd6, 0xf3, 0xd3, 0xbf, 0xb5, 0x75, 0xad, 0x5e, 0x6f, 0xfb, 0x1c, 0xcc, 0x8c, 0x38, 0xeb, 0x2b,
-	0xcd, 0x73, 0x6e, 0x7d, 0xa5, 0x05, 0x7e, 0x8d, 0x9e, 0xe6, 0xdd, 0xf2, 0xee, 0x0c, 0x41, 0xd6,
-	0x7e, 0xeb, 0xb3, 0xa0, 0xf6, 0xfa, 0x02, 0x8c, 0x94, 0x0d, 0xa3, 0xf5, 0x22, 0xe5, 0x4d, 0x39,
-	0x57, 0x6b, 0x63, 0x0e, 0xd5, 0x8e, 0x1d, 0x30, 0x52, 0x0e, 0xac, 0x1d, 0xe7, 0x28, 0x71, 0x0b,
-	0x92, 0x8b, 0xca, 0xe2, 0xdb, 0x2d, 0xa1, 0x5d, 0x30, 0xd2, 0x99, 0xa5, 0xed, 0xe7, 0x46, 0x58,
-	0xd1, 0xbe, 0x5d, 0xda, 0x2d, 0xa1, 0x6f, 0x00, 0x72, 0xee, 0x8b, 0xf4, 0xb3, 0xe7, 0xf9, 0x74,
-	0xeb, 0xfe, 0x02, 0x9e, 0x5c, 0xb0, 0x5d, 0x42, 0x6d, 0xa8, 0xbc, 0xa1, 0x21, 0x4a, 0x46, 0x61,
-	0x3e, 0xe0, 0x5a, 0x56, 0x0e, 0x64, 0x8f, 0xa9, 0xa9, 0xd9, 0x83, 0xd6, 0x94, 0xaa, 0x38, 0xc3,
-	0x5a, 0xa8, 0x08, 0xe5, 0x79, 0xca, 0xd8, 0xb4, 0xce, 0xd3, 0x3c, 0x21, 0xd7, 0x79, 0x5a, 0x24,
-	0xdd, 0x5f, 0x03, 0xe4, 0x1c, 0x56, 0x3f, 0x6b, 0x81, 0xe9, 0xea, 0x67, 0x5d, 0x41, 0x76, 0xbb,
-	0xb0, 0x54, 0xe4, 0xad, 0xc8, 0x56, 0x86, 0x57, 0x30, 0xdc, 0xd6, 0xe6, 0x15, 0x9a, 0x64, 0x93,
-	0x51, 0x5d, 0x75, 0x8c, 0x27, 0x7f, 0x05, 0x00, 0x00, 0xff, 0xff, 0x90, 0xef, 0x68, 0xe8, 0xce,
-	0x0e, 0x00, 0x00,
+	// 2493 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x18, 0xdb, 0x6e, 0xdb, 0xc8,
+	0x75, 0x75, 0xb1, 0x44, 0x1d, 0xf9, 0x22, 0x8f, 0x6f, 0x0c, 0xb7, 0xbb, 0x71, 0xd5, 0x76, 0xd7,
+	0x6b, 0xa0, 0x4e, 0xea, 0xdd, 0x62, 0xb3, 0xe9, 0x0d, 0x5e, 0x45, 0x49, 0xbc, 0xeb, 0xc8, 0x06,
+	0xed, 0x34, 0xc0, 0xa2, 0xa8, 0x3a, 0x12, 0xc7, 0xf2, 0xc4, 0x14, 0xc9, 0x0c, 0x87, 0xc9, 0x3a,
+	0x1f, 0x51, 0xf4, 0x0f, 0x0a, 0xf4, 0xbd, 0xe8, 0x3f, 0xf4, 0x57, 0xfa, 0xd2, 0x7e, 0x41, 0x81,
+	0x3e, 0x15, 0x73, 0x21, 0x39, 0xa4, 0x6c, 0x2b, 0x29, 0xd0, 0x27, 0xe9, 0x5c, 0x87, 0x73, 0xe6,
+	0xdc, 0xe1, 0x6e, 0x74, 0x39, 0xb9, 0xf7, 0x26, 0x64, 0x97, 0x7e, 0x88, 0xbd, 0x7b, 0x38, 0xa2,
+	0x19, 0xb0, 0x17, 0xb1, 0x90, 0x87, 0xa8, 0x86, 0x23, 0xea, 0xdc, 0x9d, 0x84, 0xe1, 0xc4, 0x27,
+	0xf7, 0x24, 0x6a, 0x94, 0x9c, 0xdf, 0xe3, 0x74, 0x4a, 0x62, 0x8e, 0xa7, 0x91, 0xe2, 0x72, 0x3e,
+	0x2e, 0x33, 0x78, 0x09, 0xc3, 0x9c, 0x86, 0x81, 0xa2, 0x77, 0xff, 0x58, 0x81, 0xce, 0x69, 0x32,
+	0x9a, 0x52, 0xfe, 0x4d, 0x38, 0x72, 0xc9, 0xab, 0x84, 0xc4, 0x1c, 0x6d, 0x42, 0x23, 0x1e, 0x33,
+	0x1a, 0x71, 0xbb, 0xb2, 0x5d, 0xd9, 0x69, 0xb9, 0x1a, 0x42, 0x3f, 0x80, 0x56, 0x84, 0x19, 0xa7,
+	0x42, 0xde, 0xae, 0x4a, 0x52, 0x8e, 0x40, 0x1f, 0x42, 0x6b, 0xec, 0x53, 0x12, 0xf0, 0x21, 0xf5,
+	0xec, 0x9a, 0xa4, 0x5a, 0x0a, 0x71, 0xe8, 0xa1, 0x4f, 0x61, 0x85, 0x7a, 0x64, 0x1a, 0x85, 0x9c,
+	0x04, 0xe3, 0xab, 0xe1, 0x25, 0xb9, 0xb2, 0xeb, 0x92, 0x65, 0xd9, 0x40, 0x7f, 0x4b, 0xae, 0xba,
+	0xbb, 0xb0, 0x6a, 0x7c, 0x4f, 0x1c, 0x85, 0x41, 0x4c, 0xd0, 0x06, 0x34, 0x5e, 0x86, 0x23, 0xa1,
+	0x57, 0x7c, 0x50, 0xcd, 0x5d, 0x78, 0x19, 0x8e, 0x0e, 0xbd, 0xee, 0xbf, 0x2a, 0xb0, 0xf6, 0x5b,
+	0xec, 0x53, 0x0f, 0x73, 0x62, 0xb2, 0xaf, 0xc3, 0xc2, 0x6b, 0xec, 0x6b, 0x6e, 0xcb, 0x55, 0x80,
+	0xb8, 0x15, 0x61, 0x2c, 0x64, 0xb1, 0x5d, 0xdd, 0xae, 0x89, 0x5b, 0x29, 0x08, 0x39, 0x60, 0xbd,
+	0xc1, 0x2c, 0xa0, 0xc1, 0x24, 0xb6, 0x6b, 0x92, 0x92, 0xc1, 0xe8, 0x2b, 0x80, 0x98, 0x63, 0xc6,
+	0x87, 0xc2, 0xae, 0xf2, 0x8b, 0xdb, 0xfb, 0xce, 0x9e, 0xb2, 0xe9, 0x5e, 0x6a, 0xd3, 0xbd, 0xb3,
+	0xd4, 0xe8, 0x6e, 0x4b, 0x72, 0x0b, 0x18, 0x21, 0xa8, 0x8f, 0xa3, 0x24, 0xb6, 0x17, 0xe4, 0x17,
+	0xcb, 0xff, 0xc2, 0x44, 0x41, 0xe8, 0x91, 0xa1, 0x4f, 0x63, 0x6e, 0x37, 0x94, 0x89, 0x04, 0xe2,
+	0x88, 0xc6, 0x25, 0xeb, 0x36, 0x4b, 0xd6, 0xed, 0x7e, 0x06, 0x9d, 0x1e, 0x0e, 0xc6, 0xc4, 0x37,
+	0xde, 0xe9, 0x06, 0xb3, 0xac, 0xc1, 0xaa, 0xc1, 0xaa, 0x6c, 0xd2, 0xfd, 0x14, 0x96, 0x9f, 0x86,
+	0xbe, 0x37, 0x5f, 0x7a, 0x15, 0x56, 0x32, 0x46, 0x2d, 0xbb, 0x0b, 0xab, 0x2e, 0xf1, 0x09, 0x8e,
+	0xc9, 0x7c, 0xf1, 0x75, 0x40, 0x26, 0x6f, 0xae, 0xe1, 0x34, 0x89, 0x23, 0x12, 0x78, 0xef, 0xa4,
+	0xc1, 0xe4, 0xd5, 0x1a, 0x3e, 0x83, 0x8e, 0x4b, 0xe2, 0x64, 0x4a, 0xde, 0xe9, 0xfe, 0x06, 0xab,
+	0x96, 0x1f, 0x09, 0xe4, 0xab, 0x84, 0x24, 0xf3, 0x15, 0x88, 0xa7, 0xbb, 0x08, 0x7d, 0x4f, 0xba,
+	0xb8, 0xe5, 0xca, 0xff, 0xa8, 0x0b, 0x4b, 0x98, 0x31, 0x7c, 0x35, 0xe4, 0x38, 0xbe, 0xcc, 0x3d,
+	0xbc, 0x2d, 0x91, 0x67, 0x38, 0xbe, 0x4c, 0xef, 0x9e, 0x9f, 0x91, 0x5b, 0xfe, 0x9b, 0x70, 0x74,
+	0x18, 0x9c, 0x87, 0x73, 0xbe, 0xfb, 0x73, 0x58, 0xc9, 0x18, 0xb5, 0x27, 0x6f, 0x43, 0x9d, 0x06,
+	0xe7, 0xa1, 0x5d, 0xd9, 0xae, 0xed, 0xb4, 0xf7, 0x17, 0xf7, 0x70, 0x44, 0xf7, 0x52, 0x1e, 0x49,
+	0xe9, 0xee, 0x4a, 0xa1, 0xd8, 0x54, 0xbf, 0x05, 0x4d, 0xa5, 0x3e, 0x96, 0x72, 0x35, 0xb7, 0x21,
+	0xf5, 0xc7, 0xdd, 0x5f, 0x42, 0x27, 0xe7, 0xd5, 0x27, 0xec, 0x40, 0xfd, 0x65, 0x38, 0x8a, 0xf5,
+	0x09, 0xeb, 0x85, 0x13, 0x34, 0x8f, 0x2b, 0x39, 0xba, 0x3b, 0xf2, 0xa4, 0x53, 0x4e, 0xa2, 0x78,
+	0xce, 0x45, 0x0e, 0xa0, 0x93, 0x73, 0xea, 0x73, 0x7e, 0x0a, 0x2d, 0xc1, 0x1a, 0x0b, 0xa4, 0x3e,
+	0xac, 0x93, 0x1e, 0x26, 0x38, 0xe5, 0x81, 0xd6, 0x4b, 0x2d, 0x26, 0x9e, 0x5b, 0x10, 0x64, 0xde,
+	0x99, 0x73, 0xda, 0x33, 0x58, 0x35, 0x58, 0xf5, 0x71, 0x37, 0xa5, 0xb0, 0x6d, 0x68, 0x93, 0xe0,
+	0x35, 0x65, 0x61, 0x30, 0x25, 0x01, 0xd7, 0x99, 0xc0, 0x44, 0x75, 0xff, 0x51, 0x95, 0xfa, 0x9e,
+	0xd2, 0x98, 0x87, 0xec, 0x2a, 0x3d, 0xbb, 0x90, 0xdc, 0x2a, 0xa5, 0xe4, 0x76, 0x7b, 0x5e, 0xfc,
+	0x04, 0x1a, 0x31, 0xc7, 0x3c, 0x89, 0xa5, 0xcb, 0x2c, 0xef, 0x2f, 0xe7, 0xd7, 0x16, 0x58, 0x57,
+	0x53, 0xd1, 0x8f, 0x60, 0xe9, 0x9c, 0xfa, 0x9c, 0xb0, 0xa1, 0x66, 0xaf, 0x4b, 0xf7, 0x5b, 0x54,
+	0x48, 0xc5, 0x8c, 0x7a, 0xb0, 0x12, 0xcb, 0xf4, 0xc8, 0x89, 0x37, 0xc4, 0xe7, 0x9c, 0x30, 0x7b,
+	0x61, 0x6e, 0x56, 0x5a, 0xce, 0x44, 0x0e, 0x84, 0x04, 0xea, 0x43, 0x27, 0x57, 0x32, 0x22, 0xe7,
+	0x21, 0x23, 0x76, 0x63, 0xae, 0x96, 0xfc, 0xe0, 0xaf, 0xa5, 0x88, 0xb0, 0x49, 0x84, 0x27, 0x64,
+	0x18, 0xd3, 0xb7, 0x44, 0x26, 0xac, 0x05, 0xd7, 0x12, 0x88, 0x53, 0xfa, 0x96, 0xa0, 0x8f, 0x00,
+	0x24, 0x91, 0x87, 0x97, 0x24, 0xb0, 0xad, 0xd4, 0x28, 0x13, 0x72, 0x26, 0x10, 0xdd, 0x3f, 0x00,
+	0x32, 0x8d, 0xac, 0x5f, 0xad, 0x5b, 0x70, 0xc6, 0xcc, 0x50, 0x2e, 0x19, 0x87, 0xcc, 0x53, 0x6e,
+	0x88, 0x3e, 0x81, 0x95, 0x80, 0x7c, 0xcf, 0x87, 0x86, 0x76, 0x65, 0xf2, 0x25, 0x81, 0x3e, 0xc9,
+	0x4e, 0xf8, 0x4f, 0x15, 0x5a, 0x99, 0xec, 0x4d, 0x91, 0x5e, 0x78, 0xd6, 0xea, 0xfc, 0x9a, 0x55,
+	0xbb, 0xae, 0x66, 0x15, 0xdf, 0xbf, 0x5e, 0x7e, 0x7f, 0x1b, 0x9a, 0x4c, 0x79, 0x91, 0x7c, 0xaa,
+	0x96, 0x9b, 0x82, 0xe8, 0x2e, 0xb4, 0x95, 0x5b, 0x0e, 0x2f, 0x70, 0x7c, 0xa1, 0x0b, 0x02, 0x28,
+	0xd4, 0x53, 0x1c, 0x5f, 0xa0, 0x5f, 0x40, 0x5b, 0x19, 0x5d, 0xd5, 0x9f, 0xe6, 0xdc, 0x37, 0x02,
+	0xc5, 0x2e, 0x10, 0xe8, 0xe7, 0x60, 0x91, 0xc0, 0x53, 0x92, 0xd6, 0x5c, 0xc9, 0x26, 0x09, 0x3c,
+	0x29, 0x96, 0xbb, 0x6b, 0xeb, 0x56, 0x77, 0xfd, 0x10, 0x5a, 0xe4, 0x7b, 0xca, 0x87, 0xe3, 0xd0,
+	0x23, 0x36, 0x28, 0xd3, 0x09, 0x44, 0x2f, 0xf4, 0x48, 0xf7, 0x27, 0xb0, 0x72, 0x1c, 0x91, 0xe0,
+	0x31, 0xf5, 0x49, 0x1a, 0x41, 0x08, 0xea, 0x11, 0xe6, 0x17, 0x3a, 0x78, 0xe4, 0xff, 0xee, 0x01,
+	0xac, 0xf6, 0x18, 0xc1, 0x9c, 0xcc, 0x61, 0x14, 0x36, 0x1c, 0x87, 0x01, 0x57, 0x21, 0x5b, 0xd9,
+	0x59, 0x74, 0x53, 0x50, 0xe4, 0x5c, 0x53, 0x85, 0xce, 0xb9, 0xf7, 0x65, 0xb5, 0x08, 0x13, 0x36,
+	0x26, 0x59, 0xb2, 0x2a, 0xbc, 0x52, 0xa5, 0x5c, 0x5f, 0xff, 0x52, 0x87, 0x55, 0x43, 0x24, 0xef,
+	0x24, 0x44, 0x7d, 0x8e, 0x53, 0xaf, 0x91, 0x00, 0xfa, 0x18, 0x60, 0x1c, 0x25, 0x27, 0x84, 0x0d,
+	0xc4, 0xdd, 0xab, 0x92, 0x64, 0x60, 0x04, 0x7d, 0x4a, 0xa6, 0x29, 0xbd, 0xa6, 0xe8, 0x39, 0x46,
+	0x75, 0x1c, 0xbe, 0x7f, 0x96, 0xf6, 0x14, 0x35, 0x37, 0x83, 0xd1, 0x0e, 0x58, 0xe7, 0x04, 0xf3,
+	0x84, 0x11, 0xd1, 0x3a, 0xe4, 0x59, 0xff, 0xb1, 0x42, 0xba, 0x19, 0x15, 0xdd, 0x87, 0xb6, 0xf8,
+	0x9c, 0x9e, 0x8f, 0xe3, 0x98, 0xc4, 0x76, 0xc3, 0x88, 0x99, 0x41, 0x8a, 0x77, 0x4d, 0x16, 0xf4,
+	0x19, 0x58, 0x63, 0x1c, 0xe1, 0x31, 0xe5, 0x57, 0xda, 0x97, 0x96, 0x24, 0x7b, 0x4f, 0x23, 0xdd,
+	0x8c, 0x8c, 0xbe, 0x80, 0x3a, 0x17, 0x9f, 0x60, 0x49, 0xad, 0xdb, 0x92, 0x6d, 0xc6, 0x3c, 0x7b,
+	0x67, 0x8c, 0xc4, 0xfd, 0x80, 0xb3, 0x2b, 0x57, 0x72, 0xa3, 0xdf, 0x03, 0x12, 0xbf, 0x5f, 0x53,
+	0xdf, 0xa7, 0xc1, 0xe4, 0x05, 0xa1, 0x93, 0x0b, 0x2e, 0xfc, 0x48, 0xe8, 0xd8, 0xbb, 0x45, 0x47,
+	0x51, 0x40, 0x69, 0xbc, 0x46, 0x93, 0xf3, 0x25, 0xb4, 0xb2, 0x23, 0x51, 0x07, 0x6a, 0x22, 0x24,
+	0xd5, 0x4b, 0x8a, 0xbf, 0xba, 0xef, 0x4b, 0x88, 0x8e, 0x64, 0x05, 0x3c, 0xac, 0x3e, 0xa8, 0x38,
+	0x7d, 0xd8, 0xba, 0xe1, 0x9c, 0xf7, 0x51, 0x23, 0x3a, 0x8b, 0x93, 0xd4, 0x63, 0x52, 0xbf, 0xea,
+	0xee, 0x03, 0x32, 0x91, 0xda, 0x73, 0x4a, 0xde, 0x56, 0x2b, 0x7a, 0xdb, 0x06, 0xac, 0xbd, 0xd0,
+	0xed, 0xbc, 0x51, 0xb9, 0xbb, 0x0c, 0xd6, 0x8b, 0x68, 0xad, 0x0c, 0x41, 0x3d, 0xc0, 0x53, 0x92,
+	0x86, 0x84, 0xf8, 0x2f, 0x42, 0xe2, 0x35, 0x61, 0x71, 0x5e, 0x72, 0x52, 0x50, 0xdc, 0x28, 0xd1,
+	0x0d, 0x4a, 0xcd, 0x15, 0x7f, 0x85, 0xc3, 0x8d, 0xfd, 0x24, 0xe6, 0x84, 0x89, 0xaa, 0x52, 0x53,
+	0x59, 0x4e, 0xc1, 0xdd, 0xbf, 0x57, 0xe1, 0x4e, 0xd6, 0x71, 0xf7, 0xc2, 0x80, 0x63, 0x1a, 0x10,
+	0x66, 0x04, 0x0d, 0x9d, 0xe2, 0x09, 0x19, 0xe4, 0xc7, 0xe7, 0x88, 0x3c, 0x3c, 0xaa, 0x37, 0x87,
+	0x47, 0x6d, 0x4e, 0x78, 0xd4, 0x6f, 0x0d, 0x8f, 0x85, 0x52, 0x78, 0x14, 0xcc, 0xda, 0xb8, 0x75,
+	0x04, 0x69, 0x96, 0xd2, 0xf9, 0xcf, 0xa0, 0x19, 0x46, 0xf2, 0x91, 0x74, 0x3a, 0xdc, 0x92, 0x1e,
+	0x79, 0x4a, 0x83, 0x49, 0xe2, 0x63, 0x46, 0xf9, 0xd5, 0xb1, 0x22, 0xbb, 0x29, 0xdf, 0x75, 0x15,
+	0xa0, 0x75, 0xed, 0xd4, 0xf2, 0xa7, 0x2a, 0xa0, 0x59, 0x45, 0xe2, 0x25, 0x70, 0x14, 0xa5, 0xbe,
+	0x85, 0xa3, 0x08, 0xfd, 0x18, 0x96, 0xb0, 0xef, 0x87, 0x6f, 0x9e, 0x07, 0x31, 0x9d, 0x04, 0x24,
+	0xed, 0x31, 0x8b, 0x48, 0x61, 0xd7, 0x11, 0x0d, 0xbc, 0x74, 0x1e, 0x51, 0x80, 0x7a, 0x45, 0x82,
+	0x59, 0x3f, 0x78, 0xad, 0x7b, 0x83, 0x0c, 0x16, 0xb4, 0x73, 0x7c, 0x49, 0xdc, 0x30, 0x54, 0x55,
+	0xc6, 0x72, 0x33, 0x58, 0xd0, 0x2e, 0xc2, 0x98, 0xcb, 0x27, 0xd4, 0x43, 0x47, 0x0a, 0x8b, 0x2f,
+	0xa4, 0xd1, 0x58, 0xda, 0xca, 0x72, 0xc5, 0x5f, 0x81, 0x89, 0xa8, 0x27, 0x4d, 0x64, 0xb9, 0xe2,
+	0xaf, 0xf0, 0xb4, 0x20, 0x3c, 0x61, 0xf4, 0xb5, 0x2a, 0x09, 0x96, 0x9b, 0x82, 0xf2, 0xa5, 0x18,
+	0xe5, 0x78, 0xe4, 0xab, 0x12, 0x60, 0xb9, 0x19, 0xdc, 0xfd, 0x1c, 0x9c, 0xeb, 0xdc, 0xea, 0xf6,
+	0x89, 0x6e, 0x00, 0x2b, 0x67, 0x98, 0xfa, 0x66, 0x39, 0xf8, 0x14, 0x1a, 0x78, 0x9c, 0xe5, 0xec,
+	0xe5, 0xfd, 0x15, 0xf9, 0x6a, 0x82, 0xeb, 0x40, 0xa2, 0x5d, 0x4d, 0xce, 0xea, 0x46, 0xd5, 0x28,
+	0x30, 0x0f, 0x00, 0xbe, 0xa3, 0xd1, 0x6d, 0x95, 0x65, 0x13, 0x1a, 0x1c, 0xb3, 0x09, 0xe1, 0x5a,
+	0x4e, 0x43, 0xdd, 0x25, 0x68, 0x4b, 0x49, 0x5d, 0x50, 0x1e, 0xc2, 0xe2, 0xf3, 0xe0, 0x6d, 0xae,
+	0x4a, 0xf4, 0x97, 0x32, 0x91, 0x65, 0xfd, 0xa5, 0x84, 0xae, 0xfd, 0x88, 0x15, 0x58, 0xd2, 0xb2,
+	0x5a, 0xd9, 0xbf, 0x9b, 0xd0, 0xd4, 0x3d, 0x36, 0x5a, 0x86, 0x6a, 0xd6, 0x51, 0x56, 0xa9, 0x27,
+	0x9a, 0xf7, 0x24, 0x26, 0x2c, 0xef, 0x47, 0x1a, 0x02, 0x54, 0x43, 0x89, 0xcc, 0x01, 0x35, 0x23,
+	0x07, 0x14, 0x6a, 0x70, 0xbd, 0x58, 0x83, 0x8d, 0x42, 0xbe, 0x70, 0x6b, 0x21, 0x2f, 0x35, 0x19,
+	0x8d, 0xf7, 0x6a, 0x32, 0x8a, 0x03, 0x72, 0xf3, 0x7d, 0x06, 0xe4, 0x2f, 0xc0, 0x62, 0x49, 0x60,
+	0xf6, 0x27, 0x77, 0x66, 0x04, 0x1f, 0xe9, 0x6d, 0x85, 0xdb, 0x64, 0x49, 0x20, 0xa5, 0x1e, 0x00,
+	0x08, 0x89, 0xa1, 0x4f, 0xa7, 0x94, 0xdb, 0xad, 0x79, 0x72, 0x2d, 0xc1, 0x7c, 0x24, 0x78, 0x45,
+	0xb7, 0x25, 0x56, 0x28, 0x34, 0x98, 0x0c, 0x3d, 0xca, 0x74, 0xcb, 0x02, 0x1a, 0xf5, 0x88, 0x32,
+	0x61, 0xfa, 0x98, 0x7b, 0xc3, 0x30, 0xe1, 0x76, 0x5b, 0x3f, 0x2a, 0xf7, 0x8e, 0x13, 0x9e, 0x12,
+	0x08, 0x63, 0xf6, 0x62, 0x46, 0xe8, 0x33, 0x56, 0xcc, 0x46, 0x4b, 0xd7, 0x64, 0xa3, 0x7c, 0xda,
+	0x5f, 0x2e, 0x4d, 0xfb, 0x1f, 0x01, 0x8c, 0x30, 0x1f, 0x5f, 0x0c, 0x45, 0x28, 0xda, 0x2b, 0x4a,
+	0x56, 0x62, 0x9e, 0x86, 0x6a, 0xde, 0x08, 0x92, 0xe9, 0x50, 0x65, 0xd7, 0x8e, 0x96, 0x4d, 0xa6,
+	0x22, 0x3f, 0xc6, 0xe8, 0x0e, 0x58, 0x6a, 0x16, 0xa5, 0x9e, 0xbd, 0xaa, 0x72, 0xbf, 0x84, 0x0f,
+	0xbd, 0x42, 0xd3, 0x87, 0xde, 0xbd, 0xe9, 0xdb, 0x84, 0x06, 0x23, 0x38, 0x0e, 0x03, 0x7b, 0x4d,
+	0x5d, 0x50, 0x41, 0xe8, 0x08, 0xd6, 0x49, 0xcc, 0xe9, 0x14, 0x8b, 0x49, 0xc1, 0x78, 0xe8, 0xf5,
+	0xb9, 0xaa, 0x51, 0x26, 0x77, 0x9a, 0xbd, 0xb8, 0x03, 0x56, 0xc4, 0x68, 0x28, 0x32, 0xa4, 0xbd,
+	0xa1, 0x12, 0x7b, 0x0a, 0x8b, 0x3b, 0x89, 0x0b, 0xcb, 0x95, 0xc9, 0xa6, 0xa4, 0x35, 0x83, 0x64,
+	0xda, 0x13, 0x5b, 0x93, 0x5d, 0xdd, 0x8b, 0x6c, 0xc9, 0x3e, 0x62, 0xd3, 0x1c, 0x51, 0x67, 0x3a,
+	0x10, 0x1b, 0x9a, 0x78, 0x3c, 0x0e, 0x93, 0x80, 0xdb, 0xb6, 0xb6, 0x8c, 0x02, 0x45, 0x5e, 0x7b,
+	0x15, 0xc6, 0xf6, 0x1d, 0x89, 0x15, 0x7f, 0x45, 0x96, 0x15, 0x21, 0x40, 0x6c, 0x47, 0xd5, 0x79,
+	0x09, 0xcc, 0x0e, 0xfa, 0x1f, 0xce, 0x0c, 0xfa, 0xff, 0x73, 0x1f, 0xd2, 0xfd, 0x67, 0x05, 0xda,
+	0xc6, 0xc0, 0x3b, 0x13, 0xfd, 0x69, 0x90, 0x57, 0x6f, 0x0a, 0xf2, 0x9a, 0x1a, 0xb3, 0xae, 0x09,
+	0xf2, 0xfa, 0xad, 0x41, 0x5e, 0x8c, 0xd3, 0x85, 0xf7, 0x89, 0x53, 0xd3, 0xa5, 0x1a, 0xef, 0xec,
+	0x52, 0xdd, 0x1f, 0xc2, 0x42, 0xef, 0x22, 0x09, 0x2e, 0xcd, 0xde, 0xbd, 0x52, 0xec, 0xdd, 0x4f,
+	0xa1, 0xa9, 0xdb, 0xda, 0xf7, 0xec, 0x70, 0x1c, 0xb0, 0x5e, 0x25, 0x38, 0xe0, 0xc2, 0x91, 0x54,
+	0x7f, 0x91, 0xc1, 0xdd, 0xbf, 0x56, 0xc1, 0x4a, 0x1b, 0x5a, 0xd9, 0xbe, 0x78, 0x3e, 0x19, 0x18,
+	0x3d, 0x7c, 0x8e, 0x10, 0x8d, 0x88, 0xa8, 0xbb, 0xe3, 0x81, 0xd1, 0xc3, 0x18, 0x18, 0x71, 0x8c,
+	0x60, 0x16, 0x4e, 0x98, 0x1e, 0x93, 0xc2, 0x42, 0xb3, 0xe4, 0xec, 0x45, 0xda, 0xf6, 0x35, 0x37,
+	0x47, 0x88, 0x4f, 0x17, 0x9c, 0xcf, 0xc8, 0x54, 0x77, 0x30, 0x29, 0x28, 0x74, 0x4a, 0x36, 0x41,
+	0x6a, 0x28, 0x9d, 0x29, 0x9c, 0x9e, 0xf7, 0x44, 0xa8, 0x6c, 0xe6, 0xe7, 0x3d, 0x31, 0xcf, 0x93,
+	0x44, 0xcb, 0x38, 0x4f, 0x52, 0xbf, 0x02, 0x48, 0x22, 0x4f, 0x46, 0x29, 0x4e, 0xb3, 0xe2, 0xad,
+	0xcf, 0xab, 0xb9, 0x0f, 0x78, 0xf7, 0xcf, 0x15, 0x68, 0x65, 0xf3, 0xc2, 0xff, 0x69, 0xe0, 0xd9,
+	0x86, 0xfa, 0x84, 0x11, 0xd5, 0x7b, 0x96, 0x07, 0x1a, 0x49, 0x91, 0xfd, 0x8b, 0x39, 0xf6, 0xb4,
+	0xf2, 0x41, 0x67, 0x77, 0x0f, 0x20, 0x2f, 0xf7, 0xa8, 0x05, 0x0b, 0x32, 0xa3, 0x74, 0x3e, 0x40,
+	0x1b, 0x62, 0x64, 0xc3, 0xde, 0x59, 0xd8, 0x0f, 0xbc, 0x83, 0xc0, 0xeb, 0xf9, 0x61, 0x4c, 0x3a,
+	0x95, 0xdd, 0xdf, 0x41, 0x2b, 0x0b, 0x00, 0xb4, 0x04, 0xad, 0xde, 0xf1, 0xb3, 0x93, 0xa3, 0xfe,
+	0x59, 0xff, 0x51, 0xe7, 0x03, 0x09, 0x1e, 0x0c, 0x7a, 0xfd, 0xa3, 0xa3, 0xfe, 0xa3, 0x4e, 0x05,
+	0x01, 0x34, 0x1e, 0x1f, 0x1c, 0x8a, 0xff, 0x55, 0xd4, 0x86, 0xe6, 0xd9, 0xe1, 0xb3, 0xfe, 0xf1,
+	0xf3, 0xb3, 0x4e, 0x4d, 0x00, 0x27, 0xfd, 0xc1, 0xa3, 0xc3, 0xc1, 0x93, 0x4e, 0x5d, 0x00, 0xcf,
+	0x07, 0xdf, 0x0e, 0x8e, 0x5f, 0x0c, 0x3a, 0xb0, 0xff, 0x37, 0x80, 0x95, 0xb4, 0x49, 0x7f, 0x86,
+	0x03, 0x3c, 0x21, 0x0c, 0x3d, 0x84, 0x56, 0xd6, 0xeb, 0xa0, 0x0d, 0xd5, 0x56, 0x96, 0x96, 0xea,
+	0xce, 0x66, 0x19, 0xad, 0x3b, 0xa1, 0xe7, 0x80, 0x32, 0x64, 0xd6, 0x27, 0xa1, 0x8f, 0x8b, 0xdc,
+	0xe5, 0xbe, 0xdc, 0xb9, 0x7b, 0x23, 0x5d, 0xab, 0xfd, 0x35, 0xb4, 0x8d, 0xd5, 0xf8, 0x4d, 0x1f,
+	0x65, 0x4b, 0xf4, 0x75, 0x3b, 0xf4, 0x87, 0xd0, 0xca, 0x96, 0xc8, 0x5a, 0xba, 0xbc, 0x7f, 0x76,
+	0x36, 0xcb, 0x68, 0x2d, 0xfb, 0x05, 0x34, 0xf5, 0x0a, 0x19, 0xad, 0x49, 0x96, 0xe2, 0xe6, 0xd9,
+	0x59, 0x2f, 0x22, 0xb5, 0xd4, 0xaf, 0x00, 0xf2, 0xcd, 0x31, 0xda, 0xd4, 0xe3, 0x62, 0x69, 0xed,
+	0xec, 0x6c, 0xcd, 0xe0, 0x73, 0xf1, 0x7c, 0x6d, 0x8c, 0x52, 0x6b, 0x97, 0x76, 0xce, 0xce, 0xd6,
+	0x0c, 0x3e, 0xbf, 0x6f, 0xb6, 0x34, 0xd6, 0xf7, 0x2d, 0xef, 0x9b, 0x9d, 0xcd, 0x32, 0xda, 0xfc,
+	0xf2, 0x74, 0xef, 0x9b, 0x7d, 0x79, 0x69, 0xd9, 0xec, 0x6c, 0xcd, 0xe0, 0x73, 0x73, 0xa5, 0xdd,
+	0xe0, 0x5a, 0x71, 0xff, 0x6a, 0x9a, 0xab, 0xbc, 0x1a, 0xfe, 0x12, 0xac, 0x74, 0x99, 0x8b, 0x32,
+	0x0e, 0x73, 0x0f, 0xec, 0x6c, 0x94, 0xb0, 0x05, 0x41, 0xb9, 0x66, 0xcd, 0x05, 0xcd, 0xb5, 0xae,
+	0xb3, 0x51, 0xc2, 0xe6, 0xd7, 0xcc, 0x77, 0x76, 0x28, 0xab, 0xc3, 0xc5, 0x4d, 0xa9, 0xb3, 0x35,
+	0x83, 0xcf, 0x2d, 0x9c, 0xed, 0x69, 0x51, 0x7e, 0x84, 0xb9, 0xe2, 0x75, 0x36, 0xcb, 0x68, 0x2d,
+	0xbb, 0x07, 0x56, 0xba, 0x4f, 0xd2, 0xdf, 0x5c, 0x5a, 0x2f, 0x39, 0xa0, 0x7c, 0x51, 0x54, 0x9c,
+	0xfb, 0x15, 0x74, 0x1f, 0xac, 0x74, 0x8e, 0xd0, 0xfc, 0xa5, 0xb1, 0xc2, 0xe4, 0xdf, 0xa9, 0xdc,
+	0xaf, 0xa0, 0xdf, 0x00, 0xe4, 0x7b, 0x24, 0x7d, 0xb9, 0x99, 0xdd, 0x94, 0xb3, 0x35, 0x83, 0x57,
+	0x1f, 0xb8, 0x53, 0x41, 0x3b, 0x50, 0xfb, 0x8e, 0x46, 0x48, 0x8d, 0x27, 0xf9, 0xd0, 0xe1, 0x74,
+	0x72, 0x44, 0x76, 0x99, 0x05, 0x39, 0x0f, 0xa0, 0x55, 0x49, 0x32, 0xe7, 0x0a, 0x07, 0x99, 0xa8,
+	0x82, 0x6b, 0xaa, 0xb5, 0x49, 0xee, 0x9a, 0x85, 0xe5, 0x96, 0xb3, 0x59, 0x46, 0xe7, 0x6f, 0x96,
+	0x2f, 0x27, 0xf4, 0xb5, 0x66, 0x56, 0x18, 0xce, 0xd6, 0x0c, 0x5e, 0x8b, 0xf7, 0x60, 0xd1, 0x5c,
+	0x48, 0x20, 0x95, 0x2f, 0xae, 0x59, 0x5d, 0x38, 0x77, 0xae, 0xa1, 0x28, 0x25, 0xa3, 0x86, 0x2c,
+	0x40, 0x9f, 0xff, 0x77, 0x00, 0x1c, 0x2d, 0x3f, 0x16, 0xd3, 0x1c, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1780,14 +3123,37 @@ type WorkloadManagerClient interface {
 	// SubmitJobContainer submits new job to the workload manager and
 	// returns job id that can be used to track job status.
 	SubmitJobContainer(ctx context.Context, in *SubmitJobContainerRequest, opts ...grpc.CallOption) (*SubmitJobContainerResponse, error)
+	// ValidateJob checks whether a job would be accepted by the workload manager
+	// without submitting it and estimates when it would start. Request is the same
+	// as for SubmitJob, idempotency key is ignored.
+	ValidateJob(ctx context.Context, in *SubmitJobRequest, opts ...grpc.CallOption) (*ValidateJobResponse, error)
 	// CancelJob cancels job by job id.
 	CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*CancelJobResponse, error)
+	// HoldJob prevents a pending job from being started.
+	HoldJob(ctx context.Context, in *HoldJobRequest, opts ...grpc.CallOption) (*HoldJobResponse, error)
+	// ReleaseJob releases a previously held job.
+	ReleaseJob(ctx context.Context, in *ReleaseJobRequest, opts ...grpc.CallOption) (*ReleaseJobResponse, error)
+	// SuspendJob suspends a running job, the job keeps its allocation.
+	SuspendJob(ctx context.Context, in *SuspendJobRequest, opts ...grpc.CallOption) (*SuspendJobResponse, error)
+	// ResumeJob resumes a previously suspended job.
+	ResumeJob(ctx context.Context, in *ResumeJobRequest, opts ...grpc.CallOption) (*ResumeJobResponse, error)
+	// RequeueJob puts a running job back to the queue releasing its allocation.
+	RequeueJob(ctx context.Context, in *RequeueJobRequest, opts ...grpc.CallOption) (*RequeueJobResponse, error)
 	// JobInfo returns complete information about a particular job.
 	// In case of JobArray the first job in slice is a root.
 	// JobInfoResponse have to contain at least one element
 	JobInfo(ctx context.Context, in *JobInfoRequest, opts ...grpc.CallOption) (*JobInfoResponse, error)
+	// JobsInfo returns information about several jobs at once.
+	// Workload manager may answer it from a periodically taken snapshot.
+	JobsInfo(ctx context.Context, in *JobsInfoRequest, opts ...grpc.CallOption) (*JobsInfoResponse, error)
 	// JobSteps returns information about each individual job step.
 	JobSteps(ctx context.Context, in *JobStepsRequest, opts ...grpc.CallOption) (*JobStepsResponse, error)
+	// JobHistory returns jobs submitted through the workload manager,
+	// including ones that are long gone from the workload manager itself.
+	JobHistory(ctx context.Context, in *JobHistoryRequest, opts ...grpc.CallOption) (*JobHistoryResponse, error)
+	// JobScript returns batch script of a job verbatim as the workload
+	// manager received it, along with job environment where available.
+	JobScript(ctx context.Context, in *JobScriptRequest, opts ...grpc.CallOption) (*JobScriptResponse, error)
 	// OpenFile opens a file and streams its content back. May be
 	// useful for results collecting.
 	OpenFile(ctx context.Context, in *OpenFileRequest, opts ...grpc.CallOption) (WorkloadManager_OpenFileClient, error)
@@ -1837,6 +3203,15 @@ func (c *workloadManagerClient) SubmitJobContainer(ctx context.Context, in *Subm
 	return out, nil
 }
 
+func (c *workloadManagerClient) ValidateJob(ctx context.Context, in *SubmitJobRequest, opts ...grpc.CallOption) (*ValidateJobResponse, error) {
+	out := new(ValidateJobResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkloadManager/ValidateJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *workloadManagerClient) CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*CancelJobResponse, error) {
 	out := new(CancelJobResponse)
 	err := c.cc.Invoke(ctx, "/api.WorkloadManager/CancelJob", in, out, opts...)
@@ -1846,6 +3221,51 @@ func (c *workloadManagerClient) CancelJob(ctx context.Context, in *CancelJobRequ
 	return out, nil
 }
 
+func (c *workloadManagerClient) HoldJob(ctx context.Context, in *HoldJobRequest, opts ...grpc.CallOption) (*HoldJobResponse, error) {
+	out := new(HoldJobResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkloadManager/HoldJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workloadManagerClient) ReleaseJob(ctx context.Context, in *ReleaseJobRequest, opts ...grpc.CallOption) (*ReleaseJobResponse, error) {
+	out := new(ReleaseJobResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkloadManager/ReleaseJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workloadManagerClient) SuspendJob(ctx context.Context, in *SuspendJobRequest, opts ...grpc.CallOption) (*SuspendJobResponse, error) {
+	out := new(SuspendJobResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkloadManager/SuspendJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workloadManagerClient) ResumeJob(ctx context.Context, in *ResumeJobRequest, opts ...grpc.CallOption) (*ResumeJobResponse, error) {
+	out := new(ResumeJobResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkloadManager/ResumeJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workloadManagerClient) RequeueJob(ctx context.Context, in *RequeueJobRequest, opts ...grpc.CallOption) (*RequeueJobResponse, error) {
+	out := new(RequeueJobResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkloadManager/RequeueJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *workloadManagerClient) JobInfo(ctx context.Context, in *JobInfoRequest, opts ...grpc.CallOption) (*JobInfoResponse, error) {
 	out := new(JobInfoResponse)
 	err := c.cc.Invoke(ctx, "/api.WorkloadManager/JobInfo", in, out, opts...)
@@ -1855,6 +3275,15 @@ func (c *workloadManagerClient) JobInfo(ctx context.Context, in *JobInfoRequest,
 	return out, nil
 }
 
+func (c *workloadManagerClient) JobsInfo(ctx context.Context, in *JobsInfoRequest, opts ...grpc.CallOption) (*JobsInfoResponse, error) {
+	out := new(JobsInfoResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkloadManager/JobsInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *workloadManagerClient) JobSteps(ctx context.Context, in *JobStepsRequest, opts ...grpc.CallOption) (*JobStepsResponse, error) {
 	out := new(JobStepsResponse)
 	err := c.cc.Invoke(ctx, "/api.WorkloadManager/JobSteps", in, out, opts...)
@@ -1864,6 +3293,24 @@ func (c *workloadManagerClient) JobSteps(ctx context.Context, in *JobStepsReques
 	return out, nil
 }
 
+func (c *workloadManagerClient) JobHistory(ctx context.Context, in *JobHistoryRequest, opts ...grpc.CallOption) (*JobHistoryResponse, error) {
+	out := new(JobHistoryResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkloadManager/JobHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workloadManagerClient) JobScript(ctx context.Context, in *JobScriptRequest, opts ...grpc.CallOption) (*JobScriptResponse, error) {
+	out := new(JobScriptResponse)
+	err := c.cc.Invoke(ctx, "/api.WorkloadManager/JobScript", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *workloadManagerClient) OpenFile(ctx context.Context, in *OpenFileRequest, opts ...grpc.CallOption) (WorkloadManager_OpenFileClient, error) {
 	stream, err := c.cc.NewStream(ctx, &_WorkloadManager_serviceDesc.Streams[0], "/api.WorkloadManager/OpenFile", opts...)
 	if err != nil {
@@ -2014,14 +3461,37 @@ type WorkloadManagerServer interface {
 	// SubmitJobContainer submits new job to the workload manager and
 	// returns job id that can be used to track job status.
 	SubmitJobContainer(context.Context, *SubmitJobContainerRequest) (*SubmitJobContainerResponse, error)
+	// ValidateJob checks whether a job would be accepted by the workload manager
+	// without submitting it and estimates when it would start. Request is the same
+	// as for SubmitJob, idempotency key is ignored.
+	ValidateJob(context.Context, *SubmitJobRequest) (*ValidateJobResponse, error)
 	// CancelJob cancels job by job id.
 	CancelJob(context.Context, *CancelJobRequest) (*CancelJobResponse, error)
+	// HoldJob prevents a pending job from being started.
+	HoldJob(context.Context, *HoldJobRequest) (*HoldJobResponse, error)
+	// ReleaseJob releases a previously held job.
+	ReleaseJob(context.Context, *ReleaseJobRequest) (*ReleaseJobResponse, error)
+	// SuspendJob suspends a running job, the job keeps its allocation.
+	SuspendJob(context.Context, *SuspendJobRequest) (*SuspendJobResponse, error)
+	// ResumeJob resumes a previously suspended job.
+	ResumeJob(context.Context, *ResumeJobRequest) (*ResumeJobResponse, error)
+	// RequeueJob puts a running job back to the queue releasing its allocation.
+	RequeueJob(context.Context, *RequeueJobRequest) (*RequeueJobResponse, error)
 	// JobInfo returns complete information about a particular job.
 	// In case of JobArray the first job in slice is a root.
 	// JobInfoResponse have to contain at least one element
 	JobInfo(context.Context, *JobInfoRequest) (*JobInfoResponse, error)
+	// JobsInfo returns information about several jobs at once.
+	// Workload manager may answer it from a periodically taken snapshot.
+	JobsInfo(context.Context, *JobsInfoRequest) (*JobsInfoResponse, error)
 	// JobSteps returns information about each individual job step.
 	JobSteps(context.Context, *JobStepsRequest) (*JobStepsResponse, error)
+	// JobHistory returns jobs submitted through the workload manager,
+	// including ones that are long gone from the workload manager itself.
+	JobHistory(context.Context, *JobHistoryRequest) (*JobHistoryResponse, error)
+	// JobScript returns batch script of a job verbatim as the workload
+	// manager received it, along with job environment where available.
+	JobScript(context.Context, *JobScriptRequest) (*JobScriptResponse, error)
 	// OpenFile opens a file and streams its content back. May be
 	// useful for results collecting.
 	OpenFile(*OpenFileRequest, WorkloadManager_OpenFileServer) error
@@ -2085,6 +3555,24 @@ func _WorkloadManager_SubmitJobContainer_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WorkloadManager_ValidateJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkloadManagerServer).ValidateJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkloadManager/ValidateJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkloadManagerServer).ValidateJob(ctx, req.(*SubmitJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _WorkloadManager_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CancelJobRequest)
 	if err := dec(in); err != nil {
@@ -2103,6 +3591,96 @@ func _WorkloadManager_CancelJob_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WorkloadManager_HoldJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HoldJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkloadManagerServer).HoldJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkloadManager/HoldJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkloadManagerServer).HoldJob(ctx, req.(*HoldJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkloadManager_ReleaseJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkloadManagerServer).ReleaseJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkloadManager/ReleaseJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkloadManagerServer).ReleaseJob(ctx, req.(*ReleaseJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkloadManager_SuspendJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuspendJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkloadManagerServer).SuspendJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkloadManager/SuspendJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkloadManagerServer).SuspendJob(ctx, req.(*SuspendJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkloadManager_ResumeJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkloadManagerServer).ResumeJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkloadManager/ResumeJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkloadManagerServer).ResumeJob(ctx, req.(*ResumeJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkloadManager_RequeueJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequeueJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkloadManagerServer).RequeueJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkloadManager/RequeueJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkloadManagerServer).RequeueJob(ctx, req.(*RequeueJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _WorkloadManager_JobInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(JobInfoRequest)
 	if err := dec(in); err != nil {
@@ -2121,6 +3699,24 @@ func _WorkloadManager_JobInfo_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WorkloadManager_JobsInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobsInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkloadManagerServer).JobsInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkloadManager/JobsInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkloadManagerServer).JobsInfo(ctx, req.(*JobsInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _WorkloadManager_JobSteps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(JobStepsRequest)
 	if err := dec(in); err != nil {
@@ -2139,6 +3735,42 @@ func _WorkloadManager_JobSteps_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WorkloadManager_JobHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkloadManagerServer).JobHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkloadManager/JobHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkloadManagerServer).JobHistory(ctx, req.(*JobHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkloadManager_JobScript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobScriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkloadManagerServer).JobScript(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.WorkloadManager/JobScript",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkloadManagerServer).JobScript(ctx, req.(*JobScriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _WorkloadManager_OpenFile_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(OpenFileRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -2314,18 +3946,54 @@ var _WorkloadManager_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SubmitJobContainer",
 			Handler:    _WorkloadManager_SubmitJobContainer_Handler,
 		},
+		{
+			MethodName: "ValidateJob",
+			Handler:    _WorkloadManager_ValidateJob_Handler,
+		},
 		{
 			MethodName: "CancelJob",
 			Handler:    _WorkloadManager_CancelJob_Handler,
 		},
+		{
+			MethodName: "HoldJob",
+			Handler:    _WorkloadManager_HoldJob_Handler,
+		},
+		{
+			MethodName: "ReleaseJob",
+			Handler:    _WorkloadManager_ReleaseJob_Handler,
+		},
+		{
+			MethodName: "SuspendJob",
+			Handler:    _WorkloadManager_SuspendJob_Handler,
+		},
+		{
+			MethodName: "ResumeJob",
+			Handler:    _WorkloadManager_ResumeJob_Handler,
+		},
+		{
+			MethodName: "RequeueJob",
+			Handler:    _WorkloadManager_RequeueJob_Handler,
+		},
 		{
 			MethodName: "JobInfo",
 			Handler:    _WorkloadManager_JobInfo_Handler,
 		},
+		{
+			MethodName: "JobsInfo",
+			Handler:    _WorkloadManager_JobsInfo_Handler,
+		},
 		{
 			MethodName: "JobSteps",
 			Handler:    _WorkloadManager_JobSteps_Handler,
 		},
+		{
+			MethodName: "JobHistory",
+			Handler:    _WorkloadManager_JobHistory_Handler,
+		},
+		{
+			MethodName: "JobScript",
+			Handler:    _WorkloadManager_JobScript_Handler,
+		},
 		{
 			MethodName: "Zip",
 			Handler:    _WorkloadManager_Zip_Handler,